@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/brainwhocodes/screenshot_mcp_server/internal/agent"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/screenshot"
 )
 
 func main() {
@@ -25,7 +26,7 @@ func run(args []string, stderr io.Writer) int {
 		return handleRunParseError(stderr, err)
 	}
 
-	ag := agent.NewAgent(agent.Config{
+	cfg := agent.Config{
 		Goal:          parsed.goal,
 		WindowTitle:   parsed.windowTitle,
 		OwnerName:     parsed.ownerName,
@@ -34,7 +35,18 @@ func run(args []string, stderr io.Writer) int {
 		RunDir:        parsed.runDir,
 		SaveArtifacts: parsed.saveArtifacts,
 		VisionClient:  parsed.visionClient,
-	})
+	}
+	if parsed.replay.Source != "" {
+		replay, err := screenshot.LoadReplay(parsed.replay)
+		if err != nil {
+			_ = writeStderrLine(stderr, fmt.Sprintf("Error: %v", err))
+			return 2
+		}
+		cfg.Frames = replay
+		cfg.ActionExecutor = agent.NoopActionExecutor{}
+		_ = writeStderrLine(stderr, fmt.Sprintf("Replay mode: screenshots play back %d frames from %s", replay.Len(), parsed.replay.Source))
+	}
+	ag := agent.NewAgent(cfg)
 
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
@@ -75,6 +87,7 @@ type parsedAgentArgs struct {
 	runDir        string
 	saveArtifacts bool
 	visionClient  agent.VisionClient
+	replay        screenshot.ReplayOptions
 }
 
 func parseAgentArgs(args []string) (parsedAgentArgs, error) {
@@ -90,6 +103,9 @@ func parseAgentArgs(args []string) (parsedAgentArgs, error) {
 	apiKey := fs.String("api-key", "", "OpenAI API key (or set OPENAI_API_KEY env)")
 	model := fs.String("model", "gpt-4o", "OpenAI model to use")
 	dryRun := fs.Bool("dry-run", false, "Run without executing actions (for testing)")
+	var replay screenshot.ReplayOptions
+	fs.StringVar(&replay.Source, "replay", "", "Directory or JSON manifest of frames to use instead of window screenshots")
+	fs.StringVar(&replay.Mode, "replay-mode", "", "Replay advance mode: capture or time")
 
 	if err := fs.Parse(args); err != nil {
 		return parsedAgentArgs{}, fmt.Errorf("parse flags: %w", err)
@@ -97,6 +113,9 @@ func parseAgentArgs(args []string) (parsedAgentArgs, error) {
 	if *goal == "" {
 		return parsedAgentArgs{}, fmt.Errorf("-goal is required")
 	}
+	if err := screenshot.ValidateReplayMode(replay.Mode); err != nil {
+		return parsedAgentArgs{}, fmt.Errorf("-replay-mode: %w", err)
+	}
 
 	key := *apiKey
 	if key == "" {
@@ -127,6 +146,7 @@ func parseAgentArgs(args []string) (parsedAgentArgs, error) {
 		runDir:        *runDir,
 		saveArtifacts: saveArtifacts,
 		visionClient:  visionClient,
+		replay:        replay,
 	}, nil
 }
 
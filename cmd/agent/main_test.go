@@ -1,8 +1,11 @@
 package main
 
 import (
+	"image"
+	"image/png"
 	"io"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -27,3 +30,32 @@ func TestAgentCLI_MissingGoal(t *testing.T) {
 		t.Errorf("expected exit code 2 for missing goal, got %d", code)
 	}
 }
+
+func TestAgentCLI_Replay(t *testing.T) {
+	frames := t.TempDir()
+	file, err := os.Create(filepath.Join(frames, "01.png"))
+	if err != nil {
+		t.Fatalf("create frame: %v", err)
+	}
+	if err := png.Encode(file, image.NewRGBA(image.Rect(0, 0, 16, 9))); err != nil {
+		t.Fatalf("encode frame: %v", err)
+	}
+	_ = file.Close()
+
+	// No window lookup happens, so this runs without a display.
+	runDir := t.TempDir()
+	code := run([]string{"-goal", "replay", "-dry-run", "-replay", frames, "-run-dir", runDir}, io.Discard)
+	if code != 0 {
+		t.Fatalf("expected exit code 0 under replay, got %d", code)
+	}
+	if _, err := os.Stat(filepath.Join(runDir, "step_0001.jpg")); err != nil {
+		t.Fatalf("expected the replayed frame as the step screenshot: %v", err)
+	}
+}
+
+func TestAgentCLI_BadReplayMode(t *testing.T) {
+	code := run([]string{"-goal", "replay", "-dry-run", "-replay-mode", "sometimes"}, io.Discard)
+	if code != 2 {
+		t.Errorf("expected exit code 2 for an unknown replay mode, got %d", code)
+	}
+}
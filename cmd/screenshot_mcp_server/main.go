@@ -0,0 +1,451 @@
+// Package main implements the screenshot MCP server CLI entrypoint.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/client"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/config"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/mcpserver"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/screenshot"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/tools"
+)
+
+const usageText = `Usage:
+  screenshot_mcp_server server [SERVER OPTIONS]
+  screenshot_mcp_server sse [--port PORT] [HTTP OPTIONS] [SERVER OPTIONS]
+  screenshot_mcp_server streamable-http [--port PORT] [HTTP OPTIONS] [SERVER OPTIONS]
+  screenshot_mcp_server client [OUTPUT_PATH]
+
+Server options:
+  --config PATH            Load settings from a JSON or YAML file; flags override it
+  --experimental           Register experimental tools
+  --dry-run                Log input, process and clipboard-write actions instead of executing them
+  --run-dir DIR            Restrict screenshot and fixture file operations to DIR
+  --audit-log PATH         Append a JSONL record of every tool call to PATH
+  --tool-profile NAME      Base tool set: full (default) or observe (read-only)
+  --allow-tools A,B        Enable extra tools on top of the profile (repeatable)
+  --deny-tools A,B         Disable tools; wins over the profile and --allow-tools (repeatable)
+  --capture-concurrency N  Template-matching and OCR calls allowed at once (default 2)
+  --capture-cache-max-age D  Share captured frames between calls for D (default 50ms, 0 disables)
+  --replay PATH            Play back frames from a directory or JSON manifest instead of the screen
+  --replay-mode MODE       Replay advance: capture (one frame per capture, default) or time
+
+HTTP options:
+  --bind ADDR              Interface to listen on (default 127.0.0.1)
+  --auth-token TOKEN       Require "Authorization: Bearer TOKEN" (or set SCREENSHOT_MCP_AUTH_TOKEN)
+  --auth-token-file PATH   Read the bearer token from a file
+  --allowed-origin ORIGIN  Allow a browser Origin (repeatable)
+  --allowed-host HOST      Allow a Host header value (repeatable)
+  --tls-cert PATH          Serve HTTPS with this PEM certificate (requires --tls-key)
+  --tls-key PATH           PEM private key for --tls-cert
+  --unix-socket PATH       Listen on a Unix domain socket instead of --bind/--port
+  --unix-socket-mode MODE  Octal permissions for the socket file (default 0600)
+`
+
+// authTokenEnv supplies the bearer token without exposing it in the process list.
+const authTokenEnv = "SCREENSHOT_MCP_AUTH_TOKEN"
+
+const defaultClientOutputPath = "screenshot.jpg"
+
+var errUsage = errors.New("usage")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stderr))
+}
+
+func run(args []string, stderr io.Writer) int {
+	parsed, err := parseCommandArgs(args)
+	if err != nil {
+		return handleRunParseError(stderr, err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	return runCommand(ctx, stderr, parsed)
+}
+
+func handleRunParseError(stderr io.Writer, err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		_ = writeStderrf(stderr, "%s", usageText)
+		return 0
+	}
+	if errors.Is(err, errUsage) {
+		_ = writeStderrf(stderr, "%s", usageText)
+		return 2
+	}
+	_ = writeStderrLine(stderr, fmt.Sprintf("Error: %v", err))
+	return 2
+}
+
+type parsedCommandArgs struct {
+	command    string
+	runDir     string
+	auditLog   string
+	replay     screenshot.ReplayOptions
+	port       int
+	outputPath string
+	server     mcpserver.Config
+}
+
+// stringListFlag collects repeated, comma-separated flag values.
+type stringListFlag []string
+
+func (f *stringListFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringListFlag) Set(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*f = append(*f, item)
+		}
+	}
+	return nil
+}
+
+func parseCommandArgs(args []string) (parsedCommandArgs, error) {
+	if len(args) == 0 {
+		return parsedCommandArgs{}, errUsage
+	}
+
+	command := args[0]
+	switch command {
+	case "-h", "-help", "--help", "help":
+		return parsedCommandArgs{}, flag.ErrHelp
+	case "server", "sse", "streamable-http":
+		return parseServerArgs(command, args[1:])
+	case "client":
+		return parseClientArgs(args[1:])
+	default:
+		return parsedCommandArgs{}, fmt.Errorf("unknown command %q", command)
+	}
+}
+
+func parseServerArgs(command string, args []string) (parsedCommandArgs, error) {
+	fs := flag.NewFlagSet(command, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	// Flags are parsed into their own values and only override the config file when set.
+	var flagCfg mcpserver.Config
+	var runDir, auditLog string
+	var replay screenshot.ReplayOptions
+	port := mcpserver.DefaultSSEPort
+	var allowedTools, deniedTools, allowedOrigins, allowedHosts stringListFlag
+	configPath := fs.String("config", "", "JSON or YAML server configuration file")
+	fs.BoolVar(&flagCfg.ExperimentalTools, "experimental", false, "Register experimental tools")
+	fs.BoolVar(&flagCfg.DryRun, "dry-run", false, "Log input, process and clipboard-write actions instead of executing them")
+	fs.StringVar(&runDir, "run-dir", "", "Directory that screenshot and fixture file operations are restricted to")
+	fs.StringVar(&auditLog, "audit-log", "", "Append a JSONL record of every tool call to this file")
+	fs.StringVar(&flagCfg.ToolProfile, "tool-profile", mcpserver.ToolProfileFull, "Base tool set")
+	fs.Var(&allowedTools, "allow-tools", "Tools to enable on top of the profile")
+	fs.Var(&deniedTools, "deny-tools", "Tools to disable")
+	fs.IntVar(&flagCfg.CaptureConcurrency, "capture-concurrency", mcpserver.DefaultCaptureConcurrency, "Concurrent template-matching and OCR tool calls")
+	fs.StringVar(&replay.Source, "replay", "", "Directory or JSON manifest of frames to play back instead of the screen")
+	fs.StringVar(&replay.Mode, "replay-mode", "", "Replay advance mode: capture or time")
+	fs.DurationVar(&flagCfg.CaptureCacheMaxAge, "capture-cache-max-age", screenshot.DefaultCacheMaxAge, "How long captured frames are shared between tool calls")
+	if command != "server" {
+		fs.IntVar(&port, "port", mcpserver.DefaultSSEPort, "Port for the HTTP server")
+		fs.StringVar(&flagCfg.HTTP.BindAddress, "bind", mcpserver.DefaultBindAddress, "Interface to listen on")
+		fs.StringVar(&flagCfg.HTTP.AuthToken, "auth-token", "", "Bearer token required from clients")
+		fs.StringVar(&flagCfg.HTTP.AuthTokenFile, "auth-token-file", "", "File containing the bearer token")
+		fs.Var(&allowedOrigins, "allowed-origin", "Allowed browser Origin (repeatable)")
+		fs.Var(&allowedHosts, "allowed-host", "Allowed Host header value (repeatable)")
+		fs.StringVar(&flagCfg.HTTP.TLSCertFile, "tls-cert", "", "PEM certificate file for HTTPS")
+		fs.StringVar(&flagCfg.HTTP.TLSKeyFile, "tls-key", "", "PEM private key file for HTTPS")
+		fs.StringVar(&flagCfg.HTTP.UnixSocket, "unix-socket", "", "Unix domain socket path")
+		fs.Func("unix-socket-mode", "Octal permissions for the Unix socket", func(value string) error {
+			mode, err := strconv.ParseUint(value, 8, 32)
+			if err != nil || mode > 0o777 {
+				return fmt.Errorf("invalid socket mode %q", value)
+			}
+			flagCfg.HTTP.UnixSocketMode = os.FileMode(mode)
+			return nil
+		})
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return parsedCommandArgs{}, fmt.Errorf("parse flags: %w", err)
+	}
+	if fs.NArg() > 0 {
+		return parsedCommandArgs{}, fmt.Errorf("unexpected arguments for %s: %v", command, fs.Args())
+	}
+	if flagCfg.CaptureCacheMaxAge < 0 {
+		return parsedCommandArgs{}, fmt.Errorf("--capture-cache-max-age must be >= 0")
+	}
+
+	parsed := parsedCommandArgs{command: command, port: mcpserver.DefaultSSEPort}
+	if *configPath != "" {
+		file, err := config.Load(*configPath)
+		if err != nil {
+			return parsedCommandArgs{}, fmt.Errorf("load config: %w", err)
+		}
+		parsed.server = file.ServerConfig()
+		parsed.runDir = file.RunDir
+		parsed.auditLog = file.AuditLog
+		parsed.replay = file.Replay.Options()
+		if file.Transport.Port != 0 {
+			parsed.port = file.Transport.Port
+		}
+	}
+
+	overrides := map[string]func(){
+		"experimental":          func() { parsed.server.ExperimentalTools = flagCfg.ExperimentalTools },
+		"dry-run":               func() { parsed.server.DryRun = flagCfg.DryRun },
+		"run-dir":               func() { parsed.runDir = runDir },
+		"audit-log":             func() { parsed.auditLog = auditLog },
+		"replay":                func() { parsed.replay.Source = replay.Source },
+		"replay-mode":           func() { parsed.replay.Mode = replay.Mode },
+		"tool-profile":          func() { parsed.server.ToolProfile = flagCfg.ToolProfile },
+		"allow-tools":           func() { parsed.server.AllowedTools = allowedTools },
+		"deny-tools":            func() { parsed.server.DeniedTools = deniedTools },
+		"capture-concurrency":   func() { parsed.server.CaptureConcurrency = flagCfg.CaptureConcurrency },
+		"capture-cache-max-age": func() { parsed.server.CaptureCacheMaxAge = cacheMaxAgeFromFlag(flagCfg.CaptureCacheMaxAge) },
+		"port":                  func() { parsed.port = port },
+		"bind":                  func() { parsed.server.HTTP.BindAddress = flagCfg.HTTP.BindAddress },
+		"auth-token":            func() { parsed.server.HTTP.AuthToken = flagCfg.HTTP.AuthToken },
+		"auth-token-file":       func() { parsed.server.HTTP.AuthTokenFile = flagCfg.HTTP.AuthTokenFile },
+		"allowed-origin":        func() { parsed.server.HTTP.AllowedOrigins = allowedOrigins },
+		"allowed-host":          func() { parsed.server.HTTP.AllowedHosts = allowedHosts },
+		"tls-cert":              func() { parsed.server.HTTP.TLSCertFile = flagCfg.HTTP.TLSCertFile },
+		"tls-key":               func() { parsed.server.HTTP.TLSKeyFile = flagCfg.HTTP.TLSKeyFile },
+		"unix-socket":           func() { parsed.server.HTTP.UnixSocket = flagCfg.HTTP.UnixSocket },
+		"unix-socket-mode":      func() { parsed.server.HTTP.UnixSocketMode = flagCfg.HTTP.UnixSocketMode },
+	}
+	setFlags := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		setFlags[f.Name] = true
+		if override, ok := overrides[f.Name]; ok {
+			override()
+		}
+	})
+	// A token source given on the command line replaces the other source from the file.
+	if setFlags["auth-token"] && !setFlags["auth-token-file"] {
+		parsed.server.HTTP.AuthTokenFile = ""
+	}
+	if setFlags["auth-token-file"] && !setFlags["auth-token"] {
+		parsed.server.HTTP.AuthToken = ""
+	}
+	return finalizeServerArgs(parsed)
+}
+
+// cacheMaxAgeFromFlag maps an explicit --capture-cache-max-age 0 to a negative
+// value, because zero in mcpserver.Config means the default.
+func cacheMaxAgeFromFlag(maxAge time.Duration) time.Duration {
+	if maxAge == 0 {
+		return -1
+	}
+	return maxAge
+}
+
+// finalizeServerArgs fills remaining defaults and validates the merged configuration.
+func finalizeServerArgs(parsed parsedCommandArgs) (parsedCommandArgs, error) {
+	httpCfg := &parsed.server.HTTP
+	if httpCfg.BindAddress == "" {
+		httpCfg.BindAddress = mcpserver.DefaultBindAddress
+	}
+	if httpCfg.AuthToken == "" && httpCfg.AuthTokenFile == "" {
+		httpCfg.AuthToken = os.Getenv(authTokenEnv)
+	}
+	if parsed.port <= 0 || parsed.port > 65535 {
+		return parsedCommandArgs{}, fmt.Errorf("invalid port %d", parsed.port)
+	}
+	if (httpCfg.TLSCertFile == "") != (httpCfg.TLSKeyFile == "") {
+		return parsedCommandArgs{}, fmt.Errorf("--tls-cert and --tls-key must be used together")
+	}
+	if httpCfg.AuthToken != "" && httpCfg.AuthTokenFile != "" {
+		return parsedCommandArgs{}, fmt.Errorf("--auth-token and --auth-token-file are mutually exclusive")
+	}
+	if err := parsed.server.ValidateToolPolicy(); err != nil {
+		return parsedCommandArgs{}, fmt.Errorf("tool policy: %w", err)
+	}
+	if parsed.server.CaptureConcurrency < 0 {
+		return parsedCommandArgs{}, fmt.Errorf("--capture-concurrency must be >= 0")
+	}
+	if err := screenshot.ValidateReplayMode(parsed.replay.Mode); err != nil {
+		return parsedCommandArgs{}, fmt.Errorf("--replay-mode: %w", err)
+	}
+	return parsed, nil
+}
+
+func parseClientArgs(args []string) (parsedCommandArgs, error) {
+	fs := flag.NewFlagSet("client", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	if err := fs.Parse(args); err != nil {
+		return parsedCommandArgs{}, fmt.Errorf("parse flags: %w", err)
+	}
+	if fs.NArg() > 1 {
+		return parsedCommandArgs{}, fmt.Errorf("client accepts at most one output path, got %d", fs.NArg())
+	}
+
+	outputPath := defaultClientOutputPath
+	if fs.NArg() == 1 {
+		outputPath = fs.Arg(0)
+	}
+
+	return parsedCommandArgs{
+		command:    "client",
+		outputPath: outputPath,
+	}, nil
+}
+
+func runCommand(ctx context.Context, stderr io.Writer, parsed parsedCommandArgs) int {
+	switch parsed.command {
+	case "client":
+		return runClient(ctx, stderr, parsed.outputPath)
+	default:
+		return runServer(ctx, stderr, parsed)
+	}
+}
+
+func runServer(ctx context.Context, stderr io.Writer, parsed parsedCommandArgs) int {
+	if err := mcpserver.SetAllowedRunDirectory(parsed.runDir); err != nil {
+		_ = writeStderrLine(stderr, fmt.Sprintf("Error: %v", err))
+		return 2
+	}
+
+	cfg := parsed.server
+	if parsed.auditLog != "" {
+		auditFile, err := openAuditLog(parsed.auditLog)
+		if err != nil {
+			_ = writeStderrLine(stderr, fmt.Sprintf("Error: %v", err))
+			return 2
+		}
+		defer func() {
+			_ = auditFile.Close()
+		}()
+		cfg.AuditLog = auditFile
+	}
+	if parsed.replay.Source != "" {
+		replay, err := screenshot.LoadReplay(parsed.replay)
+		if err != nil {
+			_ = writeStderrLine(stderr, fmt.Sprintf("Error: %v", err))
+			return 2
+		}
+		screenshot.SetBackend(replay)
+		_ = writeStderrLine(stderr, fmt.Sprintf("Replay mode: captures play back %d frames from %s", replay.Len(), parsed.replay.Source))
+	}
+	if rules := cfg.Redaction; !rules.Empty() {
+		// Fail at startup rather than on every screenshot.
+		if _, err := exec.LookPath("tesseract"); err != nil && len(rules.Patterns) > 0 {
+			_ = writeStderrLine(stderr, "Error: redaction patterns require tesseract on PATH")
+			return 2
+		}
+		_ = writeStderrLine(stderr, fmt.Sprintf("Redaction: %d rects, %d window rules and %d patterns apply to every screenshot and recording",
+			len(rules.Rects), len(rules.Windows), len(rules.Patterns)))
+	}
+	if cfg.DryRun {
+		cfg.DryRunLog = stderr
+		_ = writeStderrLine(stderr, "Dry-run mode: input, process and clipboard-write tools are logged, not executed")
+	}
+	server := mcpserver.NewServer(tools.NewScreenshotService(), cfg)
+
+	if parsed.command != "server" {
+		if err := printHTTPStartup(stderr, parsed); err != nil {
+			return 2
+		}
+	}
+
+	var err error
+	switch parsed.command {
+	case "sse":
+		err = mcpserver.ListenAndServeSSE(ctx, server, parsed.port, cfg.HTTP)
+	case "streamable-http":
+		err = mcpserver.ListenAndServeStreamableHTTP(ctx, server, parsed.port, cfg.HTTP)
+	default:
+		err = mcpserver.RunStdio(ctx, server)
+	}
+	return formatServeError(ctx, stderr, err)
+}
+
+func openAuditLog(path string) (*os.File, error) {
+	// Accepted G304 suppression: the audit log path is operator-provided configuration.
+	// #nosec G304
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	return file, nil
+}
+
+func printHTTPStartup(stderr io.Writer, parsed parsedCommandArgs) error {
+	scheme := "http"
+	if parsed.server.HTTP.TLSCertFile != "" {
+		scheme = "https"
+	}
+	listenOn := scheme + "://" + net.JoinHostPort(parsed.server.HTTP.BindAddress, strconv.Itoa(parsed.port))
+	if parsed.server.HTTP.UnixSocket != "" {
+		listenOn = scheme + " over unix socket " + parsed.server.HTTP.UnixSocket
+	}
+
+	switch parsed.command {
+	case "sse":
+		if err := writeStderrf(stderr, "Serving MCP over SSE on %s\n", listenOn); err != nil {
+			return err
+		}
+	default:
+		if err := writeStderrf(stderr, "Serving MCP over Streamable HTTP on %s (endpoint %s, legacy SSE at %s)\n", listenOn, mcpserver.StreamableHTTPPath, mcpserver.SSEPath); err != nil {
+			return err
+		}
+	}
+	if parsed.server.HTTP.UnixSocket == "" && !mcpserver.IsLoopbackAddress(parsed.server.HTTP.BindAddress) &&
+		parsed.server.HTTP.AuthToken == "" && parsed.server.HTTP.AuthTokenFile == "" {
+		return writeStderrLine(stderr, "Warning: authentication is disabled; any client that can reach this address can control the desktop")
+	}
+	return nil
+}
+
+func runClient(ctx context.Context, stderr io.Writer, outputPath string) int {
+	cfg := client.DefaultConfig()
+	if os.Getenv(client.ServerCommandEnv) == "" {
+		// Prefer launching this binary over whatever happens to be on PATH.
+		if executable, err := os.Executable(); err == nil {
+			cfg.ServerCommand = executable
+		}
+	}
+
+	if err := client.TakeScreenshotToFile(ctx, outputPath, cfg); err != nil {
+		return formatServeError(ctx, stderr, err)
+	}
+	if err := writeStderrf(stderr, "Screenshot saved to %s\n", outputPath); err != nil {
+		return 2
+	}
+	return 0
+}
+
+func formatServeError(ctx context.Context, stderr io.Writer, err error) int {
+	if ctx.Err() != nil && (err == nil || errors.Is(err, context.Canceled)) {
+		_ = writeStderrLine(stderr, "Interrupted")
+		return 130
+	}
+	if err == nil {
+		return 0
+	}
+	_ = writeStderrf(stderr, "Error: %v\n", err)
+	return 1
+}
+
+func writeStderrf(stderr io.Writer, format string, args ...any) error {
+	if _, err := fmt.Fprintf(stderr, format, args...); err != nil {
+		return fmt.Errorf("write stderr: %w", err)
+	}
+	return nil
+}
+
+func writeStderrLine(stderr io.Writer, text string) error {
+	return writeStderrf(stderr, "%s\n", text)
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestServerCLI_Help(t *testing.T) {
+	code := run([]string{"-help"}, io.Discard)
+	if code != 0 {
+		t.Errorf("expected exit code 0 for -help, got %d", code)
+	}
+}
+
+func TestServerCLI_MissingCommand(t *testing.T) {
+	code := run([]string{}, io.Discard)
+	if code != 2 {
+		t.Errorf("expected exit code 2 for missing command, got %d", code)
+	}
+}
+
+func TestServerCLI_UnknownCommand(t *testing.T) {
+	code := run([]string{"serve-everything"}, io.Discard)
+	if code != 2 {
+		t.Errorf("expected exit code 2 for unknown command, got %d", code)
+	}
+}
+
+func TestServerCLI_InvalidPort(t *testing.T) {
+	code := run([]string{"sse", "--port", "0"}, io.Discard)
+	if code != 2 {
+		t.Errorf("expected exit code 2 for invalid port, got %d", code)
+	}
+}
+
+func TestParseCommandArgs(t *testing.T) {
+	parsed, err := parseCommandArgs([]string{"sse", "--port", "4010", "--experimental", "--run-dir", "/tmp/run"})
+	if err != nil {
+		t.Fatalf("parseCommandArgs returned error: %v", err)
+	}
+	if parsed.command != "sse" || parsed.port != 4010 || !parsed.server.ExperimentalTools || parsed.runDir != "/tmp/run" {
+		t.Fatalf("unexpected parsed args: %+v", parsed)
+	}
+
+	parsed, err = parseCommandArgs([]string{"streamable-http", "--port", "4011"})
+	if err != nil {
+		t.Fatalf("parseCommandArgs returned error: %v", err)
+	}
+	if parsed.command != "streamable-http" || parsed.port != 4011 {
+		t.Fatalf("unexpected parsed streamable-http args: %+v", parsed)
+	}
+
+	parsed, err = parseCommandArgs([]string{"client", "out.jpg"})
+	if err != nil {
+		t.Fatalf("parseCommandArgs returned error: %v", err)
+	}
+	if parsed.command != "client" || parsed.outputPath != "out.jpg" {
+		t.Fatalf("unexpected parsed client args: %+v", parsed)
+	}
+
+	if _, err := parseCommandArgs([]string{"server", "--port", "3001"}); err == nil {
+		t.Fatal("expected --port to be rejected for the stdio server")
+	}
+}
+
+func TestParseCommandArgs_HTTPSecurity(t *testing.T) {
+	t.Setenv(authTokenEnv, "")
+	parsed, err := parseCommandArgs([]string{
+		"streamable-http",
+		"--bind", "0.0.0.0",
+		"--auth-token", "secret",
+		"--allowed-origin", "http://localhost:5173",
+		"--allowed-origin", "https://app.example",
+		"--allowed-host", "screens.internal",
+	})
+	if err != nil {
+		t.Fatalf("parseCommandArgs returned error: %v", err)
+	}
+	if parsed.server.HTTP.BindAddress != "0.0.0.0" || parsed.server.HTTP.AuthToken != "secret" {
+		t.Fatalf("unexpected http config: %+v", parsed.server.HTTP)
+	}
+	if len(parsed.server.HTTP.AllowedOrigins) != 2 || len(parsed.server.HTTP.AllowedHosts) != 1 {
+		t.Fatalf("unexpected allowlists: %+v", parsed.server.HTTP)
+	}
+
+	parsed, err = parseCommandArgs([]string{"sse"})
+	if err != nil {
+		t.Fatalf("parseCommandArgs returned error: %v", err)
+	}
+	if parsed.server.HTTP.BindAddress != "127.0.0.1" {
+		t.Fatalf("default bind = %q, want 127.0.0.1", parsed.server.HTTP.BindAddress)
+	}
+
+	t.Setenv(authTokenEnv, "from-env")
+	parsed, err = parseCommandArgs([]string{"sse"})
+	if err != nil {
+		t.Fatalf("parseCommandArgs returned error: %v", err)
+	}
+	if parsed.server.HTTP.AuthToken != "from-env" {
+		t.Fatalf("auth token = %q, want from-env", parsed.server.HTTP.AuthToken)
+	}
+}
+
+func TestParseCommandArgs_Listeners(t *testing.T) {
+	parsed, err := parseCommandArgs([]string{"sse", "--unix-socket", "/run/mcp.sock", "--unix-socket-mode", "660"})
+	if err != nil {
+		t.Fatalf("parseCommandArgs returned error: %v", err)
+	}
+	if parsed.server.HTTP.UnixSocket != "/run/mcp.sock" || parsed.server.HTTP.UnixSocketMode != 0o660 {
+		t.Fatalf("unexpected unix socket config: %+v", parsed.server.HTTP)
+	}
+
+	parsed, err = parseCommandArgs([]string{"streamable-http", "--tls-cert", "cert.pem", "--tls-key", "key.pem"})
+	if err != nil {
+		t.Fatalf("parseCommandArgs returned error: %v", err)
+	}
+	if parsed.server.HTTP.TLSCertFile != "cert.pem" || parsed.server.HTTP.TLSKeyFile != "key.pem" {
+		t.Fatalf("unexpected tls config: %+v", parsed.server.HTTP)
+	}
+
+	for _, args := range [][]string{
+		{"sse", "--tls-cert", "cert.pem"},
+		{"sse", "--unix-socket-mode", "999"},
+		{"server", "--unix-socket", "/run/mcp.sock"},
+	} {
+		if _, err := parseCommandArgs(args); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
+
+func TestParseCommandArgs_ToolPolicy(t *testing.T) {
+	parsed, err := parseCommandArgs([]string{
+		"server",
+		"--tool-profile", "observe",
+		"--allow-tools", "click, focus_window",
+		"--deny-tools", "list_windows",
+		"--capture-concurrency", "4",
+		"--capture-cache-max-age", "0",
+	})
+	if err != nil {
+		t.Fatalf("parseCommandArgs returned error: %v", err)
+	}
+	cfg := parsed.server
+	if cfg.ToolProfile != "observe" || len(cfg.AllowedTools) != 2 || cfg.AllowedTools[1] != "focus_window" || len(cfg.DeniedTools) != 1 {
+		t.Fatalf("unexpected tool policy: %+v", cfg)
+	}
+	if cfg.CaptureConcurrency != 4 {
+		t.Fatalf("capture concurrency = %d, want 4", cfg.CaptureConcurrency)
+	}
+	if cfg.CaptureCacheMaxAge >= 0 {
+		t.Fatalf("capture cache max age = %v, want an explicit 0 to disable the cache", cfg.CaptureCacheMaxAge)
+	}
+
+	for _, args := range [][]string{
+		{"server", "--tool-profile", "admin"},
+		{"sse", "--deny-tools", "not_a_tool"},
+		{"server", "--capture-concurrency", "-1"},
+		{"server", "--capture-cache-max-age", "-5ms"},
+	} {
+		if code := run(args, io.Discard); code != 2 {
+			t.Fatalf("run(%v) = %d, want 2", args, code)
+		}
+	}
+}
+
+func TestParseCommandArgs_ConfigFile(t *testing.T) {
+	t.Setenv(authTokenEnv, "")
+	path := filepath.Join(t.TempDir(), "server.yaml")
+	content := "experimental: true\nrun_dir: /tmp/from-file\ntransport:\n  port: 4200\n  bind: 0.0.0.0\nsecurity:\n  auth_token_file: /etc/token\ntools:\n  profile: observe\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	parsed, err := parseCommandArgs([]string{"sse", "--config", path})
+	if err != nil {
+		t.Fatalf("parseCommandArgs returned error: %v", err)
+	}
+	if parsed.port != 4200 || parsed.runDir != "/tmp/from-file" || !parsed.server.ExperimentalTools ||
+		parsed.server.HTTP.BindAddress != "0.0.0.0" || parsed.server.ToolProfile != "observe" {
+		t.Fatalf("config file values not applied: %+v", parsed)
+	}
+
+	parsed, err = parseCommandArgs([]string{"sse", "--config", path, "--port", "4300", "--tool-profile", "full", "--auth-token", "cli"})
+	if err != nil {
+		t.Fatalf("parseCommandArgs returned error: %v", err)
+	}
+	if parsed.port != 4300 || parsed.server.ToolProfile != "full" {
+		t.Fatalf("flags did not override config file: %+v", parsed)
+	}
+	if parsed.server.HTTP.AuthToken != "cli" || parsed.server.HTTP.AuthTokenFile != "" {
+		t.Fatalf("--auth-token should replace the file's token source: %+v", parsed.server.HTTP)
+	}
+
+	badPath := filepath.Join(t.TempDir(), "bad.yaml")
+	if err := os.WriteFile(badPath, []byte("transprot: {}\n"), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if code := run([]string{"server", "--config", badPath}, io.Discard); code != 2 {
+		t.Fatalf("run with unknown config key = %d, want 2", code)
+	}
+}
+
+func TestParseCommandArgs_AuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.json")
+	if err := os.WriteFile(path, []byte(`{"audit_log": "/var/log/from-file.jsonl"}`), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	parsed, err := parseCommandArgs([]string{"server", "--config", path})
+	if err != nil {
+		t.Fatalf("parseCommandArgs returned error: %v", err)
+	}
+	if parsed.auditLog != "/var/log/from-file.jsonl" {
+		t.Fatalf("audit log = %q, want the config file value", parsed.auditLog)
+	}
+
+	parsed, err = parseCommandArgs([]string{"server", "--config", path, "--audit-log", "/tmp/cli.jsonl"})
+	if err != nil {
+		t.Fatalf("parseCommandArgs returned error: %v", err)
+	}
+	if parsed.auditLog != "/tmp/cli.jsonl" {
+		t.Fatalf("audit log = %q, want the flag value", parsed.auditLog)
+	}
+}
+
+func TestParseCommandArgs_Replay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.yaml")
+	content := "replay:\n  source: ./frames\n  mode: time\n  frame_interval: 250ms\n  loop: true\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	parsed, err := parseCommandArgs([]string{"server", "--config", path, "--replay-mode", "capture"})
+	if err != nil {
+		t.Fatalf("parseCommandArgs returned error: %v", err)
+	}
+	replay := parsed.replay
+	if replay.Source != "./frames" || replay.Mode != "capture" || replay.FrameInterval != 250*time.Millisecond || !replay.Loop {
+		t.Fatalf("replay = %+v, want the file values with the flag's mode", replay)
+	}
+
+	if code := run([]string{"server", "--replay-mode", "shuffle"}, io.Discard); code != 2 {
+		t.Fatalf("run with an unknown replay mode = %d, want 2", code)
+	}
+	if code := run([]string{"server", "--replay", filepath.Join(t.TempDir(), "missing")}, io.Discard); code != 2 {
+		t.Fatalf("run with a missing replay source = %d, want 2", code)
+	}
+}
+
+func TestOpenAuditLog_AppendsWithPrivateMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	for _, line := range []string{"first\n", "second\n"} {
+		file, err := openAuditLog(path)
+		if err != nil {
+			t.Fatalf("openAuditLog: %v", err)
+		}
+		if _, err := file.WriteString(line); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		_ = file.Close()
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read audit log: %v", err)
+	}
+	if string(data) != "first\nsecond\n" {
+		t.Fatalf("audit log content = %q, want both lines appended", data)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat audit log: %v", err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o600 {
+		t.Fatalf("audit log mode = %o, want 600", mode)
+	}
+}
+
+func TestParseCommandArgs_DryRun(t *testing.T) {
+	parsed, err := parseCommandArgs([]string{"server", "--dry-run"})
+	if err != nil {
+		t.Fatalf("parseCommandArgs returned error: %v", err)
+	}
+	if !parsed.server.DryRun {
+		t.Fatal("expected --dry-run to enable dry-run mode")
+	}
+
+	path := filepath.Join(t.TempDir(), "server.yaml")
+	if err := os.WriteFile(path, []byte("dry_run: true\n"), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	parsed, err = parseCommandArgs([]string{"server", "--config", path, "--dry-run=false"})
+	if err != nil {
+		t.Fatalf("parseCommandArgs returned error: %v", err)
+	}
+	if parsed.server.DryRun {
+		t.Fatal("expected --dry-run=false to override the config file")
+	}
+}
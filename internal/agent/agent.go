@@ -12,6 +12,7 @@ import (
 
 	"github.com/brainwhocodes/screenshot_mcp_server/internal/imgencode"
 	"github.com/brainwhocodes/screenshot_mcp_server/internal/input"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/screenshot"
 	"github.com/brainwhocodes/screenshot_mcp_server/internal/window"
 )
 
@@ -26,6 +27,10 @@ type Config struct {
 	SaveArtifacts  bool
 	VisionClient   VisionClient
 	ActionExecutor ActionExecutor
+	// Frames, when set, supplies every screenshot instead of the target window,
+	// for example a screenshot.Replay. No window is looked up or focused and
+	// actions are sent with window ID 0.
+	Frames screenshot.Capturer
 }
 
 // VisionClient returns the next action from an LLM or policy engine.
@@ -68,6 +73,21 @@ func (WindowActionExecutor) PressKey(ctx context.Context, windowID uint32, key s
 	return nil
 }
 
+// NoopActionExecutor accepts every action without touching the desktop. Replayed
+// runs use it because their frames do not react to input.
+type NoopActionExecutor struct{}
+
+// FocusWindow does nothing.
+func (NoopActionExecutor) FocusWindow(context.Context, uint32) error { return nil }
+
+// Click does nothing.
+func (NoopActionExecutor) Click(context.Context, uint32, float64, float64, string, int) error {
+	return nil
+}
+
+// PressKey does nothing.
+func (NoopActionExecutor) PressKey(context.Context, uint32, string, []string) error { return nil }
+
 // Action is the normalized command returned by the vision model.
 type Action struct {
 	Action     string   `json:"action"`
@@ -121,16 +141,20 @@ func (a *Agent) Run(ctx context.Context) error {
 	ctx, cancel := context.WithTimeout(ctx, a.config.MaxDuration)
 	defer cancel()
 
-	targetWindow, err := a.findTargetWindow(ctx)
-	if err != nil {
-		return err
+	var windowID uint32
+	if a.config.Frames == nil {
+		targetWindow, err := a.findTargetWindow(ctx)
+		if err != nil {
+			return err
+		}
+		windowID = targetWindow.WindowID
 	}
 
 	if err := a.ensureArtifactDir(); err != nil {
 		return err
 	}
 
-	return a.runSteps(ctx, targetWindow.WindowID)
+	return a.runSteps(ctx, windowID)
 }
 
 func (a *Agent) runSteps(ctx context.Context, windowID uint32) error {
@@ -221,19 +245,10 @@ func (a *Agent) ensureArtifactDir() error {
 }
 
 func (a *Agent) takeStep(ctx context.Context, windowID uint32, step int, artifact *StepArtifact, goal string) (*Action, error) {
-	if err := a.config.ActionExecutor.FocusWindow(ctx, windowID); err != nil {
-		return nil, fmt.Errorf("focus window: %w", err)
-	}
-
-	data, metadata, err := window.TakeWindowScreenshot(ctx, windowID, imgencode.DefaultOptions)
+	data, err := a.capture(ctx, windowID, artifact)
 	if err != nil {
-		return nil, fmt.Errorf("take screenshot: %w", err)
+		return nil, err
 	}
-
-	artifact.WindowBounds = metadata.Bounds
-	artifact.ImageWidth = metadata.ImageWidth
-	artifact.ImageHeight = metadata.ImageHeight
-	artifact.Scale = metadata.Scale
 	artifact.Step = step
 
 	if a.config.SaveArtifacts && a.config.RunDir != "" {
@@ -251,6 +266,39 @@ func (a *Agent) takeStep(ctx context.Context, windowID uint32, step int, artifac
 	return action, nil
 }
 
+// capture focuses the target window and screenshots it, or reads the next
+// frame from Config.Frames, and records the image geometry in artifact.
+func (a *Agent) capture(ctx context.Context, windowID uint32, artifact *StepArtifact) ([]byte, error) {
+	if a.config.Frames != nil {
+		img, err := a.config.Frames.Capture(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("take screenshot: %w", err)
+		}
+		data, err := imgencode.EncodeJPEG(img, imgencode.DefaultOptions)
+		if err != nil {
+			return nil, fmt.Errorf("encode screenshot: %w", err)
+		}
+		bounds := img.Bounds()
+		artifact.WindowBounds = window.Bounds{Width: float64(bounds.Dx()), Height: float64(bounds.Dy())}
+		artifact.ImageWidth, artifact.ImageHeight = bounds.Dx(), bounds.Dy()
+		artifact.Scale = 1
+		return data, nil
+	}
+
+	if err := a.config.ActionExecutor.FocusWindow(ctx, windowID); err != nil {
+		return nil, fmt.Errorf("focus window: %w", err)
+	}
+	data, metadata, err := window.TakeWindowScreenshot(ctx, windowID, imgencode.DefaultOptions)
+	if err != nil {
+		return nil, fmt.Errorf("take screenshot: %w", err)
+	}
+	artifact.WindowBounds = metadata.Bounds
+	artifact.ImageWidth = metadata.ImageWidth
+	artifact.ImageHeight = metadata.ImageHeight
+	artifact.Scale = metadata.Scale
+	return data, nil
+}
+
 func (a *Agent) executeAction(ctx context.Context, windowID uint32, action *Action) error {
 	switch action.Action {
 	case "click":
@@ -3,6 +3,9 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"image"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -90,3 +93,62 @@ func TestEncodeImageBase64(t *testing.T) {
 		t.Fatal("expected non-empty base64")
 	}
 }
+
+type frameSequence struct {
+	sizes []image.Point
+	next  int
+}
+
+func (f *frameSequence) Capture(context.Context) (image.Image, error) {
+	size := f.sizes[min(f.next, len(f.sizes)-1)]
+	f.next++
+	return image.NewRGBA(image.Rect(0, 0, size.X, size.Y)), nil
+}
+
+type recordingExecutor struct {
+	NoopActionExecutor
+	clicks []uint32
+}
+
+func (e *recordingExecutor) Click(_ context.Context, windowID uint32, _, _ float64, _ string, _ int) error {
+	e.clicks = append(e.clicks, windowID)
+	return nil
+}
+
+func TestAgent_RunFromFrames(t *testing.T) {
+	frames := &frameSequence{sizes: []image.Point{{X: 40, Y: 30}, {X: 20, Y: 10}}}
+	executor := &recordingExecutor{}
+	steps := 0
+	runDir := t.TempDir()
+	ag := NewAgent(Config{
+		Goal:          "click then finish",
+		RunDir:        runDir,
+		SaveArtifacts: true,
+		Frames:        frames,
+		VisionClient: &MockVisionClient{
+			GetActionFunc: func(_ context.Context, _ []byte, _ string) (*Action, error) {
+				steps++
+				return &Action{Action: "click", X: 5, Y: 5, Done: steps == 2}, nil
+			},
+		},
+		ActionExecutor: executor,
+	})
+	if err := ag.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if frames.next != 2 || len(executor.clicks) != 1 || executor.clicks[0] != 0 {
+		t.Fatalf("captured %d frames, clicks %v; want 2 frames and one click on window 0", frames.next, executor.clicks)
+	}
+
+	data, err := os.ReadFile(filepath.Join(runDir, "step_0002.json"))
+	if err != nil {
+		t.Fatalf("read artifact: %v", err)
+	}
+	var artifact StepArtifact
+	if err := json.Unmarshal(data, &artifact); err != nil {
+		t.Fatalf("decode artifact: %v", err)
+	}
+	if artifact.ImageWidth != 20 || artifact.ImageHeight != 10 || artifact.ScreenshotPath == "" {
+		t.Fatalf("artifact = %+v, want the second 20x10 frame", artifact)
+	}
+}
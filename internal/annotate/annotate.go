@@ -0,0 +1,217 @@
+// Package annotate draws set-of-marks overlays onto screenshots so vision
+// models can refer to screen positions by label instead of raw coordinates.
+package annotate
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"strconv"
+	"strings"
+)
+
+// Annotation modes.
+const (
+	// ModeGrid draws a coordinate grid with a label such as "C4" in every cell.
+	ModeGrid = "grid"
+	// ModeMarks draws numbered boxes around caller-supplied regions.
+	ModeMarks = "marks"
+)
+
+// DefaultGridSize is the grid cell size in image pixels.
+const DefaultGridSize = 100
+
+const (
+	minGridSize  = 20
+	maxGridCells = 2500
+)
+
+// Box is a region to mark, in image pixel coordinates.
+type Box struct {
+	X, Y, Width, Height float64
+	// Label names the box; empty uses its 1-based position.
+	Label string
+	// Text is reported back with the label, e.g. the word OCR found there.
+	Text string
+}
+
+// Rect is a rectangle in image pixel coordinates.
+type Rect struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// Target is what a label refers to. X and Y are the center of Box, ready to
+// pass to the click tools.
+type Target struct {
+	X    float64 `json:"x"`
+	Y    float64 `json:"y"`
+	Box  Rect    `json:"box"`
+	Text string  `json:"text,omitempty"`
+}
+
+// Options selects the overlay to draw.
+type Options struct {
+	// Mode is ModeGrid or ModeMarks.
+	Mode string
+	// GridSize is the grid cell size; zero uses DefaultGridSize.
+	GridSize int
+	// Boxes are the regions ModeMarks numbers.
+	Boxes []Box
+}
+
+// Result maps every drawn label to its target.
+type Result struct {
+	Mode     string            `json:"mode"`
+	GridSize int               `json:"grid_size,omitempty"`
+	Labels   map[string]Target `json:"labels"`
+}
+
+var (
+	gridLineColor = color.NRGBA{R: 255, G: 0, B: 255, A: 150}
+	tagColor      = color.NRGBA{A: 180}
+	textColor     = color.White
+	// markColors cycle across boxes so neighbouring marks stay distinguishable.
+	markColors = []color.NRGBA{
+		{R: 220, G: 20, B: 60, A: 255},
+		{R: 30, G: 90, B: 230, A: 255},
+		{R: 0, G: 140, B: 60, A: 255},
+		{R: 220, G: 100, B: 0, A: 255},
+		{R: 140, G: 40, B: 200, A: 255},
+		{R: 0, G: 130, B: 140, A: 255},
+	}
+)
+
+// Draw returns a copy of img, moved to the origin, with the overlay drawn on
+// it. img itself is never modified, so shared cached frames are safe to pass.
+func Draw(img image.Image, opts Options) (*image.RGBA, *Result, error) {
+	if img == nil {
+		return nil, nil, fmt.Errorf("image is nil")
+	}
+	bounds := img.Bounds()
+	canvas := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(canvas, canvas.Bounds(), img, bounds.Min, draw.Src)
+
+	switch opts.Mode {
+	case ModeGrid:
+		result, err := drawGrid(canvas, opts.GridSize)
+		if err != nil {
+			return nil, nil, err
+		}
+		return canvas, result, nil
+	case ModeMarks:
+		result, err := drawMarks(canvas, opts.Boxes)
+		if err != nil {
+			return nil, nil, err
+		}
+		return canvas, result, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown annotation mode %q (use %q or %q)", opts.Mode, ModeGrid, ModeMarks)
+	}
+}
+
+// labelScale sizes label text for the image so it stays legible on large
+// captures and after moderate downscaling.
+func labelScale(bounds image.Rectangle) int {
+	return max(2, min(bounds.Dx(), bounds.Dy())/400)
+}
+
+func drawGrid(canvas *image.RGBA, size int) (*Result, error) {
+	if size == 0 {
+		size = DefaultGridSize
+	}
+	if size < minGridSize {
+		return nil, fmt.Errorf("grid_size must be at least %d pixels", minGridSize)
+	}
+	bounds := canvas.Bounds()
+	cols := (bounds.Dx() + size - 1) / size
+	rows := (bounds.Dy() + size - 1) / size
+	if cols*rows > maxGridCells {
+		return nil, fmt.Errorf("grid_size %d gives %d cells on a %dx%d image; use a larger size", size, cols*rows, bounds.Dx(), bounds.Dy())
+	}
+
+	line := image.NewUniform(gridLineColor)
+	for x := size; x < bounds.Dx(); x += size {
+		draw.Draw(canvas, image.Rect(x, 0, x+1, bounds.Dy()), line, image.Point{}, draw.Over)
+	}
+	for y := size; y < bounds.Dy(); y += size {
+		draw.Draw(canvas, image.Rect(0, y, bounds.Dx(), y+1), line, image.Point{}, draw.Over)
+	}
+
+	scale := labelScale(bounds)
+	result := &Result{Mode: ModeGrid, GridSize: size, Labels: make(map[string]Target, cols*rows)}
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			cell := image.Rect(col*size, row*size, (col+1)*size, (row+1)*size).Intersect(bounds)
+			label := columnName(col) + strconv.Itoa(row+1)
+			drawLabel(canvas, cell.Min.Add(image.Pt(1, 1)), label, scale, textColor, tagColor)
+			result.Labels[label] = targetFor(rectOf(cell), "")
+		}
+	}
+	return result, nil
+}
+
+// columnName returns spreadsheet-style column letters: A..Z, AA, AB, ...
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+func drawMarks(canvas *image.RGBA, boxes []Box) (*Result, error) {
+	bounds := canvas.Bounds()
+	scale := labelScale(bounds)
+	stroke := scale
+	result := &Result{Mode: ModeMarks, Labels: make(map[string]Target, len(boxes))}
+	for i, box := range boxes {
+		if box.Width <= 0 || box.Height <= 0 {
+			return nil, fmt.Errorf("mark %d must have a positive width and height", i+1)
+		}
+		label := box.Label
+		if label == "" {
+			label = strconv.Itoa(i + 1)
+		}
+		if _, exists := result.Labels[label]; exists {
+			return nil, fmt.Errorf("duplicate mark label %q", label)
+		}
+		result.Labels[label] = targetFor(Rect{X: box.X, Y: box.Y, Width: box.Width, Height: box.Height}, box.Text)
+
+		rect := image.Rect(int(box.X), int(box.Y), int(box.X+box.Width+0.5), int(box.Y+box.Height+0.5)).Intersect(bounds)
+		if rect.Empty() {
+			continue
+		}
+		ink := image.NewUniform(markColors[i%len(markColors)])
+		for _, edge := range []image.Rectangle{
+			image.Rect(rect.Min.X, rect.Min.Y, rect.Max.X, rect.Min.Y+stroke),
+			image.Rect(rect.Min.X, rect.Max.Y-stroke, rect.Max.X, rect.Max.Y),
+			image.Rect(rect.Min.X, rect.Min.Y, rect.Min.X+stroke, rect.Max.Y),
+			image.Rect(rect.Max.X-stroke, rect.Min.Y, rect.Max.X, rect.Max.Y),
+		} {
+			draw.Draw(canvas, edge.Intersect(rect), ink, image.Point{}, draw.Src)
+		}
+
+		// Put the tag above the box when there is room so it does not cover the target.
+		tagSize := textSize(strings.ToUpper(label), scale)
+		at := image.Pt(rect.Min.X, rect.Min.Y-tagSize.Y)
+		if at.Y < 0 {
+			at.Y = rect.Min.Y
+		}
+		at.X = min(at.X, max(0, bounds.Max.X-tagSize.X))
+		drawLabel(canvas, at, strings.ToUpper(label), scale, textColor, ink.C)
+	}
+	return result, nil
+}
+
+func rectOf(r image.Rectangle) Rect {
+	return Rect{X: float64(r.Min.X), Y: float64(r.Min.Y), Width: float64(r.Dx()), Height: float64(r.Dy())}
+}
+
+func targetFor(box Rect, text string) Target {
+	return Target{X: box.X + box.Width/2, Y: box.Y + box.Height/2, Box: box, Text: text}
+}
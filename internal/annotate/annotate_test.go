@@ -0,0 +1,82 @@
+package annotate
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func whiteImage(bounds image.Rectangle) *image.RGBA {
+	img := image.NewRGBA(bounds)
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	return img
+}
+
+func TestDraw_GridLabelsEveryCell(t *testing.T) {
+	src := whiteImage(image.Rect(10, 10, 260, 130))
+	canvas, result, err := Draw(src, Options{Mode: ModeGrid})
+	if err != nil {
+		t.Fatalf("Draw: %v", err)
+	}
+	if canvas.Bounds() != image.Rect(0, 0, 250, 120) {
+		t.Fatalf("canvas bounds = %v, want the source size at the origin", canvas.Bounds())
+	}
+	if result.Mode != ModeGrid || result.GridSize != DefaultGridSize || len(result.Labels) != 6 {
+		t.Fatalf("result = %+v, want 3x2 cells", result)
+	}
+	// The last column is only 50 pixels wide, so its center moves with it.
+	c2 := result.Labels["C2"]
+	if c2.X != 225 || c2.Y != 110 || c2.Box.Width != 50 || c2.Box.Height != 20 {
+		t.Fatalf("C2 = %+v", c2)
+	}
+	if src.RGBAAt(11, 11) != (color.RGBA{R: 255, G: 255, B: 255, A: 255}) {
+		t.Fatal("Draw must not modify the source image")
+	}
+	if canvas.RGBAAt(100, 50) == (color.RGBA{R: 255, G: 255, B: 255, A: 255}) {
+		t.Fatal("expected a grid line at x=100")
+	}
+
+	if _, _, err := Draw(src, Options{Mode: ModeGrid, GridSize: 5}); err == nil {
+		t.Fatal("expected a tiny grid to be rejected")
+	}
+}
+
+func TestDraw_MarksNumberBoxes(t *testing.T) {
+	src := whiteImage(image.Rect(0, 0, 200, 100))
+	canvas, result, err := Draw(src, Options{Mode: ModeMarks, Boxes: []Box{
+		{X: 20, Y: 40, Width: 40, Height: 20},
+		{X: 120, Y: 0, Width: 30, Height: 30, Label: "ok", Text: "OK"},
+	}})
+	if err != nil {
+		t.Fatalf("Draw: %v", err)
+	}
+	first, ok := result.Labels["1"]
+	if !ok || first.X != 40 || first.Y != 50 {
+		t.Fatalf("label 1 = %+v, want center 40,50", first)
+	}
+	if second := result.Labels["ok"]; second.Text != "OK" || second.X != 135 {
+		t.Fatalf("label ok = %+v", second)
+	}
+	if canvas.RGBAAt(20, 50) != (color.RGBA(markColors[0])) {
+		t.Fatalf("left edge of box 1 = %v, want the first mark color", canvas.RGBAAt(20, 50))
+	}
+
+	_, _, err = Draw(src, Options{Mode: ModeMarks, Boxes: []Box{{Width: 1, Height: 1, Label: "a"}, {Width: 1, Height: 1, Label: "a"}}})
+	if err == nil || !strings.Contains(err.Error(), "duplicate") {
+		t.Fatalf("duplicate labels error = %v", err)
+	}
+	if _, _, err := Draw(src, Options{Mode: "arrows"}); err == nil {
+		t.Fatal("expected an unknown mode to be rejected")
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for index, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(index); got != want {
+			t.Errorf("columnName(%d) = %q, want %q", index, got, want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package annotate
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// glyphWidth and glyphHeight are the size of one character before scaling.
+const (
+	glyphWidth  = 3
+	glyphHeight = 5
+)
+
+// glyphs is a 3x5 bitmap font covering the characters used in labels. Each
+// row is three cells, '#' for a lit pixel.
+var glyphs = map[rune][glyphHeight]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", ".##", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", ".#.", ".#.", ".#."},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'A': {".#.", "#.#", "###", "#.#", "#.#"},
+	'B': {"##.", "#.#", "##.", "#.#", "##."},
+	'C': {".##", "#..", "#..", "#..", ".##"},
+	'D': {"##.", "#.#", "#.#", "#.#", "##."},
+	'E': {"###", "#..", "##.", "#..", "###"},
+	'F': {"###", "#..", "##.", "#..", "#.."},
+	'G': {".##", "#..", "#.#", "#.#", ".##"},
+	'H': {"#.#", "#.#", "###", "#.#", "#.#"},
+	'I': {"###", ".#.", ".#.", ".#.", "###"},
+	'J': {"..#", "..#", "..#", "#.#", ".#."},
+	'K': {"#.#", "#.#", "##.", "#.#", "#.#"},
+	'L': {"#..", "#..", "#..", "#..", "###"},
+	'M': {"#.#", "###", "###", "#.#", "#.#"},
+	'N': {"##.", "#.#", "#.#", "#.#", "#.#"},
+	'O': {".#.", "#.#", "#.#", "#.#", ".#."},
+	'P': {"##.", "#.#", "##.", "#..", "#.."},
+	'Q': {".#.", "#.#", "#.#", "##.", ".##"},
+	'R': {"##.", "#.#", "##.", "#.#", "#.#"},
+	'S': {".##", "#..", ".#.", "..#", "##."},
+	'T': {"###", ".#.", ".#.", ".#.", ".#."},
+	'U': {"#.#", "#.#", "#.#", "#.#", "###"},
+	'V': {"#.#", "#.#", "#.#", "#.#", ".#."},
+	'W': {"#.#", "#.#", "###", "###", "#.#"},
+	'X': {"#.#", "#.#", ".#.", "#.#", "#.#"},
+	'Y': {"#.#", "#.#", ".#.", ".#.", ".#."},
+	'Z': {"###", "..#", ".#.", "#..", "###"},
+	'-': {"...", "...", "###", "...", "..."},
+	'?': {"###", "..#", ".#.", "...", ".#."},
+}
+
+// textSize returns the size of text drawn at scale, with a one-cell gap
+// between characters and a one-cell margin around the text.
+func textSize(text string, scale int) image.Point {
+	n := len([]rune(text))
+	return image.Pt((n*(glyphWidth+1)+1)*scale, (glyphHeight+2)*scale)
+}
+
+// drawLabel draws text in fg on a bg tag whose top-left corner is at.
+// Characters without a glyph are drawn as '?'.
+func drawLabel(dst draw.Image, at image.Point, text string, scale int, fg, bg color.Color) image.Rectangle {
+	tag := image.Rectangle{Min: at, Max: at.Add(textSize(text, scale))}
+	draw.Draw(dst, tag, image.NewUniform(bg), image.Point{}, draw.Over)
+
+	ink := image.NewUniform(fg)
+	x := at.X + scale
+	for _, r := range text {
+		glyph, ok := glyphs[r]
+		if !ok {
+			glyph = glyphs['?']
+		}
+		for row, cells := range glyph {
+			for col, cell := range cells {
+				if cell != '#' {
+					continue
+				}
+				px := image.Rect(x+col*scale, at.Y+(row+1)*scale, x+(col+1)*scale, at.Y+(row+2)*scale)
+				draw.Draw(dst, px, ink, image.Point{}, draw.Src)
+			}
+		}
+		x += (glyphWidth + 1) * scale
+	}
+	return tag
+}
@@ -0,0 +1,149 @@
+// Package clipboard reads and writes the system clipboard as plain text, HTML or PNG.
+//
+// macOS uses pbcopy/pbpaste and osascript. Linux talks to the X11 CLIPBOARD
+// selection directly, or uses wl-copy/wl-paste under Wayland when they are installed.
+package clipboard
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+)
+
+// MIME types supported by every backend.
+const (
+	TypeText = "text/plain"
+	TypeHTML = "text/html"
+	TypePNG  = "image/png"
+)
+
+// ErrUnavailable is returned by Read when the clipboard holds no content of the requested type.
+var ErrUnavailable = errors.New("clipboard has no content of the requested type")
+
+// Clipboard is a system clipboard backend.
+type Clipboard interface {
+	// Types lists the supported MIME types the clipboard currently offers, in
+	// TypeText, TypeHTML, TypePNG order. An empty clipboard has no types.
+	Types(ctx context.Context) ([]string, error)
+	// Read returns the content for mimeType, or ErrUnavailable.
+	Read(ctx context.Context, mimeType string) ([]byte, error)
+	// Write replaces the clipboard with data of a single MIME type.
+	Write(ctx context.Context, mimeType string, data []byte) error
+	// Clear empties the clipboard.
+	Clear(ctx context.Context) error
+}
+
+// supportedTypes is the canonical order used by Types.
+var supportedTypes = []string{TypeText, TypeHTML, TypePNG}
+
+// SupportedTypes returns the MIME types accepted by Read and Write.
+func SupportedTypes() []string {
+	return append([]string(nil), supportedTypes...)
+}
+
+// NormalizeType maps a MIME type to one of the supported types. Parameters such
+// as charset are dropped and an empty value means TypeText.
+func NormalizeType(mimeType string) (string, error) {
+	mimeType = strings.TrimSpace(mimeType)
+	if mimeType == "" {
+		return TypeText, nil
+	}
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return "", fmt.Errorf("invalid mime type %q: %w", mimeType, err)
+	}
+	for _, supported := range supportedTypes {
+		if mediaType == supported {
+			return supported, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported mime type %q (supported: %s)", mimeType, strings.Join(supportedTypes, ", "))
+}
+
+// typesFromTargets maps platform target names (X11 atoms, Wayland MIME types)
+// to supported MIME types in canonical order.
+func typesFromTargets(targets []string) []string {
+	offered := make(map[string]bool, len(targets))
+	for _, target := range targets {
+		for _, mimeType := range supportedTypes {
+			for _, name := range targetNames[mimeType] {
+				if target == name {
+					offered[mimeType] = true
+				}
+			}
+		}
+	}
+	types := make([]string, 0, len(offered))
+	for _, mimeType := range supportedTypes {
+		if offered[mimeType] {
+			types = append(types, mimeType)
+		}
+	}
+	return types
+}
+
+// targetNames lists the names a supported type is exchanged under, most preferred first.
+var targetNames = map[string][]string{
+	TypeText: {"UTF8_STRING", "text/plain;charset=utf-8", "text/plain", "STRING", "TEXT"},
+	TypeHTML: {"text/html"},
+	TypePNG:  {"image/png"},
+}
+
+// Snapshot identifies the clipboard content at one point in time.
+type Snapshot struct {
+	Types []string
+	// Digest hashes the content of the most preferred offered type.
+	Digest [sha256.Size]byte
+}
+
+// Equal reports whether two snapshots describe the same content.
+func (s Snapshot) Equal(other Snapshot) bool {
+	return s.Digest == other.Digest && strings.Join(s.Types, ",") == strings.Join(other.Types, ",")
+}
+
+// Take records the current clipboard types and a digest of the preferred content.
+func Take(ctx context.Context, c Clipboard) (Snapshot, error) {
+	types, err := c.Types(ctx)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	snapshot := Snapshot{Types: types}
+	if len(types) == 0 {
+		return snapshot, nil
+	}
+	data, err := c.Read(ctx, types[0])
+	if err != nil && !errors.Is(err, ErrUnavailable) {
+		return Snapshot{}, err
+	}
+	snapshot.Digest = sha256.Sum256(data)
+	return snapshot, nil
+}
+
+// WaitForChange polls until the clipboard differs from initial and returns the new snapshot.
+func WaitForChange(ctx context.Context, c Clipboard, initial Snapshot, timeout, pollInterval time.Duration) (Snapshot, error) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return Snapshot{}, fmt.Errorf("wait for clipboard change: %w", ctx.Err())
+		case <-deadline.C:
+			return Snapshot{}, fmt.Errorf("timeout after %s waiting for the clipboard to change", timeout)
+		case <-ticker.C:
+			current, err := Take(ctx, c)
+			if err != nil {
+				return Snapshot{}, err
+			}
+			if !current.Equal(initial) {
+				return current, nil
+			}
+		}
+	}
+}
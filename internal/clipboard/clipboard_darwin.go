@@ -0,0 +1,173 @@
+//go:build darwin
+
+package clipboard
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/safeexec"
+)
+
+// appleScriptClasses are the pasteboard classes AppleScript uses for the non-text types.
+var appleScriptClasses = map[string]string{
+	TypeHTML: "«class HTML»",
+	TypePNG:  "«class PNGf»",
+}
+
+// New returns the macOS general pasteboard.
+func New() Clipboard {
+	return macClipboard{}
+}
+
+// macClipboard uses pbcopy/pbpaste for text and osascript for HTML and PNG,
+// which pbcopy cannot write.
+type macClipboard struct{}
+
+func (macClipboard) Types(ctx context.Context) ([]string, error) {
+	output, err := runOsascript(ctx, "clipboard info")
+	if err != nil {
+		return nil, err
+	}
+	return parseClipboardInfo(string(output)), nil
+}
+
+func (c macClipboard) Read(ctx context.Context, mimeType string) ([]byte, error) {
+	types, err := c.Types(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !containsType(types, mimeType) {
+		return nil, ErrUnavailable
+	}
+
+	if mimeType == TypeText {
+		cmd, cancel, err := safeexec.CommandContext(ctx, "pbpaste", "-Prefer", "txt")
+		if err != nil {
+			return nil, err
+		}
+		defer cancel()
+		output, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("pbpaste: %w", err)
+		}
+		return output, nil
+	}
+
+	output, err := runOsascript(ctx, "the clipboard as "+appleScriptClasses[mimeType])
+	if err != nil {
+		return nil, err
+	}
+	return parseAppleScriptData(string(output))
+}
+
+func (macClipboard) Write(ctx context.Context, mimeType string, data []byte) error {
+	if mimeType == TypeText {
+		if output, err := safeexec.RunCommandWithInput(ctx, data, "pbcopy"); err != nil {
+			return fmt.Errorf("pbcopy: %w, output: %s", err, strings.TrimSpace(string(output)))
+		}
+		return nil
+	}
+
+	// AppleScript cannot take binary data inline, so it reads the value from a temporary file.
+	file, err := os.CreateTemp("", "screenshot-mcp-clipboard-*")
+	if err != nil {
+		return fmt.Errorf("create clipboard file: %w", err)
+	}
+	defer func() { _ = os.Remove(file.Name()) }()
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("write clipboard file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("write clipboard file: %w", err)
+	}
+
+	script := fmt.Sprintf(`set the clipboard to (read (POSIX file "%s") as %s)`,
+		safeexec.QuoteAppleScriptString(file.Name()), appleScriptClasses[mimeType])
+	if _, err := runOsascript(ctx, script); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (macClipboard) Clear(ctx context.Context) error {
+	// AppleScript can only replace the clipboard, so clear it through AppKit from JXA.
+	script := `ObjC.import("AppKit"); $.NSPasteboard.generalPasteboard.clearContents`
+	cmd, cancel, err := safeexec.CommandContext(ctx, "osascript", "-l", "JavaScript", "-e", script)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("clear clipboard: %w, output: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// runOsascript runs an AppleScript and returns its stdout.
+func runOsascript(ctx context.Context, script string) ([]byte, error) {
+	cmd, cancel, err := safeexec.CommandContext(ctx, "osascript", "-e", script)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("osascript: %w", err)
+	}
+	return output, nil
+}
+
+// parseClipboardInfo maps the flattened "class, size, class, size" list printed for
+// `clipboard info` to supported MIME types.
+func parseClipboardInfo(info string) []string {
+	offered := make(map[string]bool)
+	for _, field := range strings.Split(info, ",") {
+		switch strings.TrimSpace(field) {
+		case "string", "Unicode text", "«class utf8»", "«class ut16»":
+			offered[TypeText] = true
+		case appleScriptClasses[TypeHTML]:
+			offered[TypeHTML] = true
+		case appleScriptClasses[TypePNG]:
+			offered[TypePNG] = true
+		}
+	}
+	types := make([]string, 0, len(offered))
+	for _, mimeType := range supportedTypes {
+		if offered[mimeType] {
+			types = append(types, mimeType)
+		}
+	}
+	return types
+}
+
+// parseAppleScriptData decodes a raw data literal such as «data PNGf89504E47...».
+func parseAppleScriptData(output string) ([]byte, error) {
+	literal := strings.TrimSpace(output)
+	if !strings.HasPrefix(literal, "«data ") || !strings.HasSuffix(literal, "»") {
+		return nil, fmt.Errorf("unexpected clipboard data from osascript: %.40q", literal)
+	}
+	body := strings.TrimSuffix(strings.TrimPrefix(literal, "«data "), "»")
+	// The first four characters are the class code, the rest is hex.
+	if len(body) < 4 {
+		return nil, fmt.Errorf("unexpected clipboard data from osascript: %.40q", literal)
+	}
+	data, err := hex.DecodeString(body[4:])
+	if err != nil {
+		return nil, fmt.Errorf("decode clipboard data: %w", err)
+	}
+	return data, nil
+}
+
+func containsType(types []string, mimeType string) bool {
+	for _, t := range types {
+		if t == mimeType {
+			return true
+		}
+	}
+	return false
+}
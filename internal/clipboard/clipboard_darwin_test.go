@@ -0,0 +1,32 @@
+//go:build darwin
+
+package clipboard
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestParseClipboardInfo(t *testing.T) {
+	info := "«class PNGf», 2048, «class HTML», 30, «class utf8», 5, Unicode text, 10, string, 5\n"
+	if got, want := parseClipboardInfo(info), []string{TypeText, TypeHTML, TypePNG}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseClipboardInfo = %v, want %v", got, want)
+	}
+	if got := parseClipboardInfo("\n"); len(got) != 0 {
+		t.Fatalf("empty clipboard info = %v, want none", got)
+	}
+}
+
+func TestParseAppleScriptData(t *testing.T) {
+	data, err := parseAppleScriptData("«data PNGf89504E47»\n")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !bytes.Equal(data, []byte{0x89, 0x50, 0x4e, 0x47}) {
+		t.Fatalf("data = %x", data)
+	}
+	if _, err := parseAppleScriptData("missing value"); err == nil {
+		t.Fatal("expected non-data output to fail")
+	}
+}
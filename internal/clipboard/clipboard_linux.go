@@ -0,0 +1,56 @@
+//go:build linux
+
+package clipboard
+
+import (
+	"context"
+	"os"
+	"os/exec"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/virtualdisplay"
+)
+
+// New returns the clipboard for the current session. Wayland sessions use
+// wl-clipboard when it is installed; everything else uses the X11 selection,
+// which also covers XWayland. Calls whose context targets a virtual display
+// always use that display's X11 selection.
+func New() Clipboard {
+	if os.Getenv("WAYLAND_DISPLAY") != "" && hasCommand("wl-copy") && hasCommand("wl-paste") {
+		return virtualDisplayClipboard{host: waylandClipboard{}}
+	}
+	return x11Clipboard{}
+}
+
+func hasCommand(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+// virtualDisplayClipboard routes calls aimed at a virtual display to its X11
+// selection and everything else to the host clipboard.
+type virtualDisplayClipboard struct {
+	host Clipboard
+}
+
+func (c virtualDisplayClipboard) pick(ctx context.Context) Clipboard {
+	if virtualdisplay.FromContext(ctx) != "" {
+		return x11Clipboard{}
+	}
+	return c.host
+}
+
+func (c virtualDisplayClipboard) Types(ctx context.Context) ([]string, error) {
+	return c.pick(ctx).Types(ctx)
+}
+
+func (c virtualDisplayClipboard) Read(ctx context.Context, mimeType string) ([]byte, error) {
+	return c.pick(ctx).Read(ctx, mimeType)
+}
+
+func (c virtualDisplayClipboard) Write(ctx context.Context, mimeType string, data []byte) error {
+	return c.pick(ctx).Write(ctx, mimeType, data)
+}
+
+func (c virtualDisplayClipboard) Clear(ctx context.Context) error {
+	return c.pick(ctx).Clear(ctx)
+}
@@ -0,0 +1,36 @@
+//go:build !darwin && !linux
+
+package clipboard
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+)
+
+// New returns a clipboard that reports the platform as unsupported.
+func New() Clipboard {
+	return unsupportedClipboard{}
+}
+
+type unsupportedClipboard struct{}
+
+func (unsupportedClipboard) Types(context.Context) ([]string, error) {
+	return nil, unsupportedError()
+}
+
+func (unsupportedClipboard) Read(context.Context, string) ([]byte, error) {
+	return nil, unsupportedError()
+}
+
+func (unsupportedClipboard) Write(context.Context, string, []byte) error {
+	return unsupportedError()
+}
+
+func (unsupportedClipboard) Clear(context.Context) error {
+	return unsupportedError()
+}
+
+func unsupportedError() error {
+	return fmt.Errorf("clipboard is only supported on macOS (darwin) and Linux, current platform: %s", runtime.GOOS)
+}
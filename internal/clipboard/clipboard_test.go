@@ -0,0 +1,117 @@
+package clipboard
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClipboard serves a fixed set of typed values.
+type fakeClipboard struct {
+	values map[string][]byte
+}
+
+func (c *fakeClipboard) Types(context.Context) ([]string, error) {
+	var types []string
+	for _, mimeType := range supportedTypes {
+		if _, ok := c.values[mimeType]; ok {
+			types = append(types, mimeType)
+		}
+	}
+	return types, nil
+}
+
+func (c *fakeClipboard) Read(_ context.Context, mimeType string) ([]byte, error) {
+	data, ok := c.values[mimeType]
+	if !ok {
+		return nil, ErrUnavailable
+	}
+	return data, nil
+}
+
+func (c *fakeClipboard) Write(_ context.Context, mimeType string, data []byte) error {
+	c.values = map[string][]byte{mimeType: data}
+	return nil
+}
+
+func (c *fakeClipboard) Clear(context.Context) error {
+	c.values = nil
+	return nil
+}
+
+func TestNormalizeType(t *testing.T) {
+	cases := map[string]string{
+		"":                          TypeText,
+		"text/plain":                TypeText,
+		"text/plain; charset=utf-8": TypeText,
+		" TEXT/HTML ":               TypeHTML,
+		"image/png":                 TypePNG,
+	}
+	for input, want := range cases {
+		got, err := NormalizeType(input)
+		if err != nil || got != want {
+			t.Errorf("NormalizeType(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"image/jpeg", "not a type/"} {
+		if _, err := NormalizeType(input); err == nil {
+			t.Errorf("NormalizeType(%q) should fail", input)
+		}
+	}
+}
+
+func TestTypesFromTargets(t *testing.T) {
+	got := typesFromTargets([]string{"TARGETS", "image/png", "UTF8_STRING", "STRING", "text/uri-list"})
+	if want := []string{TypeText, TypePNG}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("typesFromTargets = %v, want %v", got, want)
+	}
+	if got := typesFromTargets(nil); len(got) != 0 {
+		t.Fatalf("typesFromTargets(nil) = %v, want none", got)
+	}
+}
+
+func TestSnapshotDetectsContentAndTypeChanges(t *testing.T) {
+	ctx := context.Background()
+	board := &fakeClipboard{values: map[string][]byte{TypeText: []byte("one")}}
+	first, err := Take(ctx, board)
+	if err != nil {
+		t.Fatalf("take: %v", err)
+	}
+
+	same, _ := Take(ctx, board)
+	if !first.Equal(same) {
+		t.Fatal("unchanged clipboard should produce an equal snapshot")
+	}
+
+	_ = board.Write(ctx, TypeText, []byte("two"))
+	if second, _ := Take(ctx, board); first.Equal(second) {
+		t.Fatal("new text should change the snapshot")
+	}
+
+	_ = board.Clear(ctx)
+	empty, _ := Take(ctx, board)
+	if first.Equal(empty) || len(empty.Types) != 0 {
+		t.Fatalf("cleared clipboard snapshot = %+v", empty)
+	}
+}
+
+func TestWaitForChange(t *testing.T) {
+	ctx := context.Background()
+	board := &fakeClipboard{}
+	initial, _ := Take(ctx, board)
+
+	if _, err := WaitForChange(ctx, board, initial, 30*time.Millisecond, 5*time.Millisecond); err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+
+	_ = board.Write(ctx, TypeHTML, []byte("<p>"))
+	changed, err := WaitForChange(ctx, board, initial, time.Second, 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+	if !reflect.DeepEqual(changed.Types, []string{TypeHTML}) {
+		t.Fatalf("types = %v, want [%s]", changed.Types, TypeHTML)
+	}
+}
@@ -0,0 +1,52 @@
+//go:build linux && integration
+
+package clipboard
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// Integration tests require an X display and the integration build tag.
+// Run with: xvfb-run go test -tags=integration ./internal/clipboard/...
+
+func TestIntegration_X11RoundTrip(t *testing.T) {
+	if os.Getenv("DISPLAY") == "" {
+		t.Skip("DISPLAY is not set")
+	}
+	ctx := context.Background()
+	board := x11Clipboard{}
+
+	if err := board.Write(ctx, TypeText, []byte("héllo")); err != nil {
+		t.Fatalf("write text: %v", err)
+	}
+	if types, err := board.Types(ctx); err != nil || !reflect.DeepEqual(types, []string{TypeText}) {
+		t.Fatalf("types = %v, %v", types, err)
+	}
+	if data, err := board.Read(ctx, TypeText); err != nil || string(data) != "héllo" {
+		t.Fatalf("read text = %q, %v", data, err)
+	}
+	if _, err := board.Read(ctx, TypePNG); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("read png from text clipboard: %v", err)
+	}
+
+	// Larger than incrChunkSize so the value is transferred with INCR.
+	large := bytes.Repeat([]byte{0x89, 'P', 'N', 'G'}, incrChunkSize)
+	if err := board.Write(ctx, TypePNG, large); err != nil {
+		t.Fatalf("write png: %v", err)
+	}
+	if data, err := board.Read(ctx, TypePNG); err != nil || !bytes.Equal(data, large) {
+		t.Fatalf("read png: %d bytes, %v", len(data), err)
+	}
+
+	if err := board.Clear(ctx); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	if types, err := board.Types(ctx); err != nil || len(types) != 0 {
+		t.Fatalf("types after clear = %v, %v", types, err)
+	}
+}
@@ -0,0 +1,91 @@
+//go:build linux
+
+package clipboard
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/safeexec"
+)
+
+// waylandClipboard shells out to wl-copy and wl-paste from wl-clipboard.
+type waylandClipboard struct{}
+
+func (waylandClipboard) Types(ctx context.Context) ([]string, error) {
+	output, err := runWlPaste(ctx, "--list-types")
+	if errors.Is(err, ErrUnavailable) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return typesFromTargets(strings.Fields(string(output))), nil
+}
+
+func (c waylandClipboard) Read(ctx context.Context, mimeType string) ([]byte, error) {
+	output, err := runWlPaste(ctx, "--list-types")
+	if err != nil {
+		return nil, err
+	}
+	offered := strings.Fields(string(output))
+	for _, target := range targetNames[mimeType] {
+		for _, name := range offered {
+			if name == target {
+				return runWlPaste(ctx, "--no-newline", "--type", target)
+			}
+		}
+	}
+	return nil, ErrUnavailable
+}
+
+func (waylandClipboard) Write(ctx context.Context, mimeType string, data []byte) error {
+	cmd, cancel, err := safeexec.CommandContext(ctx, "wl-copy", "--type", mimeType)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	// wl-copy forks a child that keeps serving the data. Leaving stdout and stderr
+	// unset means Run only waits for the parent, not for the child's copies of the pipes.
+	cmd.Stdin = bytes.NewReader(data)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("wl-copy: %w", err)
+	}
+	return nil
+}
+
+func (waylandClipboard) Clear(ctx context.Context) error {
+	output, err := safeexec.RunCommand(ctx, "wl-copy", "--clear")
+	if err != nil {
+		return fmt.Errorf("wl-copy --clear: %w, output: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// runWlPaste returns wl-paste's stdout. An empty clipboard is reported as ErrUnavailable.
+func runWlPaste(ctx context.Context, args ...string) ([]byte, error) {
+	cmd, cancel, err := safeexec.CommandContext(ctx, "wl-paste", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && isEmptyWlPasteMessage(string(exitErr.Stderr)) {
+			return nil, ErrUnavailable
+		}
+		return nil, fmt.Errorf("wl-paste: %w", err)
+	}
+	return output, nil
+}
+
+// isEmptyWlPasteMessage recognizes the messages wl-paste prints when nothing is copied.
+func isEmptyWlPasteMessage(stderr string) bool {
+	stderr = strings.ToLower(stderr)
+	return strings.Contains(stderr, "nothing is copied") || strings.Contains(stderr, "no selection")
+}
@@ -0,0 +1,32 @@
+//go:build linux
+
+package clipboard
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/virtualdisplay"
+)
+
+func TestIsEmptyWlPasteMessage(t *testing.T) {
+	for _, stderr := range []string{"Nothing is copied\n", "No selection\n"} {
+		if !isEmptyWlPasteMessage(stderr) {
+			t.Errorf("%q should count as an empty clipboard", stderr)
+		}
+	}
+	if isEmptyWlPasteMessage("Failed to connect to a Wayland server\n") {
+		t.Error("connection failures must not look like an empty clipboard")
+	}
+}
+
+func TestVirtualDisplayClipboardRouting(t *testing.T) {
+	board := virtualDisplayClipboard{host: waylandClipboard{}}
+	if _, ok := board.pick(context.Background()).(waylandClipboard); !ok {
+		t.Error("calls without a virtual display should use the host clipboard")
+	}
+	ctx := virtualdisplay.WithDisplay(context.Background(), ":42")
+	if _, ok := board.pick(ctx).(x11Clipboard); !ok {
+		t.Error("calls aimed at a virtual display should use its X11 selection")
+	}
+}
@@ -0,0 +1,399 @@
+//go:build linux
+
+package clipboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/virtualdisplay"
+)
+
+const (
+	// selectionTimeout bounds how long the selection owner may take to answer one request.
+	selectionTimeout = 2 * time.Second
+	// incrChunkSize is the largest property written in one piece; larger values
+	// are sent with the INCR protocol so requests stay under the X request size limit.
+	incrChunkSize = 64 * 1024
+	// transferProperty is the property on our window that receives converted selections.
+	transferProperty = "SCREENSHOT_MCP_SELECTION"
+)
+
+var errNoConversion = errors.New("selection owner refused the conversion")
+
+// x11Clipboard uses the CLIPBOARD selection. Writing makes a hidden window the
+// selection owner; a goroutine serves requests from other clients until another
+// application takes the selection or the display goes away.
+type x11Clipboard struct{}
+
+// x11Conn is one X connection with an unmapped window for selection traffic.
+type x11Conn struct {
+	conn   *xgb.Conn
+	window xproto.Window
+	atoms  map[string]xproto.Atom
+	// queue receives events once events has been called; done stops the forwarder.
+	queue chan xgb.Event
+	done  chan struct{}
+}
+
+// openX11 connects to the display ctx targets: a session's virtual display or DISPLAY.
+func openX11(ctx context.Context) (*x11Conn, error) {
+	display := virtualdisplay.XDisplay(ctx)
+	if display == "" {
+		return nil, errors.New("the clipboard on Linux requires an X11 or Wayland display; set DISPLAY or call start_virtual_display")
+	}
+	conn, err := xgb.NewConnDisplay(display)
+	if err != nil {
+		return nil, fmt.Errorf("connect to X display %q: %w", display, err)
+	}
+	c := &x11Conn{conn: conn, atoms: make(map[string]xproto.Atom), done: make(chan struct{})}
+	if err := c.init(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *x11Conn) init() error {
+	names := []string{"CLIPBOARD", "TARGETS", "INCR", transferProperty}
+	for _, mimeType := range supportedTypes {
+		names = append(names, targetNames[mimeType]...)
+	}
+	cookies := make([]xproto.InternAtomCookie, len(names))
+	for i, name := range names {
+		cookies[i] = xproto.InternAtom(c.conn, false, uint16(len(name)), name)
+	}
+	for i, cookie := range cookies {
+		reply, err := cookie.Reply()
+		if err != nil {
+			return fmt.Errorf("intern atom %s: %w", names[i], err)
+		}
+		c.atoms[names[i]] = reply.Atom
+	}
+
+	window, err := xproto.NewWindowId(c.conn)
+	if err != nil {
+		return fmt.Errorf("allocate window id: %w", err)
+	}
+	root := xproto.Setup(c.conn).DefaultScreen(c.conn).Root
+	if err := xproto.CreateWindowChecked(c.conn, 0, window, root, 0, 0, 1, 1, 0,
+		xproto.WindowClassInputOnly, 0, xproto.CwEventMask, []uint32{xproto.EventMaskPropertyChange}).Check(); err != nil {
+		return fmt.Errorf("create selection window: %w", err)
+	}
+	c.window = window
+	return nil
+}
+
+func (c *x11Conn) close() {
+	close(c.done)
+	c.conn.Close()
+}
+
+// targetsFor returns the interned targets for a supported type, most preferred first.
+func (c *x11Conn) targetsFor(mimeType string) []xproto.Atom {
+	names := targetNames[mimeType]
+	atoms := make([]xproto.Atom, len(names))
+	for i, name := range names {
+		atoms[i] = c.atoms[name]
+	}
+	return atoms
+}
+
+func (x11Clipboard) Types(ctx context.Context) ([]string, error) {
+	c, err := openX11(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.close()
+
+	reply, err := c.convert(ctx, c.atoms["TARGETS"])
+	if errors.Is(err, errNoConversion) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[xproto.Atom]string, len(c.atoms))
+	for name, atom := range c.atoms {
+		names[atom] = name
+	}
+	var targets []string
+	for i := 0; i+4 <= len(reply); i += 4 {
+		if name, ok := names[xproto.Atom(xgb.Get32(reply[i:]))]; ok {
+			targets = append(targets, name)
+		}
+	}
+	return typesFromTargets(targets), nil
+}
+
+func (x11Clipboard) Read(ctx context.Context, mimeType string) ([]byte, error) {
+	c, err := openX11(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.close()
+
+	for _, target := range c.targetsFor(mimeType) {
+		data, err := c.convert(ctx, target)
+		if errors.Is(err, errNoConversion) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return data, nil
+	}
+	return nil, ErrUnavailable
+}
+
+func (x11Clipboard) Write(ctx context.Context, mimeType string, data []byte) error {
+	c, err := openX11(ctx)
+	if err != nil {
+		return err
+	}
+	clipboard := c.atoms["CLIPBOARD"]
+	xproto.SetSelectionOwner(c.conn, c.window, clipboard, xproto.TimeCurrentTime)
+	owner, err := xproto.GetSelectionOwner(c.conn, clipboard).Reply()
+	if err != nil {
+		c.close()
+		return fmt.Errorf("get clipboard owner: %w", err)
+	}
+	if owner.Owner != c.window {
+		c.close()
+		return fmt.Errorf("could not take ownership of the clipboard")
+	}
+
+	o := &x11Owner{x11Conn: c, data: data, targets: c.targetsFor(mimeType), transfers: make(map[transferKey]*incrTransfer)}
+	go o.serve()
+	return nil
+}
+
+func (x11Clipboard) Clear(ctx context.Context) error {
+	c, err := openX11(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.close()
+
+	// Any previous owner, including our own serving goroutine, receives SelectionClear.
+	clipboard := c.atoms["CLIPBOARD"]
+	xproto.SetSelectionOwner(c.conn, xproto.WindowNone, clipboard, xproto.TimeCurrentTime)
+	if _, err := xproto.GetSelectionOwner(c.conn, clipboard).Reply(); err != nil {
+		return fmt.Errorf("clear clipboard: %w", err)
+	}
+	return nil
+}
+
+// convert asks the selection owner for target and returns the transferred bytes.
+func (c *x11Conn) convert(ctx context.Context, target xproto.Atom) ([]byte, error) {
+	events := c.events()
+	property := c.atoms[transferProperty]
+	xproto.ConvertSelection(c.conn, c.window, c.atoms["CLIPBOARD"], target, property, xproto.TimeCurrentTime)
+
+	event, err := waitForEvent(ctx, events, func(event xgb.Event) bool {
+		notify, ok := event.(xproto.SelectionNotifyEvent)
+		return ok && notify.Requestor == c.window
+	})
+	if err != nil {
+		return nil, err
+	}
+	if event.(xproto.SelectionNotifyEvent).Property == xproto.AtomNone {
+		return nil, errNoConversion
+	}
+
+	reply, err := c.takeProperty(property)
+	if err != nil {
+		return nil, err
+	}
+	if reply.Type != c.atoms["INCR"] {
+		return reply.Value, nil
+	}
+
+	// INCR: deleting the property (done by takeProperty) asks for the next chunk;
+	// a zero-length chunk ends the transfer.
+	var data []byte
+	for {
+		if _, err := waitForEvent(ctx, events, func(event xgb.Event) bool {
+			notify, ok := event.(xproto.PropertyNotifyEvent)
+			return ok && notify.Window == c.window && notify.Atom == property && notify.State == xproto.PropertyNewValue
+		}); err != nil {
+			return nil, err
+		}
+		chunk, err := c.takeProperty(property)
+		if err != nil {
+			return nil, err
+		}
+		if len(chunk.Value) == 0 {
+			return data, nil
+		}
+		data = append(data, chunk.Value...)
+	}
+}
+
+// takeProperty reads and deletes a property on our window.
+func (c *x11Conn) takeProperty(property xproto.Atom) (*xproto.GetPropertyReply, error) {
+	reply, err := xproto.GetProperty(c.conn, true, c.window, property, xproto.GetPropertyTypeAny, 0, ^uint32(0)/4).Reply()
+	if err != nil {
+		return nil, fmt.Errorf("read selection property: %w", err)
+	}
+	return reply, nil
+}
+
+// events forwards the connection's events to a channel that is closed with the connection.
+func (c *x11Conn) events() <-chan xgb.Event {
+	if c.queue != nil {
+		return c.queue
+	}
+	c.queue = make(chan xgb.Event, 16)
+	go func() {
+		defer close(c.queue)
+		for {
+			event, err := c.conn.WaitForEvent()
+			if event == nil && err == nil {
+				return
+			}
+			if event == nil {
+				continue
+			}
+			select {
+			case c.queue <- event:
+			case <-c.done:
+				return
+			}
+		}
+	}()
+	return c.queue
+}
+
+func waitForEvent(ctx context.Context, events <-chan xgb.Event, match func(xgb.Event) bool) (xgb.Event, error) {
+	timer := time.NewTimer(selectionTimeout)
+	defer timer.Stop()
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return nil, fmt.Errorf("X connection closed")
+			}
+			if match(event) {
+				return event, nil
+			}
+		case <-timer.C:
+			return nil, fmt.Errorf("timed out after %s waiting for the clipboard owner", selectionTimeout)
+		case <-ctx.Done():
+			return nil, fmt.Errorf("read clipboard: %w", ctx.Err())
+		}
+	}
+}
+
+// x11Owner serves the CLIPBOARD selection for data written by Write.
+type x11Owner struct {
+	*x11Conn
+	data      []byte
+	targets   []xproto.Atom
+	transfers map[transferKey]*incrTransfer
+}
+
+type transferKey struct {
+	requestor xproto.Window
+	property  xproto.Atom
+}
+
+// incrTransfer is an INCR transfer in progress; offset is the next byte to send.
+type incrTransfer struct {
+	target xproto.Atom
+	offset int
+}
+
+func (o *x11Owner) serve() {
+	defer o.close()
+	for {
+		event, err := o.conn.WaitForEvent()
+		if event == nil && err == nil {
+			return
+		}
+		switch e := event.(type) {
+		case xproto.SelectionClearEvent:
+			return
+		case xproto.SelectionRequestEvent:
+			o.answer(e)
+		case xproto.PropertyNotifyEvent:
+			if e.State == xproto.PropertyDelete {
+				o.continueTransfer(transferKey{requestor: e.Window, property: e.Atom})
+			}
+		}
+	}
+}
+
+func (o *x11Owner) answer(request xproto.SelectionRequestEvent) {
+	// Obsolete clients pass no property and expect the target to be used.
+	property := request.Property
+	if property == xproto.AtomNone {
+		property = request.Target
+	}
+
+	switch {
+	case request.Target == o.atoms["TARGETS"]:
+		offered := append([]xproto.Atom{o.atoms["TARGETS"]}, o.targets...)
+		buf := make([]byte, 4*len(offered))
+		for i, atom := range offered {
+			xgb.Put32(buf[i*4:], uint32(atom))
+		}
+		xproto.ChangeProperty(o.conn, xproto.PropModeReplace, request.Requestor, property, xproto.AtomAtom, 32, uint32(len(offered)), buf)
+	case o.offers(request.Target):
+		o.send(request.Requestor, property, request.Target)
+	default:
+		property = xproto.AtomNone
+	}
+
+	notify := xproto.SelectionNotifyEvent{
+		Time:      request.Time,
+		Requestor: request.Requestor,
+		Selection: request.Selection,
+		Target:    request.Target,
+		Property:  property,
+	}
+	xproto.SendEvent(o.conn, false, request.Requestor, xproto.EventMaskNoEvent, string(notify.Bytes()))
+}
+
+func (o *x11Owner) offers(target xproto.Atom) bool {
+	for _, offered := range o.targets {
+		if offered == target {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *x11Owner) send(requestor xproto.Window, property, target xproto.Atom) {
+	if len(o.data) <= incrChunkSize {
+		xproto.ChangeProperty(o.conn, xproto.PropModeReplace, requestor, property, target, 8, uint32(len(o.data)), o.data)
+		return
+	}
+	// Watch the requestor's property so each deletion can trigger the next chunk.
+	xproto.ChangeWindowAttributes(o.conn, requestor, xproto.CwEventMask, []uint32{xproto.EventMaskPropertyChange})
+	size := make([]byte, 4)
+	xgb.Put32(size, uint32(len(o.data)))
+	xproto.ChangeProperty(o.conn, xproto.PropModeReplace, requestor, property, o.atoms["INCR"], 32, 1, size)
+	o.transfers[transferKey{requestor: requestor, property: property}] = &incrTransfer{target: target}
+}
+
+func (o *x11Owner) continueTransfer(key transferKey) {
+	transfer, ok := o.transfers[key]
+	if !ok {
+		return
+	}
+	end := min(transfer.offset+incrChunkSize, len(o.data))
+	chunk := o.data[transfer.offset:end]
+	xproto.ChangeProperty(o.conn, xproto.PropModeReplace, key.requestor, key.property, transfer.target, 8, uint32(len(chunk)), chunk)
+	if len(chunk) == 0 {
+		delete(o.transfers, key)
+		xproto.ChangeWindowAttributes(o.conn, key.requestor, xproto.CwEventMask, []uint32{xproto.EventMaskNoEvent})
+		return
+	}
+	transfer.offset = end
+}
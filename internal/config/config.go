@@ -0,0 +1,316 @@
+// Package config loads declarative server configuration files.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/imgencode"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/mcpserver"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/redact"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/screenshot"
+)
+
+// File is the on-disk server configuration. Every field is optional; omitted
+// values keep the compiled-in defaults and command-line flags override the file.
+type File struct {
+	Experimental bool      `json:"experimental" yaml:"experimental"`
+	DryRun       bool      `json:"dry_run" yaml:"dry_run"`
+	RunDir       string    `json:"run_dir" yaml:"run_dir"`
+	AuditLog     string    `json:"audit_log" yaml:"audit_log"`
+	Transport    Transport `json:"transport" yaml:"transport"`
+	Security     Security  `json:"security" yaml:"security"`
+	Tools        Tools     `json:"tools" yaml:"tools"`
+	Encoding     Encoding  `json:"encoding" yaml:"encoding"`
+	Defaults     Defaults  `json:"defaults" yaml:"defaults"`
+	Replay       Replay    `json:"replay" yaml:"replay"`
+	// Redaction blacks out screen regions in every screenshot and recording.
+	Redaction redact.Rules `json:"redaction" yaml:"redaction"`
+}
+
+// Transport configures where the HTTP transports listen.
+type Transport struct {
+	Port           int      `json:"port" yaml:"port"`
+	Bind           string   `json:"bind" yaml:"bind"`
+	UnixSocket     string   `json:"unix_socket" yaml:"unix_socket"`
+	UnixSocketMode FileMode `json:"unix_socket_mode" yaml:"unix_socket_mode"`
+	TLSCert        string   `json:"tls_cert" yaml:"tls_cert"`
+	TLSKey         string   `json:"tls_key" yaml:"tls_key"`
+	Timeouts       Timeouts `json:"timeouts" yaml:"timeouts"`
+}
+
+// Timeouts configures the HTTP server timeouts as Go duration strings such as "10s".
+type Timeouts struct {
+	ReadHeader Duration `json:"read_header" yaml:"read_header"`
+	Read       Duration `json:"read" yaml:"read"`
+	Write      Duration `json:"write" yaml:"write"`
+	Idle       Duration `json:"idle" yaml:"idle"`
+}
+
+// Security configures authentication and request origin checks for HTTP transports.
+type Security struct {
+	AuthToken      string   `json:"auth_token" yaml:"auth_token"`
+	AuthTokenFile  string   `json:"auth_token_file" yaml:"auth_token_file"`
+	AllowedOrigins []string `json:"allowed_origins" yaml:"allowed_origins"`
+	AllowedHosts   []string `json:"allowed_hosts" yaml:"allowed_hosts"`
+}
+
+// Tools selects the exposed tool set and how many capture-heavy calls run at once.
+type Tools struct {
+	Profile            string   `json:"profile" yaml:"profile"`
+	Allow              []string `json:"allow" yaml:"allow"`
+	Deny               []string `json:"deny" yaml:"deny"`
+	CaptureConcurrency int      `json:"capture_concurrency" yaml:"capture_concurrency"`
+	// CaptureCacheMaxAge is how long captured frames are shared; "0s" disables
+	// the cache and omitting it keeps the default.
+	CaptureCacheMaxAge *Duration `json:"capture_cache_max_age" yaml:"capture_cache_max_age"`
+}
+
+// Encoding configures JPEG output; see imgencode.Options. Omitted fields keep
+// the imgencode.DefaultOptions values.
+type Encoding struct {
+	Quality     int `json:"quality" yaml:"quality"`
+	MaxBytes    int `json:"max_bytes" yaml:"max_bytes"`
+	MinQuality  int `json:"min_quality" yaml:"min_quality"`
+	QualityStep int `json:"quality_step" yaml:"quality_step"`
+}
+
+// Defaults configures values tools fall back to when arguments are omitted.
+type Defaults struct {
+	ImageMatchThreshold float64 `json:"image_match_threshold" yaml:"image_match_threshold"`
+	ComparisonThreshold float64 `json:"comparison_threshold" yaml:"comparison_threshold"`
+	WaitTimeoutMs       int     `json:"wait_timeout_ms" yaml:"wait_timeout_ms"`
+	PollIntervalMs      int     `json:"poll_interval_ms" yaml:"poll_interval_ms"`
+	ImageWaitTimeoutMs  int     `json:"image_wait_timeout_ms" yaml:"image_wait_timeout_ms"`
+	ImageWaitPollMs     int     `json:"image_wait_poll_ms" yaml:"image_wait_poll_ms"`
+}
+
+// Replay plays back recorded frames instead of reading the screen; see
+// screenshot.ReplayOptions.
+type Replay struct {
+	Source        string   `json:"source" yaml:"source"`
+	Mode          string   `json:"mode" yaml:"mode"`
+	FrameInterval Duration `json:"frame_interval" yaml:"frame_interval"`
+	Loop          bool     `json:"loop" yaml:"loop"`
+}
+
+// Options converts the section into screenshot.ReplayOptions.
+func (r Replay) Options() screenshot.ReplayOptions {
+	return screenshot.ReplayOptions{
+		Source:        r.Source,
+		Mode:          r.Mode,
+		FrameInterval: time.Duration(r.FrameInterval),
+		Loop:          r.Loop,
+	}
+}
+
+// Duration is a time.Duration written as a string such as "500ms" or "2m".
+type Duration time.Duration
+
+// UnmarshalJSON parses a duration string.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("duration must be a string like \"10s\": %w", err)
+	}
+	return d.parse(text)
+}
+
+// UnmarshalYAML parses a duration string.
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	return d.parse(node.Value)
+}
+
+func (d *Duration) parse(text string) error {
+	parsed, err := time.ParseDuration(strings.TrimSpace(text))
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", text, err)
+	}
+	if parsed < 0 {
+		return fmt.Errorf("duration %q must not be negative", text)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// FileMode is a permission mode written as an octal string such as "0660".
+type FileMode os.FileMode
+
+// UnmarshalJSON parses an octal mode string.
+func (m *FileMode) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("file mode must be an octal string like \"0660\": %w", err)
+	}
+	return m.parse(text)
+}
+
+// UnmarshalYAML parses an octal mode string.
+func (m *FileMode) UnmarshalYAML(node *yaml.Node) error {
+	return m.parse(node.Value)
+}
+
+func (m *FileMode) parse(text string) error {
+	mode, err := strconv.ParseUint(strings.TrimSpace(text), 8, 32)
+	if err != nil || mode > 0o777 {
+		return fmt.Errorf("invalid file mode %q: expected octal permissions like \"0660\"", text)
+	}
+	*m = FileMode(mode)
+	return nil
+}
+
+// Load reads a JSON (.json) or YAML (.yaml, .yml) configuration file.
+// Unknown keys are rejected so typos do not silently fall back to defaults.
+func Load(path string) (*File, error) {
+	// Accepted G304 suppression: the config path is operator-provided on the command line.
+	// #nosec G304
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+
+	var file File
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = decodeJSON(data, &file)
+	case ".yaml", ".yml":
+		err = decodeYAML(data, &file)
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported extension; use .json, .yaml or .yml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+	if err := file.Validate(); err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+	return &file, nil
+}
+
+func decodeJSON(data []byte, file *File) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(file); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return fmt.Errorf("parse json: %w", err)
+	}
+	if decoder.More() {
+		return fmt.Errorf("parse json: unexpected data after the top-level object")
+	}
+	return nil
+}
+
+func decodeYAML(data []byte, file *File) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(file); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return fmt.Errorf("parse yaml: %w", err)
+	}
+	return nil
+}
+
+// Validate checks values that would otherwise fail later, at listen or tool-call time.
+func (f *File) Validate() error {
+	if port := f.Transport.Port; port < 0 || port > 65535 {
+		return fmt.Errorf("transport.port %d is out of range", port)
+	}
+	if (f.Transport.TLSCert == "") != (f.Transport.TLSKey == "") {
+		return fmt.Errorf("transport.tls_cert and transport.tls_key must be set together")
+	}
+	if f.Security.AuthToken != "" && f.Security.AuthTokenFile != "" {
+		return fmt.Errorf("security.auth_token and security.auth_token_file are mutually exclusive")
+	}
+	if q := f.Encoding.Quality; q < 0 || q > 100 {
+		return fmt.Errorf("encoding.quality %d must be 0 (default) or 1..100", q)
+	}
+	if q := f.Encoding.MinQuality; q < 0 || q > 100 {
+		return fmt.Errorf("encoding.min_quality %d must be 0 (default) or 1..100", q)
+	}
+	if f.Encoding.MaxBytes < 0 || f.Encoding.QualityStep < 0 {
+		return fmt.Errorf("encoding.max_bytes and encoding.quality_step must be >= 0")
+	}
+	if f.Tools.CaptureConcurrency < 0 {
+		return fmt.Errorf("tools.capture_concurrency must be >= 0")
+	}
+	if err := screenshot.ValidateReplayMode(f.Replay.Mode); err != nil {
+		return fmt.Errorf("replay.mode: %w", err)
+	}
+	if err := f.Redaction.Validate(); err != nil {
+		return fmt.Errorf("redaction.%w", err)
+	}
+	cfg := f.ServerConfig()
+	if err := cfg.Defaults.Validate(); err != nil {
+		return fmt.Errorf("defaults: %w", err)
+	}
+	if err := cfg.ValidateToolPolicy(); err != nil {
+		return fmt.Errorf("tools: %w", err)
+	}
+	return nil
+}
+
+// ServerConfig converts the file into an mcpserver.Config.
+func (f *File) ServerConfig() mcpserver.Config {
+	var cacheMaxAge time.Duration
+	if f.Tools.CaptureCacheMaxAge != nil {
+		cacheMaxAge = time.Duration(*f.Tools.CaptureCacheMaxAge)
+		if cacheMaxAge == 0 {
+			cacheMaxAge = -1
+		}
+	}
+	return mcpserver.Config{
+		ExperimentalTools: f.Experimental,
+		DryRun:            f.DryRun,
+		HTTP: mcpserver.HTTPConfig{
+			BindAddress:    f.Transport.Bind,
+			AuthToken:      f.Security.AuthToken,
+			AuthTokenFile:  f.Security.AuthTokenFile,
+			AllowedOrigins: f.Security.AllowedOrigins,
+			AllowedHosts:   f.Security.AllowedHosts,
+			TLSCertFile:    f.Transport.TLSCert,
+			TLSKeyFile:     f.Transport.TLSKey,
+			UnixSocket:     f.Transport.UnixSocket,
+			UnixSocketMode: os.FileMode(f.Transport.UnixSocketMode),
+			Timeouts: mcpserver.HTTPTimeouts{
+				ReadHeader: time.Duration(f.Transport.Timeouts.ReadHeader),
+				Read:       time.Duration(f.Transport.Timeouts.Read),
+				Write:      time.Duration(f.Transport.Timeouts.Write),
+				Idle:       time.Duration(f.Transport.Timeouts.Idle),
+			},
+		},
+		ToolProfile:        f.Tools.Profile,
+		AllowedTools:       f.Tools.Allow,
+		DeniedTools:        f.Tools.Deny,
+		CaptureConcurrency: f.Tools.CaptureConcurrency,
+		CaptureCacheMaxAge: cacheMaxAge,
+		Encoding: imgencode.WithDefaults(imgencode.Options{
+			Quality:     f.Encoding.Quality,
+			MaxBytes:    f.Encoding.MaxBytes,
+			MinQuality:  f.Encoding.MinQuality,
+			QualityStep: f.Encoding.QualityStep,
+		}),
+		Defaults: mcpserver.ToolDefaults{
+			ImageMatchThreshold: f.Defaults.ImageMatchThreshold,
+			ComparisonThreshold: f.Defaults.ComparisonThreshold,
+			WaitTimeoutMs:       f.Defaults.WaitTimeoutMs,
+			PollIntervalMs:      f.Defaults.PollIntervalMs,
+			ImageWaitTimeoutMs:  f.Defaults.ImageWaitTimeoutMs,
+			ImageWaitPollMs:     f.Defaults.ImageWaitPollMs,
+		},
+		Redaction: f.Redaction,
+	}
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/imgencode"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/mcpserver"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
+
+const sampleYAML = `
+experimental: true
+run_dir: ./artifacts
+transport:
+  port: 4100
+  bind: 0.0.0.0
+  unix_socket_mode: "0660"
+  timeouts:
+    read_header: 2s
+    write: 1m
+security:
+  auth_token_file: /etc/screenshot-mcp/token
+  allowed_origins: [http://localhost:5173]
+tools:
+  profile: observe
+  deny: [list_windows]
+  capture_cache_max_age: 20ms
+encoding:
+  quality: 80
+  max_bytes: 500000
+defaults:
+  comparison_threshold: 0.9
+  wait_timeout_ms: 2000
+  image_wait_poll_ms: 250
+redaction:
+  rects:
+    - {x: 0, y: 0, width: 300, height: 40}
+  windows:
+    - owner: 1Password
+    - title: Billing
+      regions:
+        - {x: 20, y: 100, width: 400, height: 30}
+  patterns: ['[\w.+-]+@[\w-]+\.[\w.]+']
+`
+
+func TestLoad_YAML(t *testing.T) {
+	file, err := Load(writeConfig(t, "server.yaml", sampleYAML))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	cfg := file.ServerConfig()
+
+	if !cfg.ExperimentalTools || file.RunDir != "./artifacts" || file.Transport.Port != 4100 {
+		t.Fatalf("unexpected top-level values: %+v", file)
+	}
+	if cfg.HTTP.BindAddress != "0.0.0.0" || cfg.HTTP.UnixSocketMode != 0o660 || cfg.HTTP.AuthTokenFile != "/etc/screenshot-mcp/token" {
+		t.Fatalf("unexpected http config: %+v", cfg.HTTP)
+	}
+	if cfg.HTTP.Timeouts.ReadHeader != 2*time.Second || cfg.HTTP.Timeouts.Write != time.Minute || cfg.HTTP.Timeouts.Idle != 0 {
+		t.Fatalf("unexpected timeouts: %+v", cfg.HTTP.Timeouts)
+	}
+	if cfg.ToolProfile != mcpserver.ToolProfileObserve || len(cfg.DeniedTools) != 1 || cfg.CaptureCacheMaxAge != 20*time.Millisecond {
+		t.Fatalf("unexpected tool policy: %+v", cfg)
+	}
+	if cfg.Encoding.Quality != 80 || cfg.Encoding.MaxBytes != 500_000 {
+		t.Fatalf("unexpected encoding: %+v", cfg.Encoding)
+	}
+	if cfg.Defaults.ComparisonThreshold != 0.9 || cfg.Defaults.WaitTimeoutMs != 2000 || cfg.Defaults.ImageWaitPollMs != 250 {
+		t.Fatalf("unexpected defaults: %+v", cfg.Defaults)
+	}
+	redaction := cfg.Redaction
+	if len(redaction.Rects) != 1 || redaction.Rects[0].Width != 300 || len(redaction.Windows) != 2 ||
+		redaction.Windows[1].Regions[0].Y != 100 || len(redaction.Patterns) != 1 {
+		t.Fatalf("unexpected redaction rules: %+v", redaction)
+	}
+}
+
+func TestLoad_JSON(t *testing.T) {
+	file, err := Load(writeConfig(t, "server.json", `{
+		"tools": {"profile": "full", "allow": ["click"], "capture_cache_max_age": "0s"},
+		"transport": {"timeouts": {"idle": "45s"}},
+		"encoding": {"quality": 70}
+	}`))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	cfg := file.ServerConfig()
+	if cfg.ToolProfile != "full" || cfg.Encoding.Quality != 70 || cfg.HTTP.Timeouts.Idle != 45*time.Second {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	// Fields the encoding block leaves out keep their defaults, including the size cap.
+	if cfg.Encoding.MaxBytes != 1_000_000 || cfg.Encoding.MinQuality != imgencode.DefaultOptions.MinQuality {
+		t.Fatalf("unexpected encoding: %+v", cfg.Encoding)
+	}
+	// An explicit zero disables the cache rather than keeping the default.
+	if cfg.CaptureCacheMaxAge >= 0 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestLoad_EmptyFile(t *testing.T) {
+	for _, name := range []string{"empty.yaml", "empty.json"} {
+		if _, err := Load(writeConfig(t, name, "")); err != nil {
+			t.Fatalf("Load(%s) error = %v", name, err)
+		}
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    string
+	}{
+		{name: "unknown yaml key", file: "c.yaml", content: "transport:\n  prot: 3001\n", want: "prot"},
+		{name: "unknown json key", file: "c.json", content: `{"encodng": {}}`, want: "encodng"},
+		{name: "unsupported extension", file: "c.toml", content: "", want: "unsupported extension"},
+		{name: "bad duration", file: "c.yaml", content: "transport:\n  timeouts:\n    read: soon\n", want: "invalid duration"},
+		{name: "bad mode", file: "c.json", content: `{"transport": {"unix_socket_mode": "rw"}}`, want: "invalid file mode"},
+		{name: "bad quality", file: "c.yaml", content: "encoding:\n  quality: 150\n", want: "0 (default) or 1..100"},
+		{name: "bad threshold", file: "c.yaml", content: "defaults:\n  image_match_threshold: 2\n", want: "threshold"},
+		{name: "tls pair", file: "c.yaml", content: "transport:\n  tls_cert: cert.pem\n", want: "tls_key"},
+		{name: "unknown profile", file: "c.yaml", content: "tools:\n  profile: admin\n", want: "admin"},
+		{name: "unknown tool", file: "c.yaml", content: "tools:\n  deny: [rm_rf]\n", want: "rm_rf"},
+		{name: "bad replay mode", file: "c.yaml", content: "replay:\n  mode: shuffle\n", want: "replay.mode"},
+		{name: "bad redaction pattern", file: "c.yaml", content: "redaction:\n  patterns: ['(']\n", want: "redaction.patterns[0]"},
+		{name: "unmatched window rule", file: "c.json", content: `{"redaction": {"windows": [{"regions": []}]}}`, want: "owner or title"},
+		{name: "trailing json", file: "c.json", content: `{} {}`, want: "unexpected data"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, tt.file, tt.content))
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error %q does not mention %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// Package delta finds the parts of a screenshot that changed since the
+// previous one, so a caller polling the screen only resends what moved.
+package delta
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"sort"
+)
+
+// DefaultTileSize is the tile edge in image pixels.
+const DefaultTileSize = 64
+
+// Diff is the result of comparing two frames of the same size.
+type Diff struct {
+	// Rects cover every changed tile, in reading order. Adjacent changed tiles
+	// are merged, first along a row of tiles and then down runs with the same
+	// columns, so a changed panel comes back as one rectangle rather than
+	// dozens of tiles.
+	Rects []image.Rectangle
+	// ChangedTiles and Tiles count changed tiles and all tiles.
+	ChangedTiles, Tiles int
+	// Ratio is the share of the image area covered by changed tiles.
+	Ratio float64
+}
+
+// ToRGBA copies img into a new RGBA image whose top-left pixel is the origin.
+func ToRGBA(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(out, out.Bounds(), img, bounds.Min, draw.Src)
+	return out
+}
+
+// Compare splits prev and next into tileSize squares and reports the tiles in
+// which any color channel differs by more than tolerance. Both images must
+// start at the origin, as ToRGBA returns them.
+func Compare(prev, next *image.RGBA, tileSize, tolerance int) (Diff, error) {
+	if prev.Rect.Size() != next.Rect.Size() {
+		return Diff{}, fmt.Errorf("frame size changed from %v to %v", prev.Rect.Size(), next.Rect.Size())
+	}
+	if tileSize <= 0 {
+		return Diff{}, fmt.Errorf("tile size must be positive")
+	}
+	width, height := next.Rect.Dx(), next.Rect.Dy()
+	if width == 0 || height == 0 {
+		return Diff{}, nil
+	}
+
+	var diff Diff
+	var open []image.Rectangle // runs from the previous tile row that may grow downwards
+	changedArea := 0
+	for y := 0; y < height; y += tileSize {
+		var runs []image.Rectangle
+		for x := 0; x < width; x += tileSize {
+			tile := image.Rect(x, y, min(x+tileSize, width), min(y+tileSize, height))
+			diff.Tiles++
+			if !tileChanged(prev, next, tile, tolerance) {
+				continue
+			}
+			diff.ChangedTiles++
+			changedArea += tile.Dx() * tile.Dy()
+			if n := len(runs); n > 0 && runs[n-1].Max.X == tile.Min.X {
+				runs[n-1].Max.X = tile.Max.X
+				continue
+			}
+			runs = append(runs, tile)
+		}
+
+		var grown []image.Rectangle
+		for _, run := range runs {
+			merged := false
+			for i, above := range open {
+				if above.Min.X == run.Min.X && above.Max.X == run.Max.X {
+					open[i].Max.Y = run.Max.Y
+					grown = append(grown, open[i])
+					open = append(open[:i], open[i+1:]...)
+					merged = true
+					break
+				}
+			}
+			if !merged {
+				grown = append(grown, run)
+			}
+		}
+		diff.Rects = append(diff.Rects, open...)
+		open = grown
+	}
+	diff.Rects = append(diff.Rects, open...)
+	sort.Slice(diff.Rects, func(i, j int) bool {
+		if diff.Rects[i].Min.Y != diff.Rects[j].Min.Y {
+			return diff.Rects[i].Min.Y < diff.Rects[j].Min.Y
+		}
+		return diff.Rects[i].Min.X < diff.Rects[j].Min.X
+	})
+	diff.Ratio = float64(changedArea) / float64(width*height)
+	return diff, nil
+}
+
+func tileChanged(prev, next *image.RGBA, tile image.Rectangle, tolerance int) bool {
+	for y := tile.Min.Y; y < tile.Max.Y; y++ {
+		a := prev.Pix[prev.PixOffset(tile.Min.X, y):prev.PixOffset(tile.Max.X, y)]
+		b := next.Pix[next.PixOffset(tile.Min.X, y):next.PixOffset(tile.Max.X, y)]
+		for i := range a {
+			d := int(a[i]) - int(b[i])
+			if d > tolerance || -d > tolerance {
+				return true
+			}
+		}
+	}
+	return false
+}
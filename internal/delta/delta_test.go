@@ -0,0 +1,57 @@
+package delta
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func fill(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+func TestCompare_MergesChangedTiles(t *testing.T) {
+	prev := image.NewRGBA(image.Rect(0, 0, 100, 50))
+	next := image.NewRGBA(prev.Rect)
+	red := color.RGBA{R: 255, A: 255}
+	// A panel spanning tiles 1-2 in rows 0-1, and one pixel in the bottom-right
+	// edge tile.
+	fill(next, image.Rect(25, 5, 55, 35), red)
+	next.SetRGBA(99, 49, red)
+
+	diff, err := Compare(prev, next, 20, 0)
+	if err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+	want := []image.Rectangle{image.Rect(20, 0, 60, 40), image.Rect(80, 40, 100, 50)}
+	if len(diff.Rects) != len(want) || diff.Rects[0] != want[0] || diff.Rects[1] != want[1] {
+		t.Fatalf("Rects = %v, want %v", diff.Rects, want)
+	}
+	if diff.Tiles != 15 || diff.ChangedTiles != 5 {
+		t.Fatalf("tiles = %d changed of %d, want 5 of 15", diff.ChangedTiles, diff.Tiles)
+	}
+	if want := float64(40*40+20*10) / (100 * 50); math.Abs(diff.Ratio-want) > 1e-9 {
+		t.Fatalf("Ratio = %v, want %v", diff.Ratio, want)
+	}
+}
+
+func TestCompare_Tolerance(t *testing.T) {
+	prev := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	next := image.NewRGBA(prev.Rect)
+	fill(next, next.Rect, color.RGBA{R: 3, G: 3, B: 3})
+
+	if diff, _ := Compare(prev, next, 4, 3); len(diff.Rects) != 0 || diff.Ratio != 0 {
+		t.Fatalf("diff = %+v, want no change within tolerance 3", diff)
+	}
+	if diff, _ := Compare(prev, next, 4, 2); len(diff.Rects) != 1 || diff.Ratio != 1 {
+		t.Fatalf("diff = %+v, want one rect covering the frame", diff)
+	}
+	if _, err := Compare(prev, image.NewRGBA(image.Rect(0, 0, 8, 9)), 4, 0); err == nil {
+		t.Fatal("expected frames of different sizes to be rejected")
+	}
+}
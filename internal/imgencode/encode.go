@@ -0,0 +1,164 @@
+package imgencode
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+)
+
+// Output formats accepted by Encode.
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+)
+
+// Output selects an image format and the transforms applied before encoding.
+type Output struct {
+	// Format is FormatJPEG or FormatPNG. Empty means JPEG.
+	Format string
+	// JPEG controls quality and the size cap; PNG output ignores it.
+	JPEG Options
+	// MaxWidth and MaxHeight downscale the image to fit, keeping its aspect
+	// ratio. Zero means no limit.
+	MaxWidth  int
+	MaxHeight int
+	Grayscale bool
+}
+
+// Encoding reports the parameters an image was actually encoded with.
+type Encoding struct {
+	Format   string `json:"format"`
+	MimeType string `json:"mime_type"`
+	// Quality is the JPEG quality after the MaxBytes fallback loop.
+	Quality  int  `json:"quality,omitempty"`
+	MaxBytes int  `json:"max_bytes,omitempty"`
+	Bytes    int  `json:"bytes"`
+	Width    int  `json:"width"`
+	Height   int  `json:"height"`
+	Scaled   bool `json:"scaled"`
+	// SourceWidth and SourceHeight are the captured size before downscaling.
+	SourceWidth  int  `json:"source_width"`
+	SourceHeight int  `json:"source_height"`
+	Grayscale    bool `json:"grayscale"`
+}
+
+// NormalizeFormat returns the canonical name for format, accepting "jpg" and
+// any case. Empty means JPEG.
+func NormalizeFormat(format string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", FormatJPEG, "jpg":
+		return FormatJPEG, nil
+	case FormatPNG:
+		return FormatPNG, nil
+	default:
+		return "", fmt.Errorf("unsupported format %q (use jpeg or png)", format)
+	}
+}
+
+// Encode downscales and converts img as out requests, then encodes it.
+func Encode(img image.Image, out Output) ([]byte, *Encoding, error) {
+	if img == nil {
+		return nil, nil, fmt.Errorf("image is nil")
+	}
+	format, err := NormalizeFormat(out.Format)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	source := img.Bounds()
+	img = Fit(img, out.MaxWidth, out.MaxHeight)
+	if out.Grayscale {
+		img = toGray(img)
+	}
+	encoding := &Encoding{
+		Format:       format,
+		Width:        img.Bounds().Dx(),
+		Height:       img.Bounds().Dy(),
+		Scaled:       img.Bounds().Size() != source.Size(),
+		SourceWidth:  source.Dx(),
+		SourceHeight: source.Dy(),
+		Grayscale:    out.Grayscale,
+	}
+
+	var data []byte
+	if format == FormatPNG {
+		encoding.MimeType = "image/png"
+		if data, err = EncodePNG(img); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		opts := normalizeOptions(out.JPEG)
+		encoding.MimeType = "image/jpeg"
+		encoding.MaxBytes = opts.MaxBytes
+		if data, encoding.Quality, err = encodeJPEG(img, opts); err != nil {
+			return nil, nil, err
+		}
+	}
+	encoding.Bytes = len(data)
+	return data, encoding, nil
+}
+
+// Fit downscales img to fit within maxWidth x maxHeight, keeping its aspect ratio,
+// by averaging the source pixels that cover each output pixel. A zero limit is
+// ignored, and images that already fit are returned unchanged.
+func Fit(img image.Image, maxWidth, maxHeight int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	scale := 1.0
+	if maxWidth > 0 && width > maxWidth {
+		scale = float64(maxWidth) / float64(width)
+	}
+	if maxHeight > 0 && height > maxHeight {
+		scale = min(scale, float64(maxHeight)/float64(height))
+	}
+	if scale >= 1 {
+		return img
+	}
+	dstWidth := max(1, int(float64(width)*scale))
+	dstHeight := max(1, int(float64(height)*scale))
+
+	src := toRGBA(img)
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for dy := 0; dy < dstHeight; dy++ {
+		y0, y1 := dy*height/dstHeight, max((dy+1)*height/dstHeight, dy*height/dstHeight+1)
+		for dx := 0; dx < dstWidth; dx++ {
+			x0, x1 := dx*width/dstWidth, max((dx+1)*width/dstWidth, dx*width/dstWidth+1)
+			var r, g, b, a, n int
+			for y := y0; y < y1; y++ {
+				row := src.Pix[y*src.Stride:]
+				for x := x0; x < x1; x++ {
+					p := row[x*4 : x*4+4]
+					r, g, b, a = r+int(p[0]), g+int(p[1]), b+int(p[2]), a+int(p[3])
+					n++
+				}
+			}
+			o := dst.PixOffset(dx, dy)
+			dst.Pix[o], dst.Pix[o+1], dst.Pix[o+2], dst.Pix[o+3] = uint8(r/n), uint8(g/n), uint8(b/n), uint8(a/n)
+		}
+	}
+	return dst
+}
+
+// toRGBA returns img as an RGBA image whose bounds start at the origin.
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Rect.Min == (image.Point{}) {
+		return rgba
+	}
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	return rgba
+}
+
+func toGray(img image.Image) *image.Gray {
+	bounds := img.Bounds()
+	gray := image.NewGray(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			gray.Set(x, y, color.GrayModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)))
+		}
+	}
+	return gray
+}
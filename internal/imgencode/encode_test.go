@@ -0,0 +1,82 @@
+package imgencode
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestEncode_DownscalesAndReportsEncoding(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 400, 100))
+	data, encoding, err := Encode(img, Output{JPEG: Options{Quality: 80}, MaxWidth: 200, MaxHeight: 200})
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if encoding.Format != FormatJPEG || encoding.MimeType != "image/jpeg" || encoding.Quality != 80 {
+		t.Fatalf("encoding = %+v", encoding)
+	}
+	if encoding.Width != 200 || encoding.Height != 50 || !encoding.Scaled || encoding.SourceWidth != 400 {
+		t.Fatalf("encoding size = %+v, want 200x50 scaled from 400x100", encoding)
+	}
+	if encoding.Bytes != len(data) {
+		t.Fatalf("bytes = %d, want %d", encoding.Bytes, len(data))
+	}
+}
+
+func TestEncode_GrayscalePNG(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	img.SetRGBA(0, 0, color.RGBA{R: 255, A: 255})
+
+	data, encoding, err := Encode(img, Output{Format: "PNG", Grayscale: true})
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if encoding.Format != FormatPNG || encoding.Quality != 0 || encoding.Scaled || !encoding.Grayscale {
+		t.Fatalf("encoding = %+v", encoding)
+	}
+	decoded, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decode png: %v", err)
+	}
+	if _, ok := decoded.(*image.Gray); !ok {
+		t.Fatalf("decoded %T, want *image.Gray", decoded)
+	}
+}
+
+func TestFit_AveragesPixels(t *testing.T) {
+	img := image.NewRGBA(image.Rect(10, 10, 14, 12))
+	for x := 10; x < 14; x++ {
+		c := color.RGBA{A: 255}
+		if x%2 == 0 {
+			c.R = 200
+		}
+		img.SetRGBA(x, 10, c)
+		img.SetRGBA(x, 11, c)
+	}
+	fitted := Fit(img, 2, 0)
+	if fitted.Bounds() != image.Rect(0, 0, 2, 1) {
+		t.Fatalf("bounds = %v, want 2x1", fitted.Bounds())
+	}
+	if r, _, _, _ := fitted.At(0, 0).RGBA(); r>>8 != 100 {
+		t.Fatalf("red = %d, want the 100 average", r>>8)
+	}
+	if Fit(img, 10, 10) != image.Image(img) {
+		t.Fatal("expected an image that already fits to be returned unchanged")
+	}
+}
+
+func TestNormalizeFormat(t *testing.T) {
+	for input, want := range map[string]string{"": FormatJPEG, "JPG": FormatJPEG, "png": FormatPNG} {
+		if got, err := NormalizeFormat(input); err != nil || got != want {
+			t.Errorf("NormalizeFormat(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := NormalizeFormat("webp"); err == nil {
+		t.Fatal("expected webp to be rejected")
+	}
+}
@@ -24,10 +24,34 @@ var DefaultOptions = Options{
 	QualityStep: 5,
 }
 
+// WithDefaults returns opts with each zero field taken from DefaultOptions, so
+// setting only Quality keeps the default MaxBytes cap.
+func WithDefaults(opts Options) Options {
+	if opts.Quality == 0 {
+		opts.Quality = DefaultOptions.Quality
+	}
+	if opts.MaxBytes == 0 {
+		opts.MaxBytes = DefaultOptions.MaxBytes
+	}
+	if opts.MinQuality == 0 {
+		opts.MinQuality = DefaultOptions.MinQuality
+	}
+	if opts.QualityStep == 0 {
+		opts.QualityStep = DefaultOptions.QualityStep
+	}
+	return opts
+}
+
 // EncodeJPEG encodes img to JPEG using a quality fallback loop when MaxBytes is set.
 func EncodeJPEG(img image.Image, opts Options) ([]byte, error) {
+	data, _, err := encodeJPEG(img, opts)
+	return data, err
+}
+
+// encodeJPEG is EncodeJPEG that also returns the quality the output was encoded at.
+func encodeJPEG(img image.Image, opts Options) ([]byte, int, error) {
 	if img == nil {
-		return nil, fmt.Errorf("image is nil")
+		return nil, 0, fmt.Errorf("image is nil")
 	}
 
 	opts = normalizeOptions(opts)
@@ -36,11 +60,11 @@ func EncodeJPEG(img image.Image, opts Options) ([]byte, error) {
 	for {
 		data, err := encodeAtQuality(img, quality)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 
 		if opts.MaxBytes <= 0 || len(data) <= opts.MaxBytes || quality <= opts.MinQuality {
-			return data, nil
+			return data, quality, nil
 		}
 
 		quality -= opts.QualityStep
@@ -27,7 +27,6 @@ import "C"
 import (
 	"context"
 	"fmt"
-	"strings"
 	"time"
 )
 
@@ -200,10 +199,6 @@ func (darwinController) KeyUp(ctx context.Context, key string, modifiers []strin
 	return darwinPostKeyEvent(keyCode, flags, false)
 }
 
-func normalizeToken(raw string) string {
-	return strings.TrimSpace(strings.ToLower(raw))
-}
-
 func darwinModifierFlags(raw []string) (C.CGEventFlags, error) {
 	var flags C.CGEventFlags
 	for _, item := range raw {
@@ -0,0 +1,512 @@
+//go:build linux
+
+package input
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+	"github.com/jezek/xgb/xtest"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/virtualdisplay"
+)
+
+// remapSettleDelay gives clients time to process MappingNotify before a key on
+// a temporarily remapped keycode is pressed.
+const remapSettleDelay = 10 * time.Millisecond
+
+type x11Controller struct{}
+
+// NewController returns the Linux input controller, which injects X11 events through XTEST.
+func NewController() Controller {
+	return x11Controller{}
+}
+
+func (x11Controller) PressKey(ctx context.Context, key string, modifiers []string) error {
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("context canceled: %w", ctx.Err())
+	default:
+	}
+
+	keysym, modifierSyms, err := resolveX11Key(key, modifiers)
+	if err != nil {
+		return err
+	}
+
+	keyboard, err := openX11Keyboard(ctx)
+	if err != nil {
+		return err
+	}
+	defer keyboard.close()
+
+	if err := keyboard.sendKeysym(keysym, modifierSyms, true); err != nil {
+		return err
+	}
+	if err := keyboard.sendKeysym(keysym, modifierSyms, false); err != nil {
+		return err
+	}
+	return keyboard.sync()
+}
+
+func (x11Controller) TypeText(ctx context.Context, text string, delayMs int) error {
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("context canceled: %w", ctx.Err())
+	default:
+	}
+
+	keyboard, err := openX11Keyboard(ctx)
+	if err != nil {
+		return err
+	}
+	defer keyboard.close()
+	defer keyboard.restoreScratch()
+
+	for _, r := range text {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("context canceled: %w", ctx.Err())
+		default:
+		}
+
+		if err := keyboard.typeRune(r); err != nil {
+			return err
+		}
+
+		if delayMs > 0 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("context canceled: %w", ctx.Err())
+			case <-time.After(time.Duration(delayMs) * time.Millisecond):
+			}
+		}
+	}
+
+	return keyboard.sync()
+}
+
+func (x11Controller) KeyDown(ctx context.Context, key string, modifiers []string) error {
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("context canceled: %w", ctx.Err())
+	default:
+	}
+
+	keysym, modifierSyms, err := resolveX11Key(key, modifiers)
+	if err != nil {
+		return err
+	}
+
+	keyboard, err := openX11Keyboard(ctx)
+	if err != nil {
+		return err
+	}
+	defer keyboard.close()
+
+	if err := keyboard.sendKeysym(keysym, modifierSyms, true); err != nil {
+		return err
+	}
+	return keyboard.sync()
+}
+
+func (x11Controller) KeyUp(ctx context.Context, key string, modifiers []string) error {
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("context canceled: %w", ctx.Err())
+	default:
+	}
+
+	keysym, modifierSyms, err := resolveX11Key(key, modifiers)
+	if err != nil {
+		return err
+	}
+
+	keyboard, err := openX11Keyboard(ctx)
+	if err != nil {
+		return err
+	}
+	defer keyboard.close()
+
+	if err := keyboard.sendKeysym(keysym, modifierSyms, false); err != nil {
+		return err
+	}
+	return keyboard.sync()
+}
+
+func resolveX11Key(key string, modifiers []string) (xproto.Keysym, []xproto.Keysym, error) {
+	normalizedKey := normalizeToken(key)
+	if normalizedKey == "" {
+		return 0, nil, fmt.Errorf("key is required")
+	}
+
+	keysym, ok := x11KeySyms[normalizedKey]
+	if !ok {
+		return 0, nil, fmt.Errorf("unsupported key %q", key)
+	}
+
+	modifierSyms, err := x11ModifierKeysyms(modifiers)
+	if err != nil {
+		return 0, nil, err
+	}
+	return keysym, modifierSyms, nil
+}
+
+func x11ModifierKeysyms(raw []string) ([]xproto.Keysym, error) {
+	var keysyms []xproto.Keysym
+	for _, item := range raw {
+		switch normalizeToken(item) {
+		case "":
+			continue
+		case "shift":
+			keysyms = append(keysyms, keysymShiftL)
+		case "control", "ctrl":
+			keysyms = append(keysyms, keysymControlL)
+		case "option", "alt":
+			keysyms = append(keysyms, keysymAltL)
+		case "command", "cmd", "meta", "super":
+			keysyms = append(keysyms, keysymSuperL)
+		default:
+			return nil, fmt.Errorf("unsupported modifier %q (supported: shift, control, option, command)", item)
+		}
+	}
+	return keysyms, nil
+}
+
+// runeKeysym returns the X11 keysym for a character. Latin-1 characters map
+// directly; everything else uses the Unicode keysym range.
+func runeKeysym(r rune) xproto.Keysym {
+	switch r {
+	case '\n', '\r':
+		return keysymReturn
+	case '\t':
+		return keysymTab
+	}
+	if (r >= 0x20 && r <= 0x7e) || (r >= 0xa0 && r <= 0xff) {
+		return xproto.Keysym(r)
+	}
+	return xproto.Keysym(0x01000000 | r)
+}
+
+// x11Keyboard is one X connection with XTEST initialized and a snapshot of the keyboard mapping.
+type x11Keyboard struct {
+	conn *xgb.Conn
+	root xproto.Window
+	keymap
+	// scratch is a keycode temporarily bound to characters missing from the layout.
+	scratch xproto.Keycode
+}
+
+// keymap mirrors the server's keycode → keysyms table.
+type keymap struct {
+	minKeycode xproto.Keycode
+	perKeycode int
+	keysyms    []xproto.Keysym
+}
+
+// openX11Keyboard connects to the display ctx targets: a session's virtual display or DISPLAY.
+func openX11Keyboard(ctx context.Context) (*x11Keyboard, error) {
+	display := virtualdisplay.XDisplay(ctx)
+	conn, err := xgb.NewConnDisplay(display)
+	if err != nil {
+		return nil, fmt.Errorf("connect to X display %q: %w", display, err)
+	}
+	if err := xtest.Init(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("initialize XTEST extension: %w", err)
+	}
+
+	setup := xproto.Setup(conn)
+	count := byte(setup.MaxKeycode - setup.MinKeycode + 1)
+	mapping, err := xproto.GetKeyboardMapping(conn, setup.MinKeycode, count).Reply()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("get keyboard mapping: %w", err)
+	}
+
+	return &x11Keyboard{
+		conn: conn,
+		root: setup.DefaultScreen(conn).Root,
+		keymap: keymap{
+			minKeycode: setup.MinKeycode,
+			perKeycode: int(mapping.KeysymsPerKeycode),
+			keysyms:    mapping.Keysyms,
+		},
+	}, nil
+}
+
+func (k *x11Keyboard) close() {
+	k.conn.Close()
+}
+
+// sync waits until the server has processed every request sent so far.
+func (k *x11Keyboard) sync() error {
+	if _, err := xproto.GetInputFocus(k.conn).Reply(); err != nil {
+		return fmt.Errorf("sync X connection: %w", err)
+	}
+	return nil
+}
+
+// lookup finds a keycode producing keysym and whether Shift is needed for it.
+// Only the unshifted and shifted columns are considered.
+func (m keymap) lookup(keysym xproto.Keysym) (xproto.Keycode, bool, bool) {
+	if m.perKeycode == 0 {
+		return 0, false, false
+	}
+	columns := min(m.perKeycode, 2)
+	for column := 0; column < columns; column++ {
+		for i := 0; i+column < len(m.keysyms); i += m.perKeycode {
+			if m.keysyms[i+column] == keysym {
+				return m.minKeycode + xproto.Keycode(i/m.perKeycode), column == 1, true
+			}
+		}
+	}
+	return 0, false, false
+}
+
+// unusedKeycode returns a keycode with no keysyms bound, used as scratch space for remapping.
+func (m keymap) unusedKeycode() (xproto.Keycode, bool) {
+	if m.perKeycode == 0 {
+		return 0, false
+	}
+	for i := len(m.keysyms) - m.perKeycode; i >= 0; i -= m.perKeycode {
+		unused := true
+		for _, keysym := range m.keysyms[i : i+m.perKeycode] {
+			if keysym != 0 {
+				unused = false
+				break
+			}
+		}
+		if unused {
+			return m.minKeycode + xproto.Keycode(i/m.perKeycode), true
+		}
+	}
+	return 0, false
+}
+
+func (k *x11Keyboard) fakeKey(keycode xproto.Keycode, press bool) error {
+	eventType := byte(xproto.KeyRelease)
+	if press {
+		eventType = xproto.KeyPress
+	}
+	if err := xtest.FakeInputChecked(k.conn, eventType, byte(keycode), 0, k.root, 0, 0, 0).Check(); err != nil {
+		return fmt.Errorf("send key event for keycode %d: %w", keycode, err)
+	}
+	return nil
+}
+
+func (k *x11Keyboard) keycodeFor(keysym xproto.Keysym) (xproto.Keycode, bool, error) {
+	if keycode, shifted, ok := k.lookup(keysym); ok {
+		return keycode, shifted, nil
+	}
+	return 0, false, fmt.Errorf("keysym 0x%x is not on the current keyboard layout", uint32(keysym))
+}
+
+// sendKeysym presses (modifiers first) or releases (modifiers last) a key.
+func (k *x11Keyboard) sendKeysym(keysym xproto.Keysym, modifiers []xproto.Keysym, press bool) error {
+	keycode, shifted, err := k.keycodeFor(keysym)
+	if err != nil {
+		return err
+	}
+	if shifted {
+		modifiers = append(modifiers, keysymShiftL)
+	}
+
+	modifierCodes := make([]xproto.Keycode, 0, len(modifiers))
+	for _, modifier := range modifiers {
+		code, _, err := k.keycodeFor(modifier)
+		if err != nil {
+			return err
+		}
+		modifierCodes = append(modifierCodes, code)
+	}
+
+	if !press {
+		if err := k.fakeKey(keycode, false); err != nil {
+			return err
+		}
+		for i := len(modifierCodes) - 1; i >= 0; i-- {
+			if err := k.fakeKey(modifierCodes[i], false); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, code := range modifierCodes {
+		if err := k.fakeKey(code, true); err != nil {
+			return err
+		}
+	}
+	return k.fakeKey(keycode, true)
+}
+
+// typeRune types one character, binding it to a scratch keycode when the layout lacks it.
+func (k *x11Keyboard) typeRune(r rune) error {
+	keysym := runeKeysym(r)
+	if _, _, ok := k.lookup(keysym); !ok {
+		if err := k.bindScratch(keysym); err != nil {
+			return err
+		}
+	}
+	if err := k.sendKeysym(keysym, nil, true); err != nil {
+		return err
+	}
+	return k.sendKeysym(keysym, nil, false)
+}
+
+func (k *x11Keyboard) bindScratch(keysym xproto.Keysym) error {
+	if k.scratch == 0 {
+		keycode, ok := k.unusedKeycode()
+		if !ok {
+			return fmt.Errorf("cannot type keysym 0x%x: no free keycode to remap", uint32(keysym))
+		}
+		k.scratch = keycode
+	}
+	if err := k.setKeysyms(k.scratch, keysym); err != nil {
+		return err
+	}
+	if err := k.sync(); err != nil {
+		return err
+	}
+	time.Sleep(remapSettleDelay)
+	return nil
+}
+
+// restoreScratch unbinds the scratch keycode so the user's layout is left unchanged.
+func (k *x11Keyboard) restoreScratch() {
+	if k.scratch == 0 {
+		return
+	}
+	_ = k.setKeysyms(k.scratch, 0)
+	k.scratch = 0
+}
+
+func (k *x11Keyboard) setKeysyms(keycode xproto.Keycode, keysym xproto.Keysym) error {
+	row := make([]xproto.Keysym, k.perKeycode)
+	for i := range row {
+		row[i] = keysym
+	}
+	if err := xproto.ChangeKeyboardMappingChecked(k.conn, 1, keycode, byte(k.perKeycode), row).Check(); err != nil {
+		return fmt.Errorf("remap keycode %d: %w", keycode, err)
+	}
+	offset := int(keycode-k.minKeycode) * k.perKeycode
+	copy(k.keysyms[offset:offset+k.perKeycode], row)
+	return nil
+}
+
+// X11 keysyms for modifiers and special keys (X11/keysymdef.h).
+const (
+	keysymShiftL   xproto.Keysym = 0xffe1
+	keysymControlL xproto.Keysym = 0xffe3
+	keysymAltL     xproto.Keysym = 0xffe9
+	keysymSuperL   xproto.Keysym = 0xffeb
+	keysymReturn   xproto.Keysym = 0xff0d
+	keysymTab      xproto.Keysym = 0xff09
+)
+
+var x11KeySyms = map[string]xproto.Keysym{
+	// Letters
+	"a": 'a',
+	"b": 'b',
+	"c": 'c',
+	"d": 'd',
+	"e": 'e',
+	"f": 'f',
+	"g": 'g',
+	"h": 'h',
+	"i": 'i',
+	"j": 'j',
+	"k": 'k',
+	"l": 'l',
+	"m": 'm',
+	"n": 'n',
+	"o": 'o',
+	"p": 'p',
+	"q": 'q',
+	"r": 'r',
+	"s": 's',
+	"t": 't',
+	"u": 'u',
+	"v": 'v',
+	"w": 'w',
+	"x": 'x',
+	"y": 'y',
+	"z": 'z',
+
+	// Digits
+	"0": '0',
+	"1": '1',
+	"2": '2',
+	"3": '3',
+	"4": '4',
+	"5": '5',
+	"6": '6',
+	"7": '7',
+	"8": '8',
+	"9": '9',
+
+	// Whitespace / control
+	"space":  ' ',
+	"tab":    keysymTab,
+	"enter":  keysymReturn,
+	"return": keysymReturn,
+	"escape": 0xff1b,
+	"esc":    0xff1b,
+
+	// Editing/navigation (delete matches the macOS key: backspace)
+	"backspace":     0xff08,
+	"delete":        0xff08,
+	"forwarddelete": 0xffff,
+	"home":          0xff50,
+	"end":           0xff57,
+	"pageup":        0xff55,
+	"pagedown":      0xff56,
+
+	// Arrows
+	"left":  0xff51,
+	"up":    0xff52,
+	"right": 0xff53,
+	"down":  0xff54,
+
+	// Function keys
+	"f1":  0xffbe,
+	"f2":  0xffbf,
+	"f3":  0xffc0,
+	"f4":  0xffc1,
+	"f5":  0xffc2,
+	"f6":  0xffc3,
+	"f7":  0xffc4,
+	"f8":  0xffc5,
+	"f9":  0xffc6,
+	"f10": 0xffc7,
+	"f11": 0xffc8,
+	"f12": 0xffc9,
+
+	// Punctuation
+	"-":            '-',
+	"minus":        '-',
+	"=":            '=',
+	"equal":        '=',
+	"[":            '[',
+	"leftbracket":  '[',
+	"]":            ']',
+	"rightbracket": ']',
+	"\\":           '\\',
+	"backslash":    '\\',
+	";":            ';',
+	"semicolon":    ';',
+	"'":            '\'',
+	"quote":        '\'',
+	",":            ',',
+	"comma":        ',',
+	".":            '.',
+	"period":       '.',
+	"/":            '/',
+	"slash":        '/',
+	"`":            '`',
+	"grave":        '`',
+}
@@ -0,0 +1,90 @@
+//go:build linux
+
+package input
+
+import (
+	"testing"
+
+	"github.com/jezek/xgb/xproto"
+)
+
+func TestRuneKeysym(t *testing.T) {
+	tests := []struct {
+		r    rune
+		want xproto.Keysym
+	}{
+		{'a', 'a'},
+		{'Z', 'Z'},
+		{'é', 0xe9},
+		{'\n', keysymReturn},
+		{'\t', keysymTab},
+		{'€', 0x010020ac},
+		{'日', 0x010065e5},
+	}
+	for _, tt := range tests {
+		if got := runeKeysym(tt.r); got != tt.want {
+			t.Errorf("runeKeysym(%q) = 0x%x, want 0x%x", tt.r, got, tt.want)
+		}
+	}
+}
+
+func TestKeymapLookup(t *testing.T) {
+	m := keymap{
+		minKeycode: 8,
+		perKeycode: 4,
+		keysyms: []xproto.Keysym{
+			'a', 'A', 0, 0, // 8
+			'1', '!', 0, 0, // 9
+			0, 0, 0, 0, // 10
+			keysymShiftL, 0, 0, 0, // 11
+			0, 0, 0, 0, // 12
+		},
+	}
+
+	tests := []struct {
+		keysym      xproto.Keysym
+		wantKeycode xproto.Keycode
+		wantShift   bool
+		wantOK      bool
+	}{
+		{'a', 8, false, true},
+		{'A', 8, true, true},
+		{'!', 9, true, true},
+		{keysymShiftL, 11, false, true},
+		{'b', 0, false, false},
+	}
+	for _, tt := range tests {
+		keycode, shifted, ok := m.lookup(tt.keysym)
+		if keycode != tt.wantKeycode || shifted != tt.wantShift || ok != tt.wantOK {
+			t.Errorf("lookup(0x%x) = (%d, %v, %v), want (%d, %v, %v)",
+				tt.keysym, keycode, shifted, ok, tt.wantKeycode, tt.wantShift, tt.wantOK)
+		}
+	}
+
+	if keycode, ok := m.unusedKeycode(); !ok || keycode != 12 {
+		t.Fatalf("unusedKeycode() = (%d, %v), want (12, true)", keycode, ok)
+	}
+}
+
+func TestResolveX11Key(t *testing.T) {
+	keysym, modifiers, err := resolveX11Key(" Enter ", []string{"Shift", "cmd", ""})
+	if err != nil {
+		t.Fatalf("resolveX11Key failed: %v", err)
+	}
+	if keysym != keysymReturn {
+		t.Fatalf("keysym = 0x%x, want Return", keysym)
+	}
+	if len(modifiers) != 2 || modifiers[0] != keysymShiftL || modifiers[1] != keysymSuperL {
+		t.Fatalf("unexpected modifiers: %v", modifiers)
+	}
+
+	if _, _, err := resolveX11Key("", nil); err == nil {
+		t.Fatal("expected error for empty key")
+	}
+	if _, _, err := resolveX11Key("capslock", nil); err == nil {
+		t.Fatal("expected error for unsupported key")
+	}
+	if _, _, err := resolveX11Key("a", []string{"fn"}); err == nil {
+		t.Fatal("expected error for fn modifier, which X11 does not expose")
+	}
+}
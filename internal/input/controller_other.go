@@ -1,4 +1,4 @@
-//go:build !darwin
+//go:build !darwin && !linux
 
 package input
 
@@ -9,7 +9,7 @@ import (
 
 type unsupportedController struct{}
 
-// NewController returns an input controller that reports unsupported operations on non-macOS platforms.
+// NewController returns an input controller that reports unsupported operations on platforms without an input backend.
 func NewController() Controller {
 	return unsupportedController{}
 }
@@ -20,7 +20,7 @@ func (unsupportedController) PressKey(ctx context.Context, _ string, _ []string)
 		return fmt.Errorf("context canceled: %w", ctx.Err())
 	default:
 	}
-	return fmt.Errorf("key presses are only supported on macOS (darwin)")
+	return fmt.Errorf("key presses are only supported on macOS (darwin) and Linux (X11)")
 }
 
 func (unsupportedController) TypeText(ctx context.Context, _ string, _ int) error {
@@ -29,7 +29,7 @@ func (unsupportedController) TypeText(ctx context.Context, _ string, _ int) erro
 		return fmt.Errorf("context canceled: %w", ctx.Err())
 	default:
 	}
-	return fmt.Errorf("text typing is only supported on macOS (darwin)")
+	return fmt.Errorf("text typing is only supported on macOS (darwin) and Linux (X11)")
 }
 
 func (unsupportedController) KeyDown(ctx context.Context, _ string, _ []string) error {
@@ -38,7 +38,7 @@ func (unsupportedController) KeyDown(ctx context.Context, _ string, _ []string)
 		return fmt.Errorf("context canceled: %w", ctx.Err())
 	default:
 	}
-	return fmt.Errorf("key down is only supported on macOS (darwin)")
+	return fmt.Errorf("key down is only supported on macOS (darwin) and Linux (X11)")
 }
 
 func (unsupportedController) KeyUp(ctx context.Context, _ string, _ []string) error {
@@ -47,5 +47,5 @@ func (unsupportedController) KeyUp(ctx context.Context, _ string, _ []string) er
 		return fmt.Errorf("context canceled: %w", ctx.Err())
 	default:
 	}
-	return fmt.Errorf("key up is only supported on macOS (darwin)")
+	return fmt.Errorf("key up is only supported on macOS (darwin) and Linux (X11)")
 }
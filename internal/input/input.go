@@ -1,7 +1,10 @@
 // Package input exposes OS-specific input controllers used by the MCP layer.
 package input
 
-import "context"
+import (
+	"context"
+	"strings"
+)
 
 // Controller provides OS-specific input injection primitives.
 type Controller interface {
@@ -21,3 +24,7 @@ type Controller interface {
 	// KeyUp sends a key up event (for hold actions).
 	KeyUp(ctx context.Context, key string, modifiers []string) error
 }
+
+func normalizeToken(raw string) string {
+	return strings.TrimSpace(strings.ToLower(raw))
+}
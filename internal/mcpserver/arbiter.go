@@ -0,0 +1,181 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/screenshot"
+)
+
+// DefaultCaptureConcurrency is how many capture-heavy tool calls may run at once.
+const DefaultCaptureConcurrency = 2
+
+// Queue names reported in tool result metadata.
+const (
+	inputQueueName   = "input"
+	captureQueueName = "capture"
+
+	queueMetaKey       = "queue"
+	queueWaitMsMetaKey = "queue_wait_ms"
+)
+
+// inputToolNames inject synthetic events or move focus. They run one at a time,
+// across all sessions, so event sequences from different callers never interleave.
+var inputToolNames = []string{
+	FocusWindowToolName,
+	ClickToolName,
+	ClickScreenToolName,
+	MouseMoveToolName,
+	MouseDownToolName,
+	MouseUpToolName,
+	DragToolName,
+	ScrollToolName,
+	TakeScrollingScreenshotToolName,
+	PressKeyToolName,
+	TypeTextToolName,
+	KeyDownToolName,
+	KeyUpToolName,
+}
+
+// captureHeavyToolNames run template matching or OCR over captures and are
+// limited to the configured capture concurrency.
+var captureHeavyToolNames = []string{
+	WaitForImageMatchToolName,
+	FindImageMatchesToolName,
+	AnnotateScreenshotToolName,
+	AssertScreenshotMatchesFixtureToolName,
+	WaitForTextToolName,
+}
+
+// fifoSemaphore admits up to limit holders and queues the rest in arrival order.
+type fifoSemaphore struct {
+	mu      sync.Mutex
+	limit   int
+	active  int
+	waiters []chan struct{}
+}
+
+func newFIFOSemaphore(limit int) *fifoSemaphore {
+	if limit < 1 {
+		limit = 1
+	}
+	return &fifoSemaphore{limit: limit}
+}
+
+// acquire blocks until a slot is free or ctx is done.
+func (s *fifoSemaphore) acquire(ctx context.Context) error {
+	s.mu.Lock()
+	if s.active < s.limit && len(s.waiters) == 0 {
+		s.active++
+		s.mu.Unlock()
+		return nil
+	}
+	ready := make(chan struct{})
+	s.waiters = append(s.waiters, ready)
+	s.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for i, waiter := range s.waiters {
+			if waiter == ready {
+				s.waiters = append(s.waiters[:i], s.waiters[i+1:]...)
+				return fmt.Errorf("waiting for queue: %w", ctx.Err())
+			}
+		}
+		// The slot was handed over while ctx was being cancelled; pass it on.
+		s.releaseLocked()
+		return fmt.Errorf("waiting for queue: %w", ctx.Err())
+	}
+}
+
+func (s *fifoSemaphore) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.releaseLocked()
+}
+
+// releaseLocked hands the slot directly to the oldest waiter so later arrivals cannot overtake it.
+func (s *fifoSemaphore) releaseLocked() {
+	if len(s.waiters) > 0 {
+		next := s.waiters[0]
+		s.waiters = s.waiters[1:]
+		close(next)
+		return
+	}
+	s.active--
+}
+
+// inputArbiter routes tool calls through the input or capture queue.
+type inputArbiter struct {
+	queues map[string]*fifoSemaphore
+	routes map[string]string
+}
+
+func newInputArbiter(captureConcurrency int) *inputArbiter {
+	if captureConcurrency == 0 {
+		captureConcurrency = DefaultCaptureConcurrency
+	}
+	arbiter := &inputArbiter{
+		queues: map[string]*fifoSemaphore{
+			inputQueueName:   newFIFOSemaphore(1),
+			captureQueueName: newFIFOSemaphore(captureConcurrency),
+		},
+		routes: make(map[string]string, len(inputToolNames)+len(captureHeavyToolNames)),
+	}
+	for _, name := range inputToolNames {
+		arbiter.routes[name] = inputQueueName
+	}
+	for _, name := range captureHeavyToolNames {
+		arbiter.routes[name] = captureQueueName
+	}
+	return arbiter
+}
+
+// middleware queues arbitrated tools/call requests and reports the wait in
+// the result's _meta as "queue" and "queue_wait_ms".
+func (a *inputArbiter) middleware() sdkmcp.Middleware {
+	return func(next sdkmcp.MethodHandler) sdkmcp.MethodHandler {
+		return func(ctx context.Context, method string, req sdkmcp.Request) (sdkmcp.Result, error) {
+			callReq, ok := req.(*sdkmcp.CallToolRequest)
+			if !ok || method != "tools/call" {
+				return next(ctx, method, req)
+			}
+			queueName, ok := a.routes[callReq.Params.Name]
+			if !ok {
+				return next(ctx, method, req)
+			}
+
+			queue := a.queues[queueName]
+			started := time.Now()
+			if err := queue.acquire(ctx); err != nil {
+				return nil, fmt.Errorf("%s: %w", callReq.Params.Name, err)
+			}
+			waited := time.Since(started)
+			result, err := func() (sdkmcp.Result, error) {
+				defer queue.release()
+				if queueName == inputQueueName {
+					// Frames captured before the input no longer show the screen.
+					defer screenshot.InvalidateCache()
+				}
+				return next(ctx, method, req)
+			}()
+
+			if callResult, ok := result.(*sdkmcp.CallToolResult); ok {
+				if callResult.Meta == nil {
+					callResult.Meta = sdkmcp.Meta{}
+				}
+				callResult.Meta[queueMetaKey] = queueName
+				callResult.Meta[queueWaitMsMetaKey] = float64(waited.Microseconds()) / 1000
+			}
+			return result, err
+		}
+	}
+}
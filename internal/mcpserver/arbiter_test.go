@@ -0,0 +1,219 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/screenshot"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/tools"
+)
+
+func (s *fifoSemaphore) queued() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.waiters)
+}
+
+func TestFIFOSemaphore_AdmitsInArrivalOrder(t *testing.T) {
+	sem := newFIFOSemaphore(1)
+	if err := sem.acquire(context.Background()); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	var mu sync.Mutex
+	var order []int
+	var wg sync.WaitGroup
+	for i := 1; i <= 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := sem.acquire(context.Background()); err != nil {
+				t.Errorf("acquire %d: %v", i, err)
+				return
+			}
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+			sem.release()
+		}()
+		waitForCondition(t, "waiter to queue", func() bool { return sem.queued() == i })
+	}
+
+	sem.release()
+	wg.Wait()
+	if len(order) != 3 || order[0] != 1 || order[1] != 2 || order[2] != 3 {
+		t.Fatalf("admission order = %v, want [1 2 3]", order)
+	}
+}
+
+func TestFIFOSemaphore_CancelledWaiterLeavesQueue(t *testing.T) {
+	sem := newFIFOSemaphore(1)
+	if err := sem.acquire(context.Background()); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := sem.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("acquire error = %v, want deadline exceeded", err)
+	}
+	if sem.queued() != 0 {
+		t.Fatal("expected cancelled waiter to be removed from the queue")
+	}
+
+	sem.release()
+	if err := sem.acquire(context.Background()); err != nil {
+		t.Fatalf("acquire after release: %v", err)
+	}
+}
+
+func TestArbiterToolsAreInCatalog(t *testing.T) {
+	known := make(map[string]bool, len(allToolNames))
+	for _, name := range allToolNames {
+		known[name] = true
+	}
+	routes := newInputArbiter(0).routes
+	for name, queue := range routes {
+		if !known[name] {
+			t.Errorf("%s queue routes unknown tool %q", queue, name)
+		}
+	}
+	if len(routes) != len(inputToolNames)+len(captureHeavyToolNames) {
+		t.Error("a tool is routed to more than one queue")
+	}
+	// compare_images only reads files, so it never waits behind captures.
+	if queue, ok := routes[CompareImagesToolName]; ok {
+		t.Errorf("%s is routed to the %s queue", CompareImagesToolName, queue)
+	}
+}
+
+func TestInputArbiter_SerializesInputAcrossSessions(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	running, maxRunning, started := 0, 0, 0
+	inputService := &tools.InputService{
+		TypeTextFn: func(context.Context, string, int) error {
+			mu.Lock()
+			running++
+			started++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mu.Unlock()
+			<-release
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return nil
+		},
+	}
+	server := NewServer(nil, Config{WindowService: auditWindowService{}, InputService: inputService})
+
+	results := make(chan *sdkmcp.CallToolResult, 2)
+	for i := 0; i < 2; i++ {
+		_, session := connectTestSession(t, server)
+		t.Cleanup(func() { _ = session.Close() })
+		go func() {
+			result, err := session.CallTool(context.Background(), &sdkmcp.CallToolParams{
+				Name:      TypeTextToolName,
+				Arguments: map[string]any{"window_id": 1, "text": "x"},
+			})
+			if err != nil {
+				t.Errorf("call tool: %v", err)
+			}
+			results <- result
+		}()
+	}
+
+	waitForCondition(t, "first call to start", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return started == 1
+	})
+	time.Sleep(30 * time.Millisecond)
+	close(release)
+
+	var maxWait float64
+	for i := 0; i < 2; i++ {
+		result := <-results
+		if result == nil {
+			t.Fatal("missing result")
+		}
+		if queue := result.Meta[queueMetaKey]; queue != inputQueueName {
+			t.Fatalf("queue = %v, want %q", queue, inputQueueName)
+		}
+		wait, ok := result.Meta[queueWaitMsMetaKey].(float64)
+		if !ok {
+			t.Fatalf("missing %s in result meta: %v", queueWaitMsMetaKey, result.Meta)
+		}
+		maxWait = max(maxWait, wait)
+	}
+	if maxRunning != 1 {
+		t.Fatalf("max concurrent input calls = %d, want 1", maxRunning)
+	}
+	if maxWait < 20 {
+		t.Fatalf("expected the queued call to report its wait, got %.1fms", maxWait)
+	}
+}
+
+func TestInputArbiter_InvalidatesCaptureCache(t *testing.T) {
+	inputService := &tools.InputService{TypeTextFn: func(context.Context, string, int) error { return nil }}
+	server := NewServer(nil, Config{WindowService: auditWindowService{}, InputService: inputService})
+	_, session := connectTestSession(t, server)
+	t.Cleanup(func() { _ = session.Close() })
+
+	frames := screenshot.NewCache[int]()
+	captures := 0
+	capture := func(context.Context) (int, error) {
+		captures++
+		return captures, nil
+	}
+	_, _ = frames.Get(context.Background(), "all", capture)
+
+	if _, err := session.CallTool(context.Background(), &sdkmcp.CallToolParams{
+		Name:      TypeTextToolName,
+		Arguments: map[string]any{"window_id": 1, "text": "x"},
+	}); err != nil {
+		t.Fatalf("call tool: %v", err)
+	}
+	if got, _ := frames.Get(context.Background(), "all", capture); got != 2 {
+		t.Fatalf("frame after type_text = %d, want a new capture", got)
+	}
+}
+
+// cacheMaxAgeService records the frame cache max age each capture was made with.
+type cacheMaxAgeService struct {
+	solidScreenshotService
+	seen *time.Duration
+}
+
+func (s cacheMaxAgeService) TakeScreenshotPNG(ctx context.Context) ([]byte, error) {
+	*s.seen = screenshot.CacheMaxAge(ctx)
+	return nil, errors.New("no screen")
+}
+
+func TestNewServer_PassesCacheMaxAgeToCaptures(t *testing.T) {
+	for _, tc := range []struct {
+		configured, want time.Duration
+	}{
+		{0, screenshot.DefaultCacheMaxAge},
+		{20 * time.Millisecond, 20 * time.Millisecond},
+		{-1, -1},
+	} {
+		var seen time.Duration
+		server := NewServer(cacheMaxAgeService{seen: &seen}, Config{WindowService: auditWindowService{}, CaptureCacheMaxAge: tc.configured})
+		_, session := connectTestSession(t, server)
+		if _, err := session.CallTool(context.Background(), &sdkmcp.CallToolParams{Name: TakeScreenshotPNGToolName}); err != nil {
+			t.Fatalf("call tool: %v", err)
+		}
+		_ = session.Close()
+		if seen != tc.want {
+			t.Fatalf("configured %v: capture max age = %v, want %v", tc.configured, seen, tc.want)
+		}
+	}
+}
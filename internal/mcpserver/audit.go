@@ -0,0 +1,279 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	auditMaxStringLen  = 256
+	auditMaxSummaryLen = 200
+	auditRedacted      = "[redacted]"
+)
+
+// auditRedaction describes what the audit log must not copy for one tool.
+type auditRedaction struct {
+	// arguments lists argument names whose values are replaced.
+	arguments []string
+	// results hides text result content, for tools that return user data.
+	results bool
+}
+
+// auditRedactions is the per-tool redaction table. Argument names matching
+// auditSecretKeyHints are redacted for every tool in addition to these.
+var auditRedactions = map[string]auditRedaction{
+	TypeTextToolName:               {arguments: []string{"text"}},
+	SetClipboardToolName:           {arguments: []string{"text", "data_base64"}},
+	GetClipboardToolName:           {results: true},
+	WaitForClipboardChangeToolName: {results: true},
+}
+
+// auditSecretKeyHints redacts any argument whose name looks like a credential.
+var auditSecretKeyHints = []string{"token", "password", "secret", "credential"}
+
+// AuditRecord is one JSONL line describing a single tool invocation.
+type AuditRecord struct {
+	Time        time.Time      `json:"time"`
+	Session     string         `json:"session,omitempty"`
+	Tool        string         `json:"tool"`
+	Arguments   map[string]any `json:"arguments,omitempty"`
+	DurationMs  float64        `json:"duration_ms"`
+	QueueWaitMs float64        `json:"queue_wait_ms,omitempty"`
+	Status      string         `json:"status"`
+	Summary     string         `json:"summary,omitempty"`
+	ErrorCode   ToolErrorCode  `json:"error_code,omitempty"`
+	Error       string         `json:"error,omitempty"`
+	Artifacts   []string       `json:"artifacts,omitempty"`
+}
+
+// auditLogger serializes audit records to a writer, one JSON object per line.
+type auditLogger struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func newAuditLogger(out io.Writer) *auditLogger {
+	return &auditLogger{out: out}
+}
+
+func (l *auditLogger) write(record AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshal audit record: %w", err)
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.out.Write(line); err != nil {
+		return fmt.Errorf("write audit record: %w", err)
+	}
+	// Flush to stable storage when the writer supports it so records survive a crash.
+	if syncer, ok := l.out.(interface{ Sync() error }); ok {
+		if err := syncer.Sync(); err != nil {
+			return fmt.Errorf("sync audit log: %w", err)
+		}
+	}
+	return nil
+}
+
+// auditMiddleware records every tools/call request after it completes.
+// Sessions are identified by their transport session ID, or by the server's
+// per-session artifact scope for transports without one, such as stdio.
+func auditMiddleware(logger *auditLogger, sessions *sessionStore) sdkmcp.Middleware {
+	return func(next sdkmcp.MethodHandler) sdkmcp.MethodHandler {
+		return func(ctx context.Context, method string, req sdkmcp.Request) (sdkmcp.Result, error) {
+			callReq, ok := req.(*sdkmcp.CallToolRequest)
+			if !ok || method != "tools/call" {
+				return next(ctx, method, req)
+			}
+
+			ctx, artifacts := withArtifactRecorder(ctx)
+			started := time.Now()
+			result, err := next(ctx, method, req)
+
+			record := AuditRecord{
+				Time:       started.UTC(),
+				Tool:       callReq.Params.Name,
+				Arguments:  redactArguments(callReq.Params.Name, callReq.Params.Arguments),
+				DurationMs: float64(time.Since(started).Microseconds()) / 1000,
+				Artifacts:  artifacts.paths(),
+			}
+			if callReq.Session != nil {
+				record.Session = callReq.Session.ID()
+			}
+			if record.Session == "" {
+				record.Session = sessions.forRequest(callReq).artifactScope
+			}
+			callResult, _ := result.(*sdkmcp.CallToolResult)
+			secrets := auditSecrets(callReq.Params.Name, callReq.Params.Arguments)
+			describeAuditOutcome(&record, callResult, err, secrets, auditRedactions[callReq.Params.Name].results)
+
+			// Audit failures must not turn a completed action into a reported failure.
+			_ = logger.write(record)
+			return result, err
+		}
+	}
+}
+
+// describeAuditOutcome fills the status fields. Redacted argument values are
+// scrubbed from messages too, since handlers often echo their input back, and
+// redactResults hides successful text results entirely.
+func describeAuditOutcome(record *AuditRecord, result *sdkmcp.CallToolResult, err error, secrets []string, redactResults bool) {
+	if result != nil {
+		record.QueueWaitMs, _ = result.Meta[queueWaitMsMetaKey].(float64)
+	}
+	if err == nil && result != nil && result.IsError {
+		err = result.GetError()
+		if err == nil {
+			err = fmt.Errorf("%s", summarizeContent(result.Content, nil, false))
+		}
+	}
+	if err != nil {
+		record.Status = "error"
+		record.Error = truncateAuditString(scrubSecrets(err.Error(), secrets), auditMaxSummaryLen)
+		if te, ok := asToolError(err); ok {
+			record.ErrorCode = te.Code
+		}
+		return
+	}
+	record.Status = "ok"
+	if result != nil {
+		record.Summary = summarizeContent(result.Content, secrets, redactResults)
+	}
+}
+
+// summarizeContent describes a tool result without copying image payloads into the log.
+// With redactText set, text content is reduced to its length.
+func summarizeContent(content []sdkmcp.Content, secrets []string, redactText bool) string {
+	parts := make([]string, 0, len(content))
+	for _, item := range content {
+		switch c := item.(type) {
+		case *sdkmcp.TextContent:
+			if redactText {
+				parts = append(parts, fmt.Sprintf("text: %s, %d bytes", auditRedacted, len(c.Text)))
+				continue
+			}
+			parts = append(parts, "text: "+truncateAuditString(scrubSecrets(c.Text, secrets), auditMaxSummaryLen))
+		case *sdkmcp.ImageContent:
+			parts = append(parts, fmt.Sprintf("image: %s, %d bytes", c.MIMEType, len(c.Data)))
+		default:
+			parts = append(parts, fmt.Sprintf("%T", item))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+func redactArguments(toolName string, raw json.RawMessage) map[string]any {
+	args := decodeAuditArguments(raw)
+	if args == nil {
+		return nil
+	}
+
+	redacted := make(map[string]any, len(args))
+	for key, value := range args {
+		if isSensitiveArgument(toolName, key) {
+			redacted[key] = auditRedacted
+			continue
+		}
+		if text, ok := value.(string); ok {
+			value = truncateAuditString(text, auditMaxStringLen)
+		}
+		redacted[key] = value
+	}
+	return redacted
+}
+
+// auditSecrets returns the string values that redactArguments hides.
+func auditSecrets(toolName string, raw json.RawMessage) []string {
+	var secrets []string
+	for key, value := range decodeAuditArguments(raw) {
+		if text, ok := value.(string); ok && text != "" && isSensitiveArgument(toolName, key) {
+			secrets = append(secrets, text)
+		}
+	}
+	return secrets
+}
+
+func decodeAuditArguments(raw json.RawMessage) map[string]any {
+	var args map[string]any
+	if len(raw) == 0 || json.Unmarshal(raw, &args) != nil {
+		return nil
+	}
+	return args
+}
+
+// scrubSecrets replaces each secret in text, in both its raw and %q-escaped forms.
+func scrubSecrets(text string, secrets []string) string {
+	for _, secret := range secrets {
+		quoted := strconv.Quote(secret)
+		text = strings.ReplaceAll(text, quoted[1:len(quoted)-1], auditRedacted)
+		text = strings.ReplaceAll(text, secret, auditRedacted)
+	}
+	return text
+}
+
+func isSensitiveArgument(toolName, key string) bool {
+	if slices.Contains(auditRedactions[toolName].arguments, key) {
+		return true
+	}
+	lower := strings.ToLower(key)
+	for _, hint := range auditSecretKeyHints {
+		if strings.Contains(lower, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// truncateAuditString keeps at most limit bytes of text, cutting on a rune
+// boundary so the audit line stays valid UTF-8.
+func truncateAuditString(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s...[%d bytes omitted]", text[:cut], len(text)-cut)
+}
+
+type artifactRecorderKey struct{}
+
+// artifactRecorder collects artifact paths created while handling one tool call.
+type artifactRecorder struct {
+	mu    sync.Mutex
+	files []string
+}
+
+func withArtifactRecorder(ctx context.Context) (context.Context, *artifactRecorder) {
+	recorder := &artifactRecorder{}
+	return context.WithValue(ctx, artifactRecorderKey{}, recorder), recorder
+}
+
+func recordArtifact(ctx context.Context, path string) {
+	recorder, ok := ctx.Value(artifactRecorderKey{}).(*artifactRecorder)
+	if !ok {
+		return
+	}
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	recorder.files = append(recorder.files, path)
+}
+
+func (r *artifactRecorder) paths() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.files...)
+}
@@ -0,0 +1,253 @@
+package mcpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/imgencode"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/tools"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/window"
+)
+
+// auditBuffer is a goroutine-safe buffer for collecting audit output.
+type auditBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *auditBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *auditBuffer) records(t *testing.T) []AuditRecord {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var records []AuditRecord
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record AuditRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("audit line %q is not JSON: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+type auditWindowService struct {
+	windowToolsService
+	permissionErr error
+}
+
+func (s auditWindowService) EnsureAutomationPermissions(context.Context, string) error {
+	return s.permissionErr
+}
+
+func (auditWindowService) FocusWindow(context.Context, uint32) error {
+	return nil
+}
+
+func newAuditTestSession(t *testing.T, windowService WindowService, out *auditBuffer) *sdkmcp.ClientSession {
+	t.Helper()
+	service := &tools.ScreenshotService{
+		Capture: func(context.Context) (image.Image, error) {
+			return image.NewRGBA(image.Rect(0, 0, 2, 2)), nil
+		},
+		Encode: func(image.Image, imgencode.Options) ([]byte, error) {
+			return []byte{0xff, 0xd8}, nil
+		},
+		Options: imgencode.DefaultOptions,
+	}
+	inputService := &tools.InputService{
+		TypeTextFn: func(context.Context, string, int) error { return nil },
+	}
+	server := NewServer(service, Config{WindowService: windowService, InputService: inputService, AuditLog: out})
+	_, session := connectTestSession(t, server)
+	t.Cleanup(func() { _ = session.Close() })
+	return session
+}
+
+func TestAuditLog_RecordsSuccessfulCall(t *testing.T) {
+	out := &auditBuffer{}
+	session := newAuditTestSession(t, auditWindowService{}, out)
+
+	if _, err := session.CallTool(context.Background(), &sdkmcp.CallToolParams{Name: ToolName}); err != nil {
+		t.Fatalf("call tool: %v", err)
+	}
+
+	records := out.records(t)
+	if len(records) != 1 {
+		t.Fatalf("expected 1 audit record, got %d", len(records))
+	}
+	record := records[0]
+	if record.Tool != ToolName || record.Status != "ok" {
+		t.Fatalf("unexpected record: %+v", record)
+	}
+	if record.Session == "" {
+		t.Error("expected the session ID to be recorded")
+	}
+	if !strings.Contains(record.Summary, "image: image/jpeg, ") || strings.Contains(record.Summary, "data") {
+		t.Errorf("expected image summary without payload, got %q", record.Summary)
+	}
+}
+
+func TestAuditLog_RedactsTypedText(t *testing.T) {
+	out := &auditBuffer{}
+	session := newAuditTestSession(t, auditWindowService{}, out)
+
+	_, err := session.CallTool(context.Background(), &sdkmcp.CallToolParams{
+		Name:      TypeTextToolName,
+		Arguments: map[string]any{"window_id": 7, "text": "hunter2"},
+	})
+	if err != nil {
+		t.Fatalf("call tool: %v", err)
+	}
+
+	records := out.records(t)
+	if len(records) != 1 {
+		t.Fatalf("expected 1 audit record, got %d", len(records))
+	}
+	if got := records[0].Arguments["text"]; got != auditRedacted {
+		t.Fatalf("text argument = %v, want %q", got, auditRedacted)
+	}
+	if got := records[0].Arguments["window_id"]; got != float64(7) {
+		t.Fatalf("window_id argument = %v, want 7", got)
+	}
+	if strings.Contains(records[0].Summary, "hunter2") {
+		t.Fatalf("summary leaked typed text: %q", records[0].Summary)
+	}
+}
+
+func TestAuditLog_RedactsClipboardResults(t *testing.T) {
+	out := &auditBuffer{}
+	board := &memoryClipboard{mimeType: "text/plain", data: []byte("correct horse battery staple")}
+	server := NewServer(nil, Config{WindowService: windowToolsService{}, InputService: &tools.InputService{}, Clipboard: board, AuditLog: out})
+	_, session := connectTestSession(t, server)
+	t.Cleanup(func() { _ = session.Close() })
+
+	result, err := session.CallTool(context.Background(), &sdkmcp.CallToolParams{Name: GetClipboardToolName})
+	if err != nil || result.IsError {
+		t.Fatalf("get_clipboard: err=%v result=%+v", err, result)
+	}
+
+	records := out.records(t)
+	if len(records) != 1 {
+		t.Fatalf("expected 1 audit record, got %d", len(records))
+	}
+	if summary := records[0].Summary; strings.Contains(summary, "horse") || !strings.Contains(summary, auditRedacted) {
+		t.Fatalf("summary = %q, want clipboard text redacted", summary)
+	}
+}
+
+func TestAuditRedactions_CoverClipboardReads(t *testing.T) {
+	for _, name := range []string{GetClipboardToolName, WaitForClipboardChangeToolName} {
+		if !auditRedactions[name].results {
+			t.Errorf("%s results are not redacted", name)
+		}
+	}
+	content := []sdkmcp.Content{&sdkmcp.TextContent{Text: "s3cret"}}
+	if got := summarizeContent(content, nil, true); got != "text: [redacted], 6 bytes" {
+		t.Errorf("summarizeContent() = %q", got)
+	}
+}
+
+func TestAuditLog_RecordsToolErrorCode(t *testing.T) {
+	out := &auditBuffer{}
+	denied := &window.PermissionError{ToolName: ClickToolName, Accessibility: true}
+	session := newAuditTestSession(t, auditWindowService{permissionErr: denied}, out)
+
+	result, err := session.CallTool(context.Background(), &sdkmcp.CallToolParams{
+		Name:      ClickToolName,
+		Arguments: map[string]any{"window_id": 7, "x": 1, "y": 1},
+	})
+	if err != nil {
+		t.Fatalf("call tool: %v", err)
+	}
+	if !result.IsError {
+		t.Fatal("expected a tool error result")
+	}
+
+	records := out.records(t)
+	if len(records) != 1 {
+		t.Fatalf("expected 1 audit record, got %d", len(records))
+	}
+	if records[0].Status != "error" || records[0].ErrorCode != toolErrorCodePermissionDenied {
+		t.Fatalf("unexpected record: %+v", records[0])
+	}
+}
+
+func TestCreateArtifactFile_RecordsArtifact(t *testing.T) {
+	if err := SetAllowedRunDirectory(t.TempDir()); err != nil {
+		t.Fatalf("set allowed directory: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = SetAllowedRunDirectory("")
+	})
+
+	ctx, recorder := withArtifactRecorder(context.Background())
+	file, err := CreateArtifactFile(ctx, "audit", "jpg")
+	if err != nil {
+		t.Fatalf("create artifact: %v", err)
+	}
+	_ = file.Close()
+
+	if paths := recorder.paths(); len(paths) != 1 || paths[0] != file.Name() {
+		t.Fatalf("recorded artifacts = %v, want [%s]", paths, file.Name())
+	}
+}
+
+func TestRedactArguments(t *testing.T) {
+	long := strings.Repeat("a", auditMaxStringLen+10)
+	raw := json.RawMessage(`{"text":"secret words","api_token":"abc","path":"` + long + `","count":3}`)
+
+	got := redactArguments(SetClipboardToolName, raw)
+	if got["text"] != auditRedacted {
+		t.Errorf("text = %v, want redacted", got["text"])
+	}
+	if got["api_token"] != auditRedacted {
+		t.Errorf("api_token = %v, want redacted", got["api_token"])
+	}
+	if path, _ := got["path"].(string); !strings.HasSuffix(path, "[10 bytes omitted]") {
+		t.Errorf("path = %q, want truncated", path)
+	}
+	if got["count"] != float64(3) {
+		t.Errorf("count = %v, want 3", got["count"])
+	}
+
+	if plain := redactArguments(ToolName, json.RawMessage(`{"text":"visible"}`)); plain["text"] != "visible" {
+		t.Errorf("text for %s = %v, want it kept", ToolName, plain["text"])
+	}
+	if got := scrubSecrets(`Typed "say \"hi\"" twice`, []string{`say "hi"`}); got != `Typed "[redacted]" twice` {
+		t.Errorf("scrubSecrets = %q", got)
+	}
+	if redactArguments(ToolName, nil) != nil {
+		t.Error("expected nil arguments for an empty payload")
+	}
+}
+
+func TestTruncateAuditString_KeepsRunesWhole(t *testing.T) {
+	// "é" is two bytes and "日" three, so a 5-byte limit falls inside "日".
+	got := truncateAuditString("éé日本", 5)
+	if want := "éé...[6 bytes omitted]"; got != want {
+		t.Fatalf("truncateAuditString = %q, want %q", got, want)
+	}
+	if !utf8.ValidString(got) {
+		t.Fatalf("truncated string %q is not valid UTF-8", got)
+	}
+	if got := truncateAuditString("日本", 2); got != "...[6 bytes omitted]" {
+		t.Fatalf("truncateAuditString inside the first rune = %q", got)
+	}
+}
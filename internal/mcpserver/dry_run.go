@@ -0,0 +1,162 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/clipboard"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/tools"
+)
+
+const dryRunMetaKey = "dry_run"
+
+// dryRunLog writes one line per simulated action.
+type dryRunLog struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func newDryRunLog(out io.Writer) *dryRunLog {
+	if out == nil {
+		out = io.Discard
+	}
+	return &dryRunLog{out: out}
+}
+
+func (l *dryRunLog) record(action string, format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	line := "dry-run: " + action
+	if details := fmt.Sprintf(format, args...); details != "" {
+		line += " " + details
+	}
+	_, _ = fmt.Fprintln(l.out, line)
+}
+
+// dryRunWindowService logs and skips actions that change the desktop: focus,
+// pointer input and process management. Listing, capture and waits pass through.
+type dryRunWindowService struct {
+	WindowService
+	log *dryRunLog
+}
+
+func (s dryRunWindowService) FocusWindow(_ context.Context, windowID uint32) error {
+	s.log.record("focus_window", "window=%d", windowID)
+	return nil
+}
+
+func (s dryRunWindowService) Click(_ context.Context, windowID uint32, x, y float64, button string, clicks int) error {
+	s.log.record("click", "window=%d x=%.1f y=%.1f button=%s clicks=%d", windowID, x, y, button, clicks)
+	return nil
+}
+
+func (s dryRunWindowService) ClickAt(_ context.Context, x, y float64, button string, clicks int, coordSpace string) error {
+	s.log.record("click_screen", "x=%.1f y=%.1f button=%s clicks=%d coordinate_space=%s", x, y, button, clicks, coordSpace)
+	return nil
+}
+
+func (s dryRunWindowService) MouseMove(_ context.Context, windowID uint32, x, y float64) error {
+	s.log.record("mouse_move", "window=%d x=%.1f y=%.1f", windowID, x, y)
+	return nil
+}
+
+func (s dryRunWindowService) MouseDown(_ context.Context, windowID uint32, x, y float64, button string) error {
+	s.log.record("mouse_down", "window=%d x=%.1f y=%.1f button=%s", windowID, x, y, button)
+	return nil
+}
+
+func (s dryRunWindowService) MouseUp(_ context.Context, windowID uint32, x, y float64, button string) error {
+	s.log.record("mouse_up", "window=%d x=%.1f y=%.1f button=%s", windowID, x, y, button)
+	return nil
+}
+
+func (s dryRunWindowService) Drag(_ context.Context, windowID uint32, fromX, fromY, toX, toY float64, button string) error {
+	s.log.record("drag", "window=%d from=(%.1f,%.1f) to=(%.1f,%.1f) button=%s", windowID, fromX, fromY, toX, toY, button)
+	return nil
+}
+
+func (s dryRunWindowService) Scroll(_ context.Context, windowID uint32, x, y, deltaX, deltaY float64) error {
+	s.log.record("scroll", "window=%d x=%.1f y=%.1f dx=%.1f dy=%.1f", windowID, x, y, deltaX, deltaY)
+	return nil
+}
+
+func (s dryRunWindowService) LaunchApp(_ context.Context, appName string) error {
+	s.log.record("launch_app", "app=%q", appName)
+	return nil
+}
+
+func (s dryRunWindowService) QuitApp(_ context.Context, appName string) error {
+	s.log.record("quit_app", "app=%q", appName)
+	return nil
+}
+
+func (s dryRunWindowService) KillProcess(_ context.Context, processName string) error {
+	s.log.record("kill_process", "process=%q", processName)
+	return nil
+}
+
+// dryRunInputService returns an input service that logs keyboard input instead of sending it.
+// Typed text is logged by length only so rehearsals do not leak secrets to the log.
+func dryRunInputService(log *dryRunLog) *tools.InputService {
+	return &tools.InputService{
+		PressKeyFn: func(_ context.Context, key string, modifiers []string) error {
+			log.record("press_key", "key=%q modifiers=[%s]", key, strings.Join(modifiers, ","))
+			return nil
+		},
+		TypeTextFn: func(_ context.Context, text string, delayMs int) error {
+			log.record("type_text", "chars=%d delay_ms=%d", len([]rune(text)), delayMs)
+			return nil
+		},
+		KeyDownFn: func(_ context.Context, key string, modifiers []string) error {
+			log.record("key_down", "key=%q modifiers=[%s]", key, strings.Join(modifiers, ","))
+			return nil
+		},
+		KeyUpFn: func(_ context.Context, key string, modifiers []string) error {
+			log.record("key_up", "key=%q modifiers=[%s]", key, strings.Join(modifiers, ","))
+			return nil
+		},
+	}
+}
+
+// dryRunClipboard logs clipboard writes and clears instead of performing them.
+// Text is logged by length only; reads still see the real clipboard.
+type dryRunClipboard struct {
+	clipboard.Clipboard
+	log *dryRunLog
+}
+
+func (c dryRunClipboard) Write(_ context.Context, mimeType string, data []byte) error {
+	if mimeType == clipboard.TypePNG {
+		c.log.record(SetClipboardToolName, "bytes=%d mime_type=%s", len(data), mimeType)
+		return nil
+	}
+	c.log.record(SetClipboardToolName, "chars=%d mime_type=%s", len([]rune(string(data))), mimeType)
+	return nil
+}
+
+func (c dryRunClipboard) Clear(context.Context) error {
+	c.log.record(ClearClipboardToolName, "")
+	return nil
+}
+
+// dryRunMiddleware marks every tool result with _meta.dry_run so clients can
+// tell a rehearsal from a real run.
+func dryRunMiddleware() sdkmcp.Middleware {
+	return func(next sdkmcp.MethodHandler) sdkmcp.MethodHandler {
+		return func(ctx context.Context, method string, req sdkmcp.Request) (sdkmcp.Result, error) {
+			result, err := next(ctx, method, req)
+			if callResult, ok := result.(*sdkmcp.CallToolResult); ok && method == "tools/call" {
+				if callResult.Meta == nil {
+					callResult.Meta = sdkmcp.Meta{}
+				}
+				callResult.Meta[dryRunMetaKey] = true
+			}
+			return result, err
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package mcpserver
+
+import (
+	"context"
+	"image"
+	"strings"
+	"testing"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/clipboard"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/imgencode"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/tools"
+)
+
+func TestNewServer_DryRunSimulatesSideEffects(t *testing.T) {
+	log := &auditBuffer{}
+	captured := false
+	service := &tools.ScreenshotService{
+		Capture: func(context.Context) (image.Image, error) {
+			captured = true
+			return image.NewRGBA(image.Rect(0, 0, 2, 2)), nil
+		},
+		Encode: func(image.Image, imgencode.Options) ([]byte, error) {
+			return []byte{0xff, 0xd8}, nil
+		},
+		Options: imgencode.DefaultOptions,
+	}
+	inputService := &tools.InputService{
+		TypeTextFn: func(context.Context, string, int) error {
+			t.Error("type_text reached the real input service in dry-run mode")
+			return nil
+		},
+	}
+	// The embedded WindowService is nil, so any call that is not simulated panics.
+	board := &memoryClipboard{mimeType: clipboard.TypeText, data: []byte("original")}
+	server := NewServer(service, Config{
+		WindowService: auditWindowService{},
+		InputService:  inputService,
+		Clipboard:     board,
+		DryRun:        true,
+		DryRunLog:     log,
+	})
+	_, session := connectTestSession(t, server)
+	defer func() { _ = session.Close() }()
+
+	calls := []sdkmcp.CallToolParams{
+		{Name: ClickToolName, Arguments: map[string]any{"window_id": 3, "x": 10, "y": 20}},
+		{Name: TypeTextToolName, Arguments: map[string]any{"window_id": 3, "text": "hunter2"}},
+		{Name: LaunchAppToolName, Arguments: map[string]any{"app_name": "Calculator"}},
+		{Name: KillProcessToolName, Arguments: map[string]any{"process_name": "Calculator"}},
+		{Name: SetClipboardToolName, Arguments: map[string]any{"text": "copied"}},
+		{Name: ClearClipboardToolName},
+		{Name: ToolName},
+	}
+	for _, params := range calls {
+		result, err := session.CallTool(context.Background(), &params)
+		if err != nil {
+			t.Fatalf("%s: %v", params.Name, err)
+		}
+		if result.IsError {
+			t.Fatalf("%s returned a tool error: %+v", params.Name, result.Content)
+		}
+		if result.Meta[dryRunMetaKey] != true {
+			t.Fatalf("%s result is missing _meta.%s", params.Name, dryRunMetaKey)
+		}
+	}
+
+	if !captured {
+		t.Error("expected screenshots to still capture in dry-run mode")
+	}
+	log.mu.Lock()
+	output := log.buf.String()
+	log.mu.Unlock()
+	for _, want := range []string{
+		"dry-run: focus_window window=3",
+		"dry-run: click window=3 x=10.0 y=20.0",
+		"dry-run: type_text chars=7",
+		`dry-run: launch_app app="Calculator"`,
+		`dry-run: kill_process process="Calculator"`,
+		"dry-run: set_clipboard chars=6 mime_type=text/plain",
+		"dry-run: clear_clipboard\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("dry-run log missing %q:\n%s", want, output)
+		}
+	}
+	if string(board.data) != "original" {
+		t.Errorf("dry-run changed the clipboard to %q", board.data)
+	}
+	if strings.Contains(output, "hunter2") {
+		t.Error("dry-run log must not contain typed text")
+	}
+}
@@ -0,0 +1,116 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// DefaultUnixSocketMode restricts the socket to the owning user.
+const DefaultUnixSocketMode os.FileMode = 0o600
+
+// HTTPTimeouts mirrors the http.Server timeouts used by the network transports.
+type HTTPTimeouts struct {
+	ReadHeader time.Duration
+	Read       time.Duration
+	Write      time.Duration
+	Idle       time.Duration
+}
+
+// DefaultHTTPTimeouts are applied to any HTTPTimeouts field left at zero.
+// Write stays zero: the SSE stream, the Streamable HTTP GET stream and slow tool
+// calls hold a response open far longer than any fixed write deadline, so stalled
+// clients are bounded by ReadHeader and Idle instead.
+var DefaultHTTPTimeouts = HTTPTimeouts{
+	ReadHeader: 5 * time.Second,
+	Read:       10 * time.Second,
+	Idle:       30 * time.Second,
+}
+
+func (t HTTPTimeouts) resolved() HTTPTimeouts {
+	if t.ReadHeader <= 0 {
+		t.ReadHeader = DefaultHTTPTimeouts.ReadHeader
+	}
+	if t.Read <= 0 {
+		t.Read = DefaultHTTPTimeouts.Read
+	}
+	if t.Idle <= 0 {
+		t.Idle = DefaultHTTPTimeouts.Idle
+	}
+	return t
+}
+
+func (cfg HTTPConfig) tlsEnabled() bool {
+	return cfg.TLSCertFile != "" || cfg.TLSKeyFile != ""
+}
+
+func (cfg HTTPConfig) unixSocketMode() os.FileMode {
+	if cfg.UnixSocketMode == 0 {
+		return DefaultUnixSocketMode
+	}
+	return cfg.UnixSocketMode.Perm()
+}
+
+// validateListener checks listener options before any socket is opened.
+func (cfg HTTPConfig) validateListener(port int) error {
+	if cfg.tlsEnabled() && (cfg.TLSCertFile == "" || cfg.TLSKeyFile == "") {
+		return fmt.Errorf("tls requires both a certificate file and a key file")
+	}
+	if cfg.UnixSocket != "" {
+		return nil
+	}
+	if port <= 0 || port > 65535 {
+		return fmt.Errorf("invalid port %d", port)
+	}
+	return nil
+}
+
+// listen opens the Unix socket when configured, otherwise a TCP listener on the bind address.
+func (cfg HTTPConfig) listen(ctx context.Context, port int) (net.Listener, error) {
+	var lc net.ListenConfig
+	if cfg.UnixSocket == "" {
+		address := net.JoinHostPort(cfg.bindAddress(), strconv.Itoa(port))
+		listener, err := lc.Listen(ctx, "tcp", address)
+		if err != nil {
+			return nil, fmt.Errorf("listen on %s: %w", address, err)
+		}
+		return listener, nil
+	}
+
+	if err := removeStaleSocket(cfg.UnixSocket); err != nil {
+		return nil, err
+	}
+	listener, err := listenUnixSocket(ctx, &lc, cfg.UnixSocket, cfg.unixSocketMode())
+	if err != nil {
+		return nil, fmt.Errorf("listen on unix socket %s: %w", cfg.UnixSocket, err)
+	}
+	if err := os.Chmod(cfg.UnixSocket, cfg.unixSocketMode()); err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("set unix socket permissions: %w", err)
+	}
+	return listener, nil
+}
+
+// removeStaleSocket deletes a socket file left behind by a previous run.
+// Anything other than a socket at path is left alone so a typo cannot delete user files.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("stat unix socket path: %w", err)
+	}
+	if info.Mode()&fs.ModeSocket == 0 {
+		return fmt.Errorf("unix socket path %s exists and is not a socket", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("remove stale unix socket: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+//go:build !darwin && !linux
+
+package mcpserver
+
+import (
+	"context"
+	"net"
+	"os"
+)
+
+// listenUnixSocket binds the socket; permissions are applied by the caller afterwards
+// because this platform has no umask to narrow.
+func listenUnixSocket(ctx context.Context, lc *net.ListenConfig, path string, _ os.FileMode) (net.Listener, error) {
+	return lc.Listen(ctx, "unix", path)
+}
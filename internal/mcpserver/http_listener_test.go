@@ -0,0 +1,221 @@
+package mcpserver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func okHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	})
+}
+
+func startTestServer(t *testing.T, listener net.Listener, cfg HTTPConfig) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- serveHTTP(ctx, listener, okHandler(), cfg)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("serveHTTP() error = %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Error("server did not shut down")
+		}
+	})
+}
+
+func TestHTTPConfig_ValidateListener(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     HTTPConfig
+		port    int
+		wantErr bool
+	}{
+		{name: "tcp", port: 3001},
+		{name: "invalid port", port: 0, wantErr: true},
+		{name: "unix ignores port", cfg: HTTPConfig{UnixSocket: "/tmp/mcp.sock"}, port: 0},
+		{name: "cert without key", cfg: HTTPConfig{TLSCertFile: "cert.pem"}, port: 3001, wantErr: true},
+		{name: "key without cert", cfg: HTTPConfig{TLSKeyFile: "key.pem"}, port: 3001, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.validateListener(tt.port)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateListener() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHTTPTimeouts_ResolvedLeavesWriteUnbounded(t *testing.T) {
+	got := HTTPTimeouts{}.resolved()
+	if got.Write != 0 {
+		t.Fatalf("default Write = %v, want 0 so SSE and Streamable HTTP streams stay open", got.Write)
+	}
+	if got.ReadHeader != DefaultHTTPTimeouts.ReadHeader || got.Idle != DefaultHTTPTimeouts.Idle {
+		t.Fatalf("resolved() = %+v, want defaults for ReadHeader and Idle", got)
+	}
+	if got := (HTTPTimeouts{Write: time.Minute}).resolved(); got.Write != time.Minute {
+		t.Fatalf("explicit Write = %v, want 1m", got.Write)
+	}
+}
+
+func TestServeHTTP_UnixSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "mcp.sock")
+	cfg := HTTPConfig{UnixSocket: socketPath, UnixSocketMode: 0o660}
+
+	listener, err := cfg.listen(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("listen() error = %v", err)
+	}
+	info, err := os.Stat(socketPath)
+	if err != nil {
+		t.Fatalf("stat socket: %v", err)
+	}
+	if info.Mode().Perm() != 0o660 {
+		t.Fatalf("socket mode = %v, want 0660", info.Mode().Perm())
+	}
+
+	secured, err := WithHTTPSecurity(okHandler(), cfg)
+	if err != nil {
+		t.Fatalf("WithHTTPSecurity() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- serveHTTP(ctx, listener, secured, cfg)
+	}()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socketPath)
+		},
+	}}
+	resp, err := client.Get("http://mcp-container" + StreamableHTTPPath)
+	if err != nil {
+		t.Fatalf("GET over unix socket: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("serveHTTP() error = %v", err)
+	}
+	if _, err := os.Stat(socketPath); !os.IsNotExist(err) {
+		t.Fatalf("expected socket to be removed on shutdown, stat err = %v", err)
+	}
+}
+
+func TestHTTPConfig_ListenReplacesStaleSocketOnly(t *testing.T) {
+	dir := t.TempDir()
+	regularFile := filepath.Join(dir, "not-a-socket")
+	if err := os.WriteFile(regularFile, []byte("keep"), 0o600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if _, err := (HTTPConfig{UnixSocket: regularFile}).listen(context.Background(), 0); err == nil {
+		t.Fatal("expected error when socket path is a regular file")
+	}
+
+	socketPath := filepath.Join(dir, "stale.sock")
+	stale, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("create stale socket: %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = stale.Close()
+
+	listener, err := (HTTPConfig{UnixSocket: socketPath}).listen(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("listen() over stale socket error = %v", err)
+	}
+	_ = listener.Close()
+}
+
+func TestServeHTTP_TLS(t *testing.T) {
+	certFile, keyFile, pool := writeTestCertificate(t)
+	cfg := HTTPConfig{TLSCertFile: certFile, TLSKeyFile: keyFile}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	startTestServer(t, listener, cfg)
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}}}
+	resp, err := client.Get("https://" + listener.Addr().String() + StreamableHTTPPath)
+	if err != nil {
+		t.Fatalf("GET over TLS: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
+
+func writeTestCertificate(t *testing.T) (string, string, *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write cert: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return certFile, keyFile, pool
+}
@@ -0,0 +1,25 @@
+//go:build darwin || linux
+
+package mcpserver
+
+import (
+	"context"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+)
+
+// umaskMu serializes socket creation so concurrent listens do not restore each other's umask.
+var umaskMu sync.Mutex
+
+// listenUnixSocket creates the socket with mode already applied by narrowing the
+// process umask for the duration of bind, so no other local user can connect
+// before the permissions are tightened.
+func listenUnixSocket(ctx context.Context, lc *net.ListenConfig, path string, mode os.FileMode) (net.Listener, error) {
+	umaskMu.Lock()
+	defer umaskMu.Unlock()
+	previous := syscall.Umask(int(^mode.Perm() & os.ModePerm))
+	defer syscall.Umask(previous)
+	return lc.Listen(ctx, "unix", path)
+}
@@ -0,0 +1,36 @@
+//go:build darwin || linux
+
+package mcpserver
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestListenUnixSocket_CreatesSocketWithModeUnderPermissiveUmask(t *testing.T) {
+	previous := syscall.Umask(0)
+	defer syscall.Umask(previous)
+
+	socketPath := filepath.Join(t.TempDir(), "mcp.sock")
+	var lc net.ListenConfig
+	listener, err := listenUnixSocket(context.Background(), &lc, socketPath, DefaultUnixSocketMode)
+	if err != nil {
+		t.Fatalf("listenUnixSocket() error = %v", err)
+	}
+	defer func() { _ = listener.Close() }()
+
+	info, err := os.Stat(socketPath)
+	if err != nil {
+		t.Fatalf("stat socket: %v", err)
+	}
+	if info.Mode().Perm() != DefaultUnixSocketMode {
+		t.Fatalf("socket mode at creation = %v, want %v", info.Mode().Perm(), DefaultUnixSocketMode)
+	}
+	if restored := syscall.Umask(0); restored != 0 {
+		t.Fatalf("umask after listen = %o, want the caller's 0 restored", restored)
+	}
+}
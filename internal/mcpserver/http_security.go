@@ -0,0 +1,157 @@
+package mcpserver
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// DefaultBindAddress keeps network transports reachable from the local machine only.
+const DefaultBindAddress = "127.0.0.1"
+
+var loopbackHosts = []string{"localhost", "127.0.0.1", "::1"}
+
+// HTTPConfig controls how the network transports are exposed.
+type HTTPConfig struct {
+	// BindAddress is the interface the HTTP server listens on. Defaults to DefaultBindAddress.
+	BindAddress string
+	// AuthToken requires clients to send "Authorization: Bearer <token>".
+	AuthToken string
+	// AuthTokenFile reads the bearer token from a file instead of AuthToken.
+	AuthTokenFile string
+	// AllowedOrigins lists browser origins (scheme://host[:port]) allowed to connect.
+	// Requests carrying any other Origin header are rejected.
+	AllowedOrigins []string
+	// AllowedHosts lists Host header values (without port) accepted by the server.
+	// When empty and the server is bound to loopback, only loopback names are accepted.
+	AllowedHosts []string
+	// TLSCertFile and TLSKeyFile serve HTTPS with the given PEM certificate and key.
+	// Both must be set together.
+	TLSCertFile string
+	TLSKeyFile  string
+	// UnixSocket serves on a Unix domain socket at this path instead of a TCP port.
+	UnixSocket string
+	// UnixSocketMode sets the socket file permissions. Defaults to DefaultUnixSocketMode.
+	UnixSocketMode os.FileMode
+	// Timeouts overrides the HTTP server timeouts; zero fields keep the defaults.
+	Timeouts HTTPTimeouts
+}
+
+func (cfg HTTPConfig) bindAddress() string {
+	if strings.TrimSpace(cfg.BindAddress) == "" {
+		return DefaultBindAddress
+	}
+	return strings.TrimSpace(cfg.BindAddress)
+}
+
+// resolveAuthToken returns the configured bearer token, or "" when authentication is disabled.
+func (cfg HTTPConfig) resolveAuthToken() (string, error) {
+	if cfg.AuthToken != "" && cfg.AuthTokenFile != "" {
+		return "", fmt.Errorf("auth token and auth token file are mutually exclusive")
+	}
+	if cfg.AuthTokenFile == "" {
+		return strings.TrimSpace(cfg.AuthToken), nil
+	}
+
+	// Accepted G304 suppression: the token file path is operator-provided configuration.
+	// #nosec G304
+	data, err := os.ReadFile(cfg.AuthTokenFile)
+	if err != nil {
+		return "", fmt.Errorf("read auth token file: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("auth token file %q is empty", cfg.AuthTokenFile)
+	}
+	return token, nil
+}
+
+func (cfg HTTPConfig) allowedHosts() []string {
+	if len(cfg.AllowedHosts) > 0 {
+		return cfg.AllowedHosts
+	}
+	if cfg.UnixSocket != "" {
+		// Socket access is governed by file permissions; clients send arbitrary Host values.
+		return nil
+	}
+	if IsLoopbackAddress(cfg.bindAddress()) {
+		return loopbackHosts
+	}
+	return nil
+}
+
+// WithHTTPSecurity wraps handler with origin, host and bearer-token checks from cfg.
+func WithHTTPSecurity(handler http.Handler, cfg HTTPConfig) (http.Handler, error) {
+	token, err := cfg.resolveAuthToken()
+	if err != nil {
+		return nil, err
+	}
+
+	allowedOrigins := make(map[string]struct{}, len(cfg.AllowedOrigins))
+	for _, origin := range cfg.AllowedOrigins {
+		allowedOrigins[normalizeOrigin(origin)] = struct{}{}
+	}
+	allowedHosts := make(map[string]struct{}, len(cfg.allowedHosts()))
+	for _, host := range cfg.allowedHosts() {
+		allowedHosts[normalizeHost(host)] = struct{}{}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if origin := r.Header.Get("Origin"); origin != "" {
+			if _, ok := allowedOrigins[normalizeOrigin(origin)]; !ok {
+				http.Error(w, "origin not allowed", http.StatusForbidden)
+				return
+			}
+		}
+		if len(allowedHosts) > 0 {
+			if _, ok := allowedHosts[normalizeHost(r.Host)]; !ok {
+				http.Error(w, "host not allowed", http.StatusForbidden)
+				return
+			}
+		}
+		if token != "" && !hasBearerToken(r, token) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="screenshot-mcp-server"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}), nil
+}
+
+func hasBearerToken(r *http.Request, token string) bool {
+	scheme, value, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimSpace(value)), []byte(token)) == 1
+}
+
+func normalizeOrigin(origin string) string {
+	parsed, err := url.Parse(strings.TrimSpace(origin))
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return strings.ToLower(strings.TrimSpace(origin))
+	}
+	return strings.ToLower(parsed.Scheme + "://" + parsed.Host)
+}
+
+func normalizeHost(hostport string) string {
+	host := strings.TrimSpace(hostport)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.Trim(host, "[]"))
+}
+
+// IsLoopbackAddress reports whether a bind address only accepts local
+// connections: "localhost" or a loopback IP, optionally in brackets.
+func IsLoopbackAddress(address string) bool {
+	if strings.EqualFold(address, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(strings.Trim(address, "[]"))
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,127 @@
+package mcpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newSecuredTestHandler(t *testing.T, cfg HTTPConfig) http.Handler {
+	t.Helper()
+	handler, err := WithHTTPSecurity(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), cfg)
+	if err != nil {
+		t.Fatalf("WithHTTPSecurity() error = %v", err)
+	}
+	return handler
+}
+
+func serveTestRequest(handler http.Handler, host string, headers map[string]string) int {
+	req := httptest.NewRequest(http.MethodPost, "http://"+host+StreamableHTTPPath, nil)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestWithHTTPSecurity_BearerToken(t *testing.T) {
+	handler := newSecuredTestHandler(t, HTTPConfig{AuthToken: "secret"})
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    int
+	}{
+		{name: "missing", want: http.StatusUnauthorized},
+		{name: "wrong", headers: map[string]string{"Authorization": "Bearer nope"}, want: http.StatusUnauthorized},
+		{name: "wrong scheme", headers: map[string]string{"Authorization": "Basic secret"}, want: http.StatusUnauthorized},
+		{name: "valid", headers: map[string]string{"Authorization": "Bearer secret"}, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := serveTestRequest(handler, "127.0.0.1:3001", tt.headers); got != tt.want {
+				t.Fatalf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithHTTPSecurity_Origin(t *testing.T) {
+	handler := newSecuredTestHandler(t, HTTPConfig{AllowedOrigins: []string{"http://localhost:5173"}})
+
+	if got := serveTestRequest(handler, "localhost:3001", map[string]string{"Origin": "https://evil.example"}); got != http.StatusForbidden {
+		t.Fatalf("disallowed origin status = %d, want %d", got, http.StatusForbidden)
+	}
+	if got := serveTestRequest(handler, "localhost:3001", map[string]string{"Origin": "http://LOCALHOST:5173"}); got != http.StatusOK {
+		t.Fatalf("allowed origin status = %d, want %d", got, http.StatusOK)
+	}
+	if got := serveTestRequest(handler, "localhost:3001", nil); got != http.StatusOK {
+		t.Fatalf("no origin status = %d, want %d", got, http.StatusOK)
+	}
+}
+
+func TestWithHTTPSecurity_HostRebinding(t *testing.T) {
+	handler := newSecuredTestHandler(t, HTTPConfig{})
+
+	if got := serveTestRequest(handler, "evil.example:3001", nil); got != http.StatusForbidden {
+		t.Fatalf("rebinding host status = %d, want %d", got, http.StatusForbidden)
+	}
+	for _, host := range []string{"localhost:3001", "127.0.0.1:3001", "[::1]:3001"} {
+		if got := serveTestRequest(handler, host, nil); got != http.StatusOK {
+			t.Fatalf("host %q status = %d, want %d", host, got, http.StatusOK)
+		}
+	}
+
+	public := newSecuredTestHandler(t, HTTPConfig{BindAddress: "0.0.0.0"})
+	if got := serveTestRequest(public, "screens.internal:3001", nil); got != http.StatusOK {
+		t.Fatalf("non-loopback bind status = %d, want %d", got, http.StatusOK)
+	}
+}
+
+func TestHTTPConfig_ResolveAuthToken(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	if err := os.WriteFile(tokenFile, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatalf("write token file: %v", err)
+	}
+	emptyFile := filepath.Join(dir, "empty")
+	if err := os.WriteFile(emptyFile, []byte("  \n"), 0o600); err != nil {
+		t.Fatalf("write empty file: %v", err)
+	}
+
+	token, err := HTTPConfig{AuthTokenFile: tokenFile}.resolveAuthToken()
+	if err != nil || token != "from-file" {
+		t.Fatalf("resolveAuthToken() = %q, %v; want from-file", token, err)
+	}
+	if _, err := (HTTPConfig{AuthTokenFile: emptyFile}).resolveAuthToken(); err == nil {
+		t.Fatal("expected error for empty token file")
+	}
+	if _, err := (HTTPConfig{AuthToken: "a", AuthTokenFile: tokenFile}).resolveAuthToken(); err == nil {
+		t.Fatal("expected error when both token and token file are set")
+	}
+	if _, err := WithHTTPSecurity(http.NotFoundHandler(), HTTPConfig{AuthTokenFile: filepath.Join(dir, "missing")}); err == nil {
+		t.Fatal("expected error for missing token file")
+	}
+}
+
+func TestIsLoopbackAddress(t *testing.T) {
+	for address, want := range map[string]bool{
+		"localhost": true,
+		"LOCALHOST": true,
+		"127.0.0.1": true,
+		"::1":       true,
+		"[::1]":     true,
+		"0.0.0.0":   false,
+		"10.0.0.5":  false,
+		"example":   false,
+	} {
+		if got := IsLoopbackAddress(address); got != want {
+			t.Errorf("IsLoopbackAddress(%q) = %v, want %v", address, got, want)
+		}
+	}
+}
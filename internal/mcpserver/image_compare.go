@@ -183,7 +183,9 @@ func isMaskedPoint(x, y int, maskRects []image.Rectangle) bool {
 }
 
 // assertScreenshotMatchesFixture compares a window screenshot to a golden fixture.
-func assertScreenshotMatchesFixture(ctx context.Context, windowID uint32, fixturePath string, threshold float64, maskRegions []MaskRegion) (*FixtureComparisonResult, error) {
+// The screenshot is redacted first, so fixtures recorded from redacted
+// screenshots still match.
+func assertScreenshotMatchesFixture(ctx context.Context, redactor *redactor, windowID uint32, fixturePath string, threshold float64, maskRegions []MaskRegion) (*FixtureComparisonResult, error) {
 	if err := ValidatePathAllowed(fixturePath); err != nil {
 		return nil, fmt.Errorf("fixture path not allowed: %w", err)
 	}
@@ -199,7 +201,7 @@ func assertScreenshotMatchesFixture(ctx context.Context, windowID uint32, fixtur
 		return nil, err
 	}
 
-	tempPath, cleanupTempFile, err := captureWindowFixtureScreenshot(ctx, windowID)
+	tempPath, cleanupTempFile, err := captureWindowFixtureScreenshot(ctx, redactor, windowID)
 	if err != nil {
 		return nil, err
 	}
@@ -219,13 +221,20 @@ func assertScreenshotMatchesFixture(ctx context.Context, windowID uint32, fixtur
 	}, nil
 }
 
-func captureWindowFixtureScreenshot(ctx context.Context, windowID uint32) (string, func() error, error) {
-	screenshotData, _, err := window.TakeWindowScreenshot(ctx, windowID, imgencode.DefaultOptions)
+func captureWindowFixtureScreenshot(ctx context.Context, redactor *redactor, windowID uint32) (string, func() error, error) {
+	img, metadata, err := window.TakeWindowScreenshotImage(ctx, windowID)
 	if err != nil {
 		return "", nil, fmt.Errorf("capture window screenshot: %w", err)
 	}
+	if img, _, err = redactor.window(ctx, img, metadata); err != nil {
+		return "", nil, err
+	}
+	screenshotData, err := imgencode.EncodeJPEG(img, imgencode.DefaultOptions)
+	if err != nil {
+		return "", nil, fmt.Errorf("encode window screenshot: %w", err)
+	}
 
-	tmpFile, err := CreateArtifactFile(fmt.Sprintf("window-%d-fixture", windowID), "jpg")
+	tmpFile, err := CreateArtifactFile(ctx, fmt.Sprintf("window-%d-fixture", windowID), "jpg")
 	if err != nil {
 		return "", nil, fmt.Errorf("create fixture screenshot: %w", err)
 	}
@@ -1,6 +1,7 @@
 package mcpserver
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -107,7 +108,8 @@ func ArtifactPath(prefix, ext string) (string, error) {
 }
 
 // CreateArtifactFile creates a deterministic artifact file with secure permissions.
-func CreateArtifactFile(prefix, ext string) (*os.File, error) {
+// The path is attached to the audit record of the tool call carried by ctx.
+func CreateArtifactFile(ctx context.Context, prefix, ext string) (*os.File, error) {
 	path, err := ArtifactPath(prefix, ext)
 	if err != nil {
 		return nil, err
@@ -120,6 +122,7 @@ func CreateArtifactFile(prefix, ext string) (*os.File, error) {
 	if err != nil {
 		return nil, fmt.Errorf("create artifact file %q: %w", path, err)
 	}
+	recordArtifact(ctx, path)
 	return file, nil
 }
 
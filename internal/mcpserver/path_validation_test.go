@@ -1,6 +1,7 @@
 package mcpserver
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
@@ -32,7 +33,7 @@ func TestArtifactPathAndFileCreation(t *testing.T) {
 		t.Fatalf("artifact name not sanitized as expected: %q", filepath.Base(path1))
 	}
 
-	file1, err := CreateArtifactFile("window/scope", "jpg")
+	file1, err := CreateArtifactFile(context.Background(), "window/scope", "jpg")
 	if err != nil {
 		t.Fatalf("create artifact file: %v", err)
 	}
@@ -44,7 +45,7 @@ func TestArtifactPathAndFileCreation(t *testing.T) {
 		_ = os.Remove(file1Name)
 	}()
 
-	file2, err := CreateArtifactFile("window/scope", "jpg")
+	file2, err := CreateArtifactFile(context.Background(), "window/scope", "jpg")
 	if err != nil {
 		t.Fatalf("create second artifact file: %v", err)
 	}
@@ -0,0 +1,178 @@
+package mcpserver
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"os/exec"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/imgencode"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/redact"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/window"
+)
+
+// redactor applies the configured redaction rules to every image a tool returns
+// or writes to disk. A nil redactor redacts nothing and reports no count.
+type redactor struct {
+	policy *redact.Policy
+	// err is the rule compile error. Every capture fails with it, so a bad rule
+	// set never lets unredacted pixels out.
+	err           error
+	service       ScreenshotService
+	windowService WindowService
+}
+
+func newRedactor(rules redact.Rules, service ScreenshotService, windowService WindowService) *redactor {
+	if rules.Empty() {
+		return nil
+	}
+	policy, err := redact.Compile(rules)
+	if err != nil {
+		err = fmt.Errorf("invalid redaction rules: %w", err)
+	}
+	return &redactor{policy: policy, err: err, service: service, windowService: windowService}
+}
+
+// screenshot redacts a full-screen capture, or a capture of one display when
+// display is set.
+func (r *redactor) screenshot(ctx context.Context, img image.Image, display *int) (image.Image, *int, error) {
+	if display != nil {
+		return r.display(ctx, img, *display)
+	}
+	return r.screen(ctx, img)
+}
+
+// screen redacts a capture of every display stitched together, whose top-left
+// pixel is the top-left corner of the display union.
+func (r *redactor) screen(ctx context.Context, img image.Image) (image.Image, *int, error) {
+	if r == nil {
+		return img, nil, nil
+	}
+	if r.err != nil {
+		return nil, nil, r.err
+	}
+	displays, err := r.service.ListDisplays(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("redact screenshot: %w", err)
+	}
+	if len(displays) == 0 {
+		return nil, nil, fmt.Errorf("redact screenshot: no active displays")
+	}
+	union := displays[0].Bounds
+	primary := displays[0]
+	for _, display := range displays[1:] {
+		union = union.Union(display.Bounds)
+		if display.Primary {
+			primary = display
+		}
+	}
+	frame := redact.Frame{X: float64(union.Min.X), Y: float64(union.Min.Y), Scale: describeDisplay(primary).Scale}
+	return r.apply(ctx, img, frame, 0)
+}
+
+// display redacts a capture of the display at index.
+func (r *redactor) display(ctx context.Context, img image.Image, index int) (image.Image, *int, error) {
+	if r == nil {
+		return img, nil, nil
+	}
+	if r.err != nil {
+		return nil, nil, r.err
+	}
+	displays, err := r.service.ListDisplays(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("redact screenshot: %w", err)
+	}
+	for _, display := range displays {
+		if display.Index == index {
+			info := describeDisplay(display)
+			return r.apply(ctx, img, redact.Frame{X: info.Bounds.X, Y: info.Bounds.Y, Scale: info.Scale}, 0)
+		}
+	}
+	return nil, nil, fmt.Errorf("redact screenshot: display %d not found", index)
+}
+
+// window redacts a window capture. Other windows cannot show through a capture
+// read from the window itself, so only that window's rules apply to it.
+func (r *redactor) window(ctx context.Context, img image.Image, metadata *window.ScreenshotMetadata) (image.Image, *int, error) {
+	if r == nil {
+		return img, nil, nil
+	}
+	if r.err != nil {
+		return nil, nil, r.err
+	}
+	frame := redact.Frame{X: metadata.Bounds.X, Y: metadata.Bounds.Y, Scale: metadata.Scale}
+	var only uint32
+	if metadata.OcclusionFree {
+		only = metadata.WindowID
+	}
+	return r.apply(ctx, img, frame, only)
+}
+
+// region redacts a region capture cropped from the screen.
+func (r *redactor) region(ctx context.Context, img image.Image, metadata *window.RegionMetadata) (image.Image, *int, error) {
+	if r == nil {
+		return img, nil, nil
+	}
+	if r.err != nil {
+		return nil, nil, r.err
+	}
+	return r.apply(ctx, img, redact.Frame{X: metadata.X, Y: metadata.Y, Scale: metadata.Scale}, 0)
+}
+
+// screenJPEG redacts an encoded full-screen capture taken outside the capture
+// backend, such as one from screencapture, and re-encodes it as JPEG.
+func (r *redactor) screenJPEG(ctx context.Context, data []byte, opts imgencode.Options) ([]byte, *int, error) {
+	if r == nil {
+		return data, nil, nil
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("decode screenshot for redaction: %w", err)
+	}
+	img, redactions, err := r.screen(ctx, img)
+	if err != nil {
+		return nil, nil, err
+	}
+	if data, err = imgencode.EncodeJPEG(img, opts); err != nil {
+		return nil, nil, fmt.Errorf("encode screenshot: %w", err)
+	}
+	return data, redactions, nil
+}
+
+// apply fills in the windows and OCR words the policy needs and redacts img.
+// A non-zero windowID limits window rules to that window.
+func (r *redactor) apply(ctx context.Context, img image.Image, frame redact.Frame, windowID uint32) (image.Image, *int, error) {
+	if r.policy.NeedsWindows() {
+		windows, err := r.windowService.ListWindows(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("list windows for redaction: %w", err)
+		}
+		for _, win := range windows {
+			if windowID != 0 && win.WindowID != windowID {
+				continue
+			}
+			frame.Windows = append(frame.Windows, redact.Window{Owner: win.OwnerName, Title: win.Title, Bounds: redact.Rect(win.Bounds)})
+		}
+	}
+
+	var words []redact.Word
+	if r.policy.NeedsOCR() {
+		if _, err := exec.LookPath("tesseract"); err != nil {
+			return nil, nil, fmt.Errorf("redaction patterns require tesseract: %w", err)
+		}
+		found, err := runOCRWords(ctx, img)
+		if err != nil {
+			return nil, nil, fmt.Errorf("run OCR for redaction: %w", err)
+		}
+		// tesseract reports positions in the encoded image, which starts at 0,0.
+		origin := img.Bounds().Min
+		for _, word := range found {
+			box := image.Rect(word.Left, word.Top, word.Left+word.Width, word.Top+word.Height).Add(origin)
+			words = append(words, redact.Word{Text: word.Text, Line: word.Line, Box: box})
+		}
+	}
+
+	redacted, count := r.policy.Apply(img, frame, words)
+	return redacted, &count, nil
+}
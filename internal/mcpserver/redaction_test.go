@@ -0,0 +1,196 @@
+package mcpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/redact"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/tools"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/window"
+)
+
+var opaqueBlack = color.RGBA{A: 255}
+
+// whiteScreenService stitches the dual displays into one white 60x30 screen.
+type whiteScreenService struct {
+	dualDisplayService
+}
+
+func (whiteScreenService) CaptureImage(context.Context) (image.Image, error) {
+	img := image.NewRGBA(image.Rect(0, 0, 60, 30))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	return img, nil
+}
+
+// slackWindowService lists a Slack window at 10,5 and captures it as a white
+// 20x10 image.
+type slackWindowService struct {
+	windowToolsService
+}
+
+var slackWindow = window.Window{WindowID: 7, OwnerName: "Slack", Title: "general", Bounds: window.Bounds{X: 10, Y: 5, Width: 20, Height: 10}}
+
+func (slackWindowService) ListWindows(context.Context) ([]window.Window, error) {
+	return []window.Window{slackWindow, {WindowID: 8, OwnerName: "Terminal", Bounds: window.Bounds{X: 0, Y: 0, Width: 60, Height: 30}}}, nil
+}
+
+func (slackWindowService) TakeWindowScreenshotImage(_ context.Context, windowID uint32) (image.Image, *window.ScreenshotMetadata, error) {
+	img, _ := whiteScreenService{}.CaptureImage(context.Background())
+	return img.(*image.RGBA).SubImage(image.Rect(0, 0, 20, 10)), &window.ScreenshotMetadata{
+		WindowID: windowID, Bounds: slackWindow.Bounds, ImageWidth: 20, ImageHeight: 10, Scale: 1, OcclusionFree: true,
+	}, nil
+}
+
+var testRedactionRules = redact.Rules{
+	Rects:   []redact.Rect{{X: 0, Y: 0, Width: 5, Height: 5}, {X: 45, Y: 2, Width: 3, Height: 3}},
+	Windows: []redact.WindowRule{{Owner: "slack", Regions: []redact.Rect{{X: 0, Y: 0, Width: 4, Height: 2}}}},
+}
+
+func newRedactionTestSession(t *testing.T, rules redact.Rules) *sdkmcp.ClientSession {
+	t.Helper()
+	server := NewServer(whiteScreenService{}, Config{WindowService: slackWindowService{}, InputService: &tools.InputService{}, Redaction: rules})
+	_, session := connectTestSession(t, server)
+	t.Cleanup(func() { _ = session.Close() })
+	return session
+}
+
+func decodePNGContent(t *testing.T, result *sdkmcp.CallToolResult) *image.RGBA {
+	t.Helper()
+	decoded, err := png.Decode(bytes.NewReader(result.Content[1].(*sdkmcp.ImageContent).Data))
+	if err != nil {
+		t.Fatalf("decode png: %v", err)
+	}
+	img := image.NewRGBA(decoded.Bounds())
+	draw.Draw(img, img.Bounds(), decoded, decoded.Bounds().Min, draw.Src)
+	return img
+}
+
+func redactionsOf(t *testing.T, result *sdkmcp.CallToolResult) int {
+	t.Helper()
+	var metadata struct {
+		Redactions *int `json:"redactions"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(*sdkmcp.TextContent).Text), &metadata); err != nil {
+		t.Fatalf("decode metadata: %v", err)
+	}
+	if metadata.Redactions == nil {
+		t.Fatal("metadata has no redactions count")
+	}
+	return *metadata.Redactions
+}
+
+func TestScreenshotToolsRedactConfiguredRegions(t *testing.T) {
+	session := newRedactionTestSession(t, testRedactionRules)
+	ctx := context.Background()
+
+	result, err := session.CallTool(ctx, &sdkmcp.CallToolParams{Name: TakeScreenshotPNGToolName})
+	if err != nil || result.IsError {
+		t.Fatalf("take_screenshot_png: %v %+v", err, result)
+	}
+	if text := result.Content[0].(*sdkmcp.TextContent).Text; !strings.Contains(text, "3 regions redacted") {
+		t.Fatalf("text = %q, want the redaction count", text)
+	}
+	img := decodePNGContent(t, result)
+	for _, black := range []image.Point{{0, 0}, {4, 4}, {10, 5}, {13, 6}, {45, 2}} {
+		if img.RGBAAt(black.X, black.Y) != opaqueBlack {
+			t.Errorf("pixel %v = %v, want it redacted", black, img.RGBAAt(black.X, black.Y))
+		}
+	}
+	for _, white := range []image.Point{{5, 5}, {14, 5}, {10, 7}, {30, 20}} {
+		if img.RGBAAt(white.X, white.Y) == opaqueBlack {
+			t.Errorf("pixel %v was redacted", white)
+		}
+	}
+
+	// Display 1 starts at x=40, so only the rect at 45,2 lands on it.
+	result, err = session.CallTool(ctx, &sdkmcp.CallToolParams{
+		Name:      ToolName,
+		Arguments: map[string]any{"display": 1, "format": "png"},
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("take_screenshot: %v %+v", err, result)
+	}
+	if got := redactionsOf(t, result); got != 1 {
+		t.Fatalf("display 1 redactions = %d, want 1", got)
+	}
+	if img := decodePNGContent(t, result); img.RGBAAt(5, 2) != opaqueBlack || img.RGBAAt(0, 0) == opaqueBlack {
+		t.Fatal("expected only the rect at screen 45,2 to be redacted on display 1")
+	}
+
+	// A window capture only shows the window, so the screen rect at 0,0 misses it.
+	result, err = session.CallTool(ctx, &sdkmcp.CallToolParams{
+		Name:      TakeWindowScreenshotToolName,
+		Arguments: map[string]any{"window_id": 7, "format": "png"},
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("take_window_screenshot: %v %+v", err, result)
+	}
+	if got := redactionsOf(t, result); got != 1 {
+		t.Fatalf("window redactions = %d, want 1", got)
+	}
+	if img := decodePNGContent(t, result); img.RGBAAt(3, 1) != opaqueBlack || img.RGBAAt(4, 2) == opaqueBlack {
+		t.Fatal("expected the window's 4x2 region to be redacted")
+	}
+}
+
+func TestScreenshotToolsOmitRedactionsWithoutRules(t *testing.T) {
+	session := newRedactionTestSession(t, redact.Rules{})
+	result, err := session.CallTool(context.Background(), &sdkmcp.CallToolParams{Name: ToolName})
+	if err != nil || result.IsError {
+		t.Fatalf("take_screenshot: %v %+v", err, result)
+	}
+	if strings.Contains(result.Content[0].(*sdkmcp.TextContent).Text, "redactions") {
+		t.Fatal("expected no redactions key when no rules are configured")
+	}
+}
+
+func TestInvalidRedactionRulesFailClosed(t *testing.T) {
+	session := newRedactionTestSession(t, redact.Rules{Rects: []redact.Rect{{Width: 0, Height: 5}}})
+	result, err := session.CallTool(context.Background(), &sdkmcp.CallToolParams{Name: ToolName})
+	if err != nil {
+		t.Fatalf("take_screenshot: %v", err)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].(*sdkmcp.TextContent).Text, "invalid redaction rules") {
+		t.Fatalf("result = %+v, want an invalid rules error instead of an image", result)
+	}
+}
+
+func TestRecordingRedactsFrames(t *testing.T) {
+	state := newSessionState("redaction-test")
+	t.Cleanup(state.recordings.discardAll)
+	rules := redact.Rules{Rects: []redact.Rect{{X: 0, Y: 0, Width: 2, Height: 2}}}
+	service := solidScreenshotService{}
+	recordingID, err := state.recordings.start(context.Background(), service, newRedactor(rules, service, windowToolsService{}), state.artifactPrefix("recording"), 0, 20, "gif")
+	if err != nil {
+		t.Fatalf("start recording: %v", err)
+	}
+	state.recordings.mu.Lock()
+	frameDir := state.recordings.active[recordingID].frameDir
+	state.recordings.mu.Unlock()
+	waitForCondition(t, "a second frame", func() bool {
+		_, err := os.Stat(filepath.Join(frameDir, "frame_000001.png"))
+		return err == nil
+	})
+
+	output, err := state.recordings.stop(context.Background(), recordingID)
+	if err != nil {
+		t.Fatalf("stop recording: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Remove(output.path) })
+	if output.redactions == nil || *output.redactions < 2 {
+		t.Fatalf("redactions = %v, want one per frame", output.redactions)
+	}
+}
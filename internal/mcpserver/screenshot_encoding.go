@@ -0,0 +1,85 @@
+package mcpserver
+
+import (
+	"fmt"
+	"image"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/annotate"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/imgencode"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/tools"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/window"
+)
+
+// windowScreenshotResult is the take_window_screenshot metadata plus the encoding
+// used, any annotation labels and, when rules are configured, the redaction count.
+type windowScreenshotResult struct {
+	*window.ScreenshotMetadata
+	Encoding    *imgencode.Encoding `json:"encoding,omitempty"`
+	Annotations *annotate.Result    `json:"annotations,omitempty"`
+	Redactions  *int                `json:"redactions,omitempty"`
+}
+
+// regionScreenshotResult is the take_region_screenshot metadata plus the encoding
+// used, any annotation labels and, when rules are configured, the redaction count.
+type regionScreenshotResult struct {
+	*window.RegionMetadata
+	Encoding    *imgencode.Encoding `json:"encoding,omitempty"`
+	Annotations *annotate.Result    `json:"annotations,omitempty"`
+	Redactions  *int                `json:"redactions,omitempty"`
+}
+
+// output merges per-call encoding arguments over the server's JPEG settings.
+func (a encodingArgs) output(base imgencode.Options) (imgencode.Output, error) {
+	format, err := imgencode.NormalizeFormat(a.Format)
+	if err != nil {
+		return imgencode.Output{}, err
+	}
+	if a.Quality < 0 || a.Quality > 100 {
+		return imgencode.Output{}, fmt.Errorf("quality must be between 1 and 100")
+	}
+	if a.MaxBytes < 0 || a.MaxWidth < 0 || a.MaxHeight < 0 {
+		return imgencode.Output{}, fmt.Errorf("max_bytes, max_width and max_height must be >= 0")
+	}
+	if format == imgencode.FormatPNG && (a.Quality != 0 || a.MaxBytes != 0) {
+		return imgencode.Output{}, fmt.Errorf("quality and max_bytes only apply to format %q", imgencode.FormatJPEG)
+	}
+
+	opts := base
+	if a.Quality > 0 {
+		opts.Quality = a.Quality
+		// An explicit quality below the fallback floor lowers the floor with it.
+		minQuality := opts.MinQuality
+		if minQuality <= 0 {
+			minQuality = imgencode.DefaultOptions.MinQuality
+		}
+		if a.Quality < minQuality {
+			opts.MinQuality = a.Quality
+		}
+	}
+	if a.MaxBytes > 0 {
+		opts.MaxBytes = a.MaxBytes
+	}
+	return imgencode.Output{
+		Format:    format,
+		JPEG:      opts,
+		MaxWidth:  a.MaxWidth,
+		MaxHeight: a.MaxHeight,
+		Grayscale: a.Grayscale,
+	}, nil
+}
+
+// encodedScreenshotResult encodes img and returns it with metadata built from the
+// effective encoding.
+func encodedScreenshotResult(img image.Image, out imgencode.Output, metadata func(*imgencode.Encoding) any) (*sdkmcp.CallToolResult, error) {
+	data, encoding, err := imgencode.Encode(img, out)
+	if err != nil {
+		return nil, fmt.Errorf("encode screenshot: %w", err)
+	}
+	result, err := tools.ToolResultFromJSONWithImage(metadata(encoding), data, encoding.MimeType)
+	if err != nil {
+		return nil, fmt.Errorf("marshal metadata: %w", err)
+	}
+	return result, nil
+}
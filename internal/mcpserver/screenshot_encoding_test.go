@@ -0,0 +1,113 @@
+package mcpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/png"
+	"testing"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/imgencode"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/tools"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/window"
+)
+
+// regionImageService returns a 100x50 region capture.
+type regionImageService struct {
+	windowToolsService
+}
+
+func (regionImageService) TakeRegionScreenshotImage(_ context.Context, x, y, width, height float64, coordSpace string) (image.Image, *window.RegionMetadata, error) {
+	return image.NewRGBA(image.Rect(0, 0, 100, 50)), &window.RegionMetadata{
+		X: x, Y: y, Width: width, Height: height, ImageWidth: 100, ImageHeight: 50, Scale: 1, CoordSpace: coordSpace,
+	}, nil
+}
+
+func TestEncodingArgsOutput(t *testing.T) {
+	base := imgencode.Options{Quality: 70, MaxBytes: 500_000, MinQuality: 40, QualityStep: 5}
+
+	out, err := encodingArgs{Quality: 20, MaxBytes: 1000}.output(base)
+	if err != nil {
+		t.Fatalf("output: %v", err)
+	}
+	if out.Format != imgencode.FormatJPEG || out.JPEG.Quality != 20 || out.JPEG.MinQuality != 20 || out.JPEG.MaxBytes != 1000 {
+		t.Fatalf("output = %+v, want quality 20 with a matching floor and max_bytes 1000", out)
+	}
+
+	out, err = encodingArgs{}.output(base)
+	if err != nil || out.JPEG != base {
+		t.Fatalf("empty arguments should keep the configured options, got %+v, %v", out.JPEG, err)
+	}
+
+	invalid := []encodingArgs{
+		{Format: "webp"},
+		{Quality: 101},
+		{MaxWidth: -1},
+		{Format: "png", Quality: 80},
+	}
+	for _, args := range invalid {
+		if _, err := args.output(base); err == nil {
+			t.Errorf("expected %+v to be rejected", args)
+		}
+	}
+}
+
+func TestTakeScreenshotEncodingControls(t *testing.T) {
+	session := newDisplayTestSession(t)
+	result, err := session.CallTool(context.Background(), &sdkmcp.CallToolParams{
+		Name:      ToolName,
+		Arguments: map[string]any{"display": 0, "format": "png", "max_width": 20, "grayscale": true},
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("take_screenshot: %v %+v", err, result)
+	}
+
+	var metadata struct {
+		Display  int                `json:"display"`
+		Encoding imgencode.Encoding `json:"encoding"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(*sdkmcp.TextContent).Text), &metadata); err != nil {
+		t.Fatalf("decode metadata: %v", err)
+	}
+	want := imgencode.Encoding{Format: "png", MimeType: "image/png", Width: 20, Height: 15, Scaled: true, SourceWidth: 40, SourceHeight: 30, Grayscale: true}
+	want.Bytes = metadata.Encoding.Bytes
+	if metadata.Encoding != want {
+		t.Fatalf("encoding = %+v, want %+v", metadata.Encoding, want)
+	}
+
+	content := result.Content[1].(*sdkmcp.ImageContent)
+	img, err := png.Decode(bytes.NewReader(content.Data))
+	if err != nil || content.MIMEType != "image/png" {
+		t.Fatalf("decode %s: %v", content.MIMEType, err)
+	}
+	if _, ok := img.(*image.Gray); !ok || img.Bounds().Dx() != 20 {
+		t.Fatalf("image = %T %v, want 20px wide grayscale", img, img.Bounds())
+	}
+}
+
+func TestTakeRegionScreenshotReportsEncoding(t *testing.T) {
+	server := NewServer(nil, Config{WindowService: regionImageService{}, InputService: &tools.InputService{}})
+	_, session := connectTestSession(t, server)
+	t.Cleanup(func() { _ = session.Close() })
+
+	result, err := session.CallTool(context.Background(), &sdkmcp.CallToolParams{
+		Name:      TakeRegionScreenshotToolName,
+		Arguments: map[string]any{"x": 0, "y": 0, "width": 100, "height": 50, "quality": 35, "max_height": 25},
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("take_region_screenshot: %v %+v", err, result)
+	}
+	var metadata regionScreenshotResult
+	if err := json.Unmarshal([]byte(result.Content[0].(*sdkmcp.TextContent).Text), &metadata); err != nil {
+		t.Fatalf("decode metadata: %v", err)
+	}
+	if metadata.RegionMetadata == nil || metadata.ImageWidth != 100 {
+		t.Fatalf("region metadata missing: %s", result.Content[0].(*sdkmcp.TextContent).Text)
+	}
+	if metadata.Encoding.Quality != 35 || metadata.Encoding.Width != 50 || metadata.Encoding.Height != 25 {
+		t.Fatalf("encoding = %+v, want quality 35 at 50x25", metadata.Encoding)
+	}
+}
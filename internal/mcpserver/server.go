@@ -6,13 +6,21 @@ import (
 	"errors"
 	"fmt"
 	"image"
+	"io"
+	"net"
 	"net/http"
+	"sync"
 	"time"
 
 	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/clipboard"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/imgencode"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/redact"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/screenshot"
 	"github.com/brainwhocodes/screenshot_mcp_server/internal/tools"
 	"github.com/brainwhocodes/screenshot_mcp_server/internal/version"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/window"
 )
 
 // ScreenshotService defines MCP-facing screenshot capture dependencies.
@@ -20,6 +28,8 @@ type ScreenshotService interface {
 	CaptureImage(context.Context) (image.Image, error)
 	TakeScreenshot(context.Context) ([]byte, error)
 	TakeScreenshotPNG(context.Context) ([]byte, error)
+	ListDisplays(context.Context) ([]screenshot.Display, error)
+	CaptureDisplayImage(context.Context, int) (image.Image, error)
 }
 
 // Tool names and descriptions are exported for tests and integrations.
@@ -27,12 +37,24 @@ const (
 	// ToolName is the public MCP tool name for screenshot capture.
 	ToolName = "take_screenshot"
 	// ToolDescription explains tool behavior to MCP clients.
-	ToolDescription = "Take a screenshot of the user's screen and return it as an image"
+	ToolDescription = "Take a screenshot of the user's screen, or of one display, and return it as an image"
 
 	// TakeScreenshotPNGToolName captures full screen as PNG
 	TakeScreenshotPNGToolName = "take_screenshot_png"
 	// TakeScreenshotPNGToolDescription describes the full-screen PNG capture tool.
-	TakeScreenshotPNGToolDescription = "Take a lossless PNG screenshot of the user's screen"
+	TakeScreenshotPNGToolDescription = "Take a lossless PNG screenshot of the user's screen, or of one display"
+
+	// ListDisplaysToolName lists the active displays
+	ListDisplaysToolName        = "list_displays"
+	ListDisplaysToolDescription = "List the active displays with their index, bounds in points and pixels, scale and primary flag"
+
+	// AnnotateScreenshotToolName draws set-of-marks overlays onto a screenshot
+	AnnotateScreenshotToolName        = "annotate_screenshot"
+	AnnotateScreenshotToolDescription = "Take a screenshot with a labelled coordinate grid, or numbered boxes around template matches, OCR words or given regions, and return the label-to-coordinate map"
+
+	// TakeScreenshotDeltaToolName returns only what changed since the last capture
+	TakeScreenshotDeltaToolName        = "take_screenshot_delta"
+	TakeScreenshotDeltaToolDescription = "Capture the screen, a display or a window and return only the tiles that changed since this session's previous delta capture of the same target, with their offsets and the changed share of the image"
 
 	// ListWindowsToolName lists all visible windows
 	ListWindowsToolName        = "list_windows"
@@ -94,6 +116,10 @@ const (
 	ScrollToolName        = "scroll"
 	ScrollToolDescription = "Perform a scroll operation at specific coordinates within a window"
 
+	// TakeScrollingScreenshotToolName scrolls a window and stitches its full content
+	TakeScrollingScreenshotToolName        = "take_scrolling_screenshot"
+	TakeScrollingScreenshotToolDescription = "Scroll a window from its current position to the end of its content, capturing as it goes, and return one stitched image of everything it scrolled past"
+
 	// KeyDownToolName sends key down event
 	KeyDownToolName        = "key_down"
 	KeyDownToolDescription = "Send a key down event (for hold actions)"
@@ -132,11 +158,19 @@ const (
 
 	// SetClipboardToolName sets clipboard content
 	SetClipboardToolName        = "set_clipboard"
-	SetClipboardToolDescription = "Set the clipboard to a text value"
+	SetClipboardToolDescription = "Set the clipboard to text, HTML (mime_type text/html) or a base64 PNG image (mime_type image/png)"
 
 	// GetClipboardToolName gets clipboard content
 	GetClipboardToolName        = "get_clipboard"
-	GetClipboardToolDescription = "Get the current clipboard text content"
+	GetClipboardToolDescription = "Get the clipboard content as text, HTML or a PNG image; defaults to text/plain"
+
+	// ClearClipboardToolName empties the clipboard
+	ClearClipboardToolName        = "clear_clipboard"
+	ClearClipboardToolDescription = "Clear the clipboard"
+
+	// WaitForClipboardChangeToolName waits for new clipboard content
+	WaitForClipboardChangeToolName        = "wait_for_clipboard_change"
+	WaitForClipboardChangeToolDescription = "Wait until the clipboard content changes and report the types now offered"
 
 	// WaitForImageMatchToolName waits for a template image to appear
 	WaitForImageMatchToolName        = "wait_for_image_match"
@@ -174,10 +208,45 @@ const (
 	TakeScreenshotWithCursorToolName        = "take_screenshot_with_cursor"
 	TakeScreenshotWithCursorToolDescription = "Take a screenshot including the mouse cursor"
 
+	// StartVirtualDisplayToolName starts a headless Xvfb display for the session
+	StartVirtualDisplayToolName        = "start_virtual_display"
+	StartVirtualDisplayToolDescription = "Start a headless virtual X display (Xvfb) for this session; capture, input and launched apps use it until it is stopped"
+
+	// StopVirtualDisplayToolName stops the session's virtual display
+	StopVirtualDisplayToolName        = "stop_virtual_display"
+	StopVirtualDisplayToolDescription = "Stop the virtual display started by this session"
+
 	// DefaultSSEPort keeps parity with the Python implementation.
 	DefaultSSEPort = 3001
+
+	// StreamableHTTPPath is where NewHTTPHandler mounts the Streamable HTTP transport.
+	StreamableHTTPPath = "/mcp"
+	// SSEPath is where NewHTTPHandler mounts the legacy HTTP+SSE transport.
+	SSEPath = "/sse"
+
+	// streamableSessionTimeout closes Streamable HTTP sessions that stop sending requests.
+	streamableSessionTimeout = 30 * time.Minute
 )
 
+// toolRequirements lists tools that need more or less than a readable display.
+// Tools that inject input need synthetic input support (XTEST on X11);
+// tools that only read files need no display at all.
+var toolRequirements = map[string]window.Requirement{
+	ClickToolName:                   window.RequireInput,
+	ClickScreenToolName:             window.RequireInput,
+	MouseMoveToolName:               window.RequireInput,
+	MouseDownToolName:               window.RequireInput,
+	MouseUpToolName:                 window.RequireInput,
+	DragToolName:                    window.RequireInput,
+	ScrollToolName:                  window.RequireInput,
+	TakeScrollingScreenshotToolName: window.RequireInput,
+	PressKeyToolName:                window.RequireInput,
+	TypeTextToolName:                window.RequireInput,
+	KeyDownToolName:                 window.RequireInput,
+	KeyUpToolName:                   window.RequireInput,
+	CompareImagesToolName:           window.RequireNothing,
+}
+
 // Config controls MCP server metadata.
 type Config struct {
 	Name              string
@@ -185,6 +254,40 @@ type Config struct {
 	ExperimentalTools bool
 	InputService      *tools.InputService
 	WindowService     WindowService
+	// HTTP controls bind address, authentication and origin checks for network transports.
+	HTTP HTTPConfig
+	// ToolProfile selects the base tool set (ToolProfileFull or ToolProfileObserve). Defaults to full.
+	ToolProfile string
+	// AllowedTools adds tools on top of the profile; DeniedTools removes tools and wins over both.
+	// An invalid policy exposes no tools, so callers should check ValidateToolPolicy first.
+	AllowedTools []string
+	DeniedTools  []string
+	// Encoding controls JPEG output of screenshot tools. Zero fields use imgencode.DefaultOptions.
+	Encoding imgencode.Options
+	// Defaults overrides thresholds and wait timings applied when tool arguments are omitted.
+	Defaults ToolDefaults
+	// AuditLog receives one JSONL AuditRecord per tool call when non-nil.
+	AuditLog io.Writer
+	// DryRun logs input, process and clipboard-write actions to DryRunLog instead of
+	// executing them. Screenshots, listing and waits still run against the real desktop.
+	DryRun    bool
+	DryRunLog io.Writer
+	// CaptureConcurrency limits concurrent template-matching and OCR tools.
+	// Zero uses DefaultCaptureConcurrency. Input tools always run one at a time.
+	CaptureConcurrency int
+	// CaptureCacheMaxAge is how long a captured frame is shared between tool
+	// calls. Zero uses screenshot.DefaultCacheMaxAge; a negative value disables
+	// the cache for this server's tool calls.
+	CaptureCacheMaxAge time.Duration
+	// VirtualDisplayService backs start_virtual_display and stop_virtual_display.
+	// The tools are registered only when it reports Xvfb as available.
+	VirtualDisplayService VirtualDisplayService
+	// Clipboard backs the clipboard tools. Defaults to clipboard.New().
+	Clipboard clipboard.Clipboard
+	// Redaction blacks out matching screen regions in every image a tool returns
+	// or records. Invalid rules make every screenshot fail, so callers should
+	// check Redaction.Validate first.
+	Redaction redact.Rules
 }
 
 // NewServer creates and configures the MCP server with all tools.
@@ -200,13 +303,35 @@ func NewServer(service ScreenshotService, cfg Config) *sdkmcp.Server {
 	if inputService == nil {
 		inputService = tools.NewInputService()
 	}
+	clipboardService := cfg.Clipboard
+	if clipboardService == nil {
+		clipboardService = clipboard.New()
+	}
 	if cfg.Name == "" {
 		cfg.Name = "Screenshot MCP Server"
 	}
 	if cfg.Version == "" {
 		cfg.Version = version.Version
 	}
-	recordingState := newRecordingState()
+	settings := toolSettings{encoding: cfg.Encoding, defaults: cfg.Defaults.resolved()}
+	if cfg.DryRun {
+		settings.dryRun = newDryRunLog(cfg.DryRunLog)
+		windowService = dryRunWindowService{WindowService: windowService, log: settings.dryRun}
+		inputService = dryRunInputService(settings.dryRun)
+		clipboardService = dryRunClipboard{Clipboard: clipboardService, log: settings.dryRun}
+	}
+	displayService := cfg.VirtualDisplayService
+	if displayService == nil {
+		displayService = defaultVirtualDisplayService{}
+	}
+	sessions := newSessionStore(inputService, windowService, displayService)
+	settings.encoding = imgencode.WithDefaults(settings.encoding)
+	if screenshotService, ok := service.(*tools.ScreenshotService); ok && cfg.Encoding != (imgencode.Options{}) {
+		configured := *screenshotService
+		configured.Options = settings.encoding
+		service = &configured
+	}
+	settings.redactor = newRedactor(cfg.Redaction, service, windowService)
 
 	server := sdkmcp.NewServer(
 		&sdkmcp.Implementation{
@@ -216,26 +341,78 @@ func NewServer(service ScreenshotService, cfg Config) *sdkmcp.Server {
 		nil,
 	)
 
-	registerScreenshotTools(server, service, windowService)
+	registerScreenshotTools(server, service, windowService, sessions, settings)
+	// Virtual displays are what make window tools usable on a headless host,
+	// so they do not depend on SupportsWindowTools.
+	if displayService.Available() {
+		registerVirtualDisplayTools(server, displayService, sessions)
+	}
 	if windowService.SupportsWindowTools() {
 		registerWindowDiscoveryTools(server, windowService)
-		registerWindowTools(server, windowService)
-		registerInputTools(server, inputService, windowService)
-		registerSystemTools(server, windowService)
-		registerImageUtilities(server, windowService)
+		registerWindowTools(server, windowService, sessions, settings)
+		registerInputTools(server, inputService, windowService, sessions)
+		registerSystemTools(server, windowService, clipboardService, settings)
+		registerImageUtilities(server, windowService, settings)
 		if cfg.ExperimentalTools {
-			registerExperimentalTools(server, service, windowService, recordingState)
+			registerExperimentalTools(server, service, windowService, sessions, settings)
 		}
 	}
 
+	cacheMaxAge := cfg.CaptureCacheMaxAge
+	if cacheMaxAge == 0 {
+		cacheMaxAge = screenshot.DefaultCacheMaxAge
+	}
+
+	server.AddReceivingMiddleware(sessions.displayMiddleware())
+	server.AddReceivingMiddleware(cacheMaxAgeMiddleware(cacheMaxAge))
+	server.AddReceivingMiddleware(newInputArbiter(cfg.CaptureConcurrency).middleware())
+	if cfg.DryRun {
+		server.AddReceivingMiddleware(dryRunMiddleware())
+	}
+	// Added last so it wraps the arbiter and sees queue wait in results.
+	if cfg.AuditLog != nil {
+		server.AddReceivingMiddleware(auditMiddleware(newAuditLogger(cfg.AuditLog), sessions))
+	}
+
+	// An invalid policy resolves to no enabled tools so misconfiguration fails closed.
+	enabled, _ := cfg.enabledTools()
+	applyToolPolicy(server, enabled)
+
+	serverSessions.Store(server, sessions)
 	return server
 }
 
+// cacheMaxAgeMiddleware gives every tool call the server's frame cache max age.
+func cacheMaxAgeMiddleware(maxAge time.Duration) sdkmcp.Middleware {
+	return func(next sdkmcp.MethodHandler) sdkmcp.MethodHandler {
+		return func(ctx context.Context, method string, req sdkmcp.Request) (sdkmcp.Result, error) {
+			if method == "tools/call" {
+				ctx = screenshot.WithCacheMaxAge(ctx, maxAge)
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// serverSessions maps each server built by NewServer to its session store.
+var serverSessions sync.Map
+
+// CloseSessions tears down the per-session state of a server built by NewServer:
+// recordings, held input and virtual displays. RunStdio and the ListenAndServe
+// functions call it when they return; callers serving NewHTTPHandler themselves
+// should call it on shutdown.
+func CloseSessions(server *sdkmcp.Server) {
+	if store, ok := serverSessions.LoadAndDelete(server); ok {
+		store.(*sessionStore).closeAll()
+	}
+}
+
 // RunStdio starts serving MCP over stdio.
 func RunStdio(ctx context.Context, server *sdkmcp.Server) error {
 	if server == nil {
 		return fmt.Errorf("server is nil")
 	}
+	defer CloseSessions(server)
 	if err := server.Run(ctx, &sdkmcp.StdioTransport{}); err != nil {
 		return fmt.Errorf("run stdio server: %w", err)
 	}
@@ -249,28 +426,79 @@ func NewSSEHTTPHandler(server *sdkmcp.Server) http.Handler {
 	}, nil)
 }
 
+// NewStreamableHTTPHandler returns an HTTP handler for the MCP Streamable HTTP transport.
+// Stream events are retained in memory so clients can resume interrupted streams.
+func NewStreamableHTTPHandler(server *sdkmcp.Server) http.Handler {
+	return sdkmcp.NewStreamableHTTPHandler(func(_ *http.Request) *sdkmcp.Server {
+		return server
+	}, &sdkmcp.StreamableHTTPOptions{
+		EventStore:     sdkmcp.NewMemoryEventStore(nil),
+		SessionTimeout: streamableSessionTimeout,
+	})
+}
+
+// NewHTTPHandler mounts Streamable HTTP at StreamableHTTPPath and legacy SSE at SSEPath.
+func NewHTTPHandler(server *sdkmcp.Server) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(StreamableHTTPPath, NewStreamableHTTPHandler(server))
+	mux.Handle(SSEPath, NewSSEHTTPHandler(server))
+	return mux
+}
+
 // ListenAndServeSSE starts an HTTP server that serves MCP SSE transport.
-func ListenAndServeSSE(ctx context.Context, server *sdkmcp.Server, port int) error {
+func ListenAndServeSSE(ctx context.Context, server *sdkmcp.Server, port int, httpCfg HTTPConfig) error {
 	if server == nil {
 		return fmt.Errorf("server is nil")
 	}
-	if port <= 0 {
-		return fmt.Errorf("invalid port %d", port)
+	defer CloseSessions(server)
+	return listenAndServeHTTP(ctx, NewSSEHTTPHandler(server), port, httpCfg)
+}
+
+// ListenAndServeStreamableHTTP starts an HTTP server that serves MCP Streamable HTTP
+// transport at StreamableHTTPPath, with legacy SSE available at SSEPath.
+func ListenAndServeStreamableHTTP(ctx context.Context, server *sdkmcp.Server, port int, httpCfg HTTPConfig) error {
+	if server == nil {
+		return fmt.Errorf("server is nil")
+	}
+	defer CloseSessions(server)
+	return listenAndServeHTTP(ctx, NewHTTPHandler(server), port, httpCfg)
+}
+
+func listenAndServeHTTP(ctx context.Context, handler http.Handler, port int, httpCfg HTTPConfig) error {
+	if err := httpCfg.validateListener(port); err != nil {
+		return err
 	}
+	securedHandler, err := WithHTTPSecurity(handler, httpCfg)
+	if err != nil {
+		return fmt.Errorf("configure http security: %w", err)
+	}
+
+	listener, err := httpCfg.listen(ctx, port)
+	if err != nil {
+		return err
+	}
+	return serveHTTP(ctx, listener, securedHandler, httpCfg)
+}
 
+// serveHTTP serves handler on listener until ctx is canceled, then shuts down gracefully.
+func serveHTTP(ctx context.Context, listener net.Listener, handler http.Handler, httpCfg HTTPConfig) error {
+	timeouts := httpCfg.Timeouts.resolved()
 	httpServer := &http.Server{
-		Addr:              fmt.Sprintf(":%d", port),
-		Handler:           NewSSEHTTPHandler(server),
-		ReadHeaderTimeout: 5 * time.Second,
-		ReadTimeout:       10 * time.Second,
-		WriteTimeout:      10 * time.Second,
-		IdleTimeout:       30 * time.Second,
+		Handler:           handler,
+		ReadHeaderTimeout: timeouts.ReadHeader,
+		ReadTimeout:       timeouts.Read,
+		WriteTimeout:      timeouts.Write,
+		IdleTimeout:       timeouts.Idle,
 		MaxHeaderBytes:    1 << 20,
 	}
 
 	errCh := make(chan error, 1)
 	go func() {
-		errCh <- httpServer.ListenAndServe()
+		if httpCfg.tlsEnabled() {
+			errCh <- httpServer.ServeTLS(listener, httpCfg.TLSCertFile, httpCfg.TLSKeyFile)
+			return
+		}
+		errCh <- httpServer.Serve(listener)
 	}()
 
 	select {
@@ -17,7 +17,7 @@ func TestServerIntegration_InMemory(t *testing.T) {
 	fixturePath := testutil.WriteFixtureJPEG(t)
 	t.Setenv(tools.FixtureImagePathEnv, fixturePath)
 
-	server := NewServer(tools.NewScreenshotService(), Config{})
+	server := NewServer(tools.NewScreenshotService(), Config{WindowService: windowToolsService{}})
 
 	clientTransport, serverTransport := sdkmcp.NewInMemoryTransports()
 	ctx, cancel := context.WithCancel(context.Background())
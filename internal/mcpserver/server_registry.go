@@ -7,7 +7,9 @@ import (
 
 	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/clipboard"
 	"github.com/brainwhocodes/screenshot_mcp_server/internal/imgencode"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/screenshot"
 	"github.com/brainwhocodes/screenshot_mcp_server/internal/tools"
 )
 
@@ -15,93 +17,178 @@ type mouseButtonAction func(context.Context, uint32, float64, float64, string) e
 
 type keyActionHandler func(context.Context, *tools.InputService, string, []string) error
 
-func registerScreenshotTools(server *sdkmcp.Server, service ScreenshotService, windowService WindowService) {
-	registerTakeScreenshotTool(server, service, windowService)
-	registerTakeScreenshotPNGTool(server, service, windowService)
+// keyHoldTracker and buttonHoldTracker record held input on the calling session
+// so it can be released if the client disconnects mid-gesture.
+type keyHoldTracker func(*sessionState, string, []string)
+
+type buttonHoldTracker func(*sessionState, heldButton)
+
+// toolSettings carries configurable encoding and argument defaults into tool handlers.
+type toolSettings struct {
+	encoding imgencode.Options
+	defaults ToolDefaults
+	// dryRun is non-nil when side-effecting tools must be logged instead of executed.
+	dryRun *dryRunLog
+	// redactor is non-nil when redaction rules are configured.
+	redactor *redactor
+}
+
+func registerScreenshotTools(server *sdkmcp.Server, service ScreenshotService, windowService WindowService, sessions *sessionStore, settings toolSettings) {
+	registerTakeScreenshotTool(server, service, windowService, settings)
+	registerTakeScreenshotPNGTool(server, service, windowService, settings)
 	registerScreenshotHashTool(server, service, windowService)
+	registerListDisplaysTool(server, service)
+	registerAnnotateScreenshotTool(server, service, windowService, settings)
+	registerTakeScreenshotDeltaTool(server, service, windowService, sessions, settings)
 }
 
 func registerWindowDiscoveryTools(server *sdkmcp.Server, windowService WindowService) {
 	registerListWindowsTool(server, windowService)
 }
 
-func registerWindowTools(server *sdkmcp.Server, windowService WindowService) {
+func registerWindowTools(server *sdkmcp.Server, windowService WindowService, sessions *sessionStore, settings toolSettings) {
 	registerFocusWindowTool(server, windowService)
-	registerTakeWindowScreenshotTool(server, windowService)
-	registerTakeWindowScreenshotPNGTool(server, windowService)
-	registerTakeRegionScreenshotTool(server, windowService)
-	registerTakeRegionScreenshotPNGTool(server, windowService)
+	registerTakeWindowScreenshotTool(server, windowService, settings)
+	registerTakeWindowScreenshotPNGTool(server, windowService, settings)
+	registerTakeRegionScreenshotTool(server, windowService, settings)
+	registerTakeRegionScreenshotPNGTool(server, windowService, settings)
 	registerClickTool(server, windowService)
 	registerClickScreenTool(server, windowService)
 	registerMouseMoveTool(server, windowService)
-	registerMouseButtonTool(server, MouseDownToolName, MouseDownToolDescription, "down", windowService.MouseDown, windowService)
-	registerMouseButtonTool(server, MouseUpToolName, MouseUpToolDescription, "up", windowService.MouseUp, windowService)
+	registerMouseButtonTool(server, MouseDownToolName, MouseDownToolDescription, "down", windowService.MouseDown, windowService, sessions, (*sessionState).holdButton)
+	registerMouseButtonTool(server, MouseUpToolName, MouseUpToolDescription, "up", windowService.MouseUp, windowService, sessions, (*sessionState).releaseButton)
 	registerDragTool(server, windowService)
 	registerScrollTool(server, windowService)
+	registerTakeScrollingScreenshotTool(server, windowService, settings)
 }
 
-func registerInputTools(server *sdkmcp.Server, inputService *tools.InputService, windowService WindowService) {
+func registerInputTools(server *sdkmcp.Server, inputService *tools.InputService, windowService WindowService, sessions *sessionStore) {
 	registerPressKeyTool(server, inputService, windowService)
 	registerTypeTextTool(server, inputService, windowService)
-	registerKeyActionTool(server, KeyDownToolName, KeyDownToolDescription, inputService, performKeyDown, windowService)
-	registerKeyActionTool(server, KeyUpToolName, KeyUpToolDescription, inputService, performKeyUp, windowService)
+	registerKeyActionTool(server, KeyDownToolName, KeyDownToolDescription, inputService, performKeyDown, windowService, sessions, (*sessionState).holdKey)
+	registerKeyActionTool(server, KeyUpToolName, KeyUpToolDescription, inputService, performKeyUp, windowService, sessions, (*sessionState).releaseKey)
 }
 
-func registerSystemTools(server *sdkmcp.Server, windowService WindowService) {
-	registerWaitForPixelTool(server, windowService)
-	registerWaitForRegionStableTool(server, windowService)
+func registerSystemTools(server *sdkmcp.Server, windowService WindowService, clipboardService clipboard.Clipboard, settings toolSettings) {
+	registerWaitForPixelTool(server, windowService, settings)
+	registerWaitForRegionStableTool(server, windowService, settings)
 	registerLaunchAppTool(server, windowService)
 	registerQuitAppTool(server, windowService)
-	registerWaitForProcessTool(server, windowService)
+	registerWaitForProcessTool(server, windowService, settings)
 	registerKillProcessTool(server, windowService)
-	registerClipboardTools(server)
+	registerClipboardTools(server, clipboardService, settings)
 }
 
-func registerImageUtilities(server *sdkmcp.Server, windowService WindowService) {
-	registerWaitForImageMatchTool(server, windowService)
-	registerFindImageMatchesTool(server, windowService)
-	registerCompareImagesTool(server, windowService)
-	registerAssertScreenshotMatchesFixtureTool(server, windowService)
+func registerImageUtilities(server *sdkmcp.Server, windowService WindowService, settings toolSettings) {
+	registerWaitForImageMatchTool(server, windowService, settings)
+	registerFindImageMatchesTool(server, windowService, settings)
+	registerCompareImagesTool(server, windowService, settings)
+	registerAssertScreenshotMatchesFixtureTool(server, windowService, settings)
 }
 
-func registerExperimentalTools(server *sdkmcp.Server, service ScreenshotService, windowService WindowService, recordingState *recordingState) {
-	registerWaitForTextTool(server, service, windowService)
-	registerRestartAppTool(server, windowService)
-	registerStartRecordingTool(server, windowService, recordingState)
-	registerStopRecordingTool(server, windowService, recordingState)
-	registerTakeScreenshotWithCursorTool(server, service, windowService)
+func registerExperimentalTools(server *sdkmcp.Server, service ScreenshotService, windowService WindowService, sessions *sessionStore, settings toolSettings) {
+	registerWaitForTextTool(server, service, windowService, settings)
+	registerRestartAppTool(server, windowService, settings)
+	registerStartRecordingTool(server, windowService, sessions, settings)
+	registerStopRecordingTool(server, windowService, sessions)
+	registerTakeScreenshotWithCursorTool(server, service, windowService, settings)
 }
 
-func registerTakeScreenshotTool(server *sdkmcp.Server, service ScreenshotService, windowService WindowService) {
+func registerTakeScreenshotTool(server *sdkmcp.Server, service ScreenshotService, windowService WindowService, settings toolSettings) {
 	sdkmcp.AddTool(server, &sdkmcp.Tool{
 		Name:        ToolName,
 		Description: ToolDescription,
-	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, _ screenshotArgs) (*sdkmcp.CallToolResult, any, error) {
-		if err := ensureWindowPermissions(windowService, ToolName); err != nil {
+	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, args screenshotArgs) (*sdkmcp.CallToolResult, any, error) {
+		if err := ensureCapturePermissions(ctx, windowService, ToolName, 0); err != nil {
+			return nil, nil, err
+		}
+		ctx = args.captureContext(ctx)
+		out, err := args.output(settings.encoding)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, _, err := args.options(); err != nil {
 			return nil, nil, err
 		}
-		data, err := service.TakeScreenshot(ctx)
+
+		var img image.Image
+		if args.Display == nil {
+			img, err = service.CaptureImage(ctx)
+		} else {
+			img, err = captureDisplay(ctx, service, *args.Display)
+		}
 		if err != nil {
 			return nil, nil, fmt.Errorf("take screenshot: %w", err)
 		}
-		return tools.ToolResultFromJPEG(data), nil, nil
+		img, redactions, err := settings.redactor.screenshot(ctx, img, args.Display)
+		if err != nil {
+			return nil, nil, err
+		}
+		img, annotations, err := args.apply(img)
+		if err != nil {
+			return nil, nil, err
+		}
+		result, err := encodedScreenshotResult(img, out, func(encoding *imgencode.Encoding) any {
+			metadata := map[string]any{"encoding": encoding}
+			if args.Display != nil {
+				metadata["display"] = *args.Display
+			}
+			if annotations != nil {
+				metadata["annotations"] = annotations
+			}
+			if redactions != nil {
+				metadata["redactions"] = *redactions
+			}
+			return metadata
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return result, nil, nil
 	})
 }
 
-func registerTakeScreenshotPNGTool(server *sdkmcp.Server, service ScreenshotService, windowService WindowService) {
+func registerTakeScreenshotPNGTool(server *sdkmcp.Server, service ScreenshotService, windowService WindowService, settings toolSettings) {
 	sdkmcp.AddTool(server, &sdkmcp.Tool{
 		Name:        TakeScreenshotPNGToolName,
 		Description: TakeScreenshotPNGToolDescription,
-	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, _ takeScreenshotPNGArgs) (*sdkmcp.CallToolResult, any, error) {
-		if err := ensureWindowPermissions(windowService, TakeScreenshotPNGToolName); err != nil {
+	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, args takeScreenshotPNGArgs) (*sdkmcp.CallToolResult, any, error) {
+		if err := ensureCapturePermissions(ctx, windowService, TakeScreenshotPNGToolName, 0); err != nil {
 			return nil, nil, err
 		}
-		data, err := service.TakeScreenshotPNG(ctx)
-		if err != nil {
-			return nil, nil, fmt.Errorf("take screenshot png: %w", err)
+		ctx = args.captureContext(ctx)
+		var data []byte
+		var redactions *int
+		if args.Display == nil && settings.redactor == nil {
+			screen, err := service.TakeScreenshotPNG(ctx)
+			if err != nil {
+				return nil, nil, fmt.Errorf("take screenshot png: %w", err)
+			}
+			data = screen
+		} else {
+			var img image.Image
+			var err error
+			if args.Display == nil {
+				img, err = service.CaptureImage(ctx)
+			} else {
+				img, err = captureDisplay(ctx, service, *args.Display)
+			}
+			if err != nil {
+				return nil, nil, fmt.Errorf("take screenshot png: %w", err)
+			}
+			if img, redactions, err = settings.redactor.screenshot(ctx, img, args.Display); err != nil {
+				return nil, nil, err
+			}
+			if data, err = imgencode.EncodePNG(img); err != nil {
+				return nil, nil, fmt.Errorf("encode screenshot: %w", err)
+			}
 		}
 
-		result := tools.ToolResultFromText("Screenshot captured (PNG).")
+		text := "Screenshot captured (PNG)."
+		if redactions != nil {
+			text = fmt.Sprintf("Screenshot captured (PNG, %d regions redacted).", *redactions)
+		}
+		result := tools.ToolResultFromText(text)
 		result.Content = append(result.Content, &sdkmcp.ImageContent{
 			Data:     data,
 			MIMEType: "image/png",
@@ -135,9 +222,10 @@ func registerScreenshotHashTool(server *sdkmcp.Server, service ScreenshotService
 		Name:        ScreenshotHashToolName,
 		Description: ScreenshotHashToolDescription,
 	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, args screenshotHashArgs) (*sdkmcp.CallToolResult, any, error) {
-		if err := ensureWindowPermissions(windowService, ScreenshotHashToolName); err != nil {
+		if err := ensureCapturePermissions(ctx, windowService, ScreenshotHashToolName, 0); err != nil {
 			return nil, nil, err
 		}
+		ctx = args.captureContext(ctx)
 		if args.Algorithm == "" {
 			args.Algorithm = "perceptual"
 		}
@@ -152,6 +240,9 @@ func registerScreenshotHashTool(server *sdkmcp.Server, service ScreenshotService
 		if err != nil {
 			return nil, nil, err
 		}
+		if target == "window" && args.Display != nil {
+			return nil, nil, fmt.Errorf("display is only used with target \"screen\"")
+		}
 
 		var img image.Image
 		switch target {
@@ -165,6 +256,14 @@ func registerScreenshotHashTool(server *sdkmcp.Server, service ScreenshotService
 			}
 			img = windowImg
 		default:
+			if args.Display != nil {
+				displayImg, err := captureDisplay(ctx, service, *args.Display)
+				if err != nil {
+					return nil, nil, fmt.Errorf("capture screenshot: %w", err)
+				}
+				img = displayImg
+				break
+			}
 			fullImage, err := service.CaptureImage(ctx)
 			if err != nil {
 				return nil, nil, fmt.Errorf("capture screenshot: %w", err)
@@ -177,12 +276,16 @@ func registerScreenshotHashTool(server *sdkmcp.Server, service ScreenshotService
 			return nil, nil, fmt.Errorf("compute hash: %w", err)
 		}
 
-		result, err := tools.ToolResultFromJSON(map[string]interface{}{
+		response := map[string]interface{}{
 			"hash":      hash,
 			"algorithm": args.Algorithm,
 			"target":    target,
 			"window_id": args.WindowID,
-		})
+		}
+		if args.Display != nil {
+			response["display"] = *args.Display
+		}
+		result, err := tools.ToolResultFromJSON(response)
 		if err != nil {
 			return nil, nil, fmt.Errorf("marshal hash: %w", err)
 		}
@@ -204,7 +307,7 @@ func registerFocusWindowTool(server *sdkmcp.Server, windowService WindowService)
 		Name:        FocusWindowToolName,
 		Description: FocusWindowToolDescription,
 	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, args focusWindowArgs) (*sdkmcp.CallToolResult, any, error) {
-		if err := ensureWindowPermissions(windowService, FocusWindowToolName); err != nil {
+		if err := ensureWindowPermissions(ctx, windowService, FocusWindowToolName); err != nil {
 			return nil, nil, err
 		}
 		if err := validateWindowID(args.WindowID); err != nil {
@@ -217,45 +320,84 @@ func registerFocusWindowTool(server *sdkmcp.Server, windowService WindowService)
 	})
 }
 
-func registerTakeWindowScreenshotTool(server *sdkmcp.Server, windowService WindowService) {
+func registerTakeWindowScreenshotTool(server *sdkmcp.Server, windowService WindowService, settings toolSettings) {
 	sdkmcp.AddTool(server, &sdkmcp.Tool{
 		Name:        TakeWindowScreenshotToolName,
 		Description: TakeWindowScreenshotToolDescription,
 	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, args takeWindowScreenshotArgs) (*sdkmcp.CallToolResult, any, error) {
-		if err := ensureWindowPermissions(windowService, TakeWindowScreenshotToolName); err != nil {
+		if err := ensureWindowPermissions(ctx, windowService, TakeWindowScreenshotToolName); err != nil {
 			return nil, nil, err
 		}
+		ctx = args.captureContext(ctx)
 		if err := validateWindowID(args.WindowID); err != nil {
 			return nil, nil, err
 		}
-		data, metadata, err := windowService.TakeWindowScreenshot(ctx, args.WindowID, imgencode.DefaultOptions)
+		out, err := args.output(settings.encoding)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, _, err := args.options(); err != nil {
+			return nil, nil, err
+		}
+		img, metadata, err := windowService.TakeWindowScreenshotImage(ctx, args.WindowID)
 		if err != nil {
 			return nil, nil, fmt.Errorf("take window screenshot: %w", err)
 		}
-		result, err := tools.ToolResultFromJSONWithImage(metadata, data, "image/jpeg")
+		img, redactions, err := settings.redactor.window(ctx, img, metadata)
 		if err != nil {
-			return nil, nil, fmt.Errorf("marshal window metadata: %w", err)
+			return nil, nil, err
+		}
+		img, annotations, err := args.apply(img)
+		if err != nil {
+			return nil, nil, err
+		}
+		result, err := encodedScreenshotResult(img, out, func(encoding *imgencode.Encoding) any {
+			return windowScreenshotResult{ScreenshotMetadata: metadata, Encoding: encoding, Annotations: annotations, Redactions: redactions}
+		})
+		if err != nil {
+			return nil, nil, err
 		}
 		return result, nil, nil
 	})
 }
 
-func registerTakeWindowScreenshotPNGTool(server *sdkmcp.Server, windowService WindowService) {
+func registerTakeWindowScreenshotPNGTool(server *sdkmcp.Server, windowService WindowService, settings toolSettings) {
 	sdkmcp.AddTool(server, &sdkmcp.Tool{
 		Name:        TakeWindowScreenshotPNGToolName,
 		Description: TakeWindowScreenshotPNGToolDescription,
 	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, args takeWindowScreenshotPNGArgs) (*sdkmcp.CallToolResult, any, error) {
-		if err := ensureWindowPermissions(windowService, TakeWindowScreenshotPNGToolName); err != nil {
+		if err := ensureWindowPermissions(ctx, windowService, TakeWindowScreenshotPNGToolName); err != nil {
 			return nil, nil, err
 		}
+		ctx = args.captureContext(ctx)
 		if err := validateWindowID(args.WindowID); err != nil {
 			return nil, nil, err
 		}
-		data, metadata, err := windowService.TakeWindowScreenshotPNG(ctx, args.WindowID)
+		if settings.redactor == nil {
+			data, metadata, err := windowService.TakeWindowScreenshotPNG(ctx, args.WindowID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("take window screenshot: %w", err)
+			}
+			result, err := tools.ToolResultFromJSONWithImage(metadata, data, "image/png")
+			if err != nil {
+				return nil, nil, fmt.Errorf("marshal window metadata: %w", err)
+			}
+			return result, nil, nil
+		}
+
+		img, metadata, err := windowService.TakeWindowScreenshotImage(ctx, args.WindowID)
 		if err != nil {
 			return nil, nil, fmt.Errorf("take window screenshot: %w", err)
 		}
-		result, err := tools.ToolResultFromJSONWithImage(metadata, data, "image/png")
+		img, redactions, err := settings.redactor.window(ctx, img, metadata)
+		if err != nil {
+			return nil, nil, err
+		}
+		data, err := imgencode.EncodePNG(img)
+		if err != nil {
+			return nil, nil, fmt.Errorf("encode screenshot: %w", err)
+		}
+		result, err := tools.ToolResultFromJSONWithImage(windowScreenshotResult{ScreenshotMetadata: metadata, Redactions: redactions}, data, "image/png")
 		if err != nil {
 			return nil, nil, fmt.Errorf("marshal window metadata: %w", err)
 		}
@@ -263,47 +405,86 @@ func registerTakeWindowScreenshotPNGTool(server *sdkmcp.Server, windowService Wi
 	})
 }
 
-func registerTakeRegionScreenshotTool(server *sdkmcp.Server, windowService WindowService) {
+func registerTakeRegionScreenshotTool(server *sdkmcp.Server, windowService WindowService, settings toolSettings) {
 	sdkmcp.AddTool(server, &sdkmcp.Tool{
 		Name:        TakeRegionScreenshotToolName,
 		Description: TakeRegionScreenshotToolDescription,
 	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, args takeRegionScreenshotArgs) (*sdkmcp.CallToolResult, any, error) {
-		if err := ensureWindowPermissions(windowService, TakeRegionScreenshotToolName); err != nil {
+		if err := ensureWindowPermissions(ctx, windowService, TakeRegionScreenshotToolName); err != nil {
 			return nil, nil, err
 		}
+		ctx = args.captureContext(ctx)
 		if err := validateRegionInput(args.Width, args.Height, args.CoordSpace); err != nil {
 			return nil, nil, err
 		}
 
-		data, metadata, err := windowService.TakeRegionScreenshot(ctx, args.X, args.Y, args.Width, args.Height, args.CoordSpace, imgencode.DefaultOptions)
+		out, err := args.output(settings.encoding)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, _, err := args.options(); err != nil {
+			return nil, nil, err
+		}
+		img, metadata, err := windowService.TakeRegionScreenshotImage(ctx, args.X, args.Y, args.Width, args.Height, args.CoordSpace)
 		if err != nil {
 			return nil, nil, fmt.Errorf("take region screenshot: %w", err)
 		}
-		result, err := tools.ToolResultFromJSONWithImage(metadata, data, "image/jpeg")
+		img, redactions, err := settings.redactor.region(ctx, img, metadata)
 		if err != nil {
-			return nil, nil, fmt.Errorf("marshal metadata: %w", err)
+			return nil, nil, err
+		}
+		img, annotations, err := args.apply(img)
+		if err != nil {
+			return nil, nil, err
+		}
+		result, err := encodedScreenshotResult(img, out, func(encoding *imgencode.Encoding) any {
+			return regionScreenshotResult{RegionMetadata: metadata, Encoding: encoding, Annotations: annotations, Redactions: redactions}
+		})
+		if err != nil {
+			return nil, nil, err
 		}
 		return result, nil, nil
 	})
 }
 
-func registerTakeRegionScreenshotPNGTool(server *sdkmcp.Server, windowService WindowService) {
+func registerTakeRegionScreenshotPNGTool(server *sdkmcp.Server, windowService WindowService, settings toolSettings) {
 	sdkmcp.AddTool(server, &sdkmcp.Tool{
 		Name:        TakeRegionScreenshotPNGToolName,
 		Description: TakeRegionScreenshotPNGToolDescription,
 	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, args takeRegionScreenshotPNGArgs) (*sdkmcp.CallToolResult, any, error) {
-		if err := ensureWindowPermissions(windowService, TakeRegionScreenshotPNGToolName); err != nil {
+		if err := ensureWindowPermissions(ctx, windowService, TakeRegionScreenshotPNGToolName); err != nil {
 			return nil, nil, err
 		}
+		ctx = args.captureContext(ctx)
 		if err := validateRegionInput(args.Width, args.Height, args.CoordSpace); err != nil {
 			return nil, nil, err
 		}
 
-		data, metadata, err := windowService.TakeRegionScreenshotPNG(ctx, args.X, args.Y, args.Width, args.Height, args.CoordSpace)
+		if settings.redactor == nil {
+			data, metadata, err := windowService.TakeRegionScreenshotPNG(ctx, args.X, args.Y, args.Width, args.Height, args.CoordSpace)
+			if err != nil {
+				return nil, nil, fmt.Errorf("take region screenshot: %w", err)
+			}
+			result, err := tools.ToolResultFromJSONWithImage(metadata, data, "image/png")
+			if err != nil {
+				return nil, nil, fmt.Errorf("marshal metadata: %w", err)
+			}
+			return result, nil, nil
+		}
+
+		img, metadata, err := windowService.TakeRegionScreenshotImage(ctx, args.X, args.Y, args.Width, args.Height, args.CoordSpace)
 		if err != nil {
 			return nil, nil, fmt.Errorf("take region screenshot: %w", err)
 		}
-		result, err := tools.ToolResultFromJSONWithImage(metadata, data, "image/png")
+		img, redactions, err := settings.redactor.region(ctx, img, metadata)
+		if err != nil {
+			return nil, nil, err
+		}
+		data, err := imgencode.EncodePNG(img)
+		if err != nil {
+			return nil, nil, fmt.Errorf("encode screenshot: %w", err)
+		}
+		result, err := tools.ToolResultFromJSONWithImage(regionScreenshotResult{RegionMetadata: metadata, Redactions: redactions}, data, "image/png")
 		if err != nil {
 			return nil, nil, fmt.Errorf("marshal metadata: %w", err)
 		}
@@ -316,7 +497,7 @@ func registerClickTool(server *sdkmcp.Server, windowService WindowService) {
 		Name:        ClickToolName,
 		Description: ClickToolDescription,
 	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, args clickArgs) (*sdkmcp.CallToolResult, any, error) {
-		if err := ensureWindowPermissions(windowService, ClickToolName); err != nil {
+		if err := ensureWindowPermissions(ctx, windowService, ClickToolName); err != nil {
 			return nil, nil, err
 		}
 		if err := validateWindowID(args.WindowID); err != nil {
@@ -340,7 +521,7 @@ func registerClickScreenTool(server *sdkmcp.Server, windowService WindowService)
 		Name:        ClickScreenToolName,
 		Description: ClickScreenToolDescription,
 	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, args clickScreenArgs) (*sdkmcp.CallToolResult, any, error) {
-		if err := ensureWindowPermissions(windowService, ClickScreenToolName); err != nil {
+		if err := ensureWindowPermissions(ctx, windowService, ClickScreenToolName); err != nil {
 			return nil, nil, err
 		}
 		if args.Button == "" {
@@ -365,7 +546,7 @@ func registerMouseMoveTool(server *sdkmcp.Server, windowService WindowService) {
 		Name:        MouseMoveToolName,
 		Description: MouseMoveToolDescription,
 	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, args mouseButtonArgs) (*sdkmcp.CallToolResult, any, error) {
-		if err := ensureWindowPermissions(windowService, MouseMoveToolName); err != nil {
+		if err := ensureWindowPermissions(ctx, windowService, MouseMoveToolName); err != nil {
 			return nil, nil, err
 		}
 		if err := validateWindowID(args.WindowID); err != nil {
@@ -386,7 +567,7 @@ func registerDragTool(server *sdkmcp.Server, windowService WindowService) {
 		Name:        DragToolName,
 		Description: DragToolDescription,
 	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, args dragArgs) (*sdkmcp.CallToolResult, any, error) {
-		if err := ensureWindowPermissions(windowService, DragToolName); err != nil {
+		if err := ensureWindowPermissions(ctx, windowService, DragToolName); err != nil {
 			return nil, nil, err
 		}
 		if err := validateWindowID(args.WindowID); err != nil {
@@ -410,7 +591,7 @@ func registerScrollTool(server *sdkmcp.Server, windowService WindowService) {
 		Name:        ScrollToolName,
 		Description: ScrollToolDescription,
 	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, args scrollArgs) (*sdkmcp.CallToolResult, any, error) {
-		if err := ensureWindowPermissions(windowService, ScrollToolName); err != nil {
+		if err := ensureWindowPermissions(ctx, windowService, ScrollToolName); err != nil {
 			return nil, nil, err
 		}
 		if err := validateWindowID(args.WindowID); err != nil {
@@ -431,7 +612,7 @@ func registerPressKeyTool(server *sdkmcp.Server, inputService *tools.InputServic
 		Name:        PressKeyToolName,
 		Description: PressKeyToolDescription,
 	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, args pressKeyArgs) (*sdkmcp.CallToolResult, any, error) {
-		if err := ensureWindowPermissions(windowService, PressKeyToolName); err != nil {
+		if err := ensureWindowPermissions(ctx, windowService, PressKeyToolName); err != nil {
 			return nil, nil, err
 		}
 		if err := validateWindowID(args.WindowID); err != nil {
@@ -463,7 +644,7 @@ func registerTypeTextTool(server *sdkmcp.Server, inputService *tools.InputServic
 		Name:        TypeTextToolName,
 		Description: TypeTextToolDescription,
 	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, args typeTextArgs) (*sdkmcp.CallToolResult, any, error) {
-		if err := ensureWindowPermissions(windowService, TypeTextToolName); err != nil {
+		if err := ensureWindowPermissions(ctx, windowService, TypeTextToolName); err != nil {
 			return nil, nil, err
 		}
 		if err := validateWindowID(args.WindowID); err != nil {
@@ -482,12 +663,12 @@ func registerTypeTextTool(server *sdkmcp.Server, inputService *tools.InputServic
 	})
 }
 
-func registerKeyActionTool(server *sdkmcp.Server, toolName, description string, inputService *tools.InputService, handler keyActionHandler, windowService WindowService) {
+func registerKeyActionTool(server *sdkmcp.Server, toolName, description string, inputService *tools.InputService, handler keyActionHandler, windowService WindowService, sessions *sessionStore, track keyHoldTracker) {
 	sdkmcp.AddTool(server, &sdkmcp.Tool{
 		Name:        toolName,
 		Description: description,
-	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, args keyActionArgs) (*sdkmcp.CallToolResult, any, error) {
-		if err := ensureWindowPermissions(windowService, toolName); err != nil {
+	}, func(ctx context.Context, req *sdkmcp.CallToolRequest, args keyActionArgs) (*sdkmcp.CallToolResult, any, error) {
+		if err := ensureWindowPermissions(ctx, windowService, toolName); err != nil {
 			return nil, nil, err
 		}
 		if err := validateWindowID(args.WindowID); err != nil {
@@ -502,6 +683,7 @@ func registerKeyActionTool(server *sdkmcp.Server, toolName, description string,
 		if err := handler(ctx, inputService, args.Key, args.Modifiers); err != nil {
 			return nil, nil, err
 		}
+		track(sessions.forRequest(req), args.Key, args.Modifiers)
 		return tools.ToolResultFromText(fmt.Sprintf("%s %q in window %d", toolName, args.Key, args.WindowID)), nil, nil
 	})
 }
@@ -520,17 +702,18 @@ func performKeyUp(ctx context.Context, inputService *tools.InputService, key str
 	return nil
 }
 
-func registerWaitForPixelTool(server *sdkmcp.Server, windowService WindowService) {
+func registerWaitForPixelTool(server *sdkmcp.Server, windowService WindowService, settings toolSettings) {
 	sdkmcp.AddTool(server, &sdkmcp.Tool{
 		Name:        WaitForPixelToolName,
 		Description: WaitForPixelToolDescription,
 	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, args waitForPixelArgs) (*sdkmcp.CallToolResult, any, error) {
-		if err := ensureWindowPermissions(windowService, WaitForPixelToolName); err != nil {
+		if err := ensureWindowPermissions(ctx, windowService, WaitForPixelToolName); err != nil {
 			return nil, nil, err
 		}
 		if err := validateWindowID(args.WindowID); err != nil {
 			return nil, nil, err
 		}
+		args.TimeoutMs, args.PollIntervalMs = settings.defaults.waitTimeoutAndPoll(args.TimeoutMs, args.PollIntervalMs)
 		if err := windowService.WaitForPixel(ctx, args.WindowID, args.X, args.Y, args.RGBA, args.Tolerance, args.TimeoutMs, args.PollIntervalMs); err != nil {
 			return nil, nil, fmt.Errorf("wait for pixel: %w", err)
 		}
@@ -538,12 +721,12 @@ func registerWaitForPixelTool(server *sdkmcp.Server, windowService WindowService
 	})
 }
 
-func registerWaitForRegionStableTool(server *sdkmcp.Server, windowService WindowService) {
+func registerWaitForRegionStableTool(server *sdkmcp.Server, windowService WindowService, settings toolSettings) {
 	sdkmcp.AddTool(server, &sdkmcp.Tool{
 		Name:        WaitForRegionStableToolName,
 		Description: WaitForRegionStableToolDescription,
 	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, args waitForRegionStableArgs) (*sdkmcp.CallToolResult, any, error) {
-		if err := ensureWindowPermissions(windowService, WaitForRegionStableToolName); err != nil {
+		if err := ensureWindowPermissions(ctx, windowService, WaitForRegionStableToolName); err != nil {
 			return nil, nil, err
 		}
 		if err := validateWindowID(args.WindowID); err != nil {
@@ -552,6 +735,7 @@ func registerWaitForRegionStableTool(server *sdkmcp.Server, windowService Window
 		if err := validateRegionInput(args.Width, args.Height, "points"); err != nil {
 			return nil, nil, err
 		}
+		args.TimeoutMs, args.PollIntervalMs = settings.defaults.waitTimeoutAndPoll(args.TimeoutMs, args.PollIntervalMs)
 		if err := windowService.WaitForRegionStable(ctx, args.WindowID, args.X, args.Y, args.Width, args.Height, args.StableCount, args.TimeoutMs, args.PollIntervalMs); err != nil {
 			return nil, nil, fmt.Errorf("wait for region stable: %w", err)
 		}
@@ -589,7 +773,7 @@ func registerQuitAppTool(server *sdkmcp.Server, windowService WindowService) {
 	})
 }
 
-func registerWaitForProcessTool(server *sdkmcp.Server, windowService WindowService) {
+func registerWaitForProcessTool(server *sdkmcp.Server, windowService WindowService, settings toolSettings) {
 	sdkmcp.AddTool(server, &sdkmcp.Tool{
 		Name:        WaitForProcessToolName,
 		Description: WaitForProcessToolDescription,
@@ -597,6 +781,7 @@ func registerWaitForProcessTool(server *sdkmcp.Server, windowService WindowServi
 		if args.ProcessName == "" {
 			return nil, nil, fmt.Errorf("process_name is required")
 		}
+		args.TimeoutMs, args.PollIntervalMs = settings.defaults.waitTimeoutAndPoll(args.TimeoutMs, args.PollIntervalMs)
 		if err := windowService.WaitForProcess(ctx, args.ProcessName, args.TimeoutMs, args.PollIntervalMs); err != nil {
 			return nil, nil, fmt.Errorf("wait for process: %w", err)
 		}
@@ -619,55 +804,22 @@ func registerKillProcessTool(server *sdkmcp.Server, windowService WindowService)
 	})
 }
 
-func registerClipboardTools(server *sdkmcp.Server) {
-	registerSetClipboardTool(server)
-	registerGetClipboardTool(server)
-}
-
-func registerSetClipboardTool(server *sdkmcp.Server) {
-	sdkmcp.AddTool(server, &sdkmcp.Tool{
-		Name:        SetClipboardToolName,
-		Description: SetClipboardToolDescription,
-	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, args setClipboardArgs) (*sdkmcp.CallToolResult, any, error) {
-		if args.Text == "" {
-			return nil, nil, fmt.Errorf("text is required")
-		}
-		if err := setClipboard(ctx, args.Text); err != nil {
-			return nil, nil, fmt.Errorf("set clipboard: %w", err)
-		}
-		return tools.ToolResultFromText("Clipboard set successfully"), nil, nil
-	})
-}
-
-func registerGetClipboardTool(server *sdkmcp.Server) {
-	sdkmcp.AddTool(server, &sdkmcp.Tool{
-		Name:        GetClipboardToolName,
-		Description: GetClipboardToolDescription,
-	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, _ emptyArgs) (*sdkmcp.CallToolResult, any, error) {
-		text, err := getClipboard(ctx)
-		if err != nil {
-			return nil, nil, fmt.Errorf("get clipboard: %w", err)
-		}
-		return tools.ToolResultFromText(text), nil, nil
-	})
-}
-
-func registerWaitForImageMatchTool(server *sdkmcp.Server, windowService WindowService) {
+func registerWaitForImageMatchTool(server *sdkmcp.Server, windowService WindowService, settings toolSettings) {
 	sdkmcp.AddTool(server, &sdkmcp.Tool{
 		Name:        WaitForImageMatchToolName,
 		Description: WaitForImageMatchToolDescription,
 	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, args waitForImageMatchArgs) (*sdkmcp.CallToolResult, any, error) {
-		if err := ensureWindowPermissions(windowService, WaitForImageMatchToolName); err != nil {
+		if err := ensureCapturePermissions(ctx, windowService, WaitForImageMatchToolName, args.WindowID); err != nil {
 			return nil, nil, err
 		}
 		if args.TemplateImage == "" {
 			return nil, nil, fmt.Errorf("template_image is required")
 		}
-		args.Threshold = defaultThreshold(args.Threshold, defaultImageMatchThreshold)
+		args.Threshold = defaultThreshold(args.Threshold, settings.defaults.ImageMatchThreshold)
 		if err := validateThreshold(args.Threshold); err != nil {
 			return nil, nil, err
 		}
-		args.TimeoutMs, args.PollIntervalMs = resolveTimeoutAndPoll(args.TimeoutMs, args.PollIntervalMs)
+		args.TimeoutMs, args.PollIntervalMs = settings.defaults.imageWaitTimeoutAndPoll(args.TimeoutMs, args.PollIntervalMs)
 		coords, err := waitForImageMatch(ctx, args.WindowID, args.TemplateImage, args.Threshold, args.TimeoutMs, args.PollIntervalMs)
 		if err != nil {
 			return nil, nil, fmt.Errorf("wait for image match: %w", err)
@@ -684,18 +836,19 @@ func registerWaitForImageMatchTool(server *sdkmcp.Server, windowService WindowSe
 	})
 }
 
-func registerFindImageMatchesTool(server *sdkmcp.Server, windowService WindowService) {
+func registerFindImageMatchesTool(server *sdkmcp.Server, windowService WindowService, settings toolSettings) {
 	sdkmcp.AddTool(server, &sdkmcp.Tool{
 		Name:        FindImageMatchesToolName,
 		Description: FindImageMatchesToolDescription,
 	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, args findImageMatchesArgs) (*sdkmcp.CallToolResult, any, error) {
-		if err := ensureWindowPermissions(windowService, FindImageMatchesToolName); err != nil {
+		if err := ensureCapturePermissions(ctx, windowService, FindImageMatchesToolName, args.WindowID); err != nil {
 			return nil, nil, err
 		}
+		ctx = args.captureContext(ctx)
 		if args.TemplateImage == "" {
 			return nil, nil, fmt.Errorf("template_image is required")
 		}
-		args.Threshold = defaultThreshold(args.Threshold, defaultImageMatchThreshold)
+		args.Threshold = defaultThreshold(args.Threshold, settings.defaults.ImageMatchThreshold)
 		if err := validateThreshold(args.Threshold); err != nil {
 			return nil, nil, err
 		}
@@ -714,18 +867,18 @@ func registerFindImageMatchesTool(server *sdkmcp.Server, windowService WindowSer
 	})
 }
 
-func registerCompareImagesTool(server *sdkmcp.Server, windowService WindowService) {
+func registerCompareImagesTool(server *sdkmcp.Server, windowService WindowService, settings toolSettings) {
 	sdkmcp.AddTool(server, &sdkmcp.Tool{
 		Name:        CompareImagesToolName,
 		Description: CompareImagesToolDescription,
-	}, func(_ context.Context, _ *sdkmcp.CallToolRequest, args compareImagesArgs) (*sdkmcp.CallToolResult, any, error) {
-		if err := ensureWindowPermissions(windowService, CompareImagesToolName); err != nil {
+	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, args compareImagesArgs) (*sdkmcp.CallToolResult, any, error) {
+		if err := ensureWindowPermissions(ctx, windowService, CompareImagesToolName); err != nil {
 			return nil, nil, err
 		}
 		if args.Image1 == "" || args.Image2 == "" {
 			return nil, nil, fmt.Errorf("both image1 and image2 are required")
 		}
-		args.Threshold = defaultThreshold(args.Threshold, defaultComparisonThreshold)
+		args.Threshold = defaultThreshold(args.Threshold, settings.defaults.ComparisonThreshold)
 		if err := validateThreshold(args.Threshold); err != nil {
 			return nil, nil, err
 		}
@@ -741,12 +894,12 @@ func registerCompareImagesTool(server *sdkmcp.Server, windowService WindowServic
 	})
 }
 
-func registerAssertScreenshotMatchesFixtureTool(server *sdkmcp.Server, windowService WindowService) {
+func registerAssertScreenshotMatchesFixtureTool(server *sdkmcp.Server, windowService WindowService, settings toolSettings) {
 	sdkmcp.AddTool(server, &sdkmcp.Tool{
 		Name:        AssertScreenshotMatchesFixtureToolName,
 		Description: AssertScreenshotMatchesFixtureToolDescription,
 	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, args assertScreenshotMatchesFixtureArgs) (*sdkmcp.CallToolResult, any, error) {
-		if err := ensureWindowPermissions(windowService, AssertScreenshotMatchesFixtureToolName); err != nil {
+		if err := ensureWindowPermissions(ctx, windowService, AssertScreenshotMatchesFixtureToolName); err != nil {
 			return nil, nil, err
 		}
 		if err := validateWindowID(args.WindowID); err != nil {
@@ -755,11 +908,11 @@ func registerAssertScreenshotMatchesFixtureTool(server *sdkmcp.Server, windowSer
 		if args.FixturePath == "" {
 			return nil, nil, fmt.Errorf("fixture_path is required")
 		}
-		args.Threshold = defaultThreshold(args.Threshold, defaultComparisonThreshold)
+		args.Threshold = defaultThreshold(args.Threshold, settings.defaults.ComparisonThreshold)
 		if err := validateThreshold(args.Threshold); err != nil {
 			return nil, nil, err
 		}
-		result, err := assertScreenshotMatchesFixture(ctx, args.WindowID, args.FixturePath, args.Threshold, args.MaskRegions)
+		result, err := assertScreenshotMatchesFixture(ctx, settings.redactor, args.WindowID, args.FixturePath, args.Threshold, args.MaskRegions)
 		if err != nil {
 			return nil, nil, fmt.Errorf("assert screenshot matches fixture: %w", err)
 		}
@@ -771,7 +924,7 @@ func registerAssertScreenshotMatchesFixtureTool(server *sdkmcp.Server, windowSer
 	})
 }
 
-func registerWaitForTextTool(server *sdkmcp.Server, service ScreenshotService, windowService WindowService) {
+func registerWaitForTextTool(server *sdkmcp.Server, service ScreenshotService, windowService WindowService, settings toolSettings) {
 	sdkmcp.AddTool(server, &sdkmcp.Tool{
 		Name:        WaitForTextToolName,
 		Description: WaitForTextToolDescription,
@@ -779,9 +932,10 @@ func registerWaitForTextTool(server *sdkmcp.Server, service ScreenshotService, w
 		if args.Text == "" {
 			return nil, nil, fmt.Errorf("text is required")
 		}
-		if err := ensureWindowPermissions(windowService, WaitForTextToolName); err != nil {
+		if err := ensureCapturePermissions(ctx, windowService, WaitForTextToolName, args.WindowID); err != nil {
 			return nil, nil, err
 		}
+		args.TimeoutMs, args.PollIntervalMs = settings.defaults.imageWaitTimeoutAndPoll(args.TimeoutMs, args.PollIntervalMs)
 		found, err := waitForText(ctx, service, args.WindowID, args.Text, args.TimeoutMs, args.PollIntervalMs)
 		if err != nil {
 			return nil, nil, fmt.Errorf("wait for text: %w", err)
@@ -794,7 +948,7 @@ func registerWaitForTextTool(server *sdkmcp.Server, service ScreenshotService, w
 	})
 }
 
-func registerRestartAppTool(server *sdkmcp.Server, windowService WindowService) {
+func registerRestartAppTool(server *sdkmcp.Server, windowService WindowService, settings toolSettings) {
 	sdkmcp.AddTool(server, &sdkmcp.Tool{
 		Name:        RestartAppToolName,
 		Description: RestartAppToolDescription,
@@ -802,22 +956,24 @@ func registerRestartAppTool(server *sdkmcp.Server, windowService WindowService)
 		if args.AppName == "" {
 			return nil, nil, fmt.Errorf("app_name is required")
 		}
-		if err := ensureWindowPermissions(windowService, RestartAppToolName); err != nil {
+		if err := ensureWindowPermissions(ctx, windowService, RestartAppToolName); err != nil {
 			return nil, nil, err
 		}
-		if err := restartApp(ctx, args.AppName); err != nil {
+		if settings.dryRun != nil {
+			settings.dryRun.record(RestartAppToolName, "app=%q", args.AppName)
+		} else if err := restartApp(ctx, args.AppName); err != nil {
 			return nil, nil, fmt.Errorf("restart app: %w", err)
 		}
 		return tools.ToolResultFromText(fmt.Sprintf("App %q restarted successfully", args.AppName)), nil, nil
 	})
 }
 
-func registerStartRecordingTool(server *sdkmcp.Server, windowService WindowService, recordingState *recordingState) {
+func registerStartRecordingTool(server *sdkmcp.Server, windowService WindowService, sessions *sessionStore, settings toolSettings) {
 	sdkmcp.AddTool(server, &sdkmcp.Tool{
 		Name:        StartRecordingToolName,
 		Description: StartRecordingToolDescription,
-	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, args startRecordingArgs) (*sdkmcp.CallToolResult, any, error) {
-		if err := ensureWindowPermissions(windowService, StartRecordingToolName); err != nil {
+	}, func(ctx context.Context, req *sdkmcp.CallToolRequest, args startRecordingArgs) (*sdkmcp.CallToolResult, any, error) {
+		if err := ensureCapturePermissions(ctx, windowService, StartRecordingToolName, args.WindowID); err != nil {
 			return nil, nil, err
 		}
 		if args.FPS == 0 {
@@ -826,7 +982,7 @@ func registerStartRecordingTool(server *sdkmcp.Server, windowService WindowServi
 		if args.Format == "" {
 			args.Format = "mp4"
 		}
-		recordingID, err := startRecording(ctx, recordingState, args.WindowID, args.FPS, args.Format)
+		recordingID, err := startRecording(ctx, sessions.forRequest(req), settings.redactor, args.WindowID, args.FPS, args.Format)
 		if err != nil {
 			return nil, nil, fmt.Errorf("start recording: %w", err)
 		}
@@ -841,28 +997,32 @@ func registerStartRecordingTool(server *sdkmcp.Server, windowService WindowServi
 	})
 }
 
-func registerStopRecordingTool(server *sdkmcp.Server, windowService WindowService, recordingState *recordingState) {
+func registerStopRecordingTool(server *sdkmcp.Server, windowService WindowService, sessions *sessionStore) {
 	sdkmcp.AddTool(server, &sdkmcp.Tool{
 		Name:        StopRecordingToolName,
 		Description: StopRecordingToolDescription,
-	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, args stopRecordingArgs) (*sdkmcp.CallToolResult, any, error) {
-		if err := ensureWindowPermissions(windowService, StopRecordingToolName); err != nil {
+	}, func(ctx context.Context, req *sdkmcp.CallToolRequest, args stopRecordingArgs) (*sdkmcp.CallToolResult, any, error) {
+		if err := ensureCapturePermissions(ctx, windowService, StopRecordingToolName, 0); err != nil {
 			return nil, nil, err
 		}
 		if args.RecordingID == "" {
 			return nil, nil, fmt.Errorf("recording_id is required")
 		}
-		videoPath, warning, err := stopRecording(ctx, recordingState, args.RecordingID)
+		recording, err := stopRecording(ctx, sessions.forRequest(req), args.RecordingID)
 		if err != nil {
 			return nil, nil, fmt.Errorf("stop recording: %w", err)
 		}
+		recordArtifact(ctx, recording.path)
 		payload := map[string]interface{}{
 			"recording_id": args.RecordingID,
-			"video_path":   videoPath,
+			"video_path":   recording.path,
 			"status":       "stopped",
 		}
-		if warning != "" {
-			payload["warning"] = warning
+		if recording.warning != "" {
+			payload["warning"] = recording.warning
+		}
+		if recording.redactions != nil {
+			payload["redactions"] = *recording.redactions
 		}
 		result, err := tools.ToolResultFromJSON(payload)
 		if err != nil {
@@ -872,21 +1032,27 @@ func registerStopRecordingTool(server *sdkmcp.Server, windowService WindowServic
 	})
 }
 
-func registerTakeScreenshotWithCursorTool(server *sdkmcp.Server, service ScreenshotService, windowService WindowService) {
+func registerTakeScreenshotWithCursorTool(server *sdkmcp.Server, service ScreenshotService, windowService WindowService, settings toolSettings) {
 	sdkmcp.AddTool(server, &sdkmcp.Tool{
 		Name:        TakeScreenshotWithCursorToolName,
 		Description: TakeScreenshotWithCursorToolDescription,
 	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, _ emptyArgs) (*sdkmcp.CallToolResult, any, error) {
-		if err := ensureWindowPermissions(windowService, TakeScreenshotWithCursorToolName); err != nil {
+		if err := ensureWindowPermissions(ctx, windowService, TakeScreenshotWithCursorToolName); err != nil {
 			return nil, nil, err
 		}
 		data, cursorCaptured, err := takeScreenshotWithCursor(ctx, service)
 		if err != nil {
 			return nil, nil, fmt.Errorf("take screenshot with cursor: %w", err)
 		}
-		result, err := tools.ToolResultFromJSONWithImage(map[string]bool{
-			"cursor_captured": cursorCaptured,
-		}, data, "image/jpeg")
+		data, redactions, err := settings.redactor.screenJPEG(ctx, data, settings.encoding)
+		if err != nil {
+			return nil, nil, fmt.Errorf("take screenshot with cursor: %w", err)
+		}
+		metadata := map[string]any{"cursor_captured": cursorCaptured}
+		if redactions != nil {
+			metadata["redactions"] = *redactions
+		}
+		result, err := tools.ToolResultFromJSONWithImage(metadata, data, "image/jpeg")
 		if err != nil {
 			return nil, nil, fmt.Errorf("build result: %w", err)
 		}
@@ -894,12 +1060,12 @@ func registerTakeScreenshotWithCursorTool(server *sdkmcp.Server, service Screens
 	})
 }
 
-func registerMouseButtonTool(server *sdkmcp.Server, toolName, description string, verb string, action mouseButtonAction, windowService WindowService) {
+func registerMouseButtonTool(server *sdkmcp.Server, toolName, description string, verb string, action mouseButtonAction, windowService WindowService, sessions *sessionStore, track buttonHoldTracker) {
 	sdkmcp.AddTool(server, &sdkmcp.Tool{
 		Name:        toolName,
 		Description: description,
-	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, args mouseButtonArgs) (*sdkmcp.CallToolResult, any, error) {
-		if err := ensureWindowPermissions(windowService, toolName); err != nil {
+	}, func(ctx context.Context, req *sdkmcp.CallToolRequest, args mouseButtonArgs) (*sdkmcp.CallToolResult, any, error) {
+		if err := ensureWindowPermissions(ctx, windowService, toolName); err != nil {
 			return nil, nil, err
 		}
 		if err := validateWindowID(args.WindowID); err != nil {
@@ -914,6 +1080,7 @@ func registerMouseButtonTool(server *sdkmcp.Server, toolName, description string
 		if err := action(ctx, args.WindowID, args.X, args.Y, args.Button); err != nil {
 			return nil, nil, fmt.Errorf("mouse %s: %w", verb, err)
 		}
+		track(sessions.forRequest(req), heldButton{windowID: args.WindowID, x: args.X, y: args.Y, button: args.Button})
 		return tools.ToolResultFromText(fmt.Sprintf("Mouse %s (%s) at (%.0f, %.0f) in window %d", verb, args.Button, args.X, args.Y, args.WindowID)), nil, nil
 	})
 }
@@ -925,8 +1092,19 @@ func focusWindowAndHandleError(ctx context.Context, windowService WindowService,
 	return nil
 }
 
-func ensureWindowPermissions(windowService WindowService, toolName string) error {
-	if err := windowService.EnsureAutomationPermissions(toolName); err != nil {
+// ensureCapturePermissions is ensureWindowPermissions for screen capture tools.
+// Without a window ID the pixels come from the screenshot backend alone, so
+// under a replay backend the display probe is skipped and replayed runs behave
+// the same with or without an X server. Window captures still open the display.
+func ensureCapturePermissions(ctx context.Context, windowService WindowService, toolName string, windowID uint32) error {
+	if windowID == 0 && !screenshot.SystemBackendActive() {
+		return nil
+	}
+	return ensureWindowPermissions(ctx, windowService, toolName)
+}
+
+func ensureWindowPermissions(ctx context.Context, windowService WindowService, toolName string) error {
+	if err := windowService.EnsureAutomationPermissions(ctx, toolName); err != nil {
 		return asToolExecutionError(toolName, err)
 	}
 	return nil
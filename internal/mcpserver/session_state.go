@@ -0,0 +1,274 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/tools"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/virtualdisplay"
+)
+
+// sessionReleaseTimeout bounds how long releasing held input may take after a client disconnects.
+const sessionReleaseTimeout = 5 * time.Second
+
+// sessionState holds the mutable tool state owned by one MCP client session.
+// HTTP transports share a single server between clients, so anything a tool
+// call leaves behind (recordings, held keys and buttons, delta baselines,
+// artifacts) lives here.
+//
+// Tool defaults (thresholds, timeouts, encoding) are deliberately not copied
+// here: they come from the server config, no tool can change them, and so one
+// client can never alter another's. Should a tool ever make them adjustable,
+// the adjusted values belong on this struct.
+type sessionState struct {
+	artifactScope string
+	cancel        context.CancelFunc
+	recordings    *recordingState
+	baselines     *deltaBaselines
+
+	mu          sync.Mutex
+	heldKeys    map[string][]string
+	heldButtons map[string]heldButton
+	// display is the virtual display started by this session, if any.
+	display *virtualdisplay.Display
+}
+
+// heldButton records where a mouse button was pressed so it can be released there.
+type heldButton struct {
+	windowID uint32
+	x, y     float64
+	button   string
+}
+
+func newSessionState(artifactScope string) *sessionState {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &sessionState{
+		artifactScope: artifactScope,
+		cancel:        cancel,
+		recordings:    newRecordingState(ctx),
+		baselines:     newDeltaBaselines(),
+		heldKeys:      make(map[string][]string),
+		heldButtons:   make(map[string]heldButton),
+	}
+}
+
+// artifactPrefix namespaces artifact file names so sessions cannot collide.
+func (state *sessionState) artifactPrefix(name string) string {
+	return state.artifactScope + "-" + name
+}
+
+// holdKey records key as pressed. Modifiers from repeated key_down calls are
+// merged so every modifier still pressed is released when the session closes.
+func (state *sessionState) holdKey(key string, modifiers []string) {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	held := state.heldKeys[key]
+	for _, modifier := range modifiers {
+		if !slices.Contains(held, modifier) {
+			held = append(held, modifier)
+		}
+	}
+	state.heldKeys[key] = held
+}
+
+func (state *sessionState) releaseKey(key string, _ []string) {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	delete(state.heldKeys, key)
+}
+
+func (state *sessionState) holdButton(held heldButton) {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	state.heldButtons[heldButtonKey(held)] = held
+}
+
+func (state *sessionState) releaseButton(held heldButton) {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	delete(state.heldButtons, heldButtonKey(held))
+}
+
+// setDisplay records the session's virtual display. It fails if one is already running.
+func (state *sessionState) setDisplay(display *virtualdisplay.Display) error {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if state.display != nil {
+		return fmt.Errorf("this session already runs virtual display %s", state.display.Name)
+	}
+	state.display = display
+	return nil
+}
+
+// displayName returns the name of the session's virtual display, or "" when it has none.
+func (state *sessionState) displayName() string {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if state.display == nil {
+		return ""
+	}
+	return state.display.Name
+}
+
+// takeDisplay returns and forgets the session's virtual display.
+func (state *sessionState) takeDisplay() *virtualdisplay.Display {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	display := state.display
+	state.display = nil
+	return display
+}
+
+func heldButtonKey(held heldButton) string {
+	return fmt.Sprintf("%d/%s", held.windowID, held.button)
+}
+
+// takeHeldInput returns and forgets everything still pressed, in a stable order.
+func (state *sessionState) takeHeldInput() (map[string][]string, []heldButton) {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	keys := state.heldKeys
+	buttons := make([]heldButton, 0, len(state.heldButtons))
+	for _, held := range state.heldButtons {
+		buttons = append(buttons, held)
+	}
+	sort.Slice(buttons, func(i, j int) bool {
+		return heldButtonKey(buttons[i]) < heldButtonKey(buttons[j])
+	})
+	state.heldKeys = make(map[string][]string)
+	state.heldButtons = make(map[string]heldButton)
+	return keys, buttons
+}
+
+// sessionStore maps MCP sessions to their state and tears it down when a session ends.
+type sessionStore struct {
+	inputService   *tools.InputService
+	windowService  WindowService
+	displayService VirtualDisplayService
+
+	mu       sync.Mutex
+	sessions map[*sdkmcp.ServerSession]*sessionState
+	fallback *sessionState
+	nextID   uint64
+	// closing tracks sessions that ended and are still being torn down.
+	closing sync.WaitGroup
+}
+
+func newSessionStore(inputService *tools.InputService, windowService WindowService, displayService VirtualDisplayService) *sessionStore {
+	return &sessionStore{
+		inputService:   inputService,
+		windowService:  windowService,
+		displayService: displayService,
+		sessions:       make(map[*sdkmcp.ServerSession]*sessionState),
+		fallback:       newSessionState("session-local"),
+	}
+}
+
+// forRequest returns the state for the session that issued req, creating it on first use.
+// Requests without a session (direct handler calls) share a process-wide fallback state.
+func (store *sessionStore) forRequest(req *sdkmcp.CallToolRequest) *sessionState {
+	if req == nil || req.Session == nil {
+		return store.fallback
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if state, ok := store.sessions[req.Session]; ok {
+		return state
+	}
+	store.nextID++
+	state := newSessionState(fmt.Sprintf("session-%d", store.nextID))
+	store.sessions[req.Session] = state
+	go store.closeWhenDone(req.Session, state)
+	return state
+}
+
+// displayMiddleware aims every tool call at the calling session's virtual
+// display, if it started one, so sessions never see each other's displays.
+func (store *sessionStore) displayMiddleware() sdkmcp.Middleware {
+	return func(next sdkmcp.MethodHandler) sdkmcp.MethodHandler {
+		return func(ctx context.Context, method string, req sdkmcp.Request) (sdkmcp.Result, error) {
+			if callReq, ok := req.(*sdkmcp.CallToolRequest); ok && method == "tools/call" {
+				ctx = virtualdisplay.WithDisplay(ctx, store.forRequest(callReq).displayName())
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
+
+func (store *sessionStore) closeWhenDone(session *sdkmcp.ServerSession, state *sessionState) {
+	_ = session.Wait()
+
+	store.mu.Lock()
+	_, open := store.sessions[session]
+	delete(store.sessions, session)
+	if open {
+		store.closing.Add(1)
+	}
+	store.mu.Unlock()
+
+	// closeAll may have torn the session down already.
+	if open {
+		defer store.closing.Done()
+		store.close(state)
+	}
+}
+
+// closeAll tears down every session when the server shuts down. Sessions can
+// outlive their transport, and their virtual displays must not outlive the server.
+func (store *sessionStore) closeAll() {
+	store.mu.Lock()
+	states := make([]*sessionState, 0, len(store.sessions)+1)
+	for session, state := range store.sessions {
+		states = append(states, state)
+		delete(store.sessions, session)
+	}
+	store.mu.Unlock()
+
+	states = append(states, store.fallback)
+	for _, state := range states {
+		store.close(state)
+	}
+	store.closing.Wait()
+}
+
+// close stops the session's recordings, releases any keys or buttons it left
+// pressed and stops its virtual display.
+func (store *sessionStore) close(state *sessionState) {
+	state.cancel()
+	state.recordings.discardAll()
+	display := state.takeDisplay()
+	if display != nil {
+		// Release held input on the virtual display before it goes away.
+		defer func() { _ = store.displayService.Stop(display) }()
+	}
+
+	keys, buttons := state.takeHeldInput()
+	if len(keys) == 0 && len(buttons) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), sessionReleaseTimeout)
+	defer cancel()
+	if display != nil {
+		ctx = virtualdisplay.WithDisplay(ctx, display.Name)
+	}
+	for key, modifiers := range keys {
+		_ = store.inputService.KeyUp(ctx, key, modifiers)
+	}
+	for _, held := range buttons {
+		_ = store.windowService.MouseUp(ctx, held.windowID, held.x, held.y, held.button)
+	}
+}
+
+func (store *sessionStore) activeSessions() int {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return len(store.sessions)
+}
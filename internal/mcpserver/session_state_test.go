@@ -0,0 +1,170 @@
+package mcpserver
+
+import (
+	"context"
+	"image"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/screenshot"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/tools"
+)
+
+type releaseRecorder struct {
+	WindowService
+
+	mu      sync.Mutex
+	keys    []string
+	buttons []string
+}
+
+func (r *releaseRecorder) MouseUp(_ context.Context, _ uint32, _, _ float64, button string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.buttons = append(r.buttons, button)
+	return nil
+}
+
+func (r *releaseRecorder) keyUp(_ context.Context, key string, _ []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys = append(r.keys, key)
+	return nil
+}
+
+func (r *releaseRecorder) released() (int, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.keys), len(r.buttons)
+}
+
+type solidScreenshotService struct{}
+
+func (solidScreenshotService) CaptureImage(context.Context) (image.Image, error) {
+	return image.NewRGBA(image.Rect(0, 0, 4, 4)), nil
+}
+
+func (solidScreenshotService) TakeScreenshot(context.Context) ([]byte, error) {
+	return nil, nil
+}
+
+func (solidScreenshotService) TakeScreenshotPNG(context.Context) ([]byte, error) {
+	return nil, nil
+}
+
+func (solidScreenshotService) ListDisplays(context.Context) ([]screenshot.Display, error) {
+	return []screenshot.Display{{Index: 0, Bounds: image.Rect(0, 0, 4, 4), Primary: true}}, nil
+}
+
+func (solidScreenshotService) CaptureDisplayImage(context.Context, int) (image.Image, error) {
+	return image.NewRGBA(image.Rect(0, 0, 4, 4)), nil
+}
+
+func connectTestSession(t *testing.T, server *sdkmcp.Server) (*sdkmcp.ServerSession, *sdkmcp.ClientSession) {
+	t.Helper()
+	ctx := context.Background()
+	serverTransport, clientTransport := sdkmcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+	client := sdkmcp.NewClient(&sdkmcp.Implementation{Name: "session-test", Version: "v0.0.0"}, nil)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	return serverSession, clientSession
+}
+
+func waitForCondition(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSessionStore_IsolatesSessions(t *testing.T) {
+	recorder := &releaseRecorder{}
+	store := newSessionStore(&tools.InputService{KeyUpFn: recorder.keyUp}, recorder, defaultVirtualDisplayService{})
+	server := sdkmcp.NewServer(&sdkmcp.Implementation{Name: "test", Version: "v0.0.0"}, nil)
+
+	sessionA, clientA := connectTestSession(t, server)
+	sessionB, clientB := connectTestSession(t, server)
+	defer func() { _ = clientB.Close() }()
+
+	stateA := store.forRequest(&sdkmcp.CallToolRequest{Session: sessionA})
+	stateB := store.forRequest(&sdkmcp.CallToolRequest{Session: sessionB})
+	if stateA == stateB {
+		t.Fatal("expected distinct state per session")
+	}
+	if again := store.forRequest(&sdkmcp.CallToolRequest{Session: sessionA}); again != stateA {
+		t.Fatal("expected the same state for repeated requests from one session")
+	}
+	if stateA.artifactPrefix("recording") == stateB.artifactPrefix("recording") {
+		t.Fatal("expected sessions to use distinct artifact scopes")
+	}
+	if store.forRequest(nil) != store.fallback {
+		t.Fatal("expected requests without a session to use the fallback state")
+	}
+
+	stateA.holdKey("shift", nil)
+	stateA.holdKey("a", []string{"cmd"})
+	stateA.releaseKey("a", nil)
+	stateA.holdButton(heldButton{windowID: 7, x: 10, y: 20, button: "left"})
+	stateB.holdKey("ctrl", nil)
+
+	if err := clientA.Close(); err != nil {
+		t.Fatalf("close client A: %v", err)
+	}
+	waitForCondition(t, "session A cleanup", func() bool {
+		keys, buttons := recorder.released()
+		return keys == 1 && buttons == 1 && store.activeSessions() == 1
+	})
+
+	keys, _ := stateB.takeHeldInput()
+	if _, ok := keys["ctrl"]; !ok {
+		t.Fatal("expected session B held keys to be untouched by session A disconnect")
+	}
+}
+
+func TestSessionState_HoldKeyMergesModifiers(t *testing.T) {
+	state := newSessionState("session-test")
+	state.holdKey("a", []string{"shift"})
+	state.holdKey("a", []string{"ctrl", "shift"})
+
+	keys, _ := state.takeHeldInput()
+	if got := keys["a"]; !slices.Equal(got, []string{"shift", "ctrl"}) {
+		t.Fatalf("held modifiers = %v, want [shift ctrl]", got)
+	}
+}
+
+func TestSessionStore_CloseDiscardsRecordings(t *testing.T) {
+	state := newSessionState("session-test")
+	recordingID, err := state.recordings.start(context.Background(), solidScreenshotService{}, nil, state.artifactPrefix("recording"), 0, 20, "gif")
+	if err != nil {
+		t.Fatalf("start recording: %v", err)
+	}
+
+	state.recordings.mu.Lock()
+	frameDir := state.recordings.active[recordingID].frameDir
+	state.recordings.mu.Unlock()
+
+	store := newSessionStore(&tools.InputService{}, &releaseRecorder{}, defaultVirtualDisplayService{})
+	store.close(state)
+
+	if _, err := os.Stat(frameDir); !os.IsNotExist(err) {
+		t.Fatalf("expected frame directory to be removed, stat err = %v", err)
+	}
+	if _, err := state.recordings.stop(context.Background(), recordingID); err == nil {
+		t.Fatal("expected stopped session to have no active recordings")
+	}
+}
@@ -17,7 +17,7 @@ func TestServerIntegration_SSE(t *testing.T) {
 	fixturePath := testutil.WriteFixtureJPEG(t)
 	t.Setenv(tools.FixtureImagePathEnv, fixturePath)
 
-	server := NewServer(tools.NewScreenshotService(), Config{})
+	server := NewServer(tools.NewScreenshotService(), Config{WindowService: windowToolsService{}})
 	httpServer := httptest.NewServer(NewSSEHTTPHandler(server))
 	defer httpServer.Close()
 
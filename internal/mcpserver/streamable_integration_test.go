@@ -0,0 +1,67 @@
+package mcpserver
+
+import (
+	"bytes"
+	"context"
+	"image/jpeg"
+	"net/http/httptest"
+	"testing"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/testutil"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/tools"
+)
+
+func TestServerIntegration_StreamableHTTP(t *testing.T) {
+	fixturePath := testutil.WriteFixtureJPEG(t)
+	t.Setenv(tools.FixtureImagePathEnv, fixturePath)
+
+	server := NewServer(tools.NewScreenshotService(), Config{})
+	httpServer := httptest.NewServer(NewHTTPHandler(server))
+	defer httpServer.Close()
+
+	client := sdkmcp.NewClient(&sdkmcp.Implementation{Name: "streamable-client"}, nil)
+	ctx := context.Background()
+
+	session, err := client.Connect(ctx, &sdkmcp.StreamableClientTransport{Endpoint: httpServer.URL + StreamableHTTPPath}, nil)
+	if err != nil {
+		t.Fatalf("connect over streamable http: %v", err)
+	}
+	defer func() {
+		_ = session.Close()
+	}()
+
+	callResult, err := session.CallTool(ctx, &sdkmcp.CallToolParams{Name: ToolName})
+	if err != nil {
+		t.Fatalf("call tool over streamable http: %v", err)
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(extractImage(t, callResult))); err != nil {
+		t.Fatalf("decode jpeg from streamable http response: %v", err)
+	}
+}
+
+func TestNewHTTPHandler_MountsLegacySSE(t *testing.T) {
+	server := NewServer(tools.NewScreenshotService(), Config{})
+	httpServer := httptest.NewServer(NewHTTPHandler(server))
+	defer httpServer.Close()
+
+	client := sdkmcp.NewClient(&sdkmcp.Implementation{Name: "sse-client"}, nil)
+	ctx := context.Background()
+
+	session, err := client.Connect(ctx, &sdkmcp.SSEClientTransport{Endpoint: httpServer.URL + SSEPath}, nil)
+	if err != nil {
+		t.Fatalf("connect over mounted sse: %v", err)
+	}
+	defer func() {
+		_ = session.Close()
+	}()
+
+	toolsResult, err := session.ListTools(ctx, &sdkmcp.ListToolsParams{})
+	if err != nil {
+		t.Fatalf("list tools over mounted sse: %v", err)
+	}
+	if !containsTool(toolsResult.Tools, ToolName) {
+		t.Fatalf("expected tool %q in list", ToolName)
+	}
+}
@@ -40,21 +40,9 @@ func waitForImageMatch(ctx context.Context, windowID uint32, templatePath string
 
 // findImageMatches finds all occurrences of a template image using normalized cross-correlation.
 func findImageMatches(ctx context.Context, windowID uint32, templatePath string, threshold float64) ([]ImageMatch, error) {
-	if err := ValidatePathAllowed(templatePath); err != nil {
-		return nil, fmt.Errorf("template path not allowed: %w", err)
-	}
-
-	// Accepted G304 suppression: templatePath is validated by path allowlist first.
-	// #nosec G304
-	templateFile, err := os.Open(templatePath)
-	if err != nil {
-		return nil, fmt.Errorf("open template image: %w", err)
-	}
-	defer func() { _ = templateFile.Close() }()
-
-	templateImg, _, err := image.Decode(templateFile)
+	templateImg, err := loadTemplateImage(templatePath)
 	if err != nil {
-		return nil, fmt.Errorf("decode template image: %w", err)
+		return nil, err
 	}
 
 	var sourceImage image.Image
@@ -76,6 +64,27 @@ func findImageMatches(ctx context.Context, windowID uint32, templatePath string,
 	return performTemplateMatching(sourceImage, templateImg, threshold), nil
 }
 
+// loadTemplateImage decodes a template image from an allowed path.
+func loadTemplateImage(templatePath string) (image.Image, error) {
+	if err := ValidatePathAllowed(templatePath); err != nil {
+		return nil, fmt.Errorf("template path not allowed: %w", err)
+	}
+
+	// Accepted G304 suppression: templatePath is validated by path allowlist first.
+	// #nosec G304
+	templateFile, err := os.Open(templatePath)
+	if err != nil {
+		return nil, fmt.Errorf("open template image: %w", err)
+	}
+	defer func() { _ = templateFile.Close() }()
+
+	templateImg, _, err := image.Decode(templateFile)
+	if err != nil {
+		return nil, fmt.Errorf("decode template image: %w", err)
+	}
+	return templateImg, nil
+}
+
 func performTemplateMatching(screenshot, template image.Image, threshold float64) []ImageMatch {
 	var matches []ImageMatch
 
@@ -1,7 +1,27 @@
 package mcpserver
 
+import (
+	"context"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/screenshot"
+)
+
 type emptyArgs struct{}
 
+// freshArgs let a capture tool skip the shared frame cache.
+type freshArgs struct {
+	// Fresh captures a new frame instead of reusing one taken in the last few milliseconds.
+	Fresh bool `json:"fresh,omitempty"`
+}
+
+// captureContext marks ctx so captures bypass the frame cache when Fresh is set.
+func (a freshArgs) captureContext(ctx context.Context) context.Context {
+	if a.Fresh {
+		return screenshot.WithFresh(ctx)
+	}
+	return ctx
+}
+
 type mouseButtonArgs struct {
 	WindowID uint32  `json:"window_id"`
 	X        float64 `json:"x"`
@@ -9,9 +29,75 @@ type mouseButtonArgs struct {
 	Button   string  `json:"button,omitempty"`
 }
 
-type screenshotArgs struct{}
+// encodingArgs are the output controls shared by the encoded screenshot tools.
+// Zero values fall back to the server's configured encoding.
+type encodingArgs struct {
+	Format    string `json:"format,omitempty"`
+	Quality   int    `json:"quality,omitempty"`
+	MaxBytes  int    `json:"max_bytes,omitempty"`
+	MaxWidth  int    `json:"max_width,omitempty"`
+	MaxHeight int    `json:"max_height,omitempty"`
+	Grayscale bool   `json:"grayscale,omitempty"`
+}
+
+// annotateArgs draw a set-of-marks overlay onto a screenshot before it is encoded.
+type annotateArgs struct {
+	// Annotate is "grid" or "marks"; empty leaves the image unchanged unless marks are given.
+	Annotate string     `json:"annotate,omitempty"`
+	GridSize int        `json:"grid_size,omitempty"`
+	Marks    []markArgs `json:"marks,omitempty"`
+}
+
+// markArgs is one box to number, in image pixels. Score is accepted so
+// find_image_matches results can be passed unchanged.
+type markArgs struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+	Label  string  `json:"label,omitempty"`
+	Score  float64 `json:"score,omitempty"`
+}
+
+type screenshotArgs struct {
+	// Display is an index from list_displays; omit it to capture every display.
+	Display *int `json:"display,omitempty"`
+	encodingArgs
+	annotateArgs
+	freshArgs
+}
+
+type takeScreenshotPNGArgs struct {
+	Display *int `json:"display,omitempty"`
+	freshArgs
+}
 
-type takeScreenshotPNGArgs struct{}
+type listDisplaysArgs struct{}
+
+type annotateScreenshotArgs struct {
+	// WindowID captures one window; Display captures one display. Omit both for the full screen.
+	WindowID      uint32  `json:"window_id,omitempty"`
+	Display       *int    `json:"display,omitempty"`
+	TemplateImage string  `json:"template_image,omitempty"`
+	Threshold     float64 `json:"threshold,omitempty"`
+	OCR           bool    `json:"ocr,omitempty"`
+	annotateArgs
+	encodingArgs
+	freshArgs
+}
+
+type takeScreenshotDeltaArgs struct {
+	// WindowID captures one window; Display captures one display. Omit both for the full screen.
+	WindowID uint32 `json:"window_id,omitempty"`
+	Display  *int   `json:"display,omitempty"`
+	// TileSize is the tile edge in pixels. Tolerance is the per-channel difference ignored as noise.
+	TileSize  int `json:"tile_size,omitempty"`
+	Tolerance int `json:"tolerance,omitempty"`
+	// Reset returns the whole frame, for a caller that lost track of earlier results.
+	Reset bool `json:"reset,omitempty"`
+	encodingArgs
+	freshArgs
+}
 
 type listWindowsArgs struct{}
 
@@ -19,7 +105,9 @@ type screenshotHashArgs struct {
 	Algorithm     string `json:"algorithm,omitempty"`
 	Target        string `json:"target,omitempty"`
 	WindowID      uint32 `json:"window_id,omitempty"`
+	Display       *int   `json:"display,omitempty"`
 	IncludeCursor bool   `json:"include_cursor,omitempty"`
+	freshArgs
 }
 
 type focusWindowArgs struct {
@@ -28,10 +116,14 @@ type focusWindowArgs struct {
 
 type takeWindowScreenshotArgs struct {
 	WindowID uint32 `json:"window_id"`
+	encodingArgs
+	annotateArgs
+	freshArgs
 }
 
 type takeWindowScreenshotPNGArgs struct {
 	WindowID uint32 `json:"window_id"`
+	freshArgs
 }
 
 type takeRegionScreenshotArgs struct {
@@ -40,6 +132,9 @@ type takeRegionScreenshotArgs struct {
 	Width      float64 `json:"width"`
 	Height     float64 `json:"height"`
 	CoordSpace string  `json:"coord_space,omitempty"`
+	encodingArgs
+	annotateArgs
+	freshArgs
 }
 
 type takeRegionScreenshotPNGArgs struct {
@@ -48,6 +143,7 @@ type takeRegionScreenshotPNGArgs struct {
 	Width      float64 `json:"width"`
 	Height     float64 `json:"height"`
 	CoordSpace string  `json:"coord_space,omitempty"`
+	freshArgs
 }
 
 type clickArgs struct {
@@ -83,6 +179,21 @@ type scrollArgs struct {
 	DeltaY   float64 `json:"delta_y"`
 }
 
+type takeScrollingScreenshotArgs struct {
+	WindowID uint32 `json:"window_id"`
+	// X and Y are where to scroll, in window screenshot pixels. Zero uses the window center.
+	X float64 `json:"x,omitempty"`
+	Y float64 `json:"y,omitempty"`
+	// ScrollAmount is the scroll distance per step; zero uses half the window height.
+	ScrollAmount float64 `json:"scroll_amount,omitempty"`
+	// MaxScrolls and MaxTotalHeight stop the capture early; MaxTotalHeight is in stitched pixels.
+	MaxScrolls     int `json:"max_scrolls,omitempty"`
+	MaxTotalHeight int `json:"max_total_height,omitempty"`
+	// SettleMs is how long to wait after each scroll before capturing.
+	SettleMs int `json:"settle_ms,omitempty"`
+	encodingArgs
+}
+
 type pressKeyArgs struct {
 	WindowID   uint32   `json:"window_id"`
 	Key        string   `json:"key"`
@@ -142,7 +253,20 @@ type killProcessArgs struct {
 }
 
 type setClipboardArgs struct {
-	Text string `json:"text"`
+	// Text is the content for text/plain and text/html.
+	Text string `json:"text,omitempty"`
+	// DataBase64 is the base64-encoded content for image/png.
+	DataBase64 string `json:"data_base64,omitempty"`
+	MimeType   string `json:"mime_type,omitempty"`
+}
+
+type getClipboardArgs struct {
+	MimeType string `json:"mime_type,omitempty"`
+}
+
+type waitForClipboardChangeArgs struct {
+	TimeoutMs      int `json:"timeout_ms,omitempty"`
+	PollIntervalMs int `json:"poll_interval_ms,omitempty"`
 }
 
 type waitForImageMatchArgs struct {
@@ -157,6 +281,7 @@ type findImageMatchesArgs struct {
 	WindowID      uint32  `json:"window_id,omitempty"`
 	TemplateImage string  `json:"template_image"`
 	Threshold     float64 `json:"threshold,omitempty"`
+	freshArgs
 }
 
 type compareImagesArgs struct {
@@ -192,3 +317,10 @@ type startRecordingArgs struct {
 type stopRecordingArgs struct {
 	RecordingID string `json:"recording_id"`
 }
+
+type startVirtualDisplayArgs struct {
+	Width         int    `json:"width,omitempty"`
+	Height        int    `json:"height,omitempty"`
+	Depth         int    `json:"depth,omitempty"`
+	WindowManager string `json:"window_manager,omitempty"`
+}
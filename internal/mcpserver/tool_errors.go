@@ -61,6 +61,19 @@ func asPermissionError(err error) bool {
 	return errors.As(err, &permissionErr)
 }
 
+// asCapabilityError maps a missing display capability to permission_denied when
+// access was refused and to feature_unavailable otherwise.
+func asCapabilityError(err error) (ToolErrorCode, string, bool) {
+	var capabilityErr *window.CapabilityError
+	if !errors.As(err, &capabilityErr) {
+		return "", "", false
+	}
+	if capabilityErr.Denied {
+		return toolErrorCodePermissionDenied, capabilityErr.Capability + " was denied", true
+	}
+	return toolErrorCodeFeatureUnavailable, capabilityErr.Capability + " is unavailable", true
+}
+
 func asFeatureUnavailableError(err error) bool {
 	return errors.Is(err, errFeatureUnavailable)
 }
@@ -81,6 +94,10 @@ func asToolExecutionError(toolName string, err error) error {
 		return newToolError(toolName, toolErrorCodePermissionDenied, permissionDeniedMessage, err)
 	}
 
+	if code, message, ok := asCapabilityError(err); ok {
+		return newToolError(toolName, code, message, err)
+	}
+
 	if asFeatureUnavailableError(err) {
 		return newToolError(toolName, toolErrorCodeFeatureUnavailable, err.Error(), err)
 	}
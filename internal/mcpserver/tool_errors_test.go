@@ -2,6 +2,7 @@ package mcpserver
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"testing"
 
@@ -55,3 +56,29 @@ func TestAsToolExecutionErrorPassesThroughGenericError(t *testing.T) {
 		t.Fatal("did not expect ToolError for generic errors")
 	}
 }
+
+func TestAsToolExecutionErrorMapsCapabilityError(t *testing.T) {
+	missing := fmt.Errorf("ensure automation permissions: %w", &window.CapabilityError{
+		ToolName:   ClickToolName,
+		Capability: "the XTEST extension",
+		Reason:     `X display ":1" does not offer XTEST`,
+	})
+	var gotTE ToolError
+	if !errors.As(asToolExecutionError(ClickToolName, missing), &gotTE) {
+		t.Fatal("expected ToolError for a missing capability")
+	}
+	if gotTE.Code != toolErrorCodeFeatureUnavailable {
+		t.Fatalf("code = %q, want %q", gotTE.Code, toolErrorCodeFeatureUnavailable)
+	}
+	if gotTE.Message != "the XTEST extension is unavailable" {
+		t.Fatalf("message = %q", gotTE.Message)
+	}
+	if !strings.Contains(gotTE.Error(), "does not offer XTEST") {
+		t.Fatalf("error should keep the actionable reason, got %q", gotTE.Error())
+	}
+
+	denied := &window.CapabilityError{ToolName: ListWindowsToolName, Capability: `access to X display ":0"`, Denied: true, Reason: "set XAUTHORITY"}
+	if !errors.As(asToolExecutionError(ListWindowsToolName, denied), &gotTE) || gotTE.Code != toolErrorCodePermissionDenied {
+		t.Fatalf("denied capability = %+v, want %q", gotTE, toolErrorCodePermissionDenied)
+	}
+}
@@ -0,0 +1,170 @@
+package mcpserver
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Tool profiles select a named base set of tools before allow/deny lists apply.
+const (
+	// ToolProfileFull exposes every tool supported on the current platform.
+	ToolProfileFull = "full"
+	// ToolProfileObserve exposes read-only tools: capture, listing, waiting and comparison.
+	ToolProfileObserve = "observe"
+)
+
+// allToolNames lists every tool the server can register. Keep it in sync with server_registry.go.
+var allToolNames = []string{
+	ToolName,
+	TakeScreenshotPNGToolName,
+	ScreenshotHashToolName,
+	ListDisplaysToolName,
+	AnnotateScreenshotToolName,
+	TakeScreenshotDeltaToolName,
+	ListWindowsToolName,
+	FocusWindowToolName,
+	TakeWindowScreenshotToolName,
+	TakeWindowScreenshotPNGToolName,
+	TakeRegionScreenshotToolName,
+	TakeRegionScreenshotPNGToolName,
+	ClickToolName,
+	ClickScreenToolName,
+	MouseMoveToolName,
+	MouseDownToolName,
+	MouseUpToolName,
+	DragToolName,
+	ScrollToolName,
+	TakeScrollingScreenshotToolName,
+	PressKeyToolName,
+	TypeTextToolName,
+	KeyDownToolName,
+	KeyUpToolName,
+	WaitForPixelToolName,
+	WaitForRegionStableToolName,
+	LaunchAppToolName,
+	QuitAppToolName,
+	WaitForProcessToolName,
+	KillProcessToolName,
+	SetClipboardToolName,
+	GetClipboardToolName,
+	ClearClipboardToolName,
+	WaitForClipboardChangeToolName,
+	WaitForImageMatchToolName,
+	FindImageMatchesToolName,
+	CompareImagesToolName,
+	AssertScreenshotMatchesFixtureToolName,
+	WaitForTextToolName,
+	RestartAppToolName,
+	StartRecordingToolName,
+	StopRecordingToolName,
+	TakeScreenshotWithCursorToolName,
+	StartVirtualDisplayToolName,
+	StopVirtualDisplayToolName,
+}
+
+// observeToolNames never change focus, inject input, touch processes or write the clipboard.
+var observeToolNames = []string{
+	ToolName,
+	TakeScreenshotPNGToolName,
+	ScreenshotHashToolName,
+	ListDisplaysToolName,
+	AnnotateScreenshotToolName,
+	TakeScreenshotDeltaToolName,
+	ListWindowsToolName,
+	TakeWindowScreenshotToolName,
+	TakeWindowScreenshotPNGToolName,
+	TakeRegionScreenshotToolName,
+	TakeRegionScreenshotPNGToolName,
+	WaitForPixelToolName,
+	WaitForRegionStableToolName,
+	WaitForProcessToolName,
+	GetClipboardToolName,
+	WaitForClipboardChangeToolName,
+	WaitForImageMatchToolName,
+	FindImageMatchesToolName,
+	CompareImagesToolName,
+	AssertScreenshotMatchesFixtureToolName,
+	WaitForTextToolName,
+	TakeScreenshotWithCursorToolName,
+}
+
+var toolProfiles = map[string][]string{
+	ToolProfileFull:    allToolNames,
+	ToolProfileObserve: observeToolNames,
+}
+
+// ToolProfiles returns the names of the built-in tool profiles.
+func ToolProfiles() []string {
+	names := make([]string, 0, len(toolProfiles))
+	for name := range toolProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// enabledTools resolves the profile, then adds AllowedTools and removes DeniedTools.
+// Deny always wins. Tools that are not registered on this platform stay unavailable.
+func (cfg Config) enabledTools() (map[string]bool, error) {
+	profile := strings.TrimSpace(cfg.ToolProfile)
+	if profile == "" {
+		profile = ToolProfileFull
+	}
+	base, ok := toolProfiles[profile]
+	if !ok {
+		return nil, fmt.Errorf("unknown tool profile %q (available: %s)", profile, strings.Join(ToolProfiles(), ", "))
+	}
+	if err := validateToolNames("allowed", cfg.AllowedTools); err != nil {
+		return nil, err
+	}
+	if err := validateToolNames("denied", cfg.DeniedTools); err != nil {
+		return nil, err
+	}
+
+	enabled := make(map[string]bool, len(base)+len(cfg.AllowedTools))
+	for _, name := range base {
+		enabled[name] = true
+	}
+	for _, name := range cfg.AllowedTools {
+		enabled[name] = true
+	}
+	for _, name := range cfg.DeniedTools {
+		delete(enabled, name)
+	}
+	return enabled, nil
+}
+
+// ValidateToolPolicy reports an unknown profile or tool name in cfg.
+func (cfg Config) ValidateToolPolicy() error {
+	_, err := cfg.enabledTools()
+	return err
+}
+
+func validateToolNames(listName string, names []string) error {
+	known := make(map[string]bool, len(allToolNames))
+	for _, name := range allToolNames {
+		known[name] = true
+	}
+	for _, name := range names {
+		if !known[name] {
+			return fmt.Errorf("unknown tool %q in %s tools", name, listName)
+		}
+	}
+	return nil
+}
+
+// applyToolPolicy removes registered tools that the profile and allow/deny lists exclude.
+func applyToolPolicy(server *sdkmcp.Server, enabled map[string]bool) {
+	var disabled []string
+	for _, name := range allToolNames {
+		if !enabled[name] {
+			disabled = append(disabled, name)
+		}
+	}
+	if len(disabled) > 0 {
+		server.RemoveTools(disabled...)
+	}
+}
@@ -0,0 +1,202 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"image"
+	"slices"
+	"sort"
+	"testing"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/imgencode"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/tools"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/window"
+)
+
+// windowToolsService reports window tool support so every tool group registers.
+type windowToolsService struct {
+	WindowService
+}
+
+func (windowToolsService) SupportsWindowTools() bool {
+	return true
+}
+
+func (windowToolsService) EnsureAutomationPermissions(context.Context, string) error {
+	return nil
+}
+
+func listServerTools(t *testing.T, server *sdkmcp.Server) []string {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	serverTransport, clientTransport := sdkmcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+	client := sdkmcp.NewClient(&sdkmcp.Implementation{Name: "policy-test"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	defer func() { _ = session.Close() }()
+
+	result, err := session.ListTools(ctx, &sdkmcp.ListToolsParams{})
+	if err != nil {
+		t.Fatalf("list tools: %v", err)
+	}
+	names := make([]string, 0, len(result.Tools))
+	for _, tool := range result.Tools {
+		names = append(names, tool.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func newPolicyTestServer(cfg Config) *sdkmcp.Server {
+	cfg.ExperimentalTools = true
+	cfg.WindowService = windowToolsService{}
+	cfg.InputService = &tools.InputService{}
+	cfg.VirtualDisplayService = &fakeDisplayService{}
+	return NewServer(nil, cfg)
+}
+
+func TestToolCatalogMatchesRegistry(t *testing.T) {
+	registered := listServerTools(t, newPolicyTestServer(Config{}))
+	catalog := append([]string(nil), allToolNames...)
+	sort.Strings(catalog)
+
+	if len(registered) != len(catalog) {
+		t.Fatalf("registered %d tools, catalog has %d:\nregistered=%v\ncatalog=%v", len(registered), len(catalog), registered, catalog)
+	}
+	for i := range registered {
+		if registered[i] != catalog[i] {
+			t.Fatalf("registered tool %q does not match catalog entry %q", registered[i], catalog[i])
+		}
+	}
+}
+
+func TestToolRequirements_InputToolsNeedInput(t *testing.T) {
+	for _, name := range inputToolNames {
+		// focus_window goes through the window manager rather than synthetic input.
+		if name == FocusWindowToolName {
+			continue
+		}
+		if toolRequirements[name] != window.RequireInput {
+			t.Errorf("%s injects input but does not require it", name)
+		}
+	}
+	for name := range toolRequirements {
+		if !slices.Contains(allToolNames, name) {
+			t.Errorf("toolRequirements names unknown tool %q", name)
+		}
+	}
+}
+
+func TestNewServer_ObserveProfile(t *testing.T) {
+	names := listServerTools(t, newPolicyTestServer(Config{ToolProfile: ToolProfileObserve}))
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+
+	for _, want := range []string{ToolName, ListWindowsToolName, CompareImagesToolName} {
+		if !set[want] {
+			t.Errorf("expected %q in observe profile", want)
+		}
+	}
+	for _, unwanted := range []string{ClickToolName, KillProcessToolName, TypeTextToolName, SetClipboardToolName} {
+		if set[unwanted] {
+			t.Errorf("did not expect %q in observe profile", unwanted)
+		}
+	}
+}
+
+func TestNewServer_AllowAndDenyLists(t *testing.T) {
+	names := listServerTools(t, newPolicyTestServer(Config{
+		ToolProfile:  ToolProfileObserve,
+		AllowedTools: []string{ClickToolName, FocusWindowToolName},
+		DeniedTools:  []string{FocusWindowToolName, ListWindowsToolName},
+	}))
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	if !set[ClickToolName] {
+		t.Error("expected allowed tool click to be enabled")
+	}
+	if set[FocusWindowToolName] || set[ListWindowsToolName] {
+		t.Error("expected denied tools to be removed, even when also allowed")
+	}
+}
+
+func TestConfig_ValidateToolPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{name: "default", cfg: Config{}},
+		{name: "observe", cfg: Config{ToolProfile: ToolProfileObserve}},
+		{name: "unknown profile", cfg: Config{ToolProfile: "admin"}, wantErr: true},
+		{name: "unknown allowed tool", cfg: Config{AllowedTools: []string{"rm_rf"}}, wantErr: true},
+		{name: "unknown denied tool", cfg: Config{DeniedTools: []string{"rm_rf"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.ValidateToolPolicy()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateToolPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewServer_InvalidPolicyFailsClosed(t *testing.T) {
+	if names := listServerTools(t, newPolicyTestServer(Config{ToolProfile: "admin"})); len(names) != 0 {
+		t.Fatalf("expected no tools for an invalid policy, got %v", names)
+	}
+}
+
+func TestNewServer_AppliesEncodingOptions(t *testing.T) {
+	service := &tools.ScreenshotService{
+		Capture: func(context.Context) (image.Image, error) {
+			return image.NewRGBA(image.Rect(0, 0, 2, 2)), nil
+		},
+		Encode:  imgencode.EncodeJPEG,
+		Options: imgencode.DefaultOptions,
+	}
+	encoding := imgencode.Options{Quality: 85, MaxBytes: 2048, MinQuality: 40, QualityStep: 10}
+	server := NewServer(service, Config{Encoding: encoding, WindowService: windowToolsService{}, InputService: &tools.InputService{}})
+
+	ctx := context.Background()
+	serverTransport, clientTransport := sdkmcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+	session, err := sdkmcp.NewClient(&sdkmcp.Implementation{Name: "encoding-test"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	defer func() { _ = session.Close() }()
+
+	result, err := session.CallTool(ctx, &sdkmcp.CallToolParams{Name: ToolName})
+	if err != nil {
+		t.Fatalf("call tool: %v", err)
+	}
+	var metadata struct {
+		Encoding imgencode.Encoding `json:"encoding"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(*sdkmcp.TextContent).Text), &metadata); err != nil {
+		t.Fatalf("decode metadata: %v", err)
+	}
+	if metadata.Encoding.Quality != encoding.Quality || metadata.Encoding.MaxBytes != encoding.MaxBytes {
+		t.Fatalf("encoding = %+v, want quality %d and max_bytes %d", metadata.Encoding, encoding.Quality, encoding.MaxBytes)
+	}
+	if service.Options != imgencode.DefaultOptions {
+		t.Fatal("NewServer must not mutate the caller's screenshot service")
+	}
+}
@@ -0,0 +1,208 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"os/exec"
+	"sort"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/annotate"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/imgencode"
+)
+
+// options converts the arguments into annotate.Options. ok is false when no
+// overlay was requested.
+func (a annotateArgs) options() (opts annotate.Options, ok bool, err error) {
+	mode := a.Annotate
+	if mode == "" && len(a.Marks) > 0 {
+		mode = annotate.ModeMarks
+	}
+	switch mode {
+	case "":
+		if a.GridSize != 0 {
+			return annotate.Options{}, false, fmt.Errorf("grid_size requires annotate %q", annotate.ModeGrid)
+		}
+		return annotate.Options{}, false, nil
+	case annotate.ModeGrid:
+		if len(a.Marks) > 0 {
+			return annotate.Options{}, false, fmt.Errorf("marks require annotate %q", annotate.ModeMarks)
+		}
+	case annotate.ModeMarks:
+	default:
+		return annotate.Options{}, false, fmt.Errorf("annotate must be %q or %q, got %q", annotate.ModeGrid, annotate.ModeMarks, a.Annotate)
+	}
+	if a.GridSize < 0 {
+		return annotate.Options{}, false, fmt.Errorf("grid_size must be >= 0")
+	}
+
+	opts = annotate.Options{Mode: mode, GridSize: a.GridSize}
+	for _, mark := range a.Marks {
+		opts.Boxes = append(opts.Boxes, annotate.Box{X: mark.X, Y: mark.Y, Width: mark.Width, Height: mark.Height, Label: mark.Label})
+	}
+	return opts, true, nil
+}
+
+// apply draws the requested overlay onto img. It returns img unchanged and a
+// nil result when no overlay was requested.
+func (a annotateArgs) apply(img image.Image) (image.Image, *annotate.Result, error) {
+	opts, ok, err := a.options()
+	if err != nil || !ok {
+		return img, nil, err
+	}
+	annotated, result, err := annotate.Draw(img, opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("annotate screenshot: %w", err)
+	}
+	return annotated, result, nil
+}
+
+func registerAnnotateScreenshotTool(server *sdkmcp.Server, service ScreenshotService, windowService WindowService, settings toolSettings) {
+	sdkmcp.AddTool(server, &sdkmcp.Tool{
+		Name:        AnnotateScreenshotToolName,
+		Description: AnnotateScreenshotToolDescription,
+	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, args annotateScreenshotArgs) (*sdkmcp.CallToolResult, any, error) {
+		if err := ensureCapturePermissions(ctx, windowService, AnnotateScreenshotToolName, args.WindowID); err != nil {
+			return nil, nil, err
+		}
+		ctx = args.captureContext(ctx)
+		if args.WindowID != 0 && args.Display != nil {
+			return nil, nil, fmt.Errorf("window_id and display are mutually exclusive")
+		}
+		detect := args.TemplateImage != "" || args.OCR
+		if detect && args.Annotate == annotate.ModeGrid {
+			return nil, nil, fmt.Errorf("template_image and ocr produce marks and cannot be combined with annotate %q", annotate.ModeGrid)
+		}
+		if args.Annotate == "" {
+			args.Annotate = annotate.ModeGrid
+			if detect || len(args.Marks) > 0 {
+				args.Annotate = annotate.ModeMarks
+			}
+		}
+		if _, _, err := args.annotateArgs.options(); err != nil {
+			return nil, nil, err
+		}
+		out, err := args.output(settings.encoding)
+		if err != nil {
+			return nil, nil, err
+		}
+		var templateImg image.Image
+		if args.TemplateImage != "" {
+			args.Threshold = defaultThreshold(args.Threshold, settings.defaults.ImageMatchThreshold)
+			if err := validateThreshold(args.Threshold); err != nil {
+				return nil, nil, err
+			}
+			if templateImg, err = loadTemplateImage(args.TemplateImage); err != nil {
+				return nil, nil, err
+			}
+		}
+		if args.OCR {
+			if _, err := exec.LookPath("tesseract"); err != nil {
+				return nil, nil, newFeatureUnavailable(AnnotateScreenshotToolName, "OCR marks require tesseract")
+			}
+		}
+
+		metadata := map[string]any{}
+		var img image.Image
+		var redactions *int
+		switch {
+		case args.WindowID != 0:
+			windowImg, windowMetadata, err := windowService.TakeWindowScreenshotImage(ctx, args.WindowID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("capture window screenshot: %w", err)
+			}
+			if img, redactions, err = settings.redactor.window(ctx, windowImg, windowMetadata); err != nil {
+				return nil, nil, err
+			}
+			metadata["window"] = windowMetadata
+		default:
+			if args.Display != nil {
+				img, err = captureDisplay(ctx, service, *args.Display)
+				metadata["display"] = *args.Display
+			} else {
+				img, err = service.CaptureImage(ctx)
+			}
+			if err != nil {
+				return nil, nil, fmt.Errorf("capture screenshot: %w", err)
+			}
+			if img, redactions, err = settings.redactor.screenshot(ctx, img, args.Display); err != nil {
+				return nil, nil, err
+			}
+		}
+		if redactions != nil {
+			metadata["redactions"] = *redactions
+		}
+
+		// Detection runs on the redacted image so marks and OCR text never reveal
+		// redacted content. Detected boxes are numbered after the caller's marks.
+		if templateImg != nil {
+			for _, match := range suppressOverlappingMatches(performTemplateMatching(img, templateImg, args.Threshold)) {
+				args.Marks = append(args.Marks, markArgs{
+					X: match.X - float64(img.Bounds().Min.X), Y: match.Y - float64(img.Bounds().Min.Y),
+					Width: match.Width, Height: match.Height, Score: match.Score,
+				})
+			}
+		}
+		var words []ocrWord
+		if args.OCR {
+			if words, err = runOCRWords(ctx, img); err != nil {
+				return nil, nil, fmt.Errorf("run OCR: %w", err)
+			}
+		}
+
+		opts, _, err := args.annotateArgs.options()
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, word := range words {
+			opts.Boxes = append(opts.Boxes, annotate.Box{
+				X: float64(word.Left), Y: float64(word.Top), Width: float64(word.Width), Height: float64(word.Height), Text: word.Text,
+			})
+		}
+		annotated, annotations, err := annotate.Draw(img, opts)
+		if err != nil {
+			return nil, nil, fmt.Errorf("annotate screenshot: %w", err)
+		}
+		metadata["annotations"] = annotations
+		result, err := encodedScreenshotResult(annotated, out, func(encoding *imgencode.Encoding) any {
+			metadata["encoding"] = encoding
+			return metadata
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return result, nil, nil
+	})
+}
+
+// suppressOverlappingMatches keeps the best-scoring match in each cluster, since
+// template matching reports every nearby offset above the threshold.
+func suppressOverlappingMatches(matches []ImageMatch) []ImageMatch {
+	sorted := append([]ImageMatch(nil), matches...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Score > sorted[j].Score })
+
+	var kept []ImageMatch
+	for _, candidate := range sorted {
+		overlaps := false
+		for _, match := range kept {
+			if candidate.X < match.X+match.Width && match.X < candidate.X+candidate.Width &&
+				candidate.Y < match.Y+match.Height && match.Y < candidate.Y+candidate.Height {
+				overlaps = true
+				break
+			}
+		}
+		if !overlaps {
+			kept = append(kept, candidate)
+		}
+	}
+	// Number the marks in reading order rather than by score.
+	sort.SliceStable(kept, func(i, j int) bool {
+		if kept[i].Y != kept[j].Y {
+			return kept[i].Y < kept[j].Y
+		}
+		return kept[i].X < kept[j].X
+	})
+	return kept
+}
@@ -0,0 +1,182 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/annotate"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/tools"
+)
+
+// drawChecker fills a 10x10 block at x, y with 2px black and white squares.
+func drawChecker(img *image.RGBA, x, y int) {
+	for dy := 0; dy < 10; dy++ {
+		for dx := 0; dx < 10; dx++ {
+			c := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+			if (dx/2+dy/2)%2 == 0 {
+				c = color.RGBA{A: 255}
+			}
+			img.SetRGBA(x+dx, y+dy, c)
+		}
+	}
+}
+
+// patternScreenshotService returns a white 120x60 screen with checker blocks at
+// (10,10) and (70,30).
+type patternScreenshotService struct {
+	solidScreenshotService
+}
+
+func (patternScreenshotService) CaptureImage(context.Context) (image.Image, error) {
+	img := image.NewRGBA(image.Rect(0, 0, 120, 60))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	drawChecker(img, 10, 10)
+	drawChecker(img, 70, 30)
+	return img, nil
+}
+
+// annotationsOf decodes the annotations from a screenshot tool's metadata.
+func annotationsOf(t *testing.T, result *sdkmcp.CallToolResult) annotate.Result {
+	t.Helper()
+	var metadata struct {
+		Annotations annotate.Result `json:"annotations"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(*sdkmcp.TextContent).Text), &metadata); err != nil {
+		t.Fatalf("decode metadata: %v", err)
+	}
+	return metadata.Annotations
+}
+
+func TestTakeScreenshotGridAnnotation(t *testing.T) {
+	session := newDisplayTestSession(t)
+	result, err := session.CallTool(context.Background(), &sdkmcp.CallToolParams{
+		Name:      ToolName,
+		Arguments: map[string]any{"display": 0, "format": "png", "annotate": "grid", "grid_size": 20},
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("take_screenshot: %v %+v", err, result)
+	}
+	annotations := annotationsOf(t, result)
+	if annotations.Mode != annotate.ModeGrid || len(annotations.Labels) != 4 {
+		t.Fatalf("annotations = %+v, want a 2x2 grid", annotations)
+	}
+	if b2 := annotations.Labels["B2"]; b2.X != 30 || b2.Y != 25 {
+		t.Fatalf("B2 = %+v, want center 30,25", b2)
+	}
+
+	result, err = session.CallTool(context.Background(), &sdkmcp.CallToolParams{
+		Name:      ToolName,
+		Arguments: map[string]any{"annotate": "arrows"},
+	})
+	if err != nil {
+		t.Fatalf("take_screenshot: %v", err)
+	}
+	if !result.IsError {
+		t.Fatal("expected an unknown annotate mode to be rejected")
+	}
+}
+
+func TestTakeRegionScreenshotNumbersMarks(t *testing.T) {
+	server := NewServer(nil, Config{WindowService: regionImageService{}, InputService: &tools.InputService{}})
+	_, session := connectTestSession(t, server)
+	t.Cleanup(func() { _ = session.Close() })
+
+	result, err := session.CallTool(context.Background(), &sdkmcp.CallToolParams{
+		Name: TakeRegionScreenshotToolName,
+		Arguments: map[string]any{
+			"x": 0, "y": 0, "width": 100, "height": 50,
+			"marks": []map[string]any{{"x": 10, "y": 10, "width": 20, "height": 10, "score": 0.93}},
+		},
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("take_region_screenshot: %v %+v", err, result)
+	}
+	var metadata regionScreenshotResult
+	if err := json.Unmarshal([]byte(result.Content[0].(*sdkmcp.TextContent).Text), &metadata); err != nil {
+		t.Fatalf("decode metadata: %v", err)
+	}
+	if metadata.Annotations == nil || metadata.Annotations.Mode != annotate.ModeMarks {
+		t.Fatalf("annotations = %+v, want marks", metadata.Annotations)
+	}
+	if mark := metadata.Annotations.Labels["1"]; mark.X != 20 || mark.Y != 15 {
+		t.Fatalf("mark 1 = %+v, want center 20,15", mark)
+	}
+}
+
+func TestAnnotateScreenshotMarksTemplateMatches(t *testing.T) {
+	template := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	drawChecker(template, 0, 0)
+	templatePath := filepath.Join(t.TempDir(), "checker.png")
+	file, err := os.Create(templatePath)
+	if err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	if err := png.Encode(file, template); err != nil {
+		t.Fatalf("encode template: %v", err)
+	}
+	_ = file.Close()
+
+	server := NewServer(patternScreenshotService{}, Config{WindowService: windowToolsService{}, InputService: &tools.InputService{}})
+	_, session := connectTestSession(t, server)
+	t.Cleanup(func() { _ = session.Close() })
+
+	result, err := session.CallTool(context.Background(), &sdkmcp.CallToolParams{
+		Name:      AnnotateScreenshotToolName,
+		Arguments: map[string]any{"template_image": templatePath, "format": "png"},
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("annotate_screenshot: %v %+v", err, result)
+	}
+	annotations := annotationsOf(t, result)
+	if annotations.Mode != annotate.ModeMarks || len(annotations.Labels) != 2 {
+		t.Fatalf("annotations = %+v, want two marks", annotations)
+	}
+	first, second := annotations.Labels["1"], annotations.Labels["2"]
+	if first.X != 15 || first.Y != 15 || second.X != 75 || second.Y != 35 {
+		t.Fatalf("marks = %+v, %+v; want centers 15,15 and 75,35", first, second)
+	}
+
+	result, err = session.CallTool(context.Background(), &sdkmcp.CallToolParams{
+		Name:      AnnotateScreenshotToolName,
+		Arguments: map[string]any{"template_image": templatePath, "annotate": "grid"},
+	})
+	if err != nil {
+		t.Fatalf("annotate_screenshot: %v", err)
+	}
+	if !result.IsError {
+		t.Fatal("expected template_image with annotate grid to be rejected")
+	}
+}
+
+func TestSuppressOverlappingMatches(t *testing.T) {
+	matches := []ImageMatch{
+		{X: 50, Y: 0, Width: 10, Height: 10, Score: 0.9},
+		{X: 0, Y: 0, Width: 10, Height: 10, Score: 0.85},
+		{X: 2, Y: 0, Width: 10, Height: 10, Score: 0.95},
+	}
+	kept := suppressOverlappingMatches(matches)
+	if len(kept) != 2 || kept[0].X != 2 || kept[1].X != 50 {
+		t.Fatalf("kept = %+v, want the best of the overlapping pair, then x=50", kept)
+	}
+}
+
+func TestParseTesseractTSV(t *testing.T) {
+	output := "level\tpage_num\tblock_num\tpar_num\tline_num\tword_num\tleft\ttop\twidth\theight\tconf\ttext\n" +
+		"1\t1\t0\t0\t0\t0\t0\t0\t640\t480\t-1\t\n" +
+		"5\t1\t1\t1\t1\t1\t36\t92\t60\t14\t96.1\tSubmit\n" +
+		"5\t1\t1\t1\t1\t2\t100\t92\t0\t14\t10\t \n"
+	words := parseTesseractTSV(output)
+	if len(words) != 1 || words[0] != (ocrWord{Text: "Submit", Line: "1.1.1", Left: 36, Top: 92, Width: 60, Height: 14}) {
+		t.Fatalf("words = %+v", words)
+	}
+}
@@ -1,26 +1,177 @@
 package mcpserver
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"image/png"
+	"strings"
+	"time"
 
-	"github.com/brainwhocodes/screenshot_mcp_server/internal/safeexec"
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/clipboard"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/tools"
+)
+
+const (
+	defaultClipboardWaitTimeoutMs = 5000
+	defaultClipboardPollMs        = 200
 )
 
-// setClipboard sets the macOS clipboard content using pbcopy.
-func setClipboard(ctx context.Context, text string) error {
-	_, err := safeexec.RunCommandWithInput(ctx, []byte(text), "pbcopy")
+func registerClipboardTools(server *sdkmcp.Server, clipboardService clipboard.Clipboard, settings toolSettings) {
+	registerSetClipboardTool(server, clipboardService)
+	registerGetClipboardTool(server, clipboardService)
+	registerClearClipboardTool(server, clipboardService)
+	registerWaitForClipboardChangeTool(server, clipboardService, settings)
+}
+
+func registerSetClipboardTool(server *sdkmcp.Server, clipboardService clipboard.Clipboard) {
+	sdkmcp.AddTool(server, &sdkmcp.Tool{
+		Name:        SetClipboardToolName,
+		Description: SetClipboardToolDescription,
+	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, args setClipboardArgs) (*sdkmcp.CallToolResult, any, error) {
+		mimeType, data, err := clipboardContent(args)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := clipboardService.Write(ctx, mimeType, data); err != nil {
+			return nil, nil, fmt.Errorf("set clipboard: %w", err)
+		}
+		return tools.ToolResultFromText("Clipboard set successfully"), nil, nil
+	})
+}
+
+// clipboardContent validates set_clipboard arguments and returns the bytes to write.
+func clipboardContent(args setClipboardArgs) (string, []byte, error) {
+	mimeType, err := clipboard.NormalizeType(args.MimeType)
 	if err != nil {
-		return fmt.Errorf("set clipboard: %w", err)
+		return "", nil, err
+	}
+	if mimeType != clipboard.TypePNG {
+		if args.DataBase64 != "" {
+			return "", nil, fmt.Errorf("data_base64 is only used with mime_type %s; pass text instead", clipboard.TypePNG)
+		}
+		if args.Text == "" {
+			return "", nil, fmt.Errorf("text is required")
+		}
+		return mimeType, []byte(args.Text), nil
 	}
-	return nil
-}
 
-// getClipboard gets the macOS clipboard content using pbpaste.
-func getClipboard(ctx context.Context) (string, error) {
-	output, err := safeexec.RunCommand(ctx, "pbpaste")
+	if args.Text != "" {
+		return "", nil, fmt.Errorf("text cannot be used with mime_type %s; pass data_base64 instead", clipboard.TypePNG)
+	}
+	if args.DataBase64 == "" {
+		return "", nil, fmt.Errorf("data_base64 is required for mime_type %s", clipboard.TypePNG)
+	}
+	data, err := base64.StdEncoding.DecodeString(args.DataBase64)
 	if err != nil {
-		return "", fmt.Errorf("get clipboard: %w", err)
+		return "", nil, fmt.Errorf("decode data_base64: %w", err)
+	}
+	if _, err := png.DecodeConfig(bytes.NewReader(data)); err != nil {
+		return "", nil, fmt.Errorf("data_base64 is not a PNG image: %w", err)
 	}
-	return string(output), nil
+	return mimeType, data, nil
+}
+
+func registerGetClipboardTool(server *sdkmcp.Server, clipboardService clipboard.Clipboard) {
+	sdkmcp.AddTool(server, &sdkmcp.Tool{
+		Name:        GetClipboardToolName,
+		Description: GetClipboardToolDescription,
+	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, args getClipboardArgs) (*sdkmcp.CallToolResult, any, error) {
+		mimeType, err := clipboard.NormalizeType(args.MimeType)
+		if err != nil {
+			return nil, nil, err
+		}
+		data, err := clipboardService.Read(ctx, mimeType)
+		if errors.Is(err, clipboard.ErrUnavailable) {
+			return nil, nil, unavailableClipboardError(ctx, clipboardService, mimeType)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("get clipboard: %w", err)
+		}
+		if mimeType != clipboard.TypePNG {
+			return tools.ToolResultFromText(string(data)), nil, nil
+		}
+
+		config, err := png.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return nil, nil, fmt.Errorf("decode clipboard image: %w", err)
+		}
+		result, err := tools.ToolResultFromJSONWithImage(map[string]any{
+			"mime_type": mimeType,
+			"width":     config.Width,
+			"height":    config.Height,
+			"bytes":     len(data),
+		}, data, mimeType)
+		if err != nil {
+			return nil, nil, fmt.Errorf("marshal result: %w", err)
+		}
+		return result, nil, nil
+	})
+}
+
+// unavailableClipboardError names the types the clipboard does offer so the caller can retry.
+func unavailableClipboardError(ctx context.Context, clipboardService clipboard.Clipboard, mimeType string) error {
+	types, err := clipboardService.Types(ctx)
+	if err != nil || len(types) == 0 {
+		return fmt.Errorf("clipboard has no %s content", mimeType)
+	}
+	return fmt.Errorf("clipboard has no %s content (available: %s)", mimeType, strings.Join(types, ", "))
+}
+
+func registerClearClipboardTool(server *sdkmcp.Server, clipboardService clipboard.Clipboard) {
+	sdkmcp.AddTool(server, &sdkmcp.Tool{
+		Name:        ClearClipboardToolName,
+		Description: ClearClipboardToolDescription,
+	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, _ emptyArgs) (*sdkmcp.CallToolResult, any, error) {
+		if err := clipboardService.Clear(ctx); err != nil {
+			return nil, nil, fmt.Errorf("clear clipboard: %w", err)
+		}
+		return tools.ToolResultFromText("Clipboard cleared"), nil, nil
+	})
+}
+
+func registerWaitForClipboardChangeTool(server *sdkmcp.Server, clipboardService clipboard.Clipboard, settings toolSettings) {
+	sdkmcp.AddTool(server, &sdkmcp.Tool{
+		Name:        WaitForClipboardChangeToolName,
+		Description: WaitForClipboardChangeToolDescription,
+	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, args waitForClipboardChangeArgs) (*sdkmcp.CallToolResult, any, error) {
+		timeoutMs, pollMs := settings.defaults.waitTimeoutAndPoll(args.TimeoutMs, args.PollIntervalMs)
+		if timeoutMs <= 0 {
+			timeoutMs = defaultClipboardWaitTimeoutMs
+		}
+		if pollMs <= 0 {
+			pollMs = defaultClipboardPollMs
+		}
+
+		initial, err := clipboard.Take(ctx, clipboardService)
+		if err != nil {
+			return nil, nil, fmt.Errorf("read clipboard: %w", err)
+		}
+		changed, err := clipboard.WaitForChange(ctx, clipboardService, initial,
+			time.Duration(timeoutMs)*time.Millisecond, time.Duration(pollMs)*time.Millisecond)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		response := map[string]any{
+			"changed": true,
+			"types":   changed.Types,
+		}
+		if len(changed.Types) > 0 && changed.Types[0] == clipboard.TypeText {
+			text, err := clipboardService.Read(ctx, clipboard.TypeText)
+			if err != nil && !errors.Is(err, clipboard.ErrUnavailable) {
+				return nil, nil, fmt.Errorf("get clipboard: %w", err)
+			}
+			response["text"] = string(text)
+		}
+		result, err := tools.ToolResultFromJSON(response)
+		if err != nil {
+			return nil, nil, fmt.Errorf("marshal result: %w", err)
+		}
+		return result, nil, nil
+	})
 }
@@ -0,0 +1,175 @@
+package mcpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/png"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/clipboard"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/tools"
+)
+
+// memoryClipboard holds one typed value, like a real clipboard after a single write.
+type memoryClipboard struct {
+	mu       sync.Mutex
+	mimeType string
+	data     []byte
+}
+
+func (c *memoryClipboard) Types(context.Context) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.mimeType == "" {
+		return nil, nil
+	}
+	return []string{c.mimeType}, nil
+}
+
+func (c *memoryClipboard) Read(_ context.Context, mimeType string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if mimeType != c.mimeType {
+		return nil, clipboard.ErrUnavailable
+	}
+	return append([]byte(nil), c.data...), nil
+}
+
+func (c *memoryClipboard) Write(_ context.Context, mimeType string, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mimeType, c.data = mimeType, append([]byte(nil), data...)
+	return nil
+}
+
+func (c *memoryClipboard) Clear(context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mimeType, c.data = "", nil
+	return nil
+}
+
+func newClipboardTestSession(t *testing.T, board clipboard.Clipboard) *sdkmcp.ClientSession {
+	t.Helper()
+	server := NewServer(nil, Config{WindowService: windowToolsService{}, InputService: &tools.InputService{}, Clipboard: board})
+	_, session := connectTestSession(t, server)
+	t.Cleanup(func() { _ = session.Close() })
+	return session
+}
+
+func callClipboardTool(t *testing.T, session *sdkmcp.ClientSession, name string, args map[string]any) *sdkmcp.CallToolResult {
+	t.Helper()
+	result, err := session.CallTool(context.Background(), &sdkmcp.CallToolParams{Name: name, Arguments: args})
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	return result
+}
+
+func TestClipboardTools_TextAndHTML(t *testing.T) {
+	board := &memoryClipboard{}
+	session := newClipboardTestSession(t, board)
+
+	if result := callClipboardTool(t, session, SetClipboardToolName, map[string]any{"text": "<b>hi</b>", "mime_type": "text/html; charset=utf-8"}); result.IsError {
+		t.Fatalf("set_clipboard failed: %+v", result.Content)
+	}
+	if board.mimeType != clipboard.TypeHTML || string(board.data) != "<b>hi</b>" {
+		t.Fatalf("clipboard = %s %q, want html", board.mimeType, board.data)
+	}
+
+	result := callClipboardTool(t, session, GetClipboardToolName, map[string]any{"mime_type": "text/html"})
+	if result.IsError || result.Content[0].(*sdkmcp.TextContent).Text != "<b>hi</b>" {
+		t.Fatalf("get_clipboard html = %+v", result.Content)
+	}
+
+	result = callClipboardTool(t, session, GetClipboardToolName, nil)
+	if !result.IsError {
+		t.Fatal("expected get_clipboard text/plain to fail when only html is offered")
+	}
+	if text := result.Content[0].(*sdkmcp.TextContent).Text; !strings.Contains(text, "available: text/html") {
+		t.Fatalf("error should list the offered types, got %q", text)
+	}
+
+	if result := callClipboardTool(t, session, SetClipboardToolName, map[string]any{"text": "x", "mime_type": "application/pdf"}); !result.IsError {
+		t.Fatal("expected an unsupported mime type to be rejected")
+	}
+}
+
+func TestClipboardTools_PNG(t *testing.T) {
+	board := &memoryClipboard{}
+	session := newClipboardTestSession(t, board)
+
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, image.NewRGBA(image.Rect(0, 0, 3, 2))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	args := map[string]any{"mime_type": clipboard.TypePNG, "data_base64": base64.StdEncoding.EncodeToString(encoded.Bytes())}
+	if result := callClipboardTool(t, session, SetClipboardToolName, args); result.IsError {
+		t.Fatalf("set_clipboard png failed: %+v", result.Content)
+	}
+
+	result := callClipboardTool(t, session, GetClipboardToolName, map[string]any{"mime_type": clipboard.TypePNG})
+	if result.IsError || len(result.Content) != 2 {
+		t.Fatalf("get_clipboard png = %+v", result.Content)
+	}
+	var meta map[string]any
+	if err := json.Unmarshal([]byte(result.Content[0].(*sdkmcp.TextContent).Text), &meta); err != nil {
+		t.Fatalf("decode metadata: %v", err)
+	}
+	if meta["width"] != float64(3) || meta["height"] != float64(2) {
+		t.Fatalf("metadata = %v", meta)
+	}
+	img := result.Content[1].(*sdkmcp.ImageContent)
+	if img.MIMEType != clipboard.TypePNG || !bytes.Equal(img.Data, encoded.Bytes()) {
+		t.Fatal("image content does not round-trip")
+	}
+
+	bad := map[string]any{"mime_type": clipboard.TypePNG, "data_base64": base64.StdEncoding.EncodeToString([]byte("not a png"))}
+	if result := callClipboardTool(t, session, SetClipboardToolName, bad); !result.IsError {
+		t.Fatal("expected non-PNG data to be rejected")
+	}
+	if result := callClipboardTool(t, session, SetClipboardToolName, map[string]any{"mime_type": clipboard.TypePNG, "text": "x"}); !result.IsError {
+		t.Fatal("expected text with image/png to be rejected")
+	}
+}
+
+func TestClipboardTools_ClearAndWaitForChange(t *testing.T) {
+	board := &memoryClipboard{mimeType: clipboard.TypeText, data: []byte("before")}
+	session := newClipboardTestSession(t, board)
+
+	if result := callClipboardTool(t, session, ClearClipboardToolName, nil); result.IsError {
+		t.Fatalf("clear_clipboard failed: %+v", result.Content)
+	}
+	if types, _ := board.Types(context.Background()); len(types) != 0 {
+		t.Fatalf("clipboard still offers %v after clear", types)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = board.Write(context.Background(), clipboard.TypeText, []byte("after"))
+	}()
+	result := callClipboardTool(t, session, WaitForClipboardChangeToolName, map[string]any{"timeout_ms": 2000, "poll_interval_ms": 10})
+	if result.IsError {
+		t.Fatalf("wait_for_clipboard_change failed: %+v", result.Content)
+	}
+	var changed map[string]any
+	if err := json.Unmarshal([]byte(result.Content[0].(*sdkmcp.TextContent).Text), &changed); err != nil {
+		t.Fatalf("decode result: %v", err)
+	}
+	if changed["text"] != "after" {
+		t.Fatalf("result = %v, want the new text", changed)
+	}
+
+	result = callClipboardTool(t, session, WaitForClipboardChangeToolName, map[string]any{"timeout_ms": 50, "poll_interval_ms": 10})
+	if !result.IsError {
+		t.Fatal("expected wait_for_clipboard_change to time out when nothing changes")
+	}
+}
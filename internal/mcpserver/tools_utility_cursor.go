@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/brainwhocodes/screenshot_mcp_server/internal/safeexec"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/screenshot"
 	"github.com/brainwhocodes/screenshot_mcp_server/internal/tools"
 )
 
@@ -29,6 +30,9 @@ func takeScreenshotWithCursor(ctx context.Context, service ScreenshotService) ([
 }
 
 func captureScreenshotWithCursorTool(ctx context.Context) ([]byte, bool, error) {
+	if !screenshot.SystemBackendActive() {
+		return nil, false, fmt.Errorf("cursor capture reads the live screen, not the replay backend")
+	}
 	if _, err := exec.LookPath("screencapture"); err != nil {
 		return nil, false, fmt.Errorf("screencapture unavailable: %w", err)
 	}
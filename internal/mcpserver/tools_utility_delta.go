@@ -0,0 +1,224 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/draw"
+	"slices"
+	"sync"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/delta"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/imgencode"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/tools"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/window"
+)
+
+const (
+	minDeltaTileSize = 16
+	maxDeltaTileSize = 1024
+	// maxDeltaBaselines bounds the frames one session keeps; the least recently
+	// used target is forgotten first and gets a full frame on its next capture.
+	maxDeltaBaselines = 8
+	// deltaFullFrameRatio is the changed share above which one full frame is
+	// cheaper to send than its tiles.
+	deltaFullFrameRatio = 0.5
+)
+
+// Delta capture statuses.
+const (
+	deltaStatusUnchanged = "unchanged"
+	deltaStatusDelta     = "delta"
+	deltaStatusFull      = "full"
+)
+
+// deltaBaselines keeps the last frame each take_screenshot_delta target
+// returned, keyed by target.
+type deltaBaselines struct {
+	mu     sync.Mutex
+	frames map[string]*image.RGBA
+	// order lists keys from least to most recently used.
+	order []string
+}
+
+func newDeltaBaselines() *deltaBaselines {
+	return &deltaBaselines{frames: make(map[string]*image.RGBA)}
+}
+
+func (b *deltaBaselines) get(key string) *image.RGBA {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.frames[key]
+}
+
+func (b *deltaBaselines) put(key string, frame *image.RGBA) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if i := slices.Index(b.order, key); i >= 0 {
+		b.order = slices.Delete(b.order, i, i+1)
+	}
+	b.order = append(b.order, key)
+	b.frames[key] = frame
+	if len(b.order) > maxDeltaBaselines {
+		delete(b.frames, b.order[0])
+		b.order = b.order[1:]
+	}
+}
+
+type deltaTile struct {
+	X        int                 `json:"x"`
+	Y        int                 `json:"y"`
+	Width    int                 `json:"width"`
+	Height   int                 `json:"height"`
+	Encoding *imgencode.Encoding `json:"encoding"`
+}
+
+type deltaScreenshotResult struct {
+	Status string `json:"status"`
+	// Reason explains why a full frame was sent.
+	Reason      string  `json:"reason,omitempty"`
+	Width       int     `json:"width"`
+	Height      int     `json:"height"`
+	TileSize    int     `json:"tile_size"`
+	ChangeRatio float64 `json:"change_ratio"`
+	// Tiles lists the images that follow, in order, with their offsets in the frame.
+	Tiles      []deltaTile                `json:"tiles,omitempty"`
+	Window     *window.ScreenshotMetadata `json:"window,omitempty"`
+	Display    *int                       `json:"display,omitempty"`
+	Redactions *int                       `json:"redactions,omitempty"`
+}
+
+func registerTakeScreenshotDeltaTool(server *sdkmcp.Server, service ScreenshotService, windowService WindowService, sessions *sessionStore, settings toolSettings) {
+	sdkmcp.AddTool(server, &sdkmcp.Tool{
+		Name:        TakeScreenshotDeltaToolName,
+		Description: TakeScreenshotDeltaToolDescription,
+	}, func(ctx context.Context, req *sdkmcp.CallToolRequest, args takeScreenshotDeltaArgs) (*sdkmcp.CallToolResult, any, error) {
+		if err := ensureCapturePermissions(ctx, windowService, TakeScreenshotDeltaToolName, args.WindowID); err != nil {
+			return nil, nil, err
+		}
+		ctx = args.captureContext(ctx)
+		if args.WindowID != 0 && args.Display != nil {
+			return nil, nil, fmt.Errorf("window_id and display are mutually exclusive")
+		}
+		if args.TileSize == 0 {
+			args.TileSize = delta.DefaultTileSize
+		}
+		if args.TileSize < minDeltaTileSize || args.TileSize > maxDeltaTileSize {
+			return nil, nil, fmt.Errorf("tile_size must be between %d and %d", minDeltaTileSize, maxDeltaTileSize)
+		}
+		if args.Tolerance < 0 || args.Tolerance > 255 {
+			return nil, nil, fmt.Errorf("tolerance must be between 0 and 255")
+		}
+		// Tile offsets are captured-image pixels, so tiles are never downscaled.
+		if args.MaxWidth != 0 || args.MaxHeight != 0 {
+			return nil, nil, fmt.Errorf("max_width and max_height are not supported by %s", TakeScreenshotDeltaToolName)
+		}
+		out, err := args.output(settings.encoding)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		res := deltaScreenshotResult{TileSize: args.TileSize, Display: args.Display}
+		var img image.Image
+		var key string
+		switch {
+		case args.WindowID != 0:
+			windowImg, metadata, err := windowService.TakeWindowScreenshotImage(ctx, args.WindowID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("take window screenshot: %w", err)
+			}
+			if img, res.Redactions, err = settings.redactor.window(ctx, windowImg, metadata); err != nil {
+				return nil, nil, err
+			}
+			res.Window = metadata
+			key = fmt.Sprintf("window:%d", args.WindowID)
+		default:
+			if args.Display != nil {
+				img, err = captureDisplay(ctx, service, *args.Display)
+				key = fmt.Sprintf("display:%d", *args.Display)
+			} else {
+				img, err = service.CaptureImage(ctx)
+				key = "screen"
+			}
+			if err != nil {
+				return nil, nil, fmt.Errorf("capture screenshot: %w", err)
+			}
+			if img, res.Redactions, err = settings.redactor.screenshot(ctx, img, args.Display); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		frame := delta.ToRGBA(img)
+		res.Width, res.Height = frame.Rect.Dx(), frame.Rect.Dy()
+		baselines := sessions.forRequest(req).baselines
+		previous := baselines.get(key)
+
+		var rects []image.Rectangle
+		switch {
+		case args.Reset:
+			res.Reason, res.ChangeRatio = "reset requested", 1
+		case previous == nil:
+			res.Reason, res.ChangeRatio = "no previous capture of this target", 1
+		case previous.Rect.Size() != frame.Rect.Size():
+			res.Reason, res.ChangeRatio = "frame size changed", 1
+		default:
+			diff, err := delta.Compare(previous, frame, args.TileSize, args.Tolerance)
+			if err != nil {
+				return nil, nil, fmt.Errorf("compare frames: %w", err)
+			}
+			res.ChangeRatio = diff.Ratio
+			if diff.Ratio > deltaFullFrameRatio {
+				res.Reason = fmt.Sprintf("more than %.0f%% of the frame changed", deltaFullFrameRatio*100)
+			} else {
+				rects = diff.Rects
+			}
+		}
+		switch {
+		case res.Reason != "":
+			res.Status = deltaStatusFull
+			rects = []image.Rectangle{frame.Rect}
+		case len(rects) == 0:
+			res.Status = deltaStatusUnchanged
+		default:
+			res.Status = deltaStatusDelta
+		}
+
+		images := make([][]byte, 0, len(rects))
+		for _, rect := range rects {
+			data, encoding, err := imgencode.Encode(frame.SubImage(rect), out)
+			if err != nil {
+				return nil, nil, fmt.Errorf("encode tile: %w", err)
+			}
+			images = append(images, data)
+			res.Tiles = append(res.Tiles, deltaTile{X: rect.Min.X, Y: rect.Min.Y, Width: rect.Dx(), Height: rect.Dy(), Encoding: encoding})
+		}
+		result, err := tools.ToolResultFromJSON(res)
+		if err != nil {
+			return nil, nil, fmt.Errorf("marshal metadata: %w", err)
+		}
+		for i, data := range images {
+			result.Content = append(result.Content, &sdkmcp.ImageContent{Data: data, MIMEType: res.Tiles[i].Encoding.MimeType})
+		}
+		baselines.put(key, nextBaseline(previous, frame, res.Status, rects))
+		return result, nil, nil
+	})
+}
+
+// nextBaseline returns the frame the caller now holds: the previous baseline
+// with only the returned tiles replaced. Changes under tolerance stay out of it
+// so that they keep adding up until a later capture reports them.
+func nextBaseline(previous, frame *image.RGBA, status string, rects []image.Rectangle) *image.RGBA {
+	switch status {
+	case deltaStatusFull:
+		return frame
+	case deltaStatusUnchanged:
+		return previous
+	}
+	baseline := delta.ToRGBA(previous)
+	for _, rect := range rects {
+		draw.Draw(baseline, rect, frame, rect.Min, draw.Src)
+	}
+	return baseline
+}
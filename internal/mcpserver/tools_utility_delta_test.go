@@ -0,0 +1,149 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"image"
+	"image/color"
+	"sync/atomic"
+	"testing"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/tools"
+)
+
+var blockColor = color.RGBA{R: 200, G: 30, B: 30, A: 255}
+
+// changingScreenService returns a black 128x64 screen that shows a 10x10 block
+// at 70,5 once changed is set.
+type changingScreenService struct {
+	solidScreenshotService
+	changed atomic.Bool
+}
+
+func (s *changingScreenService) CaptureImage(context.Context) (image.Image, error) {
+	img := image.NewRGBA(image.Rect(0, 0, 128, 64))
+	if s.changed.Load() {
+		for y := 5; y < 15; y++ {
+			for x := 70; x < 80; x++ {
+				img.SetRGBA(x, y, blockColor)
+			}
+		}
+	}
+	return img, nil
+}
+
+// shadedScreenService returns a black 128x64 screen with a gray 10x10 block at
+// 70,5 whose level is shade.
+type shadedScreenService struct {
+	solidScreenshotService
+	shade atomic.Uint32
+}
+
+func (s *shadedScreenService) CaptureImage(context.Context) (image.Image, error) {
+	img := image.NewRGBA(image.Rect(0, 0, 128, 64))
+	level := uint8(s.shade.Load())
+	for y := 5; y < 15; y++ {
+		for x := 70; x < 80; x++ {
+			img.SetRGBA(x, y, color.RGBA{R: level, G: level, B: level, A: 255})
+		}
+	}
+	return img, nil
+}
+
+func callDelta(t *testing.T, session *sdkmcp.ClientSession, args map[string]any) (*sdkmcp.CallToolResult, deltaScreenshotResult) {
+	t.Helper()
+	result, err := session.CallTool(context.Background(), &sdkmcp.CallToolParams{Name: TakeScreenshotDeltaToolName, Arguments: args})
+	if err != nil || result.IsError {
+		t.Fatalf("take_screenshot_delta: %v %+v", err, result)
+	}
+	var metadata deltaScreenshotResult
+	if err := json.Unmarshal([]byte(result.Content[0].(*sdkmcp.TextContent).Text), &metadata); err != nil {
+		t.Fatalf("decode metadata: %v", err)
+	}
+	if len(result.Content) != 1+len(metadata.Tiles) {
+		t.Fatalf("got %d content items for %d tiles", len(result.Content), len(metadata.Tiles))
+	}
+	return result, metadata
+}
+
+func TestTakeScreenshotDeltaReturnsChangedTiles(t *testing.T) {
+	service := &changingScreenService{}
+	server := NewServer(service, Config{WindowService: windowToolsService{}, InputService: &tools.InputService{}})
+	_, session := connectTestSession(t, server)
+	t.Cleanup(func() { _ = session.Close() })
+	args := map[string]any{"tile_size": 32, "format": "png"}
+
+	_, metadata := callDelta(t, session, args)
+	if metadata.Status != deltaStatusFull || metadata.ChangeRatio != 1 || len(metadata.Tiles) != 1 || metadata.Tiles[0].Width != 128 {
+		t.Fatalf("first capture = %+v, want the full frame", metadata)
+	}
+	if _, metadata = callDelta(t, session, args); metadata.Status != deltaStatusUnchanged || metadata.ChangeRatio != 0 {
+		t.Fatalf("second capture = %+v, want unchanged", metadata)
+	}
+
+	service.changed.Store(true)
+	result, metadata := callDelta(t, session, args)
+	if metadata.Status != deltaStatusDelta || metadata.ChangeRatio != 0.125 || len(metadata.Tiles) != 1 {
+		t.Fatalf("capture after the change = %+v, want one of eight tiles", metadata)
+	}
+	if tile := metadata.Tiles[0]; tile.X != 64 || tile.Y != 0 || tile.Width != 32 || tile.Height != 32 {
+		t.Fatalf("tile = %+v, want 64,0 32x32", tile)
+	}
+	if got := decodePNGContent(t, result).RGBAAt(70-64, 5); got != blockColor {
+		t.Fatalf("tile pixel = %v, want the block", got)
+	}
+
+	if _, metadata = callDelta(t, session, map[string]any{"reset": true}); metadata.Status != deltaStatusFull {
+		t.Fatalf("reset capture = %+v, want the full frame", metadata)
+	}
+
+	// Baselines belong to the session that took them.
+	_, other := connectTestSession(t, server)
+	t.Cleanup(func() { _ = other.Close() })
+	if _, metadata = callDelta(t, other, args); metadata.Status != deltaStatusFull {
+		t.Fatalf("other session = %+v, want its own full first frame", metadata)
+	}
+}
+
+func TestTakeScreenshotDeltaAccumulatesChangesUnderTolerance(t *testing.T) {
+	service := &shadedScreenService{}
+	server := NewServer(service, Config{WindowService: windowToolsService{}, InputService: &tools.InputService{}})
+	_, session := connectTestSession(t, server)
+	t.Cleanup(func() { _ = session.Close() })
+	args := map[string]any{"tile_size": 32, "tolerance": 15, "format": "png"}
+
+	if _, metadata := callDelta(t, session, args); metadata.Status != deltaStatusFull {
+		t.Fatalf("first capture = %+v, want the full frame", metadata)
+	}
+	service.shade.Store(10)
+	if _, metadata := callDelta(t, session, args); metadata.Status != deltaStatusUnchanged {
+		t.Fatalf("capture after a change under tolerance = %+v, want unchanged", metadata)
+	}
+	// Each step is under tolerance, but the caller still holds the black block.
+	service.shade.Store(20)
+	result, metadata := callDelta(t, session, args)
+	if metadata.Status != deltaStatusDelta || len(metadata.Tiles) != 1 {
+		t.Fatalf("capture after the second change = %+v, want the block's tile", metadata)
+	}
+	if got := decodePNGContent(t, result).RGBAAt(70-64, 5); got.R != 20 {
+		t.Fatalf("tile pixel = %v, want level 20", got)
+	}
+	if _, metadata = callDelta(t, session, args); metadata.Status != deltaStatusUnchanged {
+		t.Fatalf("capture after the reported change = %+v, want unchanged", metadata)
+	}
+}
+
+func TestDeltaBaselinesForgetLeastRecentlyUsed(t *testing.T) {
+	baselines := newDeltaBaselines()
+	frame := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	baselines.put("screen", frame)
+	for i := 0; i < maxDeltaBaselines; i++ {
+		baselines.put("screen", frame)
+		baselines.put(string(rune('a'+i)), frame)
+	}
+	if baselines.get("screen") == nil || baselines.get("a") != nil {
+		t.Fatal("expected the oldest unused target, not the one in use, to be forgotten")
+	}
+}
@@ -0,0 +1,95 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"math"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/screenshot"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/tools"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/window"
+)
+
+// displayInfo is one list_displays entry. Bounds are in screen points, the space
+// click_screen and region screenshots use; pixel_bounds are the same rectangle in
+// device pixels.
+type displayInfo struct {
+	Index       int           `json:"index"`
+	Primary     bool          `json:"primary"`
+	Scale       float64       `json:"scale"`
+	Bounds      window.Bounds `json:"bounds"`
+	PixelBounds pixelBounds   `json:"pixel_bounds"`
+}
+
+type pixelBounds struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// displayScale is the backing scale lookup; tests replace it to simulate Retina displays.
+var displayScale = window.DisplayScale
+
+func registerListDisplaysTool(server *sdkmcp.Server, service ScreenshotService) {
+	sdkmcp.AddTool(server, &sdkmcp.Tool{
+		Name:        ListDisplaysToolName,
+		Description: ListDisplaysToolDescription,
+	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, _ listDisplaysArgs) (*sdkmcp.CallToolResult, any, error) {
+		displays, err := service.ListDisplays(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("list displays: %w", err)
+		}
+
+		infos := make([]displayInfo, 0, len(displays))
+		for _, display := range displays {
+			infos = append(infos, describeDisplay(display))
+		}
+		result, err := tools.ToolResultFromJSON(map[string]any{
+			"displays": infos,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("marshal displays: %w", err)
+		}
+		return result, nil, nil
+	})
+}
+
+// describeDisplay converts backend bounds, which are points on macOS and pixels
+// elsewhere (where the scale is 1), into both coordinate spaces.
+func describeDisplay(display screenshot.Display) displayInfo {
+	bounds := display.Bounds
+	center := bounds.Min.Add(bounds.Max).Div(2)
+	scale := displayScale(float64(center.X), float64(center.Y))
+	toPixels := func(v int) int {
+		return int(math.Round(float64(v) * scale))
+	}
+	return displayInfo{
+		Index:   display.Index,
+		Primary: display.Primary,
+		Scale:   scale,
+		Bounds: window.Bounds{
+			X:      float64(bounds.Min.X),
+			Y:      float64(bounds.Min.Y),
+			Width:  float64(bounds.Dx()),
+			Height: float64(bounds.Dy()),
+		},
+		PixelBounds: pixelBounds{
+			X:      toPixels(bounds.Min.X),
+			Y:      toPixels(bounds.Min.Y),
+			Width:  toPixels(bounds.Dx()),
+			Height: toPixels(bounds.Dy()),
+		},
+	}
+}
+
+// captureDisplay captures a single display selected by its list_displays index.
+func captureDisplay(ctx context.Context, service ScreenshotService, index int) (image.Image, error) {
+	if index < 0 {
+		return nil, fmt.Errorf("display must be a non-negative index from list_displays, got %d", index)
+	}
+	return service.CaptureDisplayImage(ctx, index)
+}
@@ -0,0 +1,241 @@
+package mcpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/screenshot"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/tools"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/window"
+)
+
+// dualDisplayService has a 40x30 primary display and a 20x10 display to its right,
+// each filled with its own color.
+type dualDisplayService struct {
+	solidScreenshotService
+}
+
+var dualDisplays = []screenshot.Display{
+	{Index: 0, Bounds: image.Rect(0, 0, 40, 30), Primary: true},
+	{Index: 1, Bounds: image.Rect(40, 0, 60, 10)},
+}
+
+var dualDisplayColors = []color.RGBA{{R: 255, A: 255}, {B: 255, A: 255}}
+
+func (dualDisplayService) ListDisplays(context.Context) ([]screenshot.Display, error) {
+	return dualDisplays, nil
+}
+
+func (dualDisplayService) CaptureDisplayImage(_ context.Context, index int) (image.Image, error) {
+	if index >= len(dualDisplays) {
+		return nil, fmt.Errorf("display %d not found", index)
+	}
+	bounds := dualDisplays[index].Bounds
+	img := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	for i := 0; i < len(img.Pix); i += 4 {
+		c := dualDisplayColors[index]
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return img, nil
+}
+
+func newDisplayTestSession(t *testing.T) *sdkmcp.ClientSession {
+	t.Helper()
+	server := NewServer(dualDisplayService{}, Config{WindowService: windowToolsService{}, InputService: &tools.InputService{}})
+	_, session := connectTestSession(t, server)
+	t.Cleanup(func() { _ = session.Close() })
+	return session
+}
+
+func TestListDisplaysTool(t *testing.T) {
+	previous := displayScale
+	displayScale = func(x, _ float64) float64 {
+		if x < 40 {
+			return 2
+		}
+		return 1
+	}
+	t.Cleanup(func() { displayScale = previous })
+
+	session := newDisplayTestSession(t)
+	result, err := session.CallTool(context.Background(), &sdkmcp.CallToolParams{Name: ListDisplaysToolName})
+	if err != nil || result.IsError {
+		t.Fatalf("list_displays: %v %+v", err, result)
+	}
+	var listed struct {
+		Displays []displayInfo `json:"displays"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(*sdkmcp.TextContent).Text), &listed); err != nil {
+		t.Fatalf("decode displays: %v", err)
+	}
+	if len(listed.Displays) != 2 {
+		t.Fatalf("displays = %+v, want 2", listed.Displays)
+	}
+	primary := listed.Displays[0]
+	if !primary.Primary || primary.Scale != 2 || primary.Bounds.Width != 40 || primary.PixelBounds.Width != 80 || primary.PixelBounds.Height != 60 {
+		t.Fatalf("primary display = %+v", primary)
+	}
+	secondary := listed.Displays[1]
+	if secondary.Primary || secondary.Index != 1 || secondary.Bounds.X != 40 || secondary.PixelBounds.X != 40 {
+		t.Fatalf("secondary display = %+v", secondary)
+	}
+}
+
+func TestTakeScreenshotPNGCapturesOneDisplay(t *testing.T) {
+	session := newDisplayTestSession(t)
+	result, err := session.CallTool(context.Background(), &sdkmcp.CallToolParams{
+		Name:      TakeScreenshotPNGToolName,
+		Arguments: map[string]any{"display": 1},
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("take_screenshot_png: %v %+v", err, result)
+	}
+	img, err := png.Decode(bytes.NewReader(result.Content[1].(*sdkmcp.ImageContent).Data))
+	if err != nil {
+		t.Fatalf("decode png: %v", err)
+	}
+	if img.Bounds().Dx() != 20 || img.Bounds().Dy() != 10 {
+		t.Fatalf("image size = %v, want 20x10", img.Bounds())
+	}
+	if r, _, b, _ := img.At(0, 0).RGBA(); r != 0 || b == 0 {
+		t.Fatalf("captured the wrong display: pixel = %v", img.At(0, 0))
+	}
+
+	for _, display := range []int{-1, 2} {
+		result, err := session.CallTool(context.Background(), &sdkmcp.CallToolParams{
+			Name:      ToolName,
+			Arguments: map[string]any{"display": display},
+		})
+		if err != nil {
+			t.Fatalf("take_screenshot display %d: %v", display, err)
+		}
+		if !result.IsError {
+			t.Fatalf("expected display %d to be rejected", display)
+		}
+	}
+}
+
+func TestScreenshotHashPerDisplay(t *testing.T) {
+	session := newDisplayTestSession(t)
+	hashOf := func(args map[string]any) string {
+		t.Helper()
+		result, err := session.CallTool(context.Background(), &sdkmcp.CallToolParams{Name: ScreenshotHashToolName, Arguments: args})
+		if err != nil || result.IsError {
+			t.Fatalf("screenshot_hash %v: %v %+v", args, err, result)
+		}
+		var decoded map[string]any
+		if err := json.Unmarshal([]byte(result.Content[0].(*sdkmcp.TextContent).Text), &decoded); err != nil {
+			t.Fatalf("decode hash: %v", err)
+		}
+		return decoded["hash"].(string)
+	}
+
+	args := map[string]any{"algorithm": "sha256", "display": 0}
+	if hashOf(args) == hashOf(map[string]any{"algorithm": "sha256", "display": 1}) {
+		t.Fatal("expected different displays to hash differently")
+	}
+
+	result, err := session.CallTool(context.Background(), &sdkmcp.CallToolParams{
+		Name:      ScreenshotHashToolName,
+		Arguments: map[string]any{"target": "window", "window_id": 7, "display": 0},
+	})
+	if err != nil {
+		t.Fatalf("screenshot_hash: %v", err)
+	}
+	if !result.IsError {
+		t.Fatal("expected display with target window to be rejected")
+	}
+}
+
+func TestReplayBackendDrivesScreenshotTools(t *testing.T) {
+	installReplayFrames(t)
+
+	server := NewServer(nil, Config{WindowService: windowToolsService{}, InputService: &tools.InputService{}})
+	_, session := connectTestSession(t, server)
+	t.Cleanup(func() { _ = session.Close() })
+
+	for i, want := range dualDisplayColors {
+		result, err := session.CallTool(context.Background(), &sdkmcp.CallToolParams{Name: TakeScreenshotPNGToolName})
+		if err != nil || result.IsError {
+			t.Fatalf("take_screenshot_png: %v %+v", err, result)
+		}
+		img, err := png.Decode(bytes.NewReader(result.Content[1].(*sdkmcp.ImageContent).Data))
+		if err != nil {
+			t.Fatalf("decode png: %v", err)
+		}
+		if got := color.RGBAModel.Convert(img.At(0, 0)); got != want || img.Bounds().Dx() != 8 {
+			t.Fatalf("capture %d = %v at %v, want frame %d", i, got, img.Bounds(), i)
+		}
+	}
+}
+
+func TestReplayBackendSkipsDisplayProbe(t *testing.T) {
+	installReplayFrames(t)
+	t.Setenv("DISPLAY", "")
+
+	// Mirrors a host with Xvfb installed but no DISPLAY: the probe itself fails.
+	probe := auditWindowService{permissionErr: &window.CapabilityError{
+		ToolName:   ToolName,
+		Capability: "an X11 display",
+		Reason:     "DISPLAY is not set",
+	}}
+	server := NewServer(nil, Config{WindowService: probe, InputService: &tools.InputService{}})
+	_, session := connectTestSession(t, server)
+	t.Cleanup(func() { _ = session.Close() })
+
+	result, err := session.CallTool(context.Background(), &sdkmcp.CallToolParams{Name: ToolName})
+	if err != nil || result.IsError {
+		t.Fatalf("take_screenshot under replay: %v %+v", err, result)
+	}
+	result, err = session.CallTool(context.Background(), &sdkmcp.CallToolParams{
+		Name:      FocusWindowToolName,
+		Arguments: map[string]any{"window_id": 1},
+	})
+	if err != nil || !result.IsError {
+		t.Fatalf("focus_window should still probe the display, got %v %+v", err, result)
+	}
+	// A window capture opens the display even under replay.
+	result, err = session.CallTool(context.Background(), &sdkmcp.CallToolParams{
+		Name:      FindImageMatchesToolName,
+		Arguments: map[string]any{"window_id": 1, "template_image": "unused.png"},
+	})
+	if err != nil || !result.IsError || !strings.Contains(result.Content[0].(*sdkmcp.TextContent).Text, "DISPLAY is not set") {
+		t.Fatalf("find_image_matches on a window should still probe the display, got %v %+v", err, result)
+	}
+}
+
+// installReplayFrames replays one solid frame per dualDisplayColors entry for the rest of the test.
+func installReplayFrames(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	for i, c := range dualDisplayColors {
+		img := image.NewRGBA(image.Rect(0, 0, 8, 6))
+		for p := 0; p < len(img.Pix); p += 4 {
+			img.Pix[p], img.Pix[p+1], img.Pix[p+2], img.Pix[p+3] = c.R, c.G, c.B, c.A
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			t.Fatalf("encode frame: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("%02d.png", i)), buf.Bytes(), 0o600); err != nil {
+			t.Fatalf("write frame: %v", err)
+		}
+	}
+	replay, err := screenshot.LoadReplay(screenshot.ReplayOptions{Source: dir})
+	if err != nil {
+		t.Fatalf("LoadReplay: %v", err)
+	}
+	screenshot.SetBackend(replay)
+	t.Cleanup(func() { screenshot.SetBackend(nil) })
+}
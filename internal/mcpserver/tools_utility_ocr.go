@@ -7,6 +7,7 @@ import (
 	"image/png"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 	"time"
 
@@ -87,9 +88,67 @@ func captureImageForOCR(ctx context.Context, service ScreenshotService, windowID
 }
 
 func runOCR(ctx context.Context, img image.Image) (string, error) {
+	output, err := runTesseract(ctx, img)
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// ocrWord is a word tesseract recognized, in image pixel coordinates.
+type ocrWord struct {
+	Text                     string
+	Left, Top, Width, Height int
+	// Line identifies the word's text line as "block.paragraph.line".
+	Line string
+}
+
+// runOCRWords returns every recognized word with its bounding box.
+func runOCRWords(ctx context.Context, img image.Image) ([]ocrWord, error) {
+	output, err := runTesseract(ctx, img, "tsv")
+	if err != nil {
+		return nil, err
+	}
+	return parseTesseractTSV(string(output)), nil
+}
+
+// parseTesseractTSV reads the word rows (level 5) of tesseract's TSV output:
+// level, page, block, paragraph, line, word, left, top, width, height, conf, text.
+func parseTesseractTSV(output string) []ocrWord {
+	var words []ocrWord
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(strings.TrimRight(line, "\r"), "\t")
+		if len(fields) < 12 || fields[0] != "5" {
+			continue
+		}
+		text := strings.TrimSpace(fields[11])
+		if text == "" {
+			continue
+		}
+		var box [4]int
+		valid := true
+		for i := range box {
+			value, err := strconv.Atoi(fields[6+i])
+			if err != nil {
+				valid = false
+				break
+			}
+			box[i] = value
+		}
+		if valid && box[2] > 0 && box[3] > 0 {
+			line := strings.Join(fields[2:5], ".")
+			words = append(words, ocrWord{Text: text, Line: line, Left: box[0], Top: box[1], Width: box[2], Height: box[3]})
+		}
+	}
+	return words
+}
+
+// runTesseract writes img to a temp PNG and returns tesseract's stdout. Extra
+// arguments select an output config such as "tsv".
+func runTesseract(ctx context.Context, img image.Image, configs ...string) ([]byte, error) {
 	tmp, err := os.CreateTemp("", "wait-for-text-*.png")
 	if err != nil {
-		return "", fmt.Errorf("create temp image: %w", err)
+		return nil, fmt.Errorf("create temp image: %w", err)
 	}
 	defer func() {
 		_ = os.Remove(tmp.Name())
@@ -99,17 +158,18 @@ func runOCR(ctx context.Context, img image.Image) (string, error) {
 	}()
 
 	if err := png.Encode(tmp, img); err != nil {
-		return "", fmt.Errorf("encode image for OCR: %w", err)
+		return nil, fmt.Errorf("encode image for OCR: %w", err)
 	}
 	if _, err := tmp.Seek(0, 0); err != nil {
-		return "", fmt.Errorf("reset temp image: %w", err)
+		return nil, fmt.Errorf("reset temp image: %w", err)
 	}
 
-	output, err := safeexec.RunCommandWithTimeout(ctx, 10*time.Second, "tesseract", tmp.Name(), "stdout")
+	args := append([]string{tmp.Name(), "stdout"}, configs...)
+	output, err := safeexec.RunCommandWithTimeout(ctx, 10*time.Second, "tesseract", args...)
 	if err != nil {
-		return "", fmt.Errorf("tesseract command failed: %w", err)
+		return nil, fmt.Errorf("tesseract command failed: %w", err)
 	}
-	return string(output), nil
+	return output, nil
 }
 
 func normalizeTextForMatch(value string) string {
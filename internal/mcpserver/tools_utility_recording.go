@@ -18,17 +18,22 @@ import (
 	"time"
 
 	"github.com/brainwhocodes/screenshot_mcp_server/internal/safeexec"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/screenshot"
 	"github.com/brainwhocodes/screenshot_mcp_server/internal/tools"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/virtualdisplay"
 	"github.com/brainwhocodes/screenshot_mcp_server/internal/window"
 )
 
 type recordingState struct {
+	// ctx bounds the lifetime of background recordings; it is canceled when the owning session ends.
+	ctx    context.Context
 	mu     sync.Mutex
 	active map[string]*recordingSession
 }
 
-func newRecordingState() *recordingState {
+func newRecordingState(ctx context.Context) *recordingState {
 	return &recordingState{
+		ctx:    ctx,
 		active: make(map[string]*recordingSession),
 	}
 }
@@ -41,14 +46,25 @@ type recordingSession struct {
 	outputPath      string
 	frameDir        string
 	service         ScreenshotService
+	redactor        *redactor
 	done            chan struct{}
 	cancel          context.CancelFunc
 	recordingError  error
 	recordingFrames int
 	frameFiles      []string
+	// redactions totals the regions redacted across all frames.
+	redactions int
 }
 
-func (state *recordingState) start(ctx context.Context, service ScreenshotService, windowID uint32, fps int, format string) (string, error) {
+// recordingOutput describes a finished recording.
+type recordingOutput struct {
+	path    string
+	warning string
+	// redactions is nil when no redaction rules are configured.
+	redactions *int
+}
+
+func (state *recordingState) start(ctx context.Context, service ScreenshotService, redactor *redactor, artifactPrefix string, windowID uint32, fps int, format string) (string, error) {
 	if err := ctx.Err(); err != nil {
 		return "", fmt.Errorf("start recording: %w", err)
 	}
@@ -71,9 +87,16 @@ func (state *recordingState) start(ctx context.Context, service ScreenshotServic
 	if err != nil {
 		return "", fmt.Errorf("create recording directory: %w", err)
 	}
-	outputPath := filepath.Join(os.TempDir(), fmt.Sprintf("%s.%s", recordingID, format))
+	outputPath, err := ArtifactPath(artifactPrefix+"-"+recordingID, format)
+	if err != nil {
+		_ = os.RemoveAll(frameDir)
+		return "", fmt.Errorf("resolve recording path: %w", err)
+	}
 
-	recordingCtx, cancel := context.WithCancel(ctx)
+	// Recordings outlive the start_recording call, so they hang off the session
+	// context, keeping the display and frame cache max age the call was made with.
+	recordingCtx := virtualdisplay.WithDisplay(state.ctx, virtualdisplay.FromContext(ctx))
+	recordingCtx, cancel := context.WithCancel(screenshot.WithCacheMaxAge(recordingCtx, screenshot.CacheMaxAge(ctx)))
 	session := &recordingSession{
 		id:         recordingID,
 		windowID:   windowID,
@@ -82,6 +105,7 @@ func (state *recordingState) start(ctx context.Context, service ScreenshotServic
 		outputPath: outputPath,
 		frameDir:   frameDir,
 		service:    service,
+		redactor:   redactor,
 		done:       make(chan struct{}),
 		cancel:     cancel,
 	}
@@ -94,12 +118,12 @@ func (state *recordingState) start(ctx context.Context, service ScreenshotServic
 	return recordingID, nil
 }
 
-func (state *recordingState) stop(ctx context.Context, recordingID string) (string, string, error) {
+func (state *recordingState) stop(ctx context.Context, recordingID string) (recordingOutput, error) {
 	state.mu.Lock()
 	session, exists := state.active[recordingID]
 	if !exists {
 		state.mu.Unlock()
-		return "", "", fmt.Errorf("recording %s not found or already stopped", recordingID)
+		return recordingOutput{}, fmt.Errorf("recording %s not found or already stopped", recordingID)
 	}
 	delete(state.active, recordingID)
 	state.mu.Unlock()
@@ -112,21 +136,41 @@ func (state *recordingState) stop(ctx context.Context, recordingID string) (stri
 	}()
 
 	if session.recordingError != nil && !errors.Is(session.recordingError, context.Canceled) {
-		return "", "", session.recordingError
+		return recordingOutput{}, session.recordingError
 	}
 	if session.recordingFrames == 0 {
-		return "", "", fmt.Errorf("no frames captured for recording %s", recordingID)
+		return recordingOutput{}, fmt.Errorf("no frames captured for recording %s", recordingID)
 	}
 
-	recordingPath, warning := session.outputPath, ""
 	videoPath, warning, err := finalizeRecordingSession(ctx, session)
 	if err != nil {
-		return "", "", err
+		return recordingOutput{}, err
 	}
+	output := recordingOutput{path: session.outputPath, warning: warning}
 	if videoPath != "" {
-		recordingPath = videoPath
+		output.path = videoPath
+	}
+	if session.redactor != nil {
+		output.redactions = &session.redactions
+	}
+	return output, nil
+}
+
+// discardAll stops every active recording without encoding it and removes captured frames.
+func (state *recordingState) discardAll() {
+	state.mu.Lock()
+	sessions := make([]*recordingSession, 0, len(state.active))
+	for id, session := range state.active {
+		sessions = append(sessions, session)
+		delete(state.active, id)
+	}
+	state.mu.Unlock()
+
+	for _, session := range sessions {
+		session.cancel()
+		<-session.done
+		_ = os.RemoveAll(session.frameDir)
 	}
-	return recordingPath, warning, nil
 }
 
 func normalizeRecordingFormat(format string) (string, error) {
@@ -253,10 +297,13 @@ func encodeRecordingToMP4(ctx context.Context, session *recordingSession) error
 }
 
 func captureAndPersistFrame(ctx context.Context, session *recordingSession) error {
-	img, err := captureImageForRecording(ctx, session.service, session.windowID)
+	img, redactions, err := captureImageForRecording(ctx, session.service, session.redactor, session.windowID)
 	if err != nil {
 		return fmt.Errorf("capture frame: %w", err)
 	}
+	if redactions != nil {
+		session.redactions += *redactions
+	}
 
 	framePath := filepath.Join(session.frameDir, fmt.Sprintf("frame_%06d.png", session.recordingFrames))
 	// #nosec G304 -- framePath is derived from a generated temp directory.
@@ -275,20 +322,21 @@ func captureAndPersistFrame(ctx context.Context, session *recordingSession) erro
 	return nil
 }
 
-func captureImageForRecording(ctx context.Context, service ScreenshotService, windowID uint32) (image.Image, error) {
+// captureImageForRecording captures one frame, redacted before it is written to disk.
+func captureImageForRecording(ctx context.Context, service ScreenshotService, redactor *redactor, windowID uint32) (image.Image, *int, error) {
 	if windowID == 0 {
 		capturedImage, err := service.CaptureImage(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("capture screen image: %w", err)
+			return nil, nil, fmt.Errorf("capture screen image: %w", err)
 		}
-		return capturedImage, nil
+		return redactor.screen(ctx, capturedImage)
 	}
 
-	croppedImage, _, err := window.TakeWindowScreenshotImage(ctx, windowID)
+	croppedImage, metadata, err := window.TakeWindowScreenshotImage(ctx, windowID)
 	if err != nil {
-		return nil, fmt.Errorf("capture window image: %w", err)
+		return nil, nil, fmt.Errorf("capture window image: %w", err)
 	}
-	return croppedImage, nil
+	return redactor.window(ctx, croppedImage, metadata)
 }
 
 func loadPNGImage(path string) (image.Image, error) {
@@ -308,20 +356,20 @@ func loadPNGImage(path string) (image.Image, error) {
 	return decoded, nil
 }
 
-// startRecording starts screen capture into a background recording job.
-func startRecording(ctx context.Context, state *recordingState, windowID uint32, fps int, format string) (string, error) {
-	recordingID, err := state.start(ctx, nil, windowID, fps, format)
+// startRecording starts screen capture into a background recording job owned by session.
+func startRecording(ctx context.Context, session *sessionState, redactor *redactor, windowID uint32, fps int, format string) (string, error) {
+	recordingID, err := session.recordings.start(ctx, nil, redactor, session.artifactPrefix("recording"), windowID, fps, format)
 	if err != nil {
 		return "", err
 	}
 	return recordingID, nil
 }
 
-// stopRecording stops a background recording job and returns the output path.
-func stopRecording(ctx context.Context, state *recordingState, recordingID string) (string, string, error) {
-	videoPath, warning, err := state.stop(ctx, recordingID)
+// stopRecording stops a background recording job of session and returns its output.
+func stopRecording(ctx context.Context, session *sessionState, recordingID string) (recordingOutput, error) {
+	output, err := session.recordings.stop(ctx, recordingID)
 	if err != nil {
-		return "", "", err
+		return recordingOutput{}, err
 	}
-	return videoPath, warning, nil
+	return output, nil
 }
@@ -0,0 +1,171 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"runtime"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/imgencode"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/screenshot"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/stitch"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/window"
+)
+
+const (
+	defaultMaxScrolls     = 30
+	defaultMaxTotalHeight = 10000
+	defaultScrollSettleMs = 300
+)
+
+// Reasons a scrolling capture stopped.
+const (
+	scrollStopEnd       = "end"
+	scrollStopMaxHeight = "max_total_height"
+	scrollStopMaxScroll = "max_scrolls"
+	scrollStopNoOverlap = "no_overlap"
+)
+
+type scrollingScreenshotResult struct {
+	*window.ScreenshotMetadata
+	Frames      int                 `json:"frames"`
+	Scrolls     int                 `json:"scrolls"`
+	TotalHeight int                 `json:"total_height"`
+	StopReason  string              `json:"stop_reason"`
+	Encoding    *imgencode.Encoding `json:"encoding"`
+	Redactions  *int                `json:"redactions,omitempty"`
+}
+
+// scrollDownDelta returns the scroll delta that moves content up by amount.
+// Positive CGEvent wheel deltas scroll towards the top on macOS, while XTest
+// maps positive deltas to wheel-down.
+func scrollDownDelta(amount float64) float64 {
+	if runtime.GOOS == "darwin" {
+		return -amount
+	}
+	return amount
+}
+
+func registerTakeScrollingScreenshotTool(server *sdkmcp.Server, windowService WindowService, settings toolSettings) {
+	sdkmcp.AddTool(server, &sdkmcp.Tool{
+		Name:        TakeScrollingScreenshotToolName,
+		Description: TakeScrollingScreenshotToolDescription,
+	}, func(ctx context.Context, _ *sdkmcp.CallToolRequest, args takeScrollingScreenshotArgs) (*sdkmcp.CallToolResult, any, error) {
+		if err := ensureWindowPermissions(ctx, windowService, TakeScrollingScreenshotToolName); err != nil {
+			return nil, nil, err
+		}
+		if err := validateWindowID(args.WindowID); err != nil {
+			return nil, nil, err
+		}
+		if args.ScrollAmount < 0 || args.MaxScrolls < 0 || args.MaxTotalHeight < 0 || args.SettleMs < 0 {
+			return nil, nil, fmt.Errorf("scroll_amount, max_scrolls, max_total_height and settle_ms must be >= 0")
+		}
+		if args.MaxScrolls == 0 {
+			args.MaxScrolls = defaultMaxScrolls
+		}
+		if args.MaxTotalHeight == 0 {
+			args.MaxTotalHeight = defaultMaxTotalHeight
+		}
+		if args.SettleMs == 0 {
+			args.SettleMs = defaultScrollSettleMs
+		}
+		out, err := args.output(settings.encoding)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := focusWindowAndHandleError(ctx, windowService, args.WindowID); err != nil {
+			return nil, nil, err
+		}
+
+		// Every frame must show the window after the last scroll, never a cached one.
+		ctx = screenshot.WithFresh(ctx)
+		redactions := 0
+		capture := func() (image.Image, *window.ScreenshotMetadata, error) {
+			img, metadata, err := windowService.TakeWindowScreenshotImage(ctx, args.WindowID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("take window screenshot: %w", err)
+			}
+			img, count, err := settings.redactor.window(ctx, img, metadata)
+			if err != nil {
+				return nil, nil, err
+			}
+			if count != nil {
+				redactions += *count
+			}
+			return img, metadata, nil
+		}
+
+		first, metadata, err := capture()
+		if err != nil {
+			return nil, nil, err
+		}
+		if args.X == 0 && args.Y == 0 {
+			args.X, args.Y = float64(metadata.ImageWidth)/2, float64(metadata.ImageHeight)/2
+		}
+		if args.ScrollAmount == 0 {
+			args.ScrollAmount = metadata.Bounds.Height / 2
+		}
+
+		stitcher := stitch.New(first, args.MaxTotalHeight)
+		scrolls := 0
+		stopReason := scrollStopMaxScroll
+		for scrolls < args.MaxScrolls {
+			if stitcher.Full() {
+				stopReason = scrollStopMaxHeight
+				break
+			}
+			if err := windowService.Scroll(ctx, args.WindowID, args.X, args.Y, 0, scrollDownDelta(args.ScrollAmount)); err != nil {
+				return nil, nil, fmt.Errorf("scroll: %w", err)
+			}
+			scrolls++
+			select {
+			case <-ctx.Done():
+				return nil, nil, fmt.Errorf("take scrolling screenshot: %w", ctx.Err())
+			case <-time.After(time.Duration(args.SettleMs) * time.Millisecond):
+			}
+			frame, _, err := capture()
+			if err != nil {
+				return nil, nil, err
+			}
+			added, err := stitcher.Add(frame)
+			if errors.Is(err, stitch.ErrNoOverlap) {
+				stopReason = scrollStopNoOverlap
+				break
+			}
+			if err != nil {
+				return nil, nil, fmt.Errorf("stitch frames: %w", err)
+			}
+			if added == 0 {
+				stopReason = scrollStopEnd
+				break
+			}
+		}
+		if stopReason == scrollStopMaxScroll && stitcher.Full() {
+			stopReason = scrollStopMaxHeight
+		}
+
+		stitched := stitcher.Image()
+		result, err := encodedScreenshotResult(stitched, out, func(encoding *imgencode.Encoding) any {
+			res := scrollingScreenshotResult{
+				ScreenshotMetadata: metadata,
+				Frames:             stitcher.Frames(),
+				Scrolls:            scrolls,
+				TotalHeight:        stitched.Bounds().Dy(),
+				StopReason:         stopReason,
+				Encoding:           encoding,
+			}
+			if settings.redactor != nil {
+				res.Redactions = &redactions
+			}
+			return res
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return result, nil, nil
+	})
+}
@@ -0,0 +1,88 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"image"
+	"image/color"
+	"testing"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/tools"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/window"
+)
+
+// scrollingWindowService shows a 60px viewport onto a 20x200 document, scrolled
+// by the scroll tool's delta and stopping at the end of the document.
+type scrollingWindowService struct {
+	windowToolsService
+	offset int
+}
+
+func documentColor(x, y int) color.RGBA {
+	return color.RGBA{R: uint8(y), G: uint8(x * 12), B: 40, A: 255}
+}
+
+func (*scrollingWindowService) FocusWindow(context.Context, uint32) error {
+	return nil
+}
+
+func (s *scrollingWindowService) Scroll(_ context.Context, _ uint32, _, _, _, deltaY float64) error {
+	s.offset = min(max(s.offset+int(scrollDownDelta(deltaY)), 0), 200-60)
+	return nil
+}
+
+func (s *scrollingWindowService) TakeWindowScreenshotImage(_ context.Context, windowID uint32) (image.Image, *window.ScreenshotMetadata, error) {
+	img := image.NewRGBA(image.Rect(0, 0, 20, 60))
+	for y := 0; y < 60; y++ {
+		for x := 0; x < 20; x++ {
+			img.SetRGBA(x, y, documentColor(x, s.offset+y))
+		}
+	}
+	return img, &window.ScreenshotMetadata{
+		WindowID: windowID, Bounds: window.Bounds{Width: 20, Height: 60}, ImageWidth: 20, ImageHeight: 60, Scale: 1, OcclusionFree: true,
+	}, nil
+}
+
+func TestTakeScrollingScreenshotStitchesToTheEnd(t *testing.T) {
+	server := NewServer(nil, Config{WindowService: &scrollingWindowService{}, InputService: &tools.InputService{}})
+	_, session := connectTestSession(t, server)
+	t.Cleanup(func() { _ = session.Close() })
+
+	result, err := session.CallTool(context.Background(), &sdkmcp.CallToolParams{
+		Name:      TakeScrollingScreenshotToolName,
+		Arguments: map[string]any{"window_id": 3, "settle_ms": 1, "format": "png"},
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("take_scrolling_screenshot: %v %+v", err, result)
+	}
+	var metadata scrollingScreenshotResult
+	if err := json.Unmarshal([]byte(result.Content[0].(*sdkmcp.TextContent).Text), &metadata); err != nil {
+		t.Fatalf("decode metadata: %v", err)
+	}
+	// Half-window steps reach offset 140 after five scrolls; the sixth changes nothing.
+	if metadata.StopReason != scrollStopEnd || metadata.Frames != 6 || metadata.Scrolls != 6 || metadata.TotalHeight != 200 {
+		t.Fatalf("metadata = %+v, want 6 frames stitched to 200 rows", metadata)
+	}
+	img := decodePNGContent(t, result)
+	for _, y := range []int{0, 59, 60, 130, 199} {
+		if got := img.RGBAAt(5, y); got != documentColor(5, y) {
+			t.Errorf("row %d = %v, want %v", y, got, documentColor(5, y))
+		}
+	}
+
+	result, err = session.CallTool(context.Background(), &sdkmcp.CallToolParams{
+		Name:      TakeScrollingScreenshotToolName,
+		Arguments: map[string]any{"window_id": 3, "settle_ms": 1, "max_scrolls": 1},
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("take_scrolling_screenshot: %v %+v", err, result)
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(*sdkmcp.TextContent).Text), &metadata); err != nil {
+		t.Fatalf("decode metadata: %v", err)
+	}
+	if metadata.StopReason != scrollStopEnd || metadata.Frames != 1 {
+		t.Fatalf("metadata = %+v, want the already scrolled window to stop at its end", metadata)
+	}
+}
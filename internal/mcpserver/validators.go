@@ -9,6 +9,67 @@ const (
 	defaultImageWaitPollMs     = 500
 )
 
+// ToolDefaults overrides the fallback values tools use when a caller omits an argument.
+// Zero fields keep the built-in defaults.
+type ToolDefaults struct {
+	ImageMatchThreshold float64
+	ComparisonThreshold float64
+	// WaitTimeoutMs and PollIntervalMs apply to pixel, region, process and clipboard waits.
+	WaitTimeoutMs  int
+	PollIntervalMs int
+	// ImageWaitTimeoutMs and ImageWaitPollMs apply to template and OCR waits.
+	ImageWaitTimeoutMs int
+	ImageWaitPollMs    int
+}
+
+func (d ToolDefaults) resolved() ToolDefaults {
+	if d.ImageMatchThreshold == 0 {
+		d.ImageMatchThreshold = defaultImageMatchThreshold
+	}
+	if d.ComparisonThreshold == 0 {
+		d.ComparisonThreshold = defaultComparisonThreshold
+	}
+	d.ImageWaitTimeoutMs, d.ImageWaitPollMs = resolveTimeoutAndPoll(d.ImageWaitTimeoutMs, d.ImageWaitPollMs)
+	return d
+}
+
+// Validate reports defaults that tools would reject as arguments.
+func (d ToolDefaults) Validate() error {
+	if err := validateThreshold(d.ImageMatchThreshold); err != nil {
+		return fmt.Errorf("image match %w", err)
+	}
+	if err := validateThreshold(d.ComparisonThreshold); err != nil {
+		return fmt.Errorf("comparison %w", err)
+	}
+	if d.WaitTimeoutMs < 0 || d.PollIntervalMs < 0 || d.ImageWaitTimeoutMs < 0 || d.ImageWaitPollMs < 0 {
+		return fmt.Errorf("wait timeouts and poll intervals must be >= 0")
+	}
+	return nil
+}
+
+// waitTimeoutAndPoll fills omitted pixel/region/process wait arguments.
+// Zero values pass through so the window package applies its own defaults.
+func (d ToolDefaults) waitTimeoutAndPoll(timeoutMs, pollMs int) (int, int) {
+	if timeoutMs <= 0 {
+		timeoutMs = d.WaitTimeoutMs
+	}
+	if pollMs <= 0 {
+		pollMs = d.PollIntervalMs
+	}
+	return timeoutMs, pollMs
+}
+
+// imageWaitTimeoutAndPoll fills omitted template and OCR wait arguments.
+func (d ToolDefaults) imageWaitTimeoutAndPoll(timeoutMs, pollMs int) (int, int) {
+	if timeoutMs <= 0 {
+		timeoutMs = d.ImageWaitTimeoutMs
+	}
+	if pollMs <= 0 {
+		pollMs = d.ImageWaitPollMs
+	}
+	return timeoutMs, pollMs
+}
+
 func defaultThreshold(value, fallback float64) float64 {
 	if value == 0 {
 		return fallback
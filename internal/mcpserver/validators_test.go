@@ -228,3 +228,40 @@ func TestNormalizeScreenshotHashTarget(t *testing.T) {
 		t.Fatal("expected error for unsupported target")
 	}
 }
+
+func TestToolDefaults_Resolved(t *testing.T) {
+	got := ToolDefaults{ComparisonThreshold: 0.9, ImageWaitPollMs: 250}.resolved()
+	want := ToolDefaults{
+		ImageMatchThreshold: defaultImageMatchThreshold,
+		ComparisonThreshold: 0.9,
+		ImageWaitTimeoutMs:  defaultImageWaitTimeoutMs,
+		ImageWaitPollMs:     250,
+	}
+	if got != want {
+		t.Fatalf("resolved() = %+v, want %+v", got, want)
+	}
+
+	// Pixel/region/process waits pass zero through so the window package keeps its defaults.
+	if timeout, poll := got.waitTimeoutAndPoll(0, 0); timeout != 0 || poll != 0 {
+		t.Fatalf("waitTimeoutAndPoll(0, 0) = %d, %d; want 0, 0", timeout, poll)
+	}
+	configured := ToolDefaults{WaitTimeoutMs: 2000, PollIntervalMs: 50}
+	if timeout, poll := configured.waitTimeoutAndPoll(0, 10); timeout != 2000 || poll != 10 {
+		t.Fatalf("waitTimeoutAndPoll(0, 10) = %d, %d; want 2000, 10", timeout, poll)
+	}
+	if timeout, poll := got.imageWaitTimeoutAndPoll(0, 0); timeout != defaultImageWaitTimeoutMs || poll != 250 {
+		t.Fatalf("imageWaitTimeoutAndPoll(0, 0) = %d, %d", timeout, poll)
+	}
+}
+
+func TestToolDefaults_Validate(t *testing.T) {
+	if err := (ToolDefaults{}).Validate(); err != nil {
+		t.Fatalf("zero defaults should be valid: %v", err)
+	}
+	if err := (ToolDefaults{ImageMatchThreshold: 1.5}).Validate(); err == nil {
+		t.Fatal("expected error for threshold above 1")
+	}
+	if err := (ToolDefaults{PollIntervalMs: -1}).Validate(); err == nil {
+		t.Fatal("expected error for negative poll interval")
+	}
+}
@@ -0,0 +1,99 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/screenshot"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/tools"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/virtualdisplay"
+)
+
+// VirtualDisplayService starts and stops headless X displays.
+type VirtualDisplayService interface {
+	Available() bool
+	Start(context.Context, virtualdisplay.Options) (*virtualdisplay.Display, error)
+	Stop(*virtualdisplay.Display) error
+}
+
+type defaultVirtualDisplayService struct{}
+
+func (defaultVirtualDisplayService) Available() bool {
+	return virtualdisplay.Available()
+}
+
+func (defaultVirtualDisplayService) Start(ctx context.Context, opts virtualdisplay.Options) (*virtualdisplay.Display, error) {
+	display, err := virtualdisplay.Start(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("start virtual display: %w", err)
+	}
+	return display, nil
+}
+
+func (defaultVirtualDisplayService) Stop(display *virtualdisplay.Display) error {
+	// Xvfb reuses display numbers, so frames of this display must not outlive it.
+	defer screenshot.InvalidateCache()
+	if err := display.Stop(); err != nil {
+		return fmt.Errorf("stop virtual display: %w", err)
+	}
+	return nil
+}
+
+func registerVirtualDisplayTools(server *sdkmcp.Server, displayService VirtualDisplayService, sessions *sessionStore) {
+	registerStartVirtualDisplayTool(server, displayService, sessions)
+	registerStopVirtualDisplayTool(server, displayService, sessions)
+}
+
+func registerStartVirtualDisplayTool(server *sdkmcp.Server, displayService VirtualDisplayService, sessions *sessionStore) {
+	sdkmcp.AddTool(server, &sdkmcp.Tool{
+		Name:        StartVirtualDisplayToolName,
+		Description: StartVirtualDisplayToolDescription,
+	}, func(ctx context.Context, req *sdkmcp.CallToolRequest, args startVirtualDisplayArgs) (*sdkmcp.CallToolResult, any, error) {
+		state := sessions.forRequest(req)
+		display, err := displayService.Start(ctx, virtualdisplay.Options{
+			Width:         args.Width,
+			Height:        args.Height,
+			Depth:         args.Depth,
+			WindowManager: args.WindowManager,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := state.setDisplay(display); err != nil {
+			_ = displayService.Stop(display)
+			return nil, nil, err
+		}
+
+		result, err := tools.ToolResultFromJSON(display)
+		if err != nil {
+			return nil, nil, fmt.Errorf("marshal result: %w", err)
+		}
+		return result, nil, nil
+	})
+}
+
+func registerStopVirtualDisplayTool(server *sdkmcp.Server, displayService VirtualDisplayService, sessions *sessionStore) {
+	sdkmcp.AddTool(server, &sdkmcp.Tool{
+		Name:        StopVirtualDisplayToolName,
+		Description: StopVirtualDisplayToolDescription,
+	}, func(_ context.Context, req *sdkmcp.CallToolRequest, _ emptyArgs) (*sdkmcp.CallToolResult, any, error) {
+		display := sessions.forRequest(req).takeDisplay()
+		if display == nil {
+			return nil, nil, fmt.Errorf("no virtual display is running for this session")
+		}
+		if err := displayService.Stop(display); err != nil {
+			return nil, nil, err
+		}
+
+		result, err := tools.ToolResultFromJSON(map[string]string{
+			"display": display.Name,
+			"status":  "stopped",
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("marshal result: %w", err)
+		}
+		return result, nil, nil
+	})
+}
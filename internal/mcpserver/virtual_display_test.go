@@ -0,0 +1,175 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"image"
+	"sync"
+	"testing"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/imgencode"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/tools"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/virtualdisplay"
+)
+
+// fakeDisplayService hands out displays without starting Xvfb.
+type fakeDisplayService struct {
+	mu      sync.Mutex
+	started []virtualdisplay.Options
+	stopped []string
+}
+
+func (*fakeDisplayService) Available() bool {
+	return true
+}
+
+func (s *fakeDisplayService) Start(_ context.Context, opts virtualdisplay.Options) (*virtualdisplay.Display, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.started = append(s.started, opts)
+	return &virtualdisplay.Display{Name: ":42", Width: opts.Width, Height: opts.Height, Depth: opts.Depth}, nil
+}
+
+func (s *fakeDisplayService) Stop(display *virtualdisplay.Display) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopped = append(s.stopped, display.Name)
+	return nil
+}
+
+func (s *fakeDisplayService) stoppedDisplays() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.stopped...)
+}
+
+func TestVirtualDisplayTools_StartAndStop(t *testing.T) {
+	displays := &fakeDisplayService{}
+	server := NewServer(nil, Config{InputService: &tools.InputService{}, VirtualDisplayService: displays})
+	_, session := connectTestSession(t, server)
+	defer func() { _ = session.Close() }()
+	ctx := context.Background()
+
+	result, err := session.CallTool(ctx, &sdkmcp.CallToolParams{
+		Name:      StartVirtualDisplayToolName,
+		Arguments: map[string]any{"width": 800, "height": 600, "window_manager": "openbox"},
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("start_virtual_display failed: %v %+v", err, result)
+	}
+	var started virtualdisplay.Display
+	if err := json.Unmarshal([]byte(result.Content[0].(*sdkmcp.TextContent).Text), &started); err != nil {
+		t.Fatalf("decode result: %v", err)
+	}
+	if started.Name != ":42" || started.Width != 800 || started.Height != 600 {
+		t.Fatalf("unexpected display: %+v", started)
+	}
+	if displays.started[0].WindowManager != "openbox" {
+		t.Fatalf("window manager not passed through: %+v", displays.started[0])
+	}
+
+	result, err = session.CallTool(ctx, &sdkmcp.CallToolParams{Name: StartVirtualDisplayToolName})
+	if err != nil || !result.IsError {
+		t.Fatalf("expected a second display in one session to fail, got %v %+v", err, result)
+	}
+	if got := displays.stoppedDisplays(); len(got) != 1 {
+		t.Fatalf("the rejected display should be stopped, stopped=%v", got)
+	}
+
+	result, err = session.CallTool(ctx, &sdkmcp.CallToolParams{Name: StopVirtualDisplayToolName})
+	if err != nil || result.IsError {
+		t.Fatalf("stop_virtual_display failed: %v %+v", err, result)
+	}
+	if got := displays.stoppedDisplays(); len(got) != 2 {
+		t.Fatalf("expected the session display to be stopped, stopped=%v", got)
+	}
+
+	result, err = session.CallTool(ctx, &sdkmcp.CallToolParams{Name: StopVirtualDisplayToolName})
+	if err != nil || !result.IsError {
+		t.Fatalf("expected stop without a display to fail, got %v %+v", err, result)
+	}
+}
+
+func TestVirtualDisplayTools_StoppedWhenSessionEnds(t *testing.T) {
+	displays := &fakeDisplayService{}
+	server := NewServer(nil, Config{InputService: &tools.InputService{}, VirtualDisplayService: displays})
+	_, session := connectTestSession(t, server)
+
+	result, err := session.CallTool(context.Background(), &sdkmcp.CallToolParams{Name: StartVirtualDisplayToolName})
+	if err != nil || result.IsError {
+		t.Fatalf("start_virtual_display failed: %v %+v", err, result)
+	}
+	_ = session.Close()
+
+	waitForCondition(t, "display stop", func() bool {
+		return len(displays.stoppedDisplays()) == 1
+	})
+}
+
+func TestVirtualDisplayTools_StoppedOnServerShutdown(t *testing.T) {
+	displays := &fakeDisplayService{}
+	server := NewServer(nil, Config{InputService: &tools.InputService{}, VirtualDisplayService: displays})
+	_, session := connectTestSession(t, server)
+	t.Cleanup(func() { _ = session.Close() })
+
+	result, err := session.CallTool(context.Background(), &sdkmcp.CallToolParams{Name: StartVirtualDisplayToolName})
+	if err != nil || result.IsError {
+		t.Fatalf("start_virtual_display failed: %v %+v", err, result)
+	}
+
+	// The session is still connected; shutdown must not wait for it.
+	CloseSessions(server)
+	if got := displays.stoppedDisplays(); len(got) != 1 || got[0] != ":42" {
+		t.Fatalf("stopped displays = %v, want :42 stopped on shutdown", got)
+	}
+	_ = session.Close()
+	time.Sleep(50 * time.Millisecond)
+	if got := displays.stoppedDisplays(); len(got) != 1 {
+		t.Fatalf("the display was stopped again when the session ended: %v", got)
+	}
+}
+
+func TestVirtualDisplayTools_DisplayIsPerSession(t *testing.T) {
+	var mu sync.Mutex
+	var captured []string
+	service := &tools.ScreenshotService{
+		Capture: func(ctx context.Context) (image.Image, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			captured = append(captured, virtualdisplay.FromContext(ctx))
+			return image.NewRGBA(image.Rect(0, 0, 2, 2)), nil
+		},
+		Encode: func(image.Image, imgencode.Options) ([]byte, error) {
+			return []byte{0xff, 0xd8}, nil
+		},
+		Options: imgencode.DefaultOptions,
+	}
+	server := NewServer(service, Config{
+		WindowService:         windowToolsService{},
+		InputService:          &tools.InputService{},
+		VirtualDisplayService: &fakeDisplayService{},
+	})
+	_, sessionA := connectTestSession(t, server)
+	defer func() { _ = sessionA.Close() }()
+	_, sessionB := connectTestSession(t, server)
+	defer func() { _ = sessionB.Close() }()
+	ctx := context.Background()
+
+	if result, err := sessionA.CallTool(ctx, &sdkmcp.CallToolParams{Name: StartVirtualDisplayToolName}); err != nil || result.IsError {
+		t.Fatalf("start_virtual_display failed: %v %+v", err, result)
+	}
+	for _, session := range []*sdkmcp.ClientSession{sessionA, sessionB} {
+		if result, err := session.CallTool(ctx, &sdkmcp.CallToolParams{Name: ToolName}); err != nil || result.IsError {
+			t.Fatalf("take_screenshot failed: %v %+v", err, result)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(captured) != 2 || captured[0] != ":42" || captured[1] != "" {
+		t.Fatalf("capture displays = %q, want [:42 \"\"] (only the starting session uses its display)", captured)
+	}
+}
@@ -6,20 +6,18 @@ import (
 	"fmt"
 	"image"
 
-	"github.com/brainwhocodes/screenshot_mcp_server/internal/imgencode"
 	"github.com/brainwhocodes/screenshot_mcp_server/internal/window"
 )
 
 // WindowService represents the host operations required by MCP window tools.
 type WindowService interface {
 	SupportsWindowTools() bool
-	EnsureAutomationPermissions(toolName string) error
+	EnsureAutomationPermissions(ctx context.Context, toolName string) error
 	ListWindows(context.Context) ([]window.Window, error)
 	FocusWindow(context.Context, uint32) error
-	TakeWindowScreenshot(context.Context, uint32, imgencode.Options) ([]byte, *window.ScreenshotMetadata, error)
 	TakeWindowScreenshotImage(context.Context, uint32) (image.Image, *window.ScreenshotMetadata, error)
 	TakeWindowScreenshotPNG(context.Context, uint32) ([]byte, *window.ScreenshotMetadata, error)
-	TakeRegionScreenshot(context.Context, float64, float64, float64, float64, string, imgencode.Options) ([]byte, *window.RegionMetadata, error)
+	TakeRegionScreenshotImage(context.Context, float64, float64, float64, float64, string) (image.Image, *window.RegionMetadata, error)
 	TakeRegionScreenshotPNG(context.Context, float64, float64, float64, float64, string) ([]byte, *window.RegionMetadata, error)
 	Click(context.Context, uint32, float64, float64, string, int) error
 	ClickAt(context.Context, float64, float64, string, int, string) error
@@ -42,11 +40,11 @@ func (defaultWindowService) SupportsWindowTools() bool {
 	return window.SupportsWindowTools()
 }
 
-func (defaultWindowService) EnsureAutomationPermissions(toolName string) error {
+func (defaultWindowService) EnsureAutomationPermissions(ctx context.Context, toolName string) error {
 	if !window.SupportsWindowTools() {
 		return nil
 	}
-	if err := window.EnsureAutomationPermissions(toolName); err != nil {
+	if err := window.EnsureAutomationPermissions(ctx, toolName, toolRequirements[toolName]); err != nil {
 		return wrapWindowServiceError("ensure automation permissions", err)
 	}
 	return nil
@@ -67,14 +65,6 @@ func (defaultWindowService) FocusWindow(ctx context.Context, windowID uint32) er
 	return nil
 }
 
-func (defaultWindowService) TakeWindowScreenshot(ctx context.Context, windowID uint32, options imgencode.Options) ([]byte, *window.ScreenshotMetadata, error) {
-	data, metadata, err := window.TakeWindowScreenshot(ctx, windowID, options)
-	if err != nil {
-		return nil, nil, wrapWindowServiceError("take window screenshot", err)
-	}
-	return data, metadata, nil
-}
-
 func (defaultWindowService) TakeWindowScreenshotImage(ctx context.Context, windowID uint32) (image.Image, *window.ScreenshotMetadata, error) {
 	screenshot, metadata, err := window.TakeWindowScreenshotImage(ctx, windowID)
 	if err != nil {
@@ -91,12 +81,12 @@ func (defaultWindowService) TakeWindowScreenshotPNG(ctx context.Context, windowI
 	return data, metadata, nil
 }
 
-func (defaultWindowService) TakeRegionScreenshot(ctx context.Context, x, y, width, height float64, coordSpace string, options imgencode.Options) ([]byte, *window.RegionMetadata, error) {
-	data, metadata, err := window.TakeRegionScreenshot(ctx, x, y, width, height, coordSpace, options)
+func (defaultWindowService) TakeRegionScreenshotImage(ctx context.Context, x, y, width, height float64, coordSpace string) (image.Image, *window.RegionMetadata, error) {
+	img, metadata, err := window.TakeRegionScreenshotImage(ctx, x, y, width, height, coordSpace)
 	if err != nil {
 		return nil, nil, wrapWindowServiceError("take region screenshot", err)
 	}
-	return data, metadata, nil
+	return img, metadata, nil
 }
 
 func (defaultWindowService) TakeRegionScreenshotPNG(ctx context.Context, x, y, width, height float64, coordSpace string) ([]byte, *window.RegionMetadata, error) {
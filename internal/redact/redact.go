@@ -0,0 +1,237 @@
+// Package redact blacks out sensitive screen regions, such as customer data or
+// secrets, before a screenshot leaves the server.
+package redact
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// Rect is a rectangle in screen points, or in window points for window regions.
+type Rect struct {
+	X      float64 `json:"x" yaml:"x"`
+	Y      float64 `json:"y" yaml:"y"`
+	Width  float64 `json:"width" yaml:"width"`
+	Height float64 `json:"height" yaml:"height"`
+}
+
+// WindowRule redacts regions of every window whose owner and title match.
+type WindowRule struct {
+	// Owner matches the owning application name exactly, ignoring case.
+	Owner string `json:"owner" yaml:"owner"`
+	// Title matches windows whose title contains it, ignoring case.
+	Title string `json:"title" yaml:"title"`
+	// Regions are relative to the window's top-left corner. Empty redacts the
+	// whole window.
+	Regions []Rect `json:"regions" yaml:"regions"`
+}
+
+// Rules is the redaction configuration.
+type Rules struct {
+	// Rects are fixed areas of the screen, in screen points.
+	Rects []Rect `json:"rects" yaml:"rects"`
+	// Windows follow matching windows wherever they are on screen.
+	Windows []WindowRule `json:"windows" yaml:"windows"`
+	// Patterns are regular expressions matched against OCR text one line at a
+	// time, so a pattern may span several words.
+	Patterns []string `json:"patterns" yaml:"patterns"`
+}
+
+// Empty reports whether the rules redact nothing.
+func (r Rules) Empty() bool {
+	return len(r.Rects) == 0 && len(r.Windows) == 0 && len(r.Patterns) == 0
+}
+
+// Validate reports the first rule that cannot be applied.
+func (r Rules) Validate() error {
+	_, err := Compile(r)
+	return err
+}
+
+// Policy is a compiled rule set.
+type Policy struct {
+	rules    Rules
+	patterns []*regexp.Regexp
+}
+
+// Compile validates rules and compiles their patterns.
+func Compile(rules Rules) (*Policy, error) {
+	for i, rect := range rules.Rects {
+		if rect.Width <= 0 || rect.Height <= 0 {
+			return nil, fmt.Errorf("rects[%d] must have a positive width and height", i)
+		}
+	}
+	for i, rule := range rules.Windows {
+		if strings.TrimSpace(rule.Owner) == "" && strings.TrimSpace(rule.Title) == "" {
+			return nil, fmt.Errorf("windows[%d] needs an owner or title to match", i)
+		}
+		for j, region := range rule.Regions {
+			if region.Width <= 0 || region.Height <= 0 {
+				return nil, fmt.Errorf("windows[%d].regions[%d] must have a positive width and height", i, j)
+			}
+		}
+	}
+	policy := &Policy{rules: rules}
+	for i, pattern := range rules.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("patterns[%d]: %w", i, err)
+		}
+		if re.MatchString("") {
+			return nil, fmt.Errorf("patterns[%d] %q matches empty text", i, pattern)
+		}
+		policy.patterns = append(policy.patterns, re)
+	}
+	return policy, nil
+}
+
+// NeedsWindows reports whether Apply needs Frame.Windows.
+func (p *Policy) NeedsWindows() bool {
+	return len(p.rules.Windows) > 0
+}
+
+// NeedsOCR reports whether Apply needs the image's OCR words.
+func (p *Policy) NeedsOCR() bool {
+	return len(p.patterns) > 0
+}
+
+// Window is a window shown in a frame, in screen points.
+type Window struct {
+	Owner  string
+	Title  string
+	Bounds Rect
+}
+
+// Word is a word OCR found, in image pixel coordinates. Words that share a Line
+// are joined with single spaces before patterns are matched.
+type Word struct {
+	Text string
+	Line string
+	Box  image.Rectangle
+}
+
+// Frame places an image on the screen.
+type Frame struct {
+	// X and Y are the screen point shown at the image's top-left pixel.
+	X, Y float64
+	// Scale is image pixels per screen point; zero means 1.
+	Scale float64
+	// Windows are the windows shown in the image, for window rules.
+	Windows []Window
+}
+
+// Apply returns img with every matching region filled black and the number of
+// regions filled. When nothing matches img is returned as is; otherwise the
+// result is a copy moved to the origin and img itself is never modified.
+func (p *Policy) Apply(img image.Image, frame Frame, words []Word) (image.Image, int) {
+	bounds := img.Bounds()
+	var regions []image.Rectangle
+	add := func(r image.Rectangle) {
+		if r = r.Intersect(bounds); !r.Empty() {
+			regions = append(regions, r)
+		}
+	}
+	for _, rect := range p.rules.Rects {
+		add(frame.toImage(bounds, rect))
+	}
+	for _, rule := range p.rules.Windows {
+		for _, win := range frame.Windows {
+			if !rule.matches(win) {
+				continue
+			}
+			if len(rule.Regions) == 0 {
+				add(frame.toImage(bounds, win.Bounds))
+			}
+			for _, region := range rule.Regions {
+				region.X += win.Bounds.X
+				region.Y += win.Bounds.Y
+				add(frame.toImage(bounds, region))
+			}
+		}
+	}
+	for _, match := range p.matchWords(words) {
+		add(match)
+	}
+	if len(regions) == 0 {
+		return img, 0
+	}
+
+	canvas := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(canvas, canvas.Bounds(), img, bounds.Min, draw.Src)
+	black := image.NewUniform(color.Black)
+	for _, region := range regions {
+		draw.Draw(canvas, region.Sub(bounds.Min), black, image.Point{}, draw.Src)
+	}
+	return canvas, len(regions)
+}
+
+func (r WindowRule) matches(win Window) bool {
+	if r.Owner != "" && !strings.EqualFold(strings.TrimSpace(r.Owner), win.Owner) {
+		return false
+	}
+	return r.Title == "" || strings.Contains(strings.ToLower(win.Title), strings.ToLower(r.Title))
+}
+
+// toImage maps a screen rectangle to image pixels, rounding outwards so the
+// edges of a region are never left visible.
+func (f Frame) toImage(bounds image.Rectangle, r Rect) image.Rectangle {
+	scale := f.Scale
+	if scale <= 0 {
+		scale = 1
+	}
+	return image.Rect(
+		bounds.Min.X+int(math.Floor((r.X-f.X)*scale)),
+		bounds.Min.Y+int(math.Floor((r.Y-f.Y)*scale)),
+		bounds.Min.X+int(math.Ceil((r.X+r.Width-f.X)*scale)),
+		bounds.Min.Y+int(math.Ceil((r.Y+r.Height-f.Y)*scale)),
+	)
+}
+
+// matchWords returns one box per pattern match, covering every word the match
+// touches.
+func (p *Policy) matchWords(words []Word) []image.Rectangle {
+	if len(p.patterns) == 0 || len(words) == 0 {
+		return nil
+	}
+	var order []string
+	lines := map[string][]Word{}
+	for _, word := range words {
+		if _, seen := lines[word.Line]; !seen {
+			order = append(order, word.Line)
+		}
+		lines[word.Line] = append(lines[word.Line], word)
+	}
+
+	var boxes []image.Rectangle
+	for _, key := range order {
+		line := lines[key]
+		starts := make([]int, len(line))
+		var text strings.Builder
+		for i, word := range line {
+			if i > 0 {
+				text.WriteByte(' ')
+			}
+			starts[i] = text.Len()
+			text.WriteString(word.Text)
+		}
+		for _, re := range p.patterns {
+			for _, span := range re.FindAllStringIndex(text.String(), -1) {
+				var box image.Rectangle
+				for i, word := range line {
+					if starts[i] < span[1] && span[0] < starts[i]+len(word.Text) {
+						box = box.Union(word.Box)
+					}
+				}
+				if !box.Empty() {
+					boxes = append(boxes, box)
+				}
+			}
+		}
+	}
+	return boxes
+}
@@ -0,0 +1,122 @@
+package redact
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+var (
+	white = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	black = color.RGBA{A: 255}
+)
+
+func whiteImage(bounds image.Rectangle) *image.RGBA {
+	img := image.NewRGBA(bounds)
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	return img
+}
+
+func TestApply_RectsAndWindowsMapThroughFrame(t *testing.T) {
+	policy, err := Compile(Rules{
+		Rects: []Rect{{X: 100, Y: 50, Width: 10, Height: 5}},
+		Windows: []WindowRule{
+			{Owner: "slack", Regions: []Rect{{X: 0, Y: 0, Width: 4, Height: 4}}},
+			{Title: "vault"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	src := whiteImage(image.Rect(0, 0, 100, 60))
+	frame := Frame{X: 90, Y: 40, Scale: 2, Windows: []Window{
+		{Owner: "Slack", Title: "general", Bounds: Rect{X: 90, Y: 60, Width: 20, Height: 20}},
+		{Owner: "Browser", Title: "Team Vault - Passwords", Bounds: Rect{X: 130, Y: 40, Width: 5, Height: 5}},
+		{Owner: "Terminal", Title: "shell", Bounds: Rect{X: 90, Y: 40, Width: 50, Height: 30}},
+	}}
+	redacted, count := policy.Apply(src, frame, nil)
+	if count != 3 {
+		t.Fatalf("count = %d, want the rect, the Slack region and the vault window", count)
+	}
+	img := redacted.(*image.RGBA)
+	for _, tc := range []struct {
+		x, y int
+		want color.RGBA
+	}{
+		{20, 20, black}, // screen rect 100,50 at scale 2 from origin 90,40
+		{39, 29, black}, // its far corner
+		{40, 30, white}, // just outside it
+		{0, 40, black},  // Slack region, window at 90,60
+		{7, 47, black},  // its far corner
+		{8, 48, white},  // past the 4x4 region
+		{80, 0, black},  // the vault window, redacted whole
+		{89, 9, black},  // its far corner
+		{79, 0, white},  // just left of it
+		{60, 10, white}, // the unmatched terminal window
+	} {
+		if got := img.RGBAAt(tc.x, tc.y); got != tc.want {
+			t.Errorf("pixel %d,%d = %v, want %v", tc.x, tc.y, got, tc.want)
+		}
+	}
+	if src.RGBAAt(20, 20) != white {
+		t.Fatal("Apply must not modify the source image")
+	}
+}
+
+func TestApply_PatternsSpanWordsOnALine(t *testing.T) {
+	policy, err := Compile(Rules{Patterns: []string{`\b\d{4}( \d{4}){3}\b`, `[\w.]+@[\w.]+`}})
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	words := []Word{
+		{Text: "Card", Line: "1.1.1", Box: image.Rect(0, 0, 8, 4)},
+		{Text: "4111", Line: "1.1.1", Box: image.Rect(10, 0, 18, 4)},
+		{Text: "1111", Line: "1.1.1", Box: image.Rect(20, 0, 28, 4)},
+		{Text: "1111", Line: "1.1.1", Box: image.Rect(30, 0, 38, 4)},
+		{Text: "1111", Line: "1.1.1", Box: image.Rect(40, 0, 48, 4)},
+		{Text: "jo@example.com", Line: "1.1.2", Box: image.Rect(0, 10, 30, 14)},
+		{Text: "1111", Line: "1.1.3", Box: image.Rect(0, 20, 8, 24)},
+	}
+	redacted, count := policy.Apply(whiteImage(image.Rect(0, 0, 60, 30)), Frame{}, words)
+	if count != 2 {
+		t.Fatalf("count = %d, want the card number and the email", count)
+	}
+	img := redacted.(*image.RGBA)
+	if img.RGBAAt(4, 2) != white || img.RGBAAt(12, 2) != black || img.RGBAAt(19, 2) != black || img.RGBAAt(47, 3) != black {
+		t.Fatal("expected the whole card number, and not its label, to be redacted")
+	}
+	if img.RGBAAt(15, 12) != black || img.RGBAAt(4, 22) != white {
+		t.Fatal("expected the email, and not the lone digits on the next line, to be redacted")
+	}
+}
+
+func TestApply_NothingMatchedReturnsSource(t *testing.T) {
+	policy, err := Compile(Rules{Windows: []WindowRule{{Owner: "Slack"}}})
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	src := whiteImage(image.Rect(5, 5, 10, 10))
+	if got, count := policy.Apply(src, Frame{}, nil); got != image.Image(src) || count != 0 {
+		t.Fatalf("Apply = %v, %d; want the source image and no redactions", got, count)
+	}
+}
+
+func TestCompile_RejectsBadRules(t *testing.T) {
+	for name, tc := range map[string]struct {
+		rules Rules
+		want  string
+	}{
+		"empty rect":    {Rules{Rects: []Rect{{Width: 0, Height: 5}}}, "rects[0]"},
+		"unmatched":     {Rules{Windows: []WindowRule{{Regions: []Rect{{Width: 1, Height: 1}}}}}, "owner or title"},
+		"empty region":  {Rules{Windows: []WindowRule{{Owner: "a", Regions: []Rect{{Width: 1}}}}}, "regions[0]"},
+		"bad pattern":   {Rules{Patterns: []string{"("}}, "patterns[0]"},
+		"matches empty": {Rules{Patterns: []string{"x*"}}, "empty text"},
+	} {
+		if err := tc.rules.Validate(); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: error = %v, want it to mention %q", name, err, tc.want)
+		}
+	}
+}
@@ -95,6 +95,15 @@ func CommandContext(ctx context.Context, command string, args ...string) (*exec.
 	return exec.CommandContext(ctxWithTimeout, command, args...), cancel, nil
 }
 
+// Command returns a validated exec.Cmd without a timeout, for long-running
+// background processes that the caller stops explicitly.
+func Command(command string, args ...string) (*exec.Cmd, error) {
+	if err := validateCommand(command, args...); err != nil {
+		return nil, fmt.Errorf("invalid command invocation: %w", err)
+	}
+	return exec.Command(command, args...), nil
+}
+
 func wrapCommandError(command string, err error) error {
 	if err == nil {
 		return nil
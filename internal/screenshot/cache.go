@@ -0,0 +1,143 @@
+package screenshot
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/virtualdisplay"
+)
+
+// DefaultCacheMaxAge is how long a captured frame is shared between callers.
+const DefaultCacheMaxAge = 50 * time.Millisecond
+
+// cacheGeneration is bumped by InvalidateCache; frames from an older
+// generation are never reused.
+var cacheGeneration atomic.Uint64
+
+// InvalidateCache drops every cached frame, for example after synthetic input
+// changed what is on screen.
+func InvalidateCache() {
+	cacheGeneration.Add(1)
+}
+
+type freshKey struct{}
+
+// WithFresh returns a context whose captures bypass the frame cache. The new
+// frame still replaces the cached one for later callers.
+func WithFresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, freshKey{}, true)
+}
+
+// IsFresh reports whether ctx was created by WithFresh.
+func IsFresh(ctx context.Context) bool {
+	fresh, _ := ctx.Value(freshKey{}).(bool)
+	return fresh
+}
+
+type maxAgeKey struct{}
+
+// WithCacheMaxAge returns a context whose captures reuse frames up to maxAge
+// old. Zero or a negative value disables the cache so every capture is new.
+func WithCacheMaxAge(ctx context.Context, maxAge time.Duration) context.Context {
+	return context.WithValue(ctx, maxAgeKey{}, maxAge)
+}
+
+// CacheMaxAge returns the frame cache max age for captures made with ctx,
+// DefaultCacheMaxAge unless it was set with WithCacheMaxAge.
+func CacheMaxAge(ctx context.Context) time.Duration {
+	if maxAge, ok := ctx.Value(maxAgeKey{}).(time.Duration); ok {
+		return maxAge
+	}
+	return DefaultCacheMaxAge
+}
+
+// Cache shares recent captures by key. Concurrent callers for the same key wait
+// for a single capture, and later callers reuse it until it is older than the
+// caller's CacheMaxAge. Cached values are shared and must not be modified.
+type Cache[T any] struct {
+	mu      sync.Mutex
+	entries map[string]*cacheEntry[T]
+	now     func() time.Time
+}
+
+type cacheEntry[T any] struct {
+	ready      chan struct{}
+	value      T
+	err        error
+	started    time.Time
+	generation uint64
+}
+
+// NewCache returns an empty frame cache.
+func NewCache[T any]() *Cache[T] {
+	return &Cache[T]{entries: make(map[string]*cacheEntry[T]), now: time.Now}
+}
+
+// Get returns the frame cached under key, or calls capture and caches its result.
+// Failed captures are not cached; callers that were waiting on one capture again.
+// Keys are scoped to the X display ctx targets, so a frame from one display is
+// never served to a caller working on another.
+func (c *Cache[T]) Get(ctx context.Context, key string, capture func(context.Context) (T, error)) (T, error) {
+	maxAge := CacheMaxAge(ctx)
+	// Replayed frames are not cached so advance-on-capture playback stays deterministic.
+	if maxAge <= 0 || !SystemBackendActive() {
+		return capture(ctx)
+	}
+	fresh := IsFresh(ctx)
+	key = virtualdisplay.XDisplay(ctx) + "/" + key
+
+	for {
+		c.mu.Lock()
+		entry := c.entries[key]
+		if fresh || entry == nil || entry.generation != cacheGeneration.Load() {
+			break
+		}
+		select {
+		case <-entry.ready:
+			if entry.err == nil && c.now().Sub(entry.started) <= maxAge {
+				c.mu.Unlock()
+				return entry.value, nil
+			}
+		default:
+			c.mu.Unlock()
+			select {
+			case <-entry.ready:
+			case <-ctx.Done():
+				var zero T
+				return zero, ctx.Err()
+			}
+			if entry.err == nil {
+				return entry.value, nil
+			}
+			continue
+		}
+		break
+	}
+
+	// c.mu is held here.
+	now := c.now()
+	c.pruneLocked(now, maxAge)
+	entry := &cacheEntry[T]{ready: make(chan struct{}), started: now, generation: cacheGeneration.Load()}
+	c.entries[key] = entry
+	c.mu.Unlock()
+
+	entry.value, entry.err = capture(ctx)
+	close(entry.ready)
+	return entry.value, entry.err
+}
+
+// pruneLocked drops finished entries that can no longer be reused, so keys for
+// closed windows do not accumulate.
+func (c *Cache[T]) pruneLocked(now time.Time, maxAge time.Duration) {
+	for key, entry := range c.entries {
+		select {
+		case <-entry.ready:
+			if entry.err != nil || now.Sub(entry.started) > maxAge {
+				delete(c.entries, key)
+			}
+		default:
+		}
+	}
+}
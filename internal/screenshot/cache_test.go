@@ -0,0 +1,148 @@
+package screenshot
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/virtualdisplay"
+)
+
+// countingCapture returns the number of captures made so far, starting at 1.
+func countingCapture(calls *atomic.Int32) func(context.Context) (int, error) {
+	return func(context.Context) (int, error) {
+		return int(calls.Add(1)), nil
+	}
+}
+
+func TestCacheReusesFramesWithinMaxAge(t *testing.T) {
+	cache := NewCache[int]()
+	now := time.Unix(100, 0)
+	cache.now = func() time.Time { return now }
+
+	var calls atomic.Int32
+	capture := countingCapture(&calls)
+	ctx := context.Background()
+	if got, _ := cache.Get(ctx, "all", capture); got != 1 {
+		t.Fatalf("first Get = %d, want 1", got)
+	}
+	now = now.Add(DefaultCacheMaxAge)
+	if got, _ := cache.Get(ctx, "all", capture); got != 1 {
+		t.Fatalf("Get within max age = %d, want the cached frame", got)
+	}
+	if got, _ := cache.Get(ctx, "display:1", capture); got != 2 {
+		t.Fatalf("Get for another key = %d, want a new capture", got)
+	}
+	if got, _ := cache.Get(WithFresh(ctx), "all", capture); got != 3 {
+		t.Fatalf("fresh Get = %d, want a new capture", got)
+	}
+	if got, _ := cache.Get(ctx, "all", capture); got != 3 {
+		t.Fatalf("Get after fresh = %d, want the fresh frame to be cached", got)
+	}
+
+	now = now.Add(DefaultCacheMaxAge + time.Millisecond)
+	if got, _ := cache.Get(ctx, "all", capture); got != 4 {
+		t.Fatalf("Get after max age = %d, want a new capture", got)
+	}
+	InvalidateCache()
+	if got, _ := cache.Get(ctx, "all", capture); got != 5 {
+		t.Fatalf("Get after InvalidateCache = %d, want a new capture", got)
+	}
+}
+
+func TestCacheScopesFramesByDisplay(t *testing.T) {
+	t.Setenv("DISPLAY", ":0")
+	cache := NewCache[int]()
+	now := time.Unix(100, 0)
+	cache.now = func() time.Time { return now }
+
+	var calls atomic.Int32
+	capture := countingCapture(&calls)
+	if got, _ := cache.Get(context.Background(), "all", capture); got != 1 {
+		t.Fatalf("Get on :0 = %d, want 1", got)
+	}
+	virtual := virtualdisplay.WithDisplay(context.Background(), ":99")
+	if got, _ := cache.Get(virtual, "all", capture); got != 2 {
+		t.Fatalf("Get on :99 = %d, want a new capture rather than the :0 frame", got)
+	}
+	if got, _ := cache.Get(virtual, "all", capture); got != 2 {
+		t.Fatalf("second Get on :99 = %d, want its cached frame", got)
+	}
+	t.Setenv("DISPLAY", ":1")
+	if got, _ := cache.Get(context.Background(), "all", capture); got != 3 {
+		t.Fatalf("Get after DISPLAY changed = %d, want a new capture", got)
+	}
+}
+
+func TestCacheSharesConcurrentCaptures(t *testing.T) {
+	cache := NewCache[int]()
+	release := make(chan struct{})
+	var calls atomic.Int32
+	capture := func(context.Context) (int, error) {
+		calls.Add(1)
+		<-release
+		return 7, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]int, 4)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = cache.Get(context.Background(), "all", capture)
+		}()
+	}
+	// Give every caller time to find the in-flight capture before it finishes.
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Fatalf("captures = %d, want 1 shared capture", calls.Load())
+	}
+	for i, got := range results {
+		if got != 7 {
+			t.Fatalf("caller %d got %d, want 7", i, got)
+		}
+	}
+}
+
+func TestCacheDoesNotKeepErrors(t *testing.T) {
+	cache := NewCache[int]()
+	failed := false
+	capture := func(context.Context) (int, error) {
+		if !failed {
+			failed = true
+			return 0, errors.New("display asleep")
+		}
+		return 1, nil
+	}
+	if _, err := cache.Get(context.Background(), "all", capture); err == nil {
+		t.Fatal("expected the first capture to fail")
+	}
+	if got, err := cache.Get(context.Background(), "all", capture); err != nil || got != 1 {
+		t.Fatalf("Get after a failure = %d, %v; want a new capture", got, err)
+	}
+}
+
+func TestCacheDisabled(t *testing.T) {
+	cache := NewCache[int]()
+	var calls atomic.Int32
+	capture := countingCapture(&calls)
+	ctx := WithCacheMaxAge(context.Background(), 0)
+	_, _ = cache.Get(ctx, "all", capture)
+	if got, _ := cache.Get(ctx, "all", capture); got != 2 {
+		t.Fatalf("Get with the cache disabled = %d, want a new capture", got)
+	}
+	// Other callers keep their own max age.
+	if got, _ := cache.Get(context.Background(), "all", capture); got != 3 {
+		t.Fatalf("Get with the default max age = %d, want a new capture", got)
+	}
+	if got, _ := cache.Get(context.Background(), "all", capture); got != 3 {
+		t.Fatalf("second Get with the default max age = %d, want the cached frame", got)
+	}
+}
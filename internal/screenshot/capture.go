@@ -6,8 +6,11 @@ import (
 	"fmt"
 	"image"
 	"image/draw"
+	"sync"
 
 	"github.com/kbinani/screenshot"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/virtualdisplay"
 )
 
 // Capturer captures the full virtual screen as an image.
@@ -15,16 +18,100 @@ type Capturer interface {
 	Capture(context.Context) (image.Image, error)
 }
 
-// SystemCapturer captures the active displays from the local machine.
+// Display describes one active monitor. Bounds are in virtual screen
+// coordinates, which the backend reports in points on macOS and pixels elsewhere.
+type Display struct {
+	Index   int
+	Bounds  image.Rectangle
+	Primary bool
+}
+
+// DisplayCapturer enumerates the active displays and captures them one at a time.
+type DisplayCapturer interface {
+	Displays(context.Context) ([]Display, error)
+	CaptureDisplay(context.Context, int) (image.Image, error)
+}
+
+// Backend captures the whole screen and individual displays.
+type Backend interface {
+	Capturer
+	DisplayCapturer
+}
+
+var (
+	backendMu sync.RWMutex
+	backend   Backend = SystemCapturer{}
+)
+
+// SetBackend replaces the screen every capture path reads from, for example with
+// a Replay. Nil restores SystemCapturer.
+func SetBackend(b Backend) {
+	if b == nil {
+		b = SystemCapturer{}
+	}
+	backendMu.Lock()
+	defer backendMu.Unlock()
+	backend = b
+}
+
+func currentBackend() Backend {
+	backendMu.RLock()
+	defer backendMu.RUnlock()
+	return backend
+}
+
+// SystemBackendActive reports whether captures read the local displays, as
+// opposed to a backend installed with SetBackend.
+func SystemBackendActive() bool {
+	_, ok := currentBackend().(SystemCapturer)
+	return ok
+}
+
+// Active returns a Backend that forwards each call to the backend selected with
+// SetBackend at call time, so it can be created before configuration is applied.
+func Active() Backend {
+	return activeBackend{}
+}
+
+type activeBackend struct{}
+
+func (activeBackend) Capture(ctx context.Context) (image.Image, error) {
+	return currentBackend().Capture(ctx)
+}
+
+func (activeBackend) Displays(ctx context.Context) ([]Display, error) {
+	return currentBackend().Displays(ctx)
+}
+
+func (activeBackend) CaptureDisplay(ctx context.Context, index int) (image.Image, error) {
+	return currentBackend().CaptureDisplay(ctx, index)
+}
+
+// SystemCapturer captures the active displays from the local machine. Captures
+// go through a process-wide frame cache, so callers within the context's
+// CacheMaxAge of each other share one frame; use WithFresh to force a new one.
 type SystemCapturer struct{}
 
-// NewCapturer returns the default screen capturer.
+// systemFrames caches SystemCapturer frames by display.
+var systemFrames = NewCache[image.Image]()
+
+// NewCapturer returns the default screen capturer, which follows SetBackend.
 func NewCapturer() Capturer {
-	return SystemCapturer{}
+	return Active()
+}
+
+// Capture captures all active displays into one image. A context aimed at a
+// virtual display with virtualdisplay.WithDisplay captures that display instead.
+func (SystemCapturer) Capture(ctx context.Context) (image.Image, error) {
+	if name := virtualdisplay.FromContext(ctx); name != "" {
+		return systemFrames.Get(ctx, "all", func(context.Context) (image.Image, error) {
+			return captureX11Display(name)
+		})
+	}
+	return systemFrames.Get(ctx, "all", captureAllDisplays)
 }
 
-// Capture captures all active displays into one image.
-func (SystemCapturer) Capture(_ context.Context) (image.Image, error) {
+func captureAllDisplays(context.Context) (image.Image, error) {
 	displayCount := screenshot.NumActiveDisplays()
 	if displayCount <= 0 {
 		return nil, fmt.Errorf("no active displays available")
@@ -55,6 +142,50 @@ func (SystemCapturer) Capture(_ context.Context) (image.Image, error) {
 	return canvas, nil
 }
 
+// Displays lists the active displays. Index 0 is the primary display. A
+// virtual display has a single screen.
+func (SystemCapturer) Displays(ctx context.Context) ([]Display, error) {
+	if name := virtualdisplay.FromContext(ctx); name != "" {
+		bounds, err := x11DisplayBounds(name)
+		if err != nil {
+			return nil, err
+		}
+		return []Display{{Index: 0, Bounds: bounds, Primary: true}}, nil
+	}
+	displayCount := screenshot.NumActiveDisplays()
+	if displayCount <= 0 {
+		return nil, fmt.Errorf("no active displays available")
+	}
+	displays := make([]Display, displayCount)
+	for i := range displays {
+		displays[i] = Display{Index: i, Bounds: screenshot.GetDisplayBounds(i), Primary: i == 0}
+	}
+	return displays, nil
+}
+
+// CaptureDisplay captures a single display by index.
+func (SystemCapturer) CaptureDisplay(ctx context.Context, index int) (image.Image, error) {
+	if name := virtualdisplay.FromContext(ctx); name != "" {
+		if index != 0 {
+			return nil, fmt.Errorf("display %d not found (virtual display %s has 1 display)", index, name)
+		}
+		return systemFrames.Get(ctx, "display:0", func(context.Context) (image.Image, error) {
+			return captureX11Display(name)
+		})
+	}
+	displayCount := screenshot.NumActiveDisplays()
+	if index < 0 || index >= displayCount {
+		return nil, fmt.Errorf("display %d not found (%d active displays)", index, displayCount)
+	}
+	return systemFrames.Get(ctx, fmt.Sprintf("display:%d", index), func(context.Context) (image.Image, error) {
+		captured, err := screenshot.CaptureDisplay(index)
+		if err != nil {
+			return nil, fmt.Errorf("capture display %d: %w", index, err)
+		}
+		return captured, nil
+	})
+}
+
 func unionRect(a, b image.Rectangle) image.Rectangle {
 	minX := a.Min.X
 	if b.Min.X < minX {
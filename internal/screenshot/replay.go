@@ -0,0 +1,261 @@
+package screenshot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	// Register decoders for replay frames.
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// Replay modes.
+const (
+	// ReplayModeCapture advances one frame on every capture.
+	ReplayModeCapture = "capture"
+	// ReplayModeTime shows each frame for its duration, counted from the first capture.
+	ReplayModeTime = "time"
+)
+
+// DefaultReplayFrameInterval is how long a frame is shown in time mode when
+// neither the manifest nor the options give a duration.
+const DefaultReplayFrameInterval = 100 * time.Millisecond
+
+// ReplayOptions selects a replay source and how it plays back.
+type ReplayOptions struct {
+	// Source is a directory of .png/.jpg frames, played in file name order, or a
+	// JSON manifest (see ReplayManifest).
+	Source string
+	// Mode is ReplayModeCapture or ReplayModeTime. Empty uses the manifest's mode,
+	// then ReplayModeCapture.
+	Mode string
+	// FrameInterval is the time-mode duration of frames without their own.
+	FrameInterval time.Duration
+	// Loop restarts from the first frame instead of holding the last one.
+	Loop bool
+}
+
+// ReplayManifest is the JSON form of a replay. Frame paths are relative to the
+// manifest's directory.
+type ReplayManifest struct {
+	Mode            string        `json:"mode,omitempty"`
+	Loop            bool          `json:"loop,omitempty"`
+	FrameIntervalMs int           `json:"frame_interval_ms,omitempty"`
+	Frames          []ReplayFrame `json:"frames"`
+}
+
+// ReplayFrame is one manifest entry.
+type ReplayFrame struct {
+	Path       string `json:"path"`
+	DurationMs int    `json:"duration_ms,omitempty"`
+}
+
+// Replay is a Backend that plays back recorded frames instead of reading the
+// screen. It reports a single primary display the size of the first frame.
+// Returned frames are shared and must not be modified.
+type Replay struct {
+	mu        sync.Mutex
+	frames    []image.Image
+	durations []time.Duration
+	mode      string
+	loop      bool
+	next      int
+	started   time.Time
+	now       func() time.Time
+}
+
+// ValidateReplayMode reports whether mode names a replay mode. Empty is valid.
+func ValidateReplayMode(mode string) error {
+	switch mode {
+	case "", ReplayModeCapture, ReplayModeTime:
+		return nil
+	default:
+		return fmt.Errorf("unknown replay mode %q (use %q or %q)", mode, ReplayModeCapture, ReplayModeTime)
+	}
+}
+
+// LoadReplay decodes every frame of opts.Source up front, so a bad frame fails
+// at startup rather than mid-test.
+func LoadReplay(opts ReplayOptions) (*Replay, error) {
+	if err := ValidateReplayMode(opts.Mode); err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(opts.Source)
+	if err != nil {
+		return nil, fmt.Errorf("replay source: %w", err)
+	}
+
+	var manifest ReplayManifest
+	baseDir := opts.Source
+	if info.IsDir() {
+		if manifest.Frames, err = directoryFrames(opts.Source); err != nil {
+			return nil, err
+		}
+	} else {
+		if manifest, err = readReplayManifest(opts.Source); err != nil {
+			return nil, err
+		}
+		baseDir = filepath.Dir(opts.Source)
+	}
+	if len(manifest.Frames) == 0 {
+		return nil, fmt.Errorf("replay source %s has no frames", opts.Source)
+	}
+
+	mode := opts.Mode
+	if mode == "" {
+		mode = manifest.Mode
+	}
+	if mode == "" {
+		mode = ReplayModeCapture
+	}
+	if err := ValidateReplayMode(mode); err != nil {
+		return nil, fmt.Errorf("replay manifest %s: %w", opts.Source, err)
+	}
+	interval := opts.FrameInterval
+	if interval <= 0 && manifest.FrameIntervalMs > 0 {
+		interval = time.Duration(manifest.FrameIntervalMs) * time.Millisecond
+	}
+	if interval <= 0 {
+		interval = DefaultReplayFrameInterval
+	}
+
+	replay := &Replay{mode: mode, loop: opts.Loop || manifest.Loop, now: time.Now}
+	for _, frame := range manifest.Frames {
+		path := frame.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(baseDir, path)
+		}
+		img, err := decodeReplayFrame(path)
+		if err != nil {
+			return nil, err
+		}
+		duration := interval
+		if frame.DurationMs > 0 {
+			duration = time.Duration(frame.DurationMs) * time.Millisecond
+		}
+		replay.frames = append(replay.frames, img)
+		replay.durations = append(replay.durations, duration)
+	}
+	return replay, nil
+}
+
+func directoryFrames(dir string) ([]ReplayFrame, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read replay directory: %w", err)
+	}
+	var frames []ReplayFrame
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".png", ".jpg", ".jpeg":
+			if !entry.IsDir() {
+				frames = append(frames, ReplayFrame{Path: entry.Name()})
+			}
+		}
+	}
+	sort.Slice(frames, func(i, j int) bool { return frames[i].Path < frames[j].Path })
+	return frames, nil
+}
+
+func readReplayManifest(path string) (ReplayManifest, error) {
+	// Accepted G304 suppression: the replay source is operator-provided configuration.
+	// #nosec G304
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ReplayManifest{}, fmt.Errorf("read replay manifest: %w", err)
+	}
+	var manifest ReplayManifest
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&manifest); err != nil {
+		return ReplayManifest{}, fmt.Errorf("parse replay manifest %s: %w", path, err)
+	}
+	return manifest, nil
+}
+
+func decodeReplayFrame(path string) (image.Image, error) {
+	// Accepted G304 suppression: frame paths come from the operator's replay source.
+	// #nosec G304
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open replay frame: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("decode replay frame %s: %w", path, err)
+	}
+	return img, nil
+}
+
+// Len returns the number of frames.
+func (r *Replay) Len() int {
+	return len(r.frames)
+}
+
+// Capture returns the current frame. In capture mode it then advances.
+func (r *Replay) Capture(context.Context) (image.Image, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.frames[r.frameIndexLocked()], nil
+}
+
+func (r *Replay) frameIndexLocked() int {
+	if r.mode == ReplayModeTime {
+		now := r.now()
+		if r.started.IsZero() {
+			r.started = now
+		}
+		return r.timedIndex(now.Sub(r.started))
+	}
+
+	index := r.next
+	switch {
+	case r.next+1 < len(r.frames):
+		r.next++
+	case r.loop:
+		r.next = 0
+	}
+	return index
+}
+
+// timedIndex returns the frame on screen elapsed after the first capture.
+func (r *Replay) timedIndex(elapsed time.Duration) int {
+	var total time.Duration
+	for _, d := range r.durations {
+		total += d
+	}
+	if r.loop {
+		elapsed %= total
+	}
+	for i, d := range r.durations {
+		if elapsed < d {
+			return i
+		}
+		elapsed -= d
+	}
+	return len(r.frames) - 1
+}
+
+// Displays reports one primary display the size of the first frame.
+func (r *Replay) Displays(context.Context) ([]Display, error) {
+	bounds := r.frames[0].Bounds()
+	return []Display{{Index: 0, Bounds: image.Rect(0, 0, bounds.Dx(), bounds.Dy()), Primary: true}}, nil
+}
+
+// CaptureDisplay captures display 0, the only replayed display.
+func (r *Replay) CaptureDisplay(ctx context.Context, index int) (image.Image, error) {
+	if index != 0 {
+		return nil, fmt.Errorf("display %d not found (1 replayed display)", index)
+	}
+	return r.Capture(ctx)
+}
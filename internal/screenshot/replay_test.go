@@ -0,0 +1,159 @@
+package screenshot
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFrames writes 4x2 PNG frames whose red channel is the frame number.
+func writeFrames(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for i, name := range names {
+		img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+		img.SetRGBA(0, 0, color.RGBA{R: uint8(i + 1), A: 255})
+		file, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("create frame: %v", err)
+		}
+		if err := png.Encode(file, img); err != nil {
+			t.Fatalf("encode frame: %v", err)
+		}
+		_ = file.Close()
+	}
+}
+
+// frameNumber returns the number writeFrames stored in img.
+func frameNumber(t *testing.T, r *Replay) int {
+	t.Helper()
+	img, err := r.Capture(context.Background())
+	if err != nil {
+		t.Fatalf("Capture: %v", err)
+	}
+	red, _, _, _ := img.At(0, 0).RGBA()
+	return int(red >> 8)
+}
+
+func TestLoadReplay_DirectoryAdvancesOnCapture(t *testing.T) {
+	dir := t.TempDir()
+	writeFrames(t, dir, "frame-01.png", "frame-02.png", "frame-03.png")
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0o600); err != nil {
+		t.Fatalf("write notes: %v", err)
+	}
+
+	replay, err := LoadReplay(ReplayOptions{Source: dir})
+	if err != nil {
+		t.Fatalf("LoadReplay: %v", err)
+	}
+	if replay.Len() != 3 {
+		t.Fatalf("Len = %d, want 3", replay.Len())
+	}
+	var got []int
+	for range 4 {
+		got = append(got, frameNumber(t, replay))
+	}
+	if got[0] != 1 || got[1] != 2 || got[2] != 3 || got[3] != 3 {
+		t.Fatalf("frames = %v, want [1 2 3 3] holding the last frame", got)
+	}
+
+	looped, err := LoadReplay(ReplayOptions{Source: dir, Loop: true})
+	if err != nil {
+		t.Fatalf("LoadReplay loop: %v", err)
+	}
+	for range 3 {
+		frameNumber(t, looped)
+	}
+	if n := frameNumber(t, looped); n != 1 {
+		t.Fatalf("frame after the last = %d, want 1 when looping", n)
+	}
+
+	displays, _ := replay.Displays(context.Background())
+	if len(displays) != 1 || displays[0].Bounds != image.Rect(0, 0, 4, 2) || !displays[0].Primary {
+		t.Fatalf("displays = %+v, want one 4x2 primary display", displays)
+	}
+	if _, err := replay.CaptureDisplay(context.Background(), 1); err == nil {
+		t.Fatal("expected display 1 to be rejected")
+	}
+}
+
+func TestLoadReplay_ManifestTimeMode(t *testing.T) {
+	dir := t.TempDir()
+	writeFrames(t, dir, "a.png", "b.png")
+	manifest := `{"mode": "time", "frame_interval_ms": 100, "frames": [{"path": "a.png", "duration_ms": 500}, {"path": "b.png"}]}`
+	path := filepath.Join(dir, "replay.json")
+	if err := os.WriteFile(path, []byte(manifest), 0o600); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+
+	replay, err := LoadReplay(ReplayOptions{Source: path})
+	if err != nil {
+		t.Fatalf("LoadReplay: %v", err)
+	}
+	now := time.Unix(100, 0)
+	replay.now = func() time.Time { return now }
+
+	steps := []struct {
+		after time.Duration
+		want  int
+	}{{0, 1}, {499 * time.Millisecond, 1}, {time.Millisecond, 2}, {time.Hour, 2}}
+	for _, step := range steps {
+		now = now.Add(step.after)
+		if got := frameNumber(t, replay); got != step.want {
+			t.Fatalf("frame after +%v = %d, want %d", step.after, got, step.want)
+		}
+	}
+}
+
+func TestLoadReplay_Errors(t *testing.T) {
+	empty := t.TempDir()
+	badManifest := filepath.Join(t.TempDir(), "replay.json")
+	if err := os.WriteFile(badManifest, []byte(`{"frames": [{"path": "missing.png"}]}`), 0o600); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+
+	tests := []struct {
+		opts ReplayOptions
+		want string
+	}{
+		{ReplayOptions{Source: empty}, "no frames"},
+		{ReplayOptions{Source: empty, Mode: "random"}, "random"},
+		{ReplayOptions{Source: badManifest}, "missing.png"},
+		{ReplayOptions{Source: filepath.Join(empty, "nope")}, "replay source"},
+	}
+	for _, tt := range tests {
+		if _, err := LoadReplay(tt.opts); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("LoadReplay(%+v) error = %v, want it to mention %q", tt.opts, err, tt.want)
+		}
+	}
+}
+
+func TestSetBackendRoutesActiveCaptures(t *testing.T) {
+	dir := t.TempDir()
+	writeFrames(t, dir, "1.png", "2.png")
+	replay, err := LoadReplay(ReplayOptions{Source: dir})
+	if err != nil {
+		t.Fatalf("LoadReplay: %v", err)
+	}
+	SetBackend(replay)
+	t.Cleanup(func() { SetBackend(nil) })
+
+	if SystemBackendActive() {
+		t.Fatal("expected the replay backend to be active")
+	}
+	capturer := NewCapturer()
+	for want := 1; want <= 2; want++ {
+		img, err := capturer.Capture(context.Background())
+		if err != nil {
+			t.Fatalf("Capture: %v", err)
+		}
+		if red, _, _, _ := img.At(0, 0).RGBA(); int(red>>8) != want {
+			t.Fatalf("frame = %d, want %d", red>>8, want)
+		}
+	}
+}
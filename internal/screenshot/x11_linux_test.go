@@ -0,0 +1,27 @@
+package screenshot
+
+import "testing"
+
+func TestDecodeBGRX(t *testing.T) {
+	data := []byte{
+		0x10, 0x20, 0x30, 0x00, 0xff, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0xff, 0x00, 0x01, 0x02, 0x03, 0x04,
+	}
+	img, err := decodeBGRX(data, 2, 2)
+	if err != nil {
+		t.Fatalf("decodeBGRX failed: %v", err)
+	}
+	if got := img.RGBAAt(0, 0); got.R != 0x30 || got.G != 0x20 || got.B != 0x10 || got.A != 0xff {
+		t.Fatalf("pixel (0,0) = %+v", got)
+	}
+	if got := img.RGBAAt(1, 0); got.B != 0xff || got.R != 0 {
+		t.Fatalf("pixel (1,0) = %+v", got)
+	}
+	if got := img.RGBAAt(0, 1); got.R != 0xff || got.B != 0 {
+		t.Fatalf("pixel (0,1) = %+v", got)
+	}
+
+	if _, err := decodeBGRX(data[:12], 2, 2); err == nil {
+		t.Fatal("expected error for short image data")
+	}
+}
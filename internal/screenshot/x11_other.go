@@ -0,0 +1,19 @@
+//go:build !linux
+
+package screenshot
+
+import (
+	"fmt"
+	"image"
+	"runtime"
+)
+
+// captureX11Display fails: virtual displays exist only on Linux.
+func captureX11Display(name string) (image.Image, error) {
+	return nil, fmt.Errorf("cannot capture X display %q: virtual displays are not supported on %s", name, runtime.GOOS)
+}
+
+// x11DisplayBounds fails: virtual displays exist only on Linux.
+func x11DisplayBounds(name string) (image.Rectangle, error) {
+	return image.Rectangle{}, fmt.Errorf("cannot read X display %q: virtual displays are not supported on %s", name, runtime.GOOS)
+}
@@ -0,0 +1,186 @@
+// Package stitch joins the viewport captures of a scrolling window into one
+// tall image by finding where consecutive frames overlap.
+package stitch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/draw"
+)
+
+// ErrNoOverlap means a frame shares no rows with the one before it, usually
+// because the window scrolled further than the height of its scrolling area.
+var ErrNoOverlap = errors.New("frame does not overlap the previous frame")
+
+// maxMismatchRatio is the share of overlapping rows that may differ, so a
+// blinking caret or a hover highlight does not hide the real overlap.
+const maxMismatchRatio = 0.05
+
+// row summarizes one row of pixels.
+type row struct {
+	hash uint64
+	// uniform rows are a single color. They match at any offset, so they never
+	// count as evidence for one.
+	uniform bool
+}
+
+// part is a band of rows taken from one frame.
+type part struct {
+	frame  *image.RGBA
+	y0, y1 int
+}
+
+// Stitcher accumulates frames captured top to bottom. Rows that stay put while
+// the rest scrolls, such as a toolbar or status bar, appear once: the header
+// from the first frame and the footer from the last.
+type Stitcher struct {
+	width, height int
+	maxHeight     int
+	prevRows      []row
+	parts         []part
+	footer        part
+	frames        int
+}
+
+// New starts a stitch from the first frame. A positive maxHeight caps the
+// stitched image height.
+func New(first image.Image, maxHeight int) *Stitcher {
+	frame := toRGBA(first)
+	height := frame.Bounds().Dy()
+	return &Stitcher{
+		width:     frame.Bounds().Dx(),
+		height:    height,
+		maxHeight: maxHeight,
+		prevRows:  rows(frame),
+		parts:     []part{{frame: frame, y0: 0, y1: height}},
+		frames:    1,
+	}
+}
+
+// Add appends the rows that next scrolled into view and reports how many there
+// were. Zero rows means next shows exactly what the previous frame did, so the
+// content has stopped scrolling. Add returns ErrNoOverlap when next cannot be
+// placed below the previous frame; the stitch is unchanged in that case.
+func (s *Stitcher) Add(next image.Image) (int, error) {
+	if next.Bounds().Dx() != s.width || next.Bounds().Dy() != s.height {
+		return 0, fmt.Errorf("frame is %dx%d, want %dx%d", next.Bounds().Dx(), next.Bounds().Dy(), s.width, s.height)
+	}
+	frame := toRGBA(next)
+	nextRows := rows(frame)
+
+	header := 0
+	for header < s.height && nextRows[header] == s.prevRows[header] {
+		header++
+	}
+	if header == s.height {
+		return 0, nil
+	}
+	footer := 0
+	for footer < s.height-header && nextRows[s.height-1-footer] == s.prevRows[s.height-1-footer] {
+		footer++
+	}
+
+	shift, ok := findShift(s.prevRows[header:s.height-footer], nextRows[header:s.height-footer])
+	if !ok {
+		return 0, ErrNoOverlap
+	}
+
+	// The previous frame's rows now run up to where the footer starts, then the
+	// rows that scrolled in follow.
+	last := &s.parts[len(s.parts)-1]
+	last.y1 = max(last.y0, s.height-footer)
+	end := s.height - footer
+	s.parts = append(s.parts, part{frame: frame, y0: end - shift, y1: end})
+	s.footer = part{frame: frame, y0: end, y1: s.height}
+	s.prevRows = nextRows
+	s.frames++
+	return shift, nil
+}
+
+// findShift returns how many rows the content moved up between two bands of
+// equal height. It prefers the offset where the most non-uniform rows line up,
+// and the smallest such offset on a tie.
+func findShift(prev, next []row) (int, bool) {
+	best, bestMatched := 0, 0
+	for shift := 1; shift < len(prev); shift++ {
+		overlap := len(prev) - shift
+		matched, mismatched := 0, 0
+		for i := 0; i < overlap; i++ {
+			if prev[shift+i] != next[i] {
+				mismatched++
+				continue
+			}
+			if !next[i].uniform {
+				matched++
+			}
+		}
+		if float64(mismatched) > maxMismatchRatio*float64(overlap) {
+			continue
+		}
+		if matched > bestMatched {
+			best, bestMatched = shift, matched
+		}
+	}
+	return best, bestMatched > 0
+}
+
+// Frames reports how many frames the stitch holds, including the first.
+func (s *Stitcher) Frames() int {
+	return s.frames
+}
+
+// Height is the stitched image height before the maxHeight cap.
+func (s *Stitcher) Height() int {
+	height := s.footer.y1 - s.footer.y0
+	for _, p := range s.parts {
+		height += p.y1 - p.y0
+	}
+	return height
+}
+
+// Full reports whether the stitch has reached maxHeight.
+func (s *Stitcher) Full() bool {
+	return s.maxHeight > 0 && s.Height() >= s.maxHeight
+}
+
+// Image renders the stitched image, cut to maxHeight from the top.
+func (s *Stitcher) Image() *image.RGBA {
+	height := s.Height()
+	if s.maxHeight > 0 && height > s.maxHeight {
+		height = s.maxHeight
+	}
+	out := image.NewRGBA(image.Rect(0, 0, s.width, height))
+	y := 0
+	for _, p := range append(s.parts[:len(s.parts):len(s.parts)], s.footer) {
+		if y >= height {
+			break
+		}
+		rows := min(p.y1-p.y0, height-y)
+		draw.Draw(out, image.Rect(0, y, s.width, y+rows), p.frame, image.Pt(0, p.y0), draw.Src)
+		y += rows
+	}
+	return out
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(out, out.Bounds(), img, bounds.Min, draw.Src)
+	return out
+}
+
+func rows(img *image.RGBA) []row {
+	width := img.Bounds().Dx() * 4
+	out := make([]row, img.Bounds().Dy())
+	for y := range out {
+		pixels := img.Pix[y*img.Stride : y*img.Stride+width]
+		h := fnv.New64a()
+		_, _ = h.Write(pixels)
+		uniform := len(pixels) < 8 || bytes.Equal(pixels[4:], pixels[:len(pixels)-4])
+		out[y] = row{hash: h.Sum64(), uniform: uniform}
+	}
+	return out
+}
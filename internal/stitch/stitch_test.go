@@ -0,0 +1,98 @@
+package stitch
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+)
+
+const (
+	headerRows = 10
+	footerRows = 5
+	viewRows   = 85
+)
+
+// documentRow colors row y of a scrollable document so every row differs.
+func documentRow(x, y int) color.RGBA {
+	return color.RGBA{R: uint8(y), G: uint8(y / 256), B: uint8(x * 9), A: 255}
+}
+
+// viewport renders a window showing document rows offset..offset+viewRows
+// between a fixed gray toolbar and a fixed striped status bar.
+func viewport(offset int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 20, headerRows+viewRows+footerRows))
+	for y := 0; y < img.Bounds().Dy(); y++ {
+		for x := 0; x < 20; x++ {
+			switch {
+			case y < headerRows:
+				img.SetRGBA(x, y, color.RGBA{R: 128, G: 128, B: 128, A: 255})
+			case y >= headerRows+viewRows:
+				img.SetRGBA(x, y, color.RGBA{R: uint8(x * 10), A: 255})
+			default:
+				img.SetRGBA(x, y, documentRow(x, offset+y-headerRows))
+			}
+		}
+	}
+	return img
+}
+
+func TestStitcher_JoinsScrolledFramesOnce(t *testing.T) {
+	const documentRows = 300
+	s := New(viewport(0), 0)
+	for offset := 30; ; offset += 30 {
+		offset = min(offset, documentRows-viewRows)
+		added, err := s.Add(viewport(offset))
+		if err != nil {
+			t.Fatalf("Add(%d): %v", offset, err)
+		}
+		if added == 0 {
+			break
+		}
+	}
+	if s.Frames() != 9 {
+		t.Fatalf("Frames = %d, want 9 distinct frames", s.Frames())
+	}
+
+	img := s.Image()
+	if got, want := img.Bounds().Dy(), headerRows+documentRows+footerRows; got != want {
+		t.Fatalf("height = %d, want %d", got, want)
+	}
+	for y := 0; y < documentRows; y++ {
+		if got, want := img.RGBAAt(3, headerRows+y), documentRow(3, y); got != want {
+			t.Fatalf("document row %d = %v, want %v", y, got, want)
+		}
+	}
+	if img.RGBAAt(3, 0).R != 128 || img.RGBAAt(3, img.Bounds().Dy()-1).R != 30 {
+		t.Fatal("expected the toolbar on top and the status bar at the bottom")
+	}
+}
+
+func TestStitcher_CapsHeight(t *testing.T) {
+	s := New(viewport(0), 150)
+	if _, err := s.Add(viewport(40)); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if s.Full() {
+		t.Fatalf("Full at height %d, want room below 150", s.Height())
+	}
+	if _, err := s.Add(viewport(80)); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if !s.Full() || s.Image().Bounds().Dy() != 150 {
+		t.Fatalf("height = %d, image = %v; want the image cut to 150 rows", s.Height(), s.Image().Bounds())
+	}
+}
+
+func TestStitcher_RejectsFramesWithoutOverlap(t *testing.T) {
+	s := New(viewport(0), 0)
+	if _, err := s.Add(viewport(viewRows + 10)); !errors.Is(err, ErrNoOverlap) {
+		t.Fatalf("Add = %v, want ErrNoOverlap", err)
+	}
+	if s.Frames() != 1 || s.Height() != headerRows+viewRows+footerRows {
+		t.Fatal("a rejected frame must leave the stitch unchanged")
+	}
+	if _, err := s.Add(image.NewRGBA(image.Rect(0, 0, 10, 10))); err == nil {
+		t.Fatal("expected a frame of a different size to be rejected")
+	}
+}
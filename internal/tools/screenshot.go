@@ -23,23 +23,33 @@ const (
 // CaptureFunc captures a full-screen image.
 type CaptureFunc func(context.Context) (image.Image, error)
 
+// DisplaysFunc lists the active displays.
+type DisplaysFunc func(context.Context) ([]screenshot.Display, error)
+
+// CaptureDisplayFunc captures a single display by index.
+type CaptureDisplayFunc func(context.Context, int) (image.Image, error)
+
 // EncodeFunc encodes an image using the provided options.
 type EncodeFunc func(image.Image, imgencode.Options) ([]byte, error)
 
 // ScreenshotService wraps screenshot capture and JPEG encoding.
 type ScreenshotService struct {
-	Capture CaptureFunc
-	Encode  EncodeFunc
-	Options imgencode.Options
+	Capture        CaptureFunc
+	Displays       DisplaysFunc
+	CaptureDisplay CaptureDisplayFunc
+	Encode         EncodeFunc
+	Options        imgencode.Options
 }
 
 // NewScreenshotService returns the default screenshot service.
 func NewScreenshotService() *ScreenshotService {
-	capturer := screenshot.NewCapturer()
+	capturer := screenshot.Active()
 	return &ScreenshotService{
-		Capture: capturer.Capture,
-		Encode:  imgencode.EncodeJPEG,
-		Options: imgencode.DefaultOptions,
+		Capture:        capturer.Capture,
+		Displays:       capturer.Displays,
+		CaptureDisplay: capturer.CaptureDisplay,
+		Encode:         imgencode.EncodeJPEG,
+		Options:        imgencode.DefaultOptions,
 	}
 }
 
@@ -123,6 +133,48 @@ func (s *ScreenshotService) CaptureImage(ctx context.Context) (image.Image, erro
 	return img, nil
 }
 
+// ListDisplays returns the active displays. A fixture image stands in for a
+// single primary display.
+func (s *ScreenshotService) ListDisplays(ctx context.Context) ([]screenshot.Display, error) {
+	if os.Getenv(FixtureImagePathEnv) != "" {
+		img, err := s.CaptureImage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return []screenshot.Display{{Index: 0, Bounds: img.Bounds(), Primary: true}}, nil
+	}
+
+	if s == nil || s.Displays == nil {
+		return nil, fmt.Errorf("screenshot service is not configured")
+	}
+
+	displays, err := s.Displays(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list displays: %w", err)
+	}
+	return displays, nil
+}
+
+// CaptureDisplayImage captures and returns the image of a single display.
+func (s *ScreenshotService) CaptureDisplayImage(ctx context.Context, index int) (image.Image, error) {
+	if os.Getenv(FixtureImagePathEnv) != "" {
+		if index != 0 {
+			return nil, fmt.Errorf("display %d not found (1 active display)", index)
+		}
+		return s.CaptureImage(ctx)
+	}
+
+	if s == nil || s.CaptureDisplay == nil {
+		return nil, fmt.Errorf("screenshot service is not configured")
+	}
+
+	img, err := s.CaptureDisplay(ctx, index)
+	if err != nil {
+		return nil, fmt.Errorf("capture display: %w", err)
+	}
+	return img, nil
+}
+
 // ToolResultFromJPEG wraps bytes in MCP image content.
 func ToolResultFromJPEG(data []byte) *sdkmcp.CallToolResult {
 	return &sdkmcp.CallToolResult{
@@ -92,3 +92,38 @@ func TestToolResultFromJPEG(t *testing.T) {
 		t.Fatalf("unexpected image data: got=%v want=%v", imageContent.Data, data)
 	}
 }
+
+func TestListDisplays_FixtureIsSinglePrimaryDisplay(t *testing.T) {
+	t.Setenv(FixtureImagePathEnv, testutil.WriteFixtureJPEG(t))
+
+	svc := &ScreenshotService{}
+	displays, err := svc.ListDisplays(context.Background())
+	if err != nil {
+		t.Fatalf("ListDisplays failed: %v", err)
+	}
+	if len(displays) != 1 || !displays[0].Primary || displays[0].Bounds.Empty() {
+		t.Fatalf("displays = %+v, want one primary display", displays)
+	}
+	if _, err := svc.CaptureDisplayImage(context.Background(), 0); err != nil {
+		t.Fatalf("CaptureDisplayImage(0) failed: %v", err)
+	}
+	if _, err := svc.CaptureDisplayImage(context.Background(), 1); err == nil {
+		t.Fatal("expected an error for a display the fixture does not have")
+	}
+}
+
+func TestCaptureDisplayImage_UsesDisplayCapture(t *testing.T) {
+	var captured int
+	svc := &ScreenshotService{
+		CaptureDisplay: func(_ context.Context, index int) (image.Image, error) {
+			captured = index
+			return image.NewRGBA(image.Rect(0, 0, 2, 2)), nil
+		},
+	}
+	if _, err := svc.CaptureDisplayImage(context.Background(), 3); err != nil {
+		t.Fatalf("CaptureDisplayImage failed: %v", err)
+	}
+	if captured != 3 {
+		t.Fatalf("captured display %d, want 3", captured)
+	}
+}
@@ -0,0 +1,33 @@
+package virtualdisplay
+
+import (
+	"context"
+	"os"
+)
+
+type displayKey struct{}
+
+// WithDisplay returns a context whose X11 connections, captures and launched
+// applications target the named display instead of the process DISPLAY. An
+// empty name returns ctx unchanged.
+func WithDisplay(ctx context.Context, name string) context.Context {
+	if name == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, displayKey{}, name)
+}
+
+// FromContext returns the display set with WithDisplay, or "" when there is none.
+func FromContext(ctx context.Context) string {
+	name, _ := ctx.Value(displayKey{}).(string)
+	return name
+}
+
+// XDisplay returns the X display a call made with ctx should use: the one set
+// with WithDisplay, otherwise the process DISPLAY.
+func XDisplay(ctx context.Context) string {
+	if name := FromContext(ctx); name != "" {
+		return name
+	}
+	return os.Getenv("DISPLAY")
+}
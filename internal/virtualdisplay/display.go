@@ -0,0 +1,96 @@
+// Package virtualdisplay starts and stops Xvfb virtual X displays for headless automation.
+//
+// The process DISPLAY variable is never changed. Callers attach a display to
+// a context with WithDisplay, and the capture, input, clipboard and window
+// backends connect to XDisplay(ctx), so several displays can run side by side.
+// Each display only accepts clients holding its cookie, which Start adds to
+// the Xauthority file the process and its children read.
+package virtualdisplay
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Default geometry used when Options leaves a field zero.
+const (
+	DefaultWidth  = 1280
+	DefaultHeight = 800
+	DefaultDepth  = 24
+
+	maxDimension = 16384
+)
+
+// supportedWindowManagers are the lightweight window managers that may be started
+// alongside Xvfb. The list is closed so clients cannot run arbitrary binaries.
+var supportedWindowManagers = map[string]bool{
+	"openbox":                 true,
+	"fluxbox":                 true,
+	"icewm":                   true,
+	"twm":                     true,
+	"matchbox-window-manager": true,
+}
+
+// Options configures a virtual display.
+type Options struct {
+	Width  int
+	Height int
+	// Depth is the color depth in bits: 16 or 24.
+	Depth int
+	// WindowManager optionally names a window manager to run on the display.
+	WindowManager string
+}
+
+// Display is a running virtual display.
+type Display struct {
+	// Name is the X display name, for example ":99".
+	Name          string `json:"display"`
+	Width         int    `json:"width"`
+	Height        int    `json:"height"`
+	Depth         int    `json:"depth"`
+	WindowManager string `json:"window_manager,omitempty"`
+	PID           int    `json:"pid"`
+
+	processes *processes
+}
+
+// SupportedWindowManagers returns the window managers accepted by Options.WindowManager.
+func SupportedWindowManagers() []string {
+	names := make([]string, 0, len(supportedWindowManagers))
+	for name := range supportedWindowManagers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// withDefaults fills zero fields and validates the result.
+func (opts Options) withDefaults() (Options, error) {
+	if opts.Width == 0 {
+		opts.Width = DefaultWidth
+	}
+	if opts.Height == 0 {
+		opts.Height = DefaultHeight
+	}
+	if opts.Depth == 0 {
+		opts.Depth = DefaultDepth
+	}
+	opts.WindowManager = strings.TrimSpace(opts.WindowManager)
+
+	if opts.Width < 1 || opts.Width > maxDimension || opts.Height < 1 || opts.Height > maxDimension {
+		return Options{}, fmt.Errorf("display size must be between 1x1 and %dx%d, got %dx%d", maxDimension, maxDimension, opts.Width, opts.Height)
+	}
+	if opts.Depth != 16 && opts.Depth != 24 {
+		return Options{}, fmt.Errorf("depth must be 16 or 24, got %d", opts.Depth)
+	}
+	if opts.WindowManager != "" && !supportedWindowManagers[opts.WindowManager] {
+		return Options{}, fmt.Errorf("unsupported window manager %q (supported: %s)",
+			opts.WindowManager, strings.Join(SupportedWindowManagers(), ", "))
+	}
+	return opts, nil
+}
+
+func (opts Options) screenSpec() string {
+	return fmt.Sprintf("%dx%dx%d", opts.Width, opts.Height, opts.Depth)
+}
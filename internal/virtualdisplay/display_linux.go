@@ -0,0 +1,225 @@
+//go:build linux
+
+package virtualdisplay
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/safeexec"
+)
+
+const (
+	// startTimeout bounds how long Xvfb may take to report its display number.
+	startTimeout = 10 * time.Second
+	// windowManagerGrace is how long a window manager must stay up to count as started.
+	windowManagerGrace = 500 * time.Millisecond
+	// stopTimeout is how long a process gets to exit after SIGTERM before it is killed.
+	stopTimeout = 3 * time.Second
+)
+
+// processes tracks the child processes behind a Display.
+type processes struct {
+	xvfb              *exec.Cmd
+	xvfbDone          chan struct{}
+	windowManager     *exec.Cmd
+	windowManagerDone chan struct{}
+	// authDir holds the cookie file Xvfb was started with.
+	authDir  string
+	stopOnce sync.Once
+}
+
+// Available reports whether Xvfb is installed.
+func Available() bool {
+	_, err := exec.LookPath("Xvfb")
+	return err == nil
+}
+
+// Start launches Xvfb (and the optional window manager) on a free display number.
+// The process DISPLAY is left alone; use WithDisplay to direct calls at the result.
+func Start(ctx context.Context, opts Options) (*Display, error) {
+	opts, err := opts.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	xvfbPath, err := exec.LookPath("Xvfb")
+	if err != nil {
+		return nil, fmt.Errorf("find Xvfb: %w", err)
+	}
+
+	// Without -auth any local user could connect to the display, so Xvfb only
+	// accepts a fresh cookie that this process adds to its XAUTHORITY.
+	cookie, err := newCookie()
+	if err != nil {
+		return nil, err
+	}
+	authDir, err := os.MkdirTemp("", "screenshot-mcp-xvfb-")
+	if err != nil {
+		return nil, fmt.Errorf("create Xvfb auth directory: %w", err)
+	}
+	authFile := filepath.Join(authDir, "Xauthority")
+	if err := writeServerAuthority(authFile, cookie); err != nil {
+		_ = os.RemoveAll(authDir)
+		return nil, err
+	}
+
+	// -displayfd makes Xvfb pick a free display number and write it to fd 3 once it accepts connections.
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		_ = os.RemoveAll(authDir)
+		return nil, fmt.Errorf("create display pipe: %w", err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	cmd, err := safeexec.Command(xvfbPath, "-displayfd", "3", "-screen", "0", opts.screenSpec(), "-nolisten", "tcp", "-auth", authFile)
+	if err != nil {
+		_ = writer.Close()
+		_ = os.RemoveAll(authDir)
+		return nil, err
+	}
+	cmd.ExtraFiles = []*os.File{writer}
+	if err := cmd.Start(); err != nil {
+		_ = writer.Close()
+		_ = os.RemoveAll(authDir)
+		return nil, fmt.Errorf("start Xvfb: %w", err)
+	}
+	_ = writer.Close()
+	procs := &processes{xvfb: cmd, xvfbDone: waitInBackground(cmd), authDir: authDir}
+
+	number, err := readDisplayNumber(ctx, reader, procs.xvfbDone, startTimeout)
+	if err != nil {
+		terminate(cmd, procs.xvfbDone)
+		_ = os.RemoveAll(authDir)
+		return nil, fmt.Errorf("start Xvfb: %w", err)
+	}
+	if err := processAuthority.add(number, cookie); err != nil {
+		terminate(cmd, procs.xvfbDone)
+		_ = os.RemoveAll(authDir)
+		return nil, err
+	}
+	display := &Display{
+		Name:          ":" + number,
+		Width:         opts.Width,
+		Height:        opts.Height,
+		Depth:         opts.Depth,
+		WindowManager: opts.WindowManager,
+		PID:           cmd.Process.Pid,
+		processes:     procs,
+	}
+
+	if opts.WindowManager != "" {
+		if err := startWindowManager(display, opts.WindowManager); err != nil {
+			_ = display.Stop()
+			return nil, err
+		}
+	}
+
+	return display, nil
+}
+
+// Stop terminates the display's processes. Stopping an already stopped display is a no-op.
+func (d *Display) Stop() error {
+	if d == nil || d.processes == nil {
+		return nil
+	}
+	d.processes.stopOnce.Do(d.stopProcesses)
+	return nil
+}
+
+func (d *Display) stopProcesses() {
+	if d.processes.windowManager != nil {
+		terminate(d.processes.windowManager, d.processes.windowManagerDone)
+	}
+	terminate(d.processes.xvfb, d.processes.xvfbDone)
+	_ = processAuthority.remove(strings.TrimPrefix(d.Name, ":"))
+	_ = os.RemoveAll(d.processes.authDir)
+}
+
+func startWindowManager(display *Display, name string) error {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return fmt.Errorf("find window manager %s: %w", name, err)
+	}
+	cmd, err := safeexec.Command(path)
+	if err != nil {
+		return err
+	}
+	cmd.Env = append(os.Environ(), "DISPLAY="+display.Name)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start window manager %s: %w", name, err)
+	}
+	done := waitInBackground(cmd)
+
+	select {
+	case <-done:
+		return fmt.Errorf("window manager %s exited during startup", name)
+	case <-time.After(windowManagerGrace):
+	}
+	display.processes.windowManager = cmd
+	display.processes.windowManagerDone = done
+	return nil
+}
+
+// readDisplayNumber waits for the display number Xvfb writes on -displayfd.
+func readDisplayNumber(ctx context.Context, r io.Reader, exited <-chan struct{}, timeout time.Duration) (string, error) {
+	type result struct {
+		number string
+		err    error
+	}
+	lines := make(chan result, 1)
+	go func() {
+		line, err := bufio.NewReader(r).ReadString('\n')
+		lines <- result{number: strings.TrimSpace(line), err: err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case res := <-lines:
+		if _, err := strconv.Atoi(res.number); err != nil {
+			if res.err != nil {
+				return "", fmt.Errorf("read display number: %w", res.err)
+			}
+			return "", fmt.Errorf("unexpected display number %q", res.number)
+		}
+		return res.number, nil
+	case <-exited:
+		return "", fmt.Errorf("the Xvfb process exited before the display was ready")
+	case <-timer.C:
+		return "", fmt.Errorf("timed out after %s waiting for the display", timeout)
+	case <-ctx.Done():
+		return "", fmt.Errorf("wait for display: %w", ctx.Err())
+	}
+}
+
+func waitInBackground(cmd *exec.Cmd) chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(done)
+	}()
+	return done
+}
+
+// terminate sends SIGTERM and falls back to SIGKILL after stopTimeout.
+func terminate(cmd *exec.Cmd, done <-chan struct{}) {
+	_ = cmd.Process.Signal(syscall.SIGTERM)
+	select {
+	case <-done:
+		return
+	case <-time.After(stopTimeout):
+	}
+	_ = cmd.Process.Kill()
+	<-done
+}
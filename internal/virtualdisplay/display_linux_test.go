@@ -0,0 +1,41 @@
+//go:build linux
+
+package virtualdisplay
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadDisplayNumber(t *testing.T) {
+	number, err := readDisplayNumber(context.Background(), strings.NewReader("42\n"), nil, time.Second)
+	if err != nil {
+		t.Fatalf("readDisplayNumber failed: %v", err)
+	}
+	if number != "42" {
+		t.Fatalf("number = %q, want 42", number)
+	}
+}
+
+func TestReadDisplayNumber_Errors(t *testing.T) {
+	if _, err := readDisplayNumber(context.Background(), strings.NewReader(""), nil, time.Second); err == nil {
+		t.Fatal("expected error when the pipe closes without a number")
+	}
+	if _, err := readDisplayNumber(context.Background(), strings.NewReader("garbage\n"), nil, time.Second); err == nil {
+		t.Fatal("expected error for a non-numeric display")
+	}
+
+	blocked, writer := io.Pipe()
+	defer func() { _ = writer.Close() }()
+	exited := make(chan struct{})
+	close(exited)
+	if _, err := readDisplayNumber(context.Background(), blocked, exited, time.Second); err == nil {
+		t.Fatal("expected error when Xvfb exits")
+	}
+	if _, err := readDisplayNumber(context.Background(), blocked, nil, 10*time.Millisecond); err == nil {
+		t.Fatal("expected timeout")
+	}
+}
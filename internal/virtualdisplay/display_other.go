@@ -0,0 +1,26 @@
+//go:build !linux
+
+package virtualdisplay
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+)
+
+type processes struct{}
+
+// Available reports false: virtual displays require Linux with Xvfb.
+func Available() bool {
+	return false
+}
+
+// Start returns an error on platforms without Xvfb support.
+func Start(context.Context, Options) (*Display, error) {
+	return nil, fmt.Errorf("virtual displays are not supported on %s; they require Linux with Xvfb", runtime.GOOS)
+}
+
+// Stop is a no-op on platforms without Xvfb support.
+func (*Display) Stop() error {
+	return nil
+}
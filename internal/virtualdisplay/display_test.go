@@ -0,0 +1,56 @@
+package virtualdisplay
+
+import (
+	"context"
+	"testing"
+)
+
+func TestOptionsWithDefaults(t *testing.T) {
+	opts, err := Options{WindowManager: " openbox "}.withDefaults()
+	if err != nil {
+		t.Fatalf("withDefaults failed: %v", err)
+	}
+	if opts.Width != DefaultWidth || opts.Height != DefaultHeight || opts.Depth != DefaultDepth || opts.WindowManager != "openbox" {
+		t.Fatalf("unexpected defaults: %+v", opts)
+	}
+	if got := opts.screenSpec(); got != "1280x800x24" {
+		t.Fatalf("screenSpec() = %q", got)
+	}
+}
+
+func TestOptionsWithDefaults_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"negative width", Options{Width: -1}},
+		{"too tall", Options{Height: maxDimension + 1}},
+		{"unsupported depth", Options{Depth: 8}},
+		{"unknown window manager", Options{WindowManager: "/bin/sh"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.opts.withDefaults(); err == nil {
+				t.Fatalf("expected error for %+v", tt.opts)
+			}
+		})
+	}
+}
+
+func TestXDisplay(t *testing.T) {
+	t.Setenv("DISPLAY", ":0")
+	ctx := context.Background()
+	if got := XDisplay(ctx); got != ":0" {
+		t.Fatalf("XDisplay() without a context display = %q, want the process DISPLAY", got)
+	}
+	if WithDisplay(ctx, "") != ctx {
+		t.Fatal("WithDisplay with an empty name should return ctx unchanged")
+	}
+	ctx = WithDisplay(ctx, ":42")
+	if got := XDisplay(ctx); got != ":42" {
+		t.Fatalf("XDisplay() = %q, want :42", got)
+	}
+	if got := FromContext(context.Background()); got != "" {
+		t.Fatalf("FromContext() without a display = %q", got)
+	}
+}
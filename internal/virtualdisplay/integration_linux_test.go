@@ -0,0 +1,68 @@
+//go:build linux && integration
+
+package virtualdisplay
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+// Run with: go test -tags=integration ./internal/virtualdisplay/... (requires Xvfb on PATH)
+
+func TestIntegrationLinux_StartStop(t *testing.T) {
+	if !Available() {
+		t.Skip("Xvfb is not installed")
+	}
+	t.Setenv("DISPLAY", ":previous")
+
+	display, err := Start(context.Background(), Options{Width: 640, Height: 480})
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer func() { _ = display.Stop() }()
+
+	if os.Getenv("DISPLAY") != ":previous" {
+		t.Fatalf("DISPLAY = %q, want it left unchanged", os.Getenv("DISPLAY"))
+	}
+	second, err := Start(context.Background(), Options{})
+	if err != nil {
+		t.Fatalf("second Start failed: %v", err)
+	}
+	if second.Name == display.Name {
+		t.Fatalf("second display reused %s", display.Name)
+	}
+	if err := second.Stop(); err != nil {
+		t.Fatalf("Stop second display failed: %v", err)
+	}
+
+	conn, err := xgb.NewConnDisplay(XDisplay(WithDisplay(context.Background(), display.Name)))
+	if err != nil {
+		t.Fatalf("connect to %s: %v", display.Name, err)
+	}
+	screen := xproto.Setup(conn).DefaultScreen(conn)
+	if screen.WidthInPixels != 640 || screen.HeightInPixels != 480 {
+		t.Fatalf("screen is %dx%d, want 640x480", screen.WidthInPixels, screen.HeightInPixels)
+	}
+	conn.Close()
+
+	// Clients without the display's cookie are refused.
+	authorized := os.Getenv("XAUTHORITY")
+	t.Setenv("XAUTHORITY", filepath.Join(t.TempDir(), "missing"))
+	if conn, err := xgb.NewConnDisplay(display.Name); err == nil {
+		conn.Close()
+		t.Fatalf("connected to %s without its cookie", display.Name)
+	}
+	t.Setenv("XAUTHORITY", authorized)
+
+	if err := display.Stop(); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if err := display.Stop(); err != nil {
+		t.Fatalf("second Stop should be a no-op: %v", err)
+	}
+}
@@ -0,0 +1,211 @@
+//go:build linux
+
+package virtualdisplay
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	cookieAuthName = "MIT-MAGIC-COOKIE-1"
+	cookieLength   = 16
+	// familyLocal is the Xauthority address family of local (unix socket) connections.
+	familyLocal = 256
+)
+
+// authEntry is one record of an Xauthority file.
+type authEntry struct {
+	family  uint16
+	address string
+	number  string
+	name    string
+	data    []byte
+}
+
+func newCookie() ([]byte, error) {
+	cookie := make([]byte, cookieLength)
+	if _, err := rand.Read(cookie); err != nil {
+		return nil, fmt.Errorf("generate X cookie: %w", err)
+	}
+	return cookie, nil
+}
+
+// writeServerAuthority writes the file Xvfb reads with -auth. The server accepts
+// every cookie in it, so the address and display fields are left empty.
+func writeServerAuthority(path string, cookie []byte) error {
+	return writeAuthorityFile(path, []authEntry{{family: familyLocal, name: cookieAuthName, data: cookie}})
+}
+
+// authority maintains the Xauthority file this process and its children use
+// while virtual displays run. X clients, including the in-process xgb
+// connections, only find cookies through XAUTHORITY, so the first display
+// copies the entries of the file clients used before into a private file,
+// adds its own cookie and points XAUTHORITY at it. The host display keeps
+// working, and the previous XAUTHORITY returns when the last display stops.
+type authority struct {
+	mu      sync.Mutex
+	dir     string
+	cookies map[string][]byte // display number -> cookie
+	// previous is the XAUTHORITY value before the first display; hadPrevious
+	// tells an empty value from an unset one.
+	previous    string
+	hadPrevious bool
+}
+
+var processAuthority = &authority{cookies: make(map[string][]byte)}
+
+// add authorizes this process and its children for display number.
+func (a *authority) add(number string, cookie []byte) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.dir == "" {
+		dir, err := os.MkdirTemp("", "screenshot-mcp-xauth-")
+		if err != nil {
+			return fmt.Errorf("create Xauthority directory: %w", err)
+		}
+		a.dir = dir
+		a.previous, a.hadPrevious = os.LookupEnv("XAUTHORITY")
+	}
+	a.cookies[number] = cookie
+	return a.writeLocked()
+}
+
+// remove forgets display number and restores the previous XAUTHORITY once no display is left.
+func (a *authority) remove(number string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.cookies[number]; !ok {
+		return nil
+	}
+	delete(a.cookies, number)
+	if len(a.cookies) > 0 {
+		return a.writeLocked()
+	}
+
+	var err error
+	if a.hadPrevious {
+		err = os.Setenv("XAUTHORITY", a.previous)
+	} else {
+		err = os.Unsetenv("XAUTHORITY")
+	}
+	err = errors.Join(err, os.RemoveAll(a.dir))
+	a.dir = ""
+	if err != nil {
+		return fmt.Errorf("restore XAUTHORITY: %w", err)
+	}
+	return nil
+}
+
+// writeLocked rewrites the private file: the displays' cookies first, so they
+// win over stale host entries for a reused display number, then the host entries.
+func (a *authority) writeLocked() error {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return fmt.Errorf("read hostname: %w", err)
+	}
+	entries := make([]authEntry, 0, len(a.cookies))
+	for number, cookie := range a.cookies {
+		entries = append(entries, authEntry{family: familyLocal, address: hostname, number: number, name: cookieAuthName, data: cookie})
+	}
+	if previous := a.previousFile(); previous != "" {
+		host, err := readAuthorityFile(previous)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		for _, entry := range host {
+			if entry.family == familyLocal && a.cookies[entry.number] != nil {
+				continue
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	// Rename over the old file so clients never read a partial one.
+	path := filepath.Join(a.dir, "Xauthority")
+	tmp := path + ".tmp"
+	if err := writeAuthorityFile(tmp, entries); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("replace Xauthority: %w", err)
+	}
+	if err := os.Setenv("XAUTHORITY", path); err != nil {
+		return fmt.Errorf("set XAUTHORITY: %w", err)
+	}
+	return nil
+}
+
+// previousFile returns the Xauthority file clients used before the first display.
+func (a *authority) previousFile() string {
+	if a.hadPrevious {
+		return a.previous
+	}
+	if home := os.Getenv("HOME"); home != "" {
+		return filepath.Join(home, ".Xauthority")
+	}
+	return ""
+}
+
+func readAuthorityFile(path string) ([]authEntry, error) {
+	// #nosec G304 -- the path is XAUTHORITY or ~/.Xauthority.
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open Xauthority: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	r := bufio.NewReader(file)
+	var entries []authEntry
+	for {
+		var entry authEntry
+		if err := binary.Read(r, binary.BigEndian, &entry.family); err != nil {
+			if errors.Is(err, io.EOF) {
+				return entries, nil
+			}
+			return nil, fmt.Errorf("read Xauthority %s: %w", path, err)
+		}
+		fields := make([][]byte, 4)
+		for i := range fields {
+			if fields[i], err = readAuthField(r); err != nil {
+				return nil, fmt.Errorf("read Xauthority %s: %w", path, err)
+			}
+		}
+		entry.address, entry.number, entry.name, entry.data = string(fields[0]), string(fields[1]), string(fields[2]), fields[3]
+		entries = append(entries, entry)
+	}
+}
+
+func readAuthField(r io.Reader) ([]byte, error) {
+	var length uint16
+	if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+		return nil, err
+	}
+	field := make([]byte, length)
+	if _, err := io.ReadFull(r, field); err != nil {
+		return nil, err
+	}
+	return field, nil
+}
+
+func writeAuthorityFile(path string, entries []authEntry) error {
+	var buf []byte
+	for _, entry := range entries {
+		buf = binary.BigEndian.AppendUint16(buf, entry.family)
+		for _, field := range [][]byte{[]byte(entry.address), []byte(entry.number), []byte(entry.name), entry.data} {
+			buf = binary.BigEndian.AppendUint16(buf, uint16(len(field)))
+			buf = append(buf, field...)
+		}
+	}
+	if err := os.WriteFile(path, buf, 0o600); err != nil {
+		return fmt.Errorf("write Xauthority: %w", err)
+	}
+	return nil
+}
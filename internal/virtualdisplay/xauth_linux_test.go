@@ -0,0 +1,77 @@
+//go:build linux
+
+package virtualdisplay
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAuthority_MergesHostEntriesAndRestores(t *testing.T) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Fatalf("hostname: %v", err)
+	}
+	host := filepath.Join(t.TempDir(), "host-xauth")
+	hostCookie := bytes.Repeat([]byte{1}, cookieLength)
+	stale := bytes.Repeat([]byte{2}, cookieLength)
+	if err := writeAuthorityFile(host, []authEntry{
+		{family: familyLocal, address: hostname, number: "0", name: cookieAuthName, data: hostCookie},
+		{family: familyLocal, address: hostname, number: "99", name: cookieAuthName, data: stale},
+	}); err != nil {
+		t.Fatalf("write host file: %v", err)
+	}
+	t.Setenv("XAUTHORITY", host)
+
+	auth := &authority{cookies: make(map[string][]byte)}
+	cookie, err := newCookie()
+	if err != nil {
+		t.Fatalf("newCookie: %v", err)
+	}
+	if err := auth.add("99", cookie); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	merged := os.Getenv("XAUTHORITY")
+	if merged == host {
+		t.Fatal("XAUTHORITY still names the host file")
+	}
+	if info, err := os.Stat(merged); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("merged file: %v %v", info, err)
+	}
+	entries, err := readAuthorityFile(merged)
+	if err != nil {
+		t.Fatalf("read merged file: %v", err)
+	}
+	// The stale host entry for the reused display number is dropped.
+	if len(entries) != 2 || entries[0].number != "99" || !bytes.Equal(entries[0].data, cookie) ||
+		entries[1].number != "0" || !bytes.Equal(entries[1].data, hostCookie) {
+		t.Fatalf("merged entries = %+v", entries)
+	}
+
+	if err := auth.remove("99"); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if got := os.Getenv("XAUTHORITY"); got != host {
+		t.Fatalf("XAUTHORITY = %q after the last display, want %q", got, host)
+	}
+	if _, err := os.Stat(merged); !os.IsNotExist(err) {
+		t.Fatalf("merged file should be removed, stat err = %v", err)
+	}
+}
+
+func TestWriteServerAuthority(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Xauthority")
+	cookie := bytes.Repeat([]byte{7}, cookieLength)
+	if err := writeServerAuthority(path, cookie); err != nil {
+		t.Fatalf("writeServerAuthority: %v", err)
+	}
+	entries, err := readAuthorityFile(path)
+	if err != nil {
+		t.Fatalf("readAuthorityFile: %v", err)
+	}
+	if len(entries) != 1 || entries[0].name != cookieAuthName || !bytes.Equal(entries[0].data, cookie) {
+		t.Fatalf("entries = %+v", entries)
+	}
+}
@@ -0,0 +1,483 @@
+//go:build linux
+
+package window
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/safeexec"
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/virtualdisplay"
+)
+
+const (
+	// launchGrace is how long a launched process is watched for an immediate failure.
+	launchGrace = 300 * time.Millisecond
+	// quitGrace is how long an app gets to close its windows before it is signalled.
+	quitGrace = 5 * time.Second
+	// terminateGrace is how long an app gets to exit after SIGTERM before SIGKILL.
+	terminateGrace = 3 * time.Second
+	// exitPollInterval is how often quit_app checks whether processes have exited.
+	exitPollInterval = 100 * time.Millisecond
+)
+
+// procRoot is the procfs mount; tests point it at a fixture tree.
+var procRoot = "/proc"
+
+// LaunchApp starts an application detached from the server. appName can be a
+// desktop file ID or Name ("firefox", "Calculator"), a path to a .desktop file,
+// an executable path, or a command on PATH.
+func LaunchApp(ctx context.Context, appName string) error {
+	if appName == "" {
+		return fmt.Errorf("app name is required")
+	}
+	if err := safeexec.ValidateCommandArg(appName); err != nil {
+		return fmt.Errorf("invalid app name %q: %w", appName, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("launch app %q: %w", appName, err)
+	}
+
+	args, dir, err := resolveLaunchCommand(appName)
+	if err != nil {
+		return fmt.Errorf("launch app %q: %w", appName, err)
+	}
+	cmd, err := safeexec.Command(args[0], args[1:]...)
+	if err != nil {
+		return fmt.Errorf("launch app %q: %w", appName, err)
+	}
+	cmd.Dir = dir
+	if display := virtualdisplay.FromContext(ctx); display != "" {
+		cmd.Env = append(os.Environ(), "DISPLAY="+display)
+	}
+	// A new session keeps the app alive, and out of the server's process group,
+	// when the server exits.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("launch app %q: %w", appName, err)
+	}
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+	select {
+	case err := <-exited:
+		// Single-instance apps often hand off to a running copy and exit 0.
+		if err != nil {
+			return fmt.Errorf("launch app %q: exited during startup: %w", appName, err)
+		}
+	case <-time.After(launchGrace):
+	}
+	return nil
+}
+
+// resolveLaunchCommand returns the argument vector and working directory for appName.
+func resolveLaunchCommand(appName string) ([]string, string, error) {
+	if strings.Contains(appName, "/") {
+		if strings.HasSuffix(appName, ".desktop") {
+			entry, err := readDesktopEntry(appName)
+			if err != nil {
+				return nil, "", err
+			}
+			return desktopCommand(entry)
+		}
+		info, err := os.Stat(appName)
+		if err != nil {
+			return nil, "", fmt.Errorf("stat executable: %w", err)
+		}
+		if !info.Mode().IsRegular() || info.Mode().Perm()&0o111 == 0 {
+			return nil, "", fmt.Errorf("%s is not an executable file", appName)
+		}
+		return []string{appName}, "", nil
+	}
+
+	if entry, err := findDesktopEntry(appName, desktopDirs()); err == nil {
+		return desktopCommand(entry)
+	}
+	if path, err := exec.LookPath(appName); err == nil {
+		return []string{path}, "", nil
+	}
+	return nil, "", fmt.Errorf("no desktop entry or executable named %q", appName)
+}
+
+func desktopCommand(entry *desktopEntry) ([]string, string, error) {
+	if entry.Terminal {
+		return nil, "", fmt.Errorf("desktop entry %s must run in a terminal, which launch_app does not provide", entry.ID)
+	}
+	args, err := entry.command()
+	if err != nil {
+		return nil, "", err
+	}
+	return args, entry.WorkingDir, nil
+}
+
+// QuitApp closes an application's windows through the window manager, then
+// escalates to SIGTERM and SIGKILL for processes that are still running. Only
+// processes running on the display ctx targets are affected.
+func QuitApp(ctx context.Context, appName string) error {
+	if appName == "" {
+		return fmt.Errorf("app name is required")
+	}
+	if err := safeexec.ValidateCommandArg(appName); err != nil {
+		return fmt.Errorf("invalid app name %q: %w", appName, err)
+	}
+
+	names := appNames(appName)
+	display := virtualdisplay.XDisplay(ctx)
+	pids, err := findProcesses(display, names...)
+	if err != nil {
+		return fmt.Errorf("quit app %q: %w", appName, err)
+	}
+
+	closed := 0
+	if display != "" {
+		session, err := openX11(ctx)
+		if err != nil {
+			return fmt.Errorf("quit app %q: %w", appName, err)
+		}
+		windowPIDs, count, err := session.closeAppWindows(names, pids)
+		session.close()
+		if err != nil {
+			return fmt.Errorf("quit app %q: %w", appName, err)
+		}
+		closed = count
+		pids = mergePIDs(pids, windowPIDs)
+	}
+	if len(pids) == 0 {
+		if closed > 0 {
+			return nil
+		}
+		return fmt.Errorf("quit app %q: app is not running", appName)
+	}
+
+	// Windows that could not be asked to close leave nothing to wait for.
+	if closed > 0 {
+		if pids, err = waitForExit(ctx, pids, quitGrace); err != nil || len(pids) == 0 {
+			return err
+		}
+	}
+	signalProcesses(pids, syscall.SIGTERM)
+	if pids, err = waitForExit(ctx, pids, terminateGrace); err != nil || len(pids) == 0 {
+		return err
+	}
+	signalProcesses(pids, syscall.SIGKILL)
+	return nil
+}
+
+// appNames returns the names an app's processes and windows may go by: the name
+// itself plus, for a desktop entry, its executable and StartupWMClass.
+func appNames(appName string) []string {
+	names := []string{appName}
+	if entry, err := findDesktopEntry(appName, desktopDirs()); err == nil {
+		if executable := entry.executable(); executable != "" {
+			names = append(names, executable)
+		}
+		if entry.StartupWMClass != "" {
+			names = append(names, entry.StartupWMClass)
+		}
+	}
+	return names
+}
+
+// closeAppWindows asks every window owned by pids, or whose WM_CLASS matches one of
+// names, to close. It returns the PIDs of those windows and how many accepted the request.
+func (s *x11Session) closeAppWindows(names []string, pids []int) ([]int, int, error) {
+	windows, err := s.clientWindows()
+	if err != nil {
+		return nil, 0, err
+	}
+	owned := make(map[int]bool, len(pids))
+	for _, pid := range pids {
+		owned[pid] = true
+	}
+
+	var windowPIDs []int
+	closed := 0
+	for _, win := range windows {
+		pid := 0
+		if values := s.uint32Property(win, "_NET_WM_PID"); len(values) > 0 {
+			pid = int(values[0])
+		}
+		if !owned[pid] && !wmClassMatches(s.stringProperty(win, "WM_CLASS"), names) {
+			continue
+		}
+		accepted, err := s.requestClose(win)
+		if err != nil {
+			return nil, 0, err
+		}
+		if accepted {
+			closed++
+		}
+		if pid > 0 {
+			windowPIDs = append(windowPIDs, pid)
+		}
+	}
+	return windowPIDs, closed, nil
+}
+
+// wmClassMatches compares both halves of WM_CLASS ("instance\x00Class\x00") with names.
+func wmClassMatches(wmClass string, names []string) bool {
+	for _, part := range strings.Split(strings.TrimRight(wmClass, "\x00"), "\x00") {
+		for _, name := range names {
+			if part != "" && strings.EqualFold(part, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// requestClose asks the window manager to close win (_NET_CLOSE_WINDOW), or sends
+// WM_DELETE_WINDOW to the client directly when no EWMH window manager runs. It
+// reports false when the client does not support WM_DELETE_WINDOW, so nothing
+// was asked to close.
+func (s *x11Session) requestClose(win xproto.Window) (bool, error) {
+	if s.supports("_NET_CLOSE_WINDOW") {
+		closeAtom, err := s.atom("_NET_CLOSE_WINDOW")
+		if err != nil {
+			return false, err
+		}
+		// Source indication 2 marks the request as coming from a pager.
+		event := xproto.ClientMessageEvent{
+			Format: 32,
+			Window: win,
+			Type:   closeAtom,
+			Data:   xproto.ClientMessageDataUnionData32New([]uint32{xproto.TimeCurrentTime, 2, 0, 0, 0}),
+		}
+		mask := uint32(xproto.EventMaskSubstructureRedirect | xproto.EventMaskSubstructureNotify)
+		if err := xproto.SendEventChecked(s.conn, false, s.root, mask, string(event.Bytes())).Check(); err != nil {
+			return false, fmt.Errorf("request close of window %d: %w", win, err)
+		}
+		return true, nil
+	}
+
+	protocolsAtom, err := s.atom("WM_PROTOCOLS")
+	if err != nil {
+		return false, err
+	}
+	deleteAtom, err := s.atom("WM_DELETE_WINDOW")
+	if err != nil {
+		return false, err
+	}
+	supported := false
+	for _, protocol := range s.uint32Property(win, "WM_PROTOCOLS") {
+		if xproto.Atom(protocol) == deleteAtom {
+			supported = true
+		}
+	}
+	if !supported {
+		// The client cannot be asked to close; signals take over.
+		return false, nil
+	}
+	event := xproto.ClientMessageEvent{
+		Format: 32,
+		Window: win,
+		Type:   protocolsAtom,
+		Data:   xproto.ClientMessageDataUnionData32New([]uint32{uint32(deleteAtom), xproto.TimeCurrentTime, 0, 0, 0}),
+	}
+	if err := xproto.SendEventChecked(s.conn, false, win, xproto.EventMaskNoEvent, string(event.Bytes())).Check(); err != nil {
+		return false, fmt.Errorf("send WM_DELETE_WINDOW to window %d: %w", win, err)
+	}
+	return true, nil
+}
+
+// waitForExit polls until every PID has exited or timeout elapses, and returns the PIDs still running.
+func waitForExit(ctx context.Context, pids []int, timeout time.Duration) ([]int, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		pids = runningPIDs(pids)
+		if len(pids) == 0 || !time.Now().Before(deadline) {
+			return pids, nil
+		}
+		select {
+		case <-ctx.Done():
+			return pids, fmt.Errorf("wait for exit: %w", ctx.Err())
+		case <-time.After(exitPollInterval):
+		}
+	}
+}
+
+func runningPIDs(pids []int) []int {
+	var running []int
+	for _, pid := range pids {
+		if state, err := processState(pid); err == nil && state != 'Z' && state != 'X' {
+			running = append(running, pid)
+		}
+	}
+	return running
+}
+
+func signalProcesses(pids []int, signal syscall.Signal) {
+	for _, pid := range pids {
+		_ = syscall.Kill(pid, signal)
+	}
+}
+
+func mergePIDs(a, b []int) []int {
+	seen := make(map[int]bool, len(a)+len(b))
+	var merged []int
+	for _, pid := range append(append([]int(nil), a...), b...) {
+		if !seen[pid] && pid != os.Getpid() {
+			seen[pid] = true
+			merged = append(merged, pid)
+		}
+	}
+	return merged
+}
+
+// KillProcess sends SIGTERM to every process with the given name, like pkill -x,
+// that runs on the display ctx targets.
+func KillProcess(ctx context.Context, processName string) error {
+	if processName == "" {
+		return fmt.Errorf("process name is required")
+	}
+	if err := safeexec.ValidateCommandArg(processName); err != nil {
+		return fmt.Errorf("invalid process name %q: %w", processName, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("kill process %q: %w", processName, err)
+	}
+
+	pids, err := findProcesses(virtualdisplay.XDisplay(ctx), processName)
+	if err != nil {
+		return fmt.Errorf("kill process %q: %w", processName, err)
+	}
+	if len(pids) == 0 {
+		return fmt.Errorf("kill process %q: no matching process is running", processName)
+	}
+	var errs []error
+	for _, pid := range pids {
+		if err := syscall.Kill(pid, syscall.SIGTERM); err != nil && !errors.Is(err, syscall.ESRCH) {
+			errs = append(errs, fmt.Errorf("signal pid %d: %w", pid, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("kill process %q: %w", processName, err)
+	}
+	return nil
+}
+
+func isProcessRunning(ctx context.Context, processName string) bool {
+	pids, err := findProcesses(virtualdisplay.XDisplay(ctx), processName)
+	return err == nil && len(pids) > 0
+}
+
+// findProcesses returns live processes, other than the server itself, whose name
+// matches one of names and whose DISPLAY is display, so a session never touches
+// apps on another display. A process matches on its kernel name (comm, which the
+// kernel truncates to 15 bytes) or on the base name of its executable argument.
+func findProcesses(display string, names ...string) ([]int, error) {
+	dirs, err := os.ReadDir(procRoot)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", procRoot, err)
+	}
+	self := os.Getpid()
+	var pids []int
+	for _, dir := range dirs {
+		pid, err := strconv.Atoi(dir.Name())
+		if err != nil || pid == self {
+			continue
+		}
+		comm, argv0 := processNames(pid)
+		if !matchesProcessName(comm, argv0, names) {
+			continue
+		}
+		if state, err := processState(pid); err != nil || state == 'Z' || state == 'X' {
+			continue
+		}
+		if environDisplay, err := processDisplay(pid); err != nil || !sameDisplay(environDisplay, display) {
+			continue
+		}
+		pids = append(pids, pid)
+	}
+	return pids, nil
+}
+
+func matchesProcessName(comm, argv0 string, names []string) bool {
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		if comm == name || argv0 == name {
+			return true
+		}
+		// comm is truncated, so a long name can only match on its first 15 bytes
+		// when the full executable name is unavailable.
+		if len(name) > 15 && argv0 == "" && comm == name[:15] {
+			return true
+		}
+	}
+	return false
+}
+
+// processNames returns a process's comm and the base name of argv[0].
+func processNames(pid int) (string, string) {
+	dir := filepath.Join(procRoot, strconv.Itoa(pid))
+	// Accepted G304 suppression: paths are built from a numeric PID under procRoot.
+	// #nosec G304
+	comm, _ := os.ReadFile(filepath.Join(dir, "comm"))
+	// #nosec G304
+	cmdline, _ := os.ReadFile(filepath.Join(dir, "cmdline"))
+	argv0, _, _ := bytes.Cut(cmdline, []byte{0})
+	name := ""
+	if len(argv0) > 0 {
+		name = filepath.Base(string(argv0))
+	}
+	return string(bytes.TrimSpace(comm)), name
+}
+
+// processDisplay returns the DISPLAY a process was started with, or "" when it has none.
+func processDisplay(pid int) (string, error) {
+	// Accepted G304 suppression: the path is built from a numeric PID under procRoot.
+	// #nosec G304
+	environ, err := os.ReadFile(filepath.Join(procRoot, strconv.Itoa(pid), "environ"))
+	if err != nil {
+		return "", fmt.Errorf("read process %d environment: %w", pid, err)
+	}
+	for _, entry := range bytes.Split(environ, []byte{0}) {
+		if value, ok := bytes.CutPrefix(entry, []byte("DISPLAY=")); ok {
+			return string(value), nil
+		}
+	}
+	return "", nil
+}
+
+// sameDisplay compares two DISPLAY values, ignoring the screen number, so ":0"
+// and ":0.0" name the same display.
+func sameDisplay(a, b string) bool {
+	return trimScreen(a) == trimScreen(b)
+}
+
+func trimScreen(display string) string {
+	colon := strings.LastIndexByte(display, ':')
+	if dot := strings.LastIndexByte(display, '.'); colon >= 0 && dot > colon {
+		return display[:dot]
+	}
+	return display
+}
+
+// processState returns the state letter from /proc/<pid>/stat, e.g. 'R', 'S' or 'Z'.
+func processState(pid int) (byte, error) {
+	// Accepted G304 suppression: the path is built from a numeric PID under procRoot.
+	// #nosec G304
+	stat, err := os.ReadFile(filepath.Join(procRoot, strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return 0, fmt.Errorf("read process %d status: %w", pid, err)
+	}
+	// The command name is parenthesized and may itself contain ") ", so use the last one.
+	end := bytes.LastIndexByte(stat, ')')
+	if end < 0 || end+2 >= len(stat) {
+		return 0, fmt.Errorf("unexpected stat format for process %d", pid)
+	}
+	return stat[end+2], nil
+}
@@ -0,0 +1,180 @@
+//go:build linux
+
+package window
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/virtualdisplay"
+)
+
+func TestFindProcesses(t *testing.T) {
+	root := t.TempDir()
+	writeProc := func(pid int, comm, cmdline, state, display string) {
+		dir := filepath.Join(root, strconv.Itoa(pid))
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		files := map[string]string{
+			"comm":    comm + "\n",
+			"cmdline": cmdline,
+			"stat":    strconv.Itoa(pid) + " (" + comm + ") " + state + " 1 1",
+			"environ": "HOME=/root\x00DISPLAY=" + display + "\x00",
+		}
+		for name, content := range files {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	writeProc(10, "firefox", "/usr/lib/firefox/firefox\x00--new-window\x00", "S", ":0")
+	writeProc(11, "Web Content", "/usr/lib/firefox/firefox\x00-contentproc\x00", "S", ":0.0")
+	writeProc(12, "firefox", "firefox\x00", "Z", ":0")
+	writeProc(13, "gnome-calculato", "/usr/bin/gnome-calculator\x00", "R", ":0")
+	writeProc(14, "bash", "bash\x00", "S", ":0")
+	writeProc(15, "firefox", "firefox\x00", "S", ":99")
+	if err := os.MkdirAll(filepath.Join(root, "self"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	previous := procRoot
+	procRoot = root
+	t.Cleanup(func() { procRoot = previous })
+
+	cases := map[string][]int{
+		"firefox":          {10, 11},
+		"gnome-calculator": {13},
+		"gnome-calculato":  {13},
+		"zsh":              nil,
+	}
+	for name, want := range cases {
+		got, err := findProcesses(":0", name)
+		if err != nil {
+			t.Fatalf("findProcesses(%q): %v", name, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("findProcesses(%q) = %v, want %v", name, got, want)
+		}
+	}
+	if got, err := findProcesses(":99", "firefox"); err != nil || !reflect.DeepEqual(got, []int{15}) {
+		t.Errorf("findProcesses on :99 = %v, %v; want only the process on that display", got, err)
+	}
+}
+
+func TestWMClassMatches(t *testing.T) {
+	if !wmClassMatches("gnome-calculator\x00Gnome-calculator\x00", []string{"GNOME-Calculator"}) {
+		t.Error("expected a case-insensitive class match")
+	}
+	if wmClassMatches("navigator\x00Firefox\x00", []string{"chromium"}) {
+		t.Error("unexpected match")
+	}
+}
+
+func TestResolveLaunchCommand(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "app.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\n"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if args, _, err := resolveLaunchCommand(script); err != nil || !reflect.DeepEqual(args, []string{script}) {
+		t.Fatalf("resolveLaunchCommand(%q) = %v, %v", script, args, err)
+	}
+
+	plain := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(plain, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := resolveLaunchCommand(plain); err == nil {
+		t.Error("expected a non-executable path to be rejected")
+	}
+
+	desktop := filepath.Join(dir, "tool.desktop")
+	if err := os.WriteFile(desktop, []byte("[Desktop Entry]\nType=Application\nName=Tool\nExec=tool --run %F\nPath=/srv\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	args, workDir, err := resolveLaunchCommand(desktop)
+	if err != nil || !reflect.DeepEqual(args, []string{"tool", "--run"}) || workDir != "/srv" {
+		t.Fatalf("resolveLaunchCommand(%q) = %v, %q, %v", desktop, args, workDir, err)
+	}
+}
+
+func TestLaunchAppReportsImmediateFailure(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv("XDG_DATA_DIRS", t.TempDir())
+	if _, err := exec.LookPath("false"); err != nil {
+		t.Skip("false is not installed")
+	}
+	if err := LaunchApp(context.Background(), "false"); err == nil {
+		t.Fatal("expected a launcher that exits non-zero to fail")
+	}
+}
+
+func TestQuitAppSignalsProcessesWithoutDisplay(t *testing.T) {
+	t.Setenv("DISPLAY", "")
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv("XDG_DATA_DIRS", t.TempDir())
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("sleep is not installed")
+	}
+	// A copy with a unique name so only this test's process matches.
+	binary := filepath.Join(t.TempDir(), "mcpquittest")
+	copyExecutable(t, sleep, binary)
+
+	cmd := exec.Command(binary, "30")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("cannot run copied binary: %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(done)
+	}()
+	if err := WaitForProcess(context.Background(), "mcpquittest", 2000, 20); err != nil {
+		t.Fatalf("wait for process: %v", err)
+	}
+	// The process runs without a display, so a session on a virtual display cannot touch it.
+	other := virtualdisplay.WithDisplay(context.Background(), ":98")
+	if err := KillProcess(other, "mcpquittest"); err == nil {
+		t.Fatal("expected kill_process on another display to find no process")
+	}
+
+	if err := QuitApp(context.Background(), "mcpquittest"); err != nil {
+		t.Fatalf("quit app: %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("process still running after quit_app")
+	}
+	if err := QuitApp(context.Background(), "mcpquittest"); err == nil {
+		t.Fatal("expected quitting an app that is not running to fail")
+	}
+}
+
+func copyExecutable(t *testing.T, src, dst string) {
+	t.Helper()
+	in, err := os.Open(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = in.Close() }()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY, 0o700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		t.Fatal(err)
+	}
+	if err := out.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
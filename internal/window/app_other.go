@@ -0,0 +1,34 @@
+//go:build !darwin && !linux
+
+package window
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+)
+
+// LaunchApp is unsupported on this platform.
+func LaunchApp(context.Context, string) error {
+	return fmt.Errorf("%w", unsupportedPlatformError("launch_app"))
+}
+
+// QuitApp is unsupported on this platform.
+func QuitApp(context.Context, string) error {
+	return fmt.Errorf("%w", unsupportedPlatformError("quit_app"))
+}
+
+// WaitForProcess is unsupported on this platform.
+func WaitForProcess(context.Context, string, int, int) error {
+	return fmt.Errorf("%w", unsupportedPlatformError("wait_for_process"))
+}
+
+// KillProcess is unsupported on this platform.
+func KillProcess(context.Context, string) error {
+	return fmt.Errorf("%w", unsupportedPlatformError("kill_process"))
+}
+
+// unsupportedPlatformError makes stub errors consistent and easy to match in callers.
+func unsupportedPlatformError(toolName string) error {
+	return fmt.Errorf("%s is not supported on %s", toolName, runtime.GOOS)
+}
@@ -0,0 +1,14 @@
+package window
+
+// Requirement describes what a tool needs from the automation environment
+// beyond the platform permissions.
+type Requirement int
+
+const (
+	// RequireDisplay is the default: the tool reads the screen or windows.
+	RequireDisplay Requirement = iota
+	// RequireInput means the tool injects synthetic input, which on X11 needs XTEST.
+	RequireInput
+	// RequireNothing means the tool works on files alone and never needs a display.
+	RequireNothing
+)
@@ -0,0 +1,169 @@
+//go:build linux
+
+package window
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/virtualdisplay"
+)
+
+// X extensions probed before automation. XTEST is required for input; Composite
+// (occlusion-free window capture) and XFixes (cursor state) are optional, and
+// captures degrade without them.
+const (
+	extensionXTest     = "XTEST"
+	extensionComposite = "Composite"
+	extensionXFixes    = "XFIXES"
+)
+
+var probedExtensions = []string{extensionXTest, extensionComposite, extensionXFixes}
+
+// requiredExtensions maps requirements to the X extension they cannot work without.
+var requiredExtensions = map[Requirement]string{
+	RequireInput: extensionXTest,
+}
+
+// extensionHints tell the caller how to get a missing required extension.
+var extensionHints = map[string]string{
+	extensionXTest: "input is injected through XTEST; start the X server without -extension XTEST or call start_virtual_display",
+}
+
+// x11Capabilities records what an X display offers.
+type x11Capabilities struct {
+	display    string
+	extensions map[string]bool
+}
+
+// Probe results are cached per display name, so a tool call costs no X round
+// trips once its display has been seen.
+var (
+	capabilitiesMu        sync.Mutex
+	capabilitiesByDisplay = make(map[string]*x11Capabilities)
+	// queryCapabilities connects to a display and queries it; tests replace it.
+	queryCapabilities = queryX11Capabilities
+)
+
+// CheckPermissions reports whether the X display is reachable and whether it
+// offers XTEST for input. X11 has no screen-recording or accessibility grants;
+// these are the equivalent prerequisites.
+func CheckPermissions() (screenRecording bool, accessibility bool) {
+	caps, err := probeX11(context.Background(), "")
+	if err != nil {
+		return false, false
+	}
+	return true, caps.extensions[extensionXTest]
+}
+
+// EnsureAutomationPermissions probes the display ctx targets and returns a
+// *CapabilityError when the tool cannot work in it: no DISPLAY, a Wayland-only
+// session, a display that refuses the connection, or a missing X extension.
+func EnsureAutomationPermissions(ctx context.Context, toolName string, requirement Requirement) error {
+	if requirement == RequireNothing {
+		return nil
+	}
+	caps, err := probeX11(ctx, toolName)
+	if err != nil {
+		return err
+	}
+	return caps.require(toolName, requirement)
+}
+
+// probeX11 returns the capabilities of the display ctx targets. Only successful
+// probes are cached, so a display that comes up later is picked up.
+func probeX11(ctx context.Context, toolName string) (*x11Capabilities, error) {
+	display := virtualdisplay.XDisplay(ctx)
+	if display == "" {
+		return nil, missingDisplayError(toolName, os.Getenv("WAYLAND_DISPLAY"), virtualdisplay.Available())
+	}
+
+	capabilitiesMu.Lock()
+	caps, ok := capabilitiesByDisplay[display]
+	capabilitiesMu.Unlock()
+	if ok {
+		return caps, nil
+	}
+	caps, err := queryCapabilities(toolName, display)
+	if err != nil {
+		return nil, err
+	}
+	capabilitiesMu.Lock()
+	capabilitiesByDisplay[display] = caps
+	capabilitiesMu.Unlock()
+	return caps, nil
+}
+
+func queryX11Capabilities(toolName, display string) (*x11Capabilities, error) {
+	conn, err := xgb.NewConnDisplay(display)
+	if err != nil {
+		return nil, connectError(toolName, display, err)
+	}
+	defer conn.Close()
+
+	caps := &x11Capabilities{display: display, extensions: make(map[string]bool, len(probedExtensions))}
+	for _, name := range probedExtensions {
+		reply, err := xproto.QueryExtension(conn, uint16(len(name)), name).Reply()
+		if err != nil {
+			return nil, fmt.Errorf("query X extension %s: %w", name, err)
+		}
+		caps.extensions[name] = reply.Present
+	}
+	return caps, nil
+}
+
+// require returns a *CapabilityError when the display lacks the extension requirement needs.
+func (c *x11Capabilities) require(toolName string, requirement Requirement) error {
+	name, ok := requiredExtensions[requirement]
+	if !ok || c.extensions[name] {
+		return nil
+	}
+	return &CapabilityError{
+		ToolName:   toolName,
+		Capability: "the " + name + " extension",
+		Reason:     fmt.Sprintf("X display %q does not offer %s; %s", c.display, name, extensionHints[name]),
+	}
+}
+
+func missingDisplayError(toolName, waylandDisplay string, xvfbAvailable bool) error {
+	virtualHint := "call start_virtual_display"
+	if !xvfbAvailable {
+		virtualHint = "install Xvfb and call start_virtual_display"
+	}
+	if waylandDisplay != "" {
+		return &CapabilityError{
+			ToolName:   toolName,
+			Capability: "an X11 display",
+			Reason: fmt.Sprintf("this is a Wayland-only session (WAYLAND_DISPLAY=%s) and automation uses X11; "+
+				"enable XWayland and set DISPLAY, or %s to work on a virtual display", waylandDisplay, virtualHint),
+		}
+	}
+	return &CapabilityError{
+		ToolName:   toolName,
+		Capability: "an X11 display",
+		Reason:     "DISPLAY is not set; point it at a running X server or " + virtualHint,
+	}
+}
+
+func connectError(toolName, display string, err error) error {
+	if strings.Contains(err.Error(), "authentication refused") {
+		return &CapabilityError{
+			ToolName:   toolName,
+			Capability: fmt.Sprintf("access to X display %q", display),
+			Denied:     true,
+			Reason: fmt.Sprintf("the X server refused authorization (%v); set XAUTHORITY to the display's cookie file "+
+				"or grant access with xhost +si:localuser:$(id -un)", err),
+		}
+	}
+	return &CapabilityError{
+		ToolName:   toolName,
+		Capability: "an X11 display",
+		Reason:     fmt.Sprintf("cannot connect to X display %q (%v); check that the X server is running or call start_virtual_display", display, err),
+	}
+}
@@ -0,0 +1,122 @@
+//go:build linux
+
+package window
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/virtualdisplay"
+)
+
+func TestMissingDisplayError(t *testing.T) {
+	cases := []struct {
+		name    string
+		wayland string
+		xvfb    bool
+		want    string
+	}{
+		{name: "no display", xvfb: true, want: "DISPLAY is not set; point it at a running X server or call start_virtual_display"},
+		{name: "no xvfb", want: "install Xvfb and call start_virtual_display"},
+		{name: "wayland only", wayland: "wayland-0", xvfb: true, want: "Wayland-only session (WAYLAND_DISPLAY=wayland-0)"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := missingDisplayError("list_windows", tc.wayland, tc.xvfb)
+			var capabilityErr *CapabilityError
+			if !errors.As(err, &capabilityErr) || capabilityErr.Denied {
+				t.Fatalf("error = %#v, want a non-denied *CapabilityError", err)
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("error = %q, want it to contain %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestConnectError(t *testing.T) {
+	refused := connectError("click", ":0", errors.New("x protocol authentication refused: No protocol specified"))
+	var capabilityErr *CapabilityError
+	if !errors.As(refused, &capabilityErr) || !capabilityErr.Denied {
+		t.Fatalf("authentication failure = %#v, want a denied *CapabilityError", refused)
+	}
+	if !strings.Contains(refused.Error(), "XAUTHORITY") {
+		t.Fatalf("error should explain how to authorize, got %q", refused)
+	}
+
+	down := connectError("click", ":9", errors.New("cannot connect to :9"))
+	if !errors.As(down, &capabilityErr) || capabilityErr.Denied {
+		t.Fatalf("connection failure = %#v, want a non-denied *CapabilityError", down)
+	}
+}
+
+func TestCapabilitiesRequire(t *testing.T) {
+	caps := &x11Capabilities{display: ":1", extensions: map[string]bool{extensionComposite: true}}
+	if err := caps.require("list_windows", RequireDisplay); err != nil {
+		t.Fatalf("list_windows needs no extension, got %v", err)
+	}
+	err := caps.require("type_text", RequireInput)
+	var capabilityErr *CapabilityError
+	if !errors.As(err, &capabilityErr) || capabilityErr.Capability != "the XTEST extension" {
+		t.Fatalf("type_text without XTEST = %v", err)
+	}
+
+	caps.extensions[extensionXTest] = true
+	if err := caps.require("type_text", RequireInput); err != nil {
+		t.Fatalf("type_text with XTEST: %v", err)
+	}
+}
+
+func TestEnsureAutomationPermissionsWithoutDisplay(t *testing.T) {
+	t.Setenv("DISPLAY", "")
+	t.Setenv("WAYLAND_DISPLAY", "")
+	var capabilityErr *CapabilityError
+	if err := EnsureAutomationPermissions(context.Background(), "focus_window", RequireDisplay); !errors.As(err, &capabilityErr) {
+		t.Fatalf("error = %v, want *CapabilityError", err)
+	}
+	if err := EnsureAutomationPermissions(context.Background(), "compare_images", RequireNothing); err != nil {
+		t.Fatalf("compare_images should not need a display: %v", err)
+	}
+	if screen, accessibility := CheckPermissions(); screen || accessibility {
+		t.Fatalf("CheckPermissions() = %t, %t without a display", screen, accessibility)
+	}
+}
+
+func TestProbeX11CachesPerDisplay(t *testing.T) {
+	queried := map[string]int{}
+	originalQuery := queryCapabilities
+	queryCapabilities = func(_ string, display string) (*x11Capabilities, error) {
+		queried[display]++
+		if display == ":down" {
+			return nil, errors.New("cannot connect")
+		}
+		return &x11Capabilities{display: display, extensions: map[string]bool{extensionXTest: true}}, nil
+	}
+	t.Cleanup(func() {
+		queryCapabilities = originalQuery
+		capabilitiesMu.Lock()
+		capabilitiesByDisplay = make(map[string]*x11Capabilities)
+		capabilitiesMu.Unlock()
+	})
+
+	t.Setenv("DISPLAY", ":7")
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if err := EnsureAutomationPermissions(ctx, "click", RequireInput); err != nil {
+			t.Fatalf("EnsureAutomationPermissions() error = %v", err)
+		}
+	}
+	if err := EnsureAutomationPermissions(virtualdisplay.WithDisplay(ctx, ":8"), "click", RequireInput); err != nil {
+		t.Fatalf("EnsureAutomationPermissions() on :8 error = %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := EnsureAutomationPermissions(virtualdisplay.WithDisplay(ctx, ":down"), "click", RequireInput); err == nil {
+			t.Fatal("expected an unreachable display to fail")
+		}
+	}
+	if queried[":7"] != 1 || queried[":8"] != 1 || queried[":down"] != 2 {
+		t.Fatalf("queries per display = %v, want one per reachable display and a retry for failures", queried)
+	}
+}
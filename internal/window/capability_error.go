@@ -0,0 +1,23 @@
+package window
+
+import "fmt"
+
+// CapabilityError indicates the display environment lacks something the tool
+// needs, such as a reachable X display or an X extension. It is the Linux
+// counterpart of PermissionError.
+type CapabilityError struct {
+	ToolName string
+	// Capability names what is missing, e.g. "an X11 display" or "the XTEST extension".
+	Capability string
+	// Denied is set when the capability exists but refused access, as with X authorization.
+	Denied bool
+	// Reason explains what was found and how to fix it.
+	Reason string
+}
+
+func (e *CapabilityError) Error() string {
+	if e.ToolName == "" {
+		return fmt.Sprintf("%s is unavailable: %s", e.Capability, e.Reason)
+	}
+	return fmt.Sprintf("%s requires %s: %s", e.ToolName, e.Capability, e.Reason)
+}
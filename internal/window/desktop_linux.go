@@ -0,0 +1,260 @@
+//go:build linux
+
+package window
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// desktopEntry is the [Desktop Entry] group of a freedesktop.org .desktop file.
+type desktopEntry struct {
+	// ID is the desktop file ID, e.g. "org.gnome.Calculator.desktop".
+	ID             string
+	Path           string
+	Type           string
+	Name           string
+	Exec           string
+	WorkingDir     string
+	StartupWMClass string
+	Terminal       bool
+	Hidden         bool
+}
+
+// desktopDirs returns the applications directories in XDG precedence order.
+func desktopDirs() []string {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			dataHome = filepath.Join(home, ".local", "share")
+		}
+	}
+	dataDirs := os.Getenv("XDG_DATA_DIRS")
+	if dataDirs == "" {
+		dataDirs = "/usr/local/share:/usr/share"
+	}
+
+	var dirs []string
+	for _, dir := range append([]string{dataHome}, filepath.SplitList(dataDirs)...) {
+		if dir != "" {
+			dirs = append(dirs, filepath.Join(dir, "applications"))
+		}
+	}
+	return dirs
+}
+
+// findDesktopEntry looks an application up by desktop file ID, then by Name, then
+// by the executable in Exec, all case-insensitively. Earlier directories shadow
+// later ones, and hidden or non-application entries are ignored.
+func findDesktopEntry(name string, dirs []string) (*desktopEntry, error) {
+	entries := loadDesktopEntries(dirs)
+	id := strings.TrimSuffix(strings.ToLower(name), ".desktop") + ".desktop"
+	matchers := []func(*desktopEntry) bool{
+		func(e *desktopEntry) bool { return strings.ToLower(e.ID) == id },
+		func(e *desktopEntry) bool { return strings.EqualFold(e.Name, name) },
+		func(e *desktopEntry) bool { return strings.EqualFold(e.executable(), name) },
+	}
+	for _, matches := range matchers {
+		for _, entry := range entries {
+			if matches(entry) {
+				return entry, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no desktop entry for %q", name)
+}
+
+func loadDesktopEntries(dirs []string) []*desktopEntry {
+	seen := make(map[string]bool)
+	var entries []*desktopEntry
+	for _, dir := range dirs {
+		_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(path, ".desktop") {
+				return nil
+			}
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return nil
+			}
+			// Desktop file IDs replace directory separators with dashes.
+			id := strings.ReplaceAll(rel, string(filepath.Separator), "-")
+			if seen[id] {
+				return nil
+			}
+			seen[id] = true
+
+			entry, err := readDesktopEntry(path)
+			if err != nil || entry.Hidden || entry.Type != "Application" || entry.Exec == "" {
+				return nil
+			}
+			entry.ID = id
+			entries = append(entries, entry)
+			return nil
+		})
+	}
+	return entries
+}
+
+func readDesktopEntry(path string) (*desktopEntry, error) {
+	// Accepted G304 suppression: desktop files come from XDG data directories or an explicit launch_app path.
+	// #nosec G304
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open desktop entry: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	entry, err := parseDesktopEntry(file)
+	if err != nil {
+		return nil, fmt.Errorf("parse desktop entry %s: %w", path, err)
+	}
+	entry.Path = path
+	entry.ID = filepath.Base(path)
+	return entry, nil
+}
+
+// parseDesktopEntry reads the unlocalized keys of the [Desktop Entry] group.
+func parseDesktopEntry(r io.Reader) (*desktopEntry, error) {
+	entry := &desktopEntry{}
+	inGroup := false
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			inGroup = line == "[Desktop Entry]"
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !inGroup || !ok {
+			continue
+		}
+		value = unescapeDesktopValue(strings.TrimSpace(value))
+		switch strings.TrimSpace(key) {
+		case "Type":
+			entry.Type = value
+		case "Name":
+			entry.Name = value
+		case "Exec":
+			entry.Exec = value
+		case "Path":
+			entry.WorkingDir = value
+		case "StartupWMClass":
+			entry.StartupWMClass = value
+		case "Terminal":
+			entry.Terminal = value == "true"
+		case "Hidden":
+			entry.Hidden = value == "true"
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read desktop entry: %w", err)
+	}
+	return entry, nil
+}
+
+// unescapeDesktopValue applies the string escapes allowed in desktop file values.
+func unescapeDesktopValue(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+	replacer := strings.NewReplacer(`\s`, " ", `\n`, "\n", `\t`, "\t", `\r`, "\r", `\\`, `\`)
+	return replacer.Replace(value)
+}
+
+// command returns the argument vector for launching the entry without files or URLs.
+func (e *desktopEntry) command() ([]string, error) {
+	args, err := splitExec(e.Exec)
+	if err != nil {
+		return nil, fmt.Errorf("desktop entry %s: %w", e.ID, err)
+	}
+	var expanded []string
+	for _, arg := range args {
+		switch arg {
+		// File and URL placeholders expand to nothing when no files are passed;
+		// %i and the deprecated codes are dropped too.
+		case "%f", "%F", "%u", "%U", "%i", "%d", "%D", "%n", "%N", "%v", "%m":
+			continue
+		}
+		expanded = append(expanded, expandFieldCodes(arg, e))
+	}
+	if len(expanded) == 0 {
+		return nil, fmt.Errorf("desktop entry %s has an empty Exec key", e.ID)
+	}
+	return expanded, nil
+}
+
+// executable returns the base name of the program in Exec, or "" when Exec is invalid.
+func (e *desktopEntry) executable() string {
+	args, err := splitExec(e.Exec)
+	if err != nil || len(args) == 0 {
+		return ""
+	}
+	return filepath.Base(args[0])
+}
+
+// splitExec splits an Exec value into arguments, honoring double quotes and the
+// backslash escapes allowed inside them.
+func splitExec(value string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inQuotes, inArg := false, false
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case inQuotes && c == '\\' && i+1 < len(value):
+			i++
+			current.WriteByte(value[i])
+		case c == '"':
+			inQuotes = !inQuotes
+			inArg = true
+		case !inQuotes && (c == ' ' || c == '\t'):
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteByte(c)
+			inArg = true
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote in Exec %q", value)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// expandFieldCodes replaces %c, %k and %% inside an argument and removes other codes.
+func expandFieldCodes(arg string, e *desktopEntry) string {
+	if !strings.Contains(arg, "%") {
+		return arg
+	}
+	var out strings.Builder
+	for i := 0; i < len(arg); i++ {
+		if arg[i] != '%' || i+1 == len(arg) {
+			out.WriteByte(arg[i])
+			continue
+		}
+		i++
+		switch arg[i] {
+		case '%':
+			out.WriteByte('%')
+		case 'c':
+			out.WriteString(e.Name)
+		case 'k':
+			out.WriteString(e.Path)
+		}
+	}
+	return out.String()
+}
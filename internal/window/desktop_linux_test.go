@@ -0,0 +1,106 @@
+//go:build linux
+
+package window
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDesktopEntry(t *testing.T) {
+	const data = `# comment
+[Desktop Entry]
+Type=Application
+Name=Text Editor
+Name[de]=Texteditor
+Exec=gedit --new-window %U
+Path=/tmp
+StartupWMClass=Gedit
+Terminal=false
+
+[Desktop Action new-window]
+Exec=ignored
+`
+	entry, err := parseDesktopEntry(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if entry.Type != "Application" || entry.Name != "Text Editor" || entry.Exec != "gedit --new-window %U" ||
+		entry.WorkingDir != "/tmp" || entry.StartupWMClass != "Gedit" || entry.Terminal {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+}
+
+func TestDesktopEntryCommand(t *testing.T) {
+	cases := []struct {
+		exec string
+		want []string
+	}{
+		{exec: "gedit %U", want: []string{"gedit"}},
+		{exec: `"/opt/My App/app" --title=%c --file %f`, want: []string{"/opt/My App/app", "--title=Editor", "--file"}},
+		{exec: `sh -c "echo \"100%%\""`, want: []string{"sh", "-c", `echo "100%"`}},
+		{exec: `app --desktop=%k`, want: []string{"app", "--desktop=/usr/share/applications/app.desktop"}},
+	}
+	for _, tc := range cases {
+		entry := &desktopEntry{ID: "app.desktop", Name: "Editor", Exec: tc.exec, Path: "/usr/share/applications/app.desktop"}
+		got, err := entry.command()
+		if err != nil {
+			t.Errorf("command(%q): %v", tc.exec, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("command(%q) = %q, want %q", tc.exec, got, tc.want)
+		}
+	}
+
+	if _, err := (&desktopEntry{Exec: `"unterminated`}).command(); err == nil {
+		t.Error("expected an unterminated quote to fail")
+	}
+	if got := unescapeDesktopValue(`a\sb\\c`); got != `a b\c` {
+		t.Errorf("unescapeDesktopValue = %q", got)
+	}
+}
+
+func TestFindDesktopEntry(t *testing.T) {
+	user := t.TempDir()
+	system := t.TempDir()
+	writeDesktopFile(t, filepath.Join(system, "org.example.Calc.desktop"), "Calculator", "example-calc")
+	writeDesktopFile(t, filepath.Join(system, "kde", "editor.desktop"), "Editor", "kwrite %U")
+	writeDesktopFile(t, filepath.Join(user, "org.example.Calc.desktop"), "Calculator", "example-calc --user")
+	hidden := "[Desktop Entry]\nType=Application\nName=Gone\nExec=gone\nHidden=true\n"
+	if err := os.WriteFile(filepath.Join(system, "gone.desktop"), []byte(hidden), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	dirs := []string{user, system}
+
+	for _, name := range []string{"org.example.Calc", "org.example.calc.desktop", "calculator", "example-calc"} {
+		entry, err := findDesktopEntry(name, dirs)
+		if err != nil {
+			t.Errorf("findDesktopEntry(%q): %v", name, err)
+			continue
+		}
+		if entry.Exec != "example-calc --user" {
+			t.Errorf("findDesktopEntry(%q) = %s, want the user entry to shadow the system one", name, entry.Path)
+		}
+	}
+	if entry, err := findDesktopEntry("kde-editor", dirs); err != nil || entry.executable() != "kwrite" {
+		t.Errorf("subdirectory IDs use dashes: %+v, %v", entry, err)
+	}
+	if _, err := findDesktopEntry("gone", dirs); err == nil {
+		t.Error("hidden entries must not match")
+	}
+}
+
+func writeDesktopFile(t *testing.T, path, name, exec string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	data := "[Desktop Entry]\nType=Application\nName=" + name + "\nExec=" + exec + "\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,79 @@
+package window
+
+import (
+	"image"
+	"math"
+)
+
+func clampCoord(val, maxValue float64) float64 {
+	if val < 0 {
+		return 0
+	}
+	if val >= maxValue {
+		return maxValue - 1
+	}
+	return val
+}
+
+func cropRectForRegion(imgBounds image.Rectangle, x, y, width, height, scale float64, coordSpace string) image.Rectangle {
+	var x1f, y1f, x2f, y2f float64
+	if coordSpace == "pixels" {
+		x1f, y1f, x2f, y2f = x, y, x+width, y+height
+	} else {
+		x1f, y1f, x2f, y2f = x*scale, y*scale, (x+width)*scale, (y+height)*scale
+	}
+
+	return clampRect(image.Rect(
+		floatToIntBounded(x1f),
+		floatToIntBounded(y1f),
+		floatToIntBounded(x2f),
+		floatToIntBounded(y2f),
+	), imgBounds)
+}
+
+func cropRectForWindow(bounds Bounds, imgBounds image.Rectangle, scale float64) image.Rectangle {
+	x1 := floatToIntBounded(bounds.X * scale)
+	y1 := floatToIntBounded(bounds.Y * scale)
+	x2 := floatToIntBounded((bounds.X + bounds.Width) * scale)
+	y2 := floatToIntBounded((bounds.Y + bounds.Height) * scale)
+	return clampRect(image.Rect(x1, y1, x2, y2), imgBounds)
+}
+
+func floatToIntBounded(value float64) int {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0
+	}
+
+	maxInt := float64(int(^uint(0) >> 1))
+	minInt := float64(-int(^uint(0)>>1) - 1)
+	if value > maxInt {
+		return int(^uint(0) >> 1)
+	}
+	if value < minInt {
+		return -int(^uint(0)>>1) - 1
+	}
+	return int(value)
+}
+
+func clampRect(rect, bounds image.Rectangle) image.Rectangle {
+	x1, y1, x2, y2 := rect.Min.X, rect.Min.Y, rect.Max.X, rect.Max.Y
+	if x1 < bounds.Min.X {
+		x1 = bounds.Min.X
+	}
+	if y1 < bounds.Min.Y {
+		y1 = bounds.Min.Y
+	}
+	if x2 > bounds.Max.X {
+		x2 = bounds.Max.X
+	}
+	if y2 > bounds.Max.Y {
+		y2 = bounds.Max.Y
+	}
+	return image.Rect(x1, y1, x2, y2)
+}
+
+func cropImage(img image.Image, rect image.Rectangle) image.Image {
+	return img.(interface {
+		SubImage(r image.Rectangle) image.Image
+	}).SubImage(rect)
+}
@@ -0,0 +1,284 @@
+//go:build linux && integration
+
+package window
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+// Integration tests require an X display and the integration build tag.
+// Run with: xvfb-run -s "-screen 0 1280x800x24" go test -tags=integration ./internal/window/...
+
+const testWindowTitle = "screenshot-mcp integration"
+
+// openTestWindow maps a solid red 320x240 window at (40, 60) and keeps it open for the test.
+func openTestWindow(t *testing.T) uint32 {
+	t.Helper()
+	if os.Getenv("DISPLAY") == "" {
+		t.Skip("DISPLAY is not set")
+	}
+
+	conn, err := xgb.NewConn()
+	if err != nil {
+		t.Fatalf("connect to X: %v", err)
+	}
+	t.Cleanup(conn.Close)
+
+	screen := xproto.Setup(conn).DefaultScreen(conn)
+	win, err := xproto.NewWindowId(conn)
+	if err != nil {
+		t.Fatalf("allocate window id: %v", err)
+	}
+	err = xproto.CreateWindowChecked(conn, screen.RootDepth, win, screen.Root, 40, 60, 320, 240, 0,
+		xproto.WindowClassInputOutput, screen.RootVisual, xproto.CwBackPixel, []uint32{0xff0000}).Check()
+	if err != nil {
+		t.Fatalf("create window: %v", err)
+	}
+
+	setProperty := func(name, typeName string, value []byte) {
+		atom, err := xproto.InternAtom(conn, false, uint16(len(name)), name).Reply()
+		if err != nil {
+			t.Fatalf("intern %s: %v", name, err)
+		}
+		typeAtom, err := xproto.InternAtom(conn, false, uint16(len(typeName)), typeName).Reply()
+		if err != nil {
+			t.Fatalf("intern %s: %v", typeName, err)
+		}
+		err = xproto.ChangePropertyChecked(conn, xproto.PropModeReplace, win, atom.Atom, typeAtom.Atom, 8, uint32(len(value)), value).Check()
+		if err != nil {
+			t.Fatalf("set %s: %v", name, err)
+		}
+	}
+	setProperty("_NET_WM_NAME", "UTF8_STRING", []byte(testWindowTitle))
+	setProperty("WM_CLASS", "STRING", []byte("integration\x00ScreenshotMCPTest\x00"))
+
+	if err := xproto.MapWindowChecked(conn, win).Check(); err != nil {
+		t.Fatalf("map window: %v", err)
+	}
+	// Give the server (and any window manager) a moment to map and paint the window.
+	time.Sleep(300 * time.Millisecond)
+	return uint32(win)
+}
+
+func TestIntegrationLinux_ListAndFocusWindow(t *testing.T) {
+	windowID := openTestWindow(t)
+	ctx := context.Background()
+
+	windows, err := ListWindows(ctx)
+	if err != nil {
+		t.Fatalf("ListWindows failed: %v", err)
+	}
+	var found *Window
+	for i := range windows {
+		if windows[i].WindowID == windowID {
+			found = &windows[i]
+		}
+	}
+	if found == nil {
+		t.Fatalf("test window %d not listed in %+v", windowID, windows)
+	}
+	if found.Title != testWindowTitle || found.OwnerName != "ScreenshotMCPTest" {
+		t.Fatalf("unexpected window description: %+v", found)
+	}
+	if found.Bounds.Width != 320 || found.Bounds.Height != 240 {
+		t.Fatalf("unexpected bounds: %+v", found.Bounds)
+	}
+
+	if err := FocusWindow(ctx, windowID); err != nil {
+		t.Fatalf("FocusWindow failed: %v", err)
+	}
+}
+
+func TestIntegrationLinux_WindowScreenshotAndWait(t *testing.T) {
+	windowID := openTestWindow(t)
+	ctx := context.Background()
+
+	img, metadata, err := TakeWindowScreenshotImage(ctx, windowID)
+	if err != nil {
+		t.Fatalf("TakeWindowScreenshotImage failed: %v", err)
+	}
+	if metadata.Scale != 1 || metadata.ImageWidth != 320 || metadata.ImageHeight != 240 || !metadata.OcclusionFree {
+		t.Fatalf("unexpected metadata: %+v", metadata)
+	}
+	bounds := img.Bounds()
+	r, g, b, _ := img.At(bounds.Min.X+160, bounds.Min.Y+120).RGBA()
+	if r>>8 < 240 || g>>8 > 15 || b>>8 > 15 {
+		t.Fatalf("expected a red window, got rgb(%d,%d,%d)", r>>8, g>>8, b>>8)
+	}
+
+	if err := WaitForPixel(ctx, windowID, 10, 10, [4]uint8{255, 0, 0, 255}, 8, 2000, 50); err != nil {
+		t.Fatalf("WaitForPixel failed: %v", err)
+	}
+	if err := WaitForRegionStable(ctx, windowID, 0, 0, 100, 100, 2, 2000, 50); err != nil {
+		t.Fatalf("WaitForRegionStable failed: %v", err)
+	}
+
+	_, region, err := TakeRegionScreenshotPNG(ctx, 40, 60, 100, 50, "points")
+	if err != nil {
+		t.Fatalf("TakeRegionScreenshotPNG failed: %v", err)
+	}
+	if region.ImageWidth != 100 || region.ImageHeight != 50 {
+		t.Fatalf("unexpected region metadata: %+v", region)
+	}
+}
+
+func TestIntegrationLinux_MouseMove(t *testing.T) {
+	windowID := openTestWindow(t)
+	ctx := context.Background()
+
+	if err := MouseMove(ctx, windowID, 25, 35); err != nil {
+		t.Fatalf("MouseMove failed: %v", err)
+	}
+
+	conn, err := xgb.NewConn()
+	if err != nil {
+		t.Fatalf("connect to X: %v", err)
+	}
+	defer conn.Close()
+	pointer, err := xproto.QueryPointer(conn, xproto.Setup(conn).DefaultScreen(conn).Root).Reply()
+	if err != nil {
+		t.Fatalf("query pointer: %v", err)
+	}
+	if pointer.RootX != 40+25 || pointer.RootY != 60+35 {
+		t.Fatalf("pointer at (%d, %d), want (65, 95)", pointer.RootX, pointer.RootY)
+	}
+
+	if err := Click(ctx, windowID, 25, 35, "left", 1); err != nil {
+		t.Fatalf("Click failed: %v", err)
+	}
+	if err := Scroll(ctx, windowID, 25, 35, 0, 80); err != nil {
+		t.Fatalf("Scroll failed: %v", err)
+	}
+}
+
+func TestIntegrationLinux_QuitAppSendsDeleteWindow(t *testing.T) {
+	if os.Getenv("DISPLAY") == "" {
+		t.Skip("DISPLAY is not set")
+	}
+	conn, err := xgb.NewConn()
+	if err != nil {
+		t.Fatalf("connect to X: %v", err)
+	}
+	t.Cleanup(conn.Close)
+
+	intern := func(name string) xproto.Atom {
+		reply, err := xproto.InternAtom(conn, false, uint16(len(name)), name).Reply()
+		if err != nil {
+			t.Fatalf("intern %s: %v", name, err)
+		}
+		return reply.Atom
+	}
+	screen := xproto.Setup(conn).DefaultScreen(conn)
+	win, err := xproto.NewWindowId(conn)
+	if err != nil {
+		t.Fatalf("allocate window id: %v", err)
+	}
+	if err := xproto.CreateWindowChecked(conn, screen.RootDepth, win, screen.Root, 0, 0, 200, 100, 0,
+		xproto.WindowClassInputOutput, screen.RootVisual, 0, nil).Check(); err != nil {
+		t.Fatalf("create window: %v", err)
+	}
+	class := []byte("quittest\x00ScreenshotMCPQuitTest\x00")
+	xproto.ChangeProperty(conn, xproto.PropModeReplace, win, xproto.AtomWmClass, xproto.AtomString, 8, uint32(len(class)), class)
+	deleteAtom := intern("WM_DELETE_WINDOW")
+	protocols := make([]byte, 4)
+	xgb.Put32(protocols, uint32(deleteAtom))
+	xproto.ChangeProperty(conn, xproto.PropModeReplace, win, intern("WM_PROTOCOLS"), xproto.AtomAtom, 32, 1, protocols)
+	if err := xproto.MapWindowChecked(conn, win).Check(); err != nil {
+		t.Fatalf("map window: %v", err)
+	}
+	time.Sleep(300 * time.Millisecond)
+
+	if err := QuitApp(context.Background(), "ScreenshotMCPQuitTest"); err != nil {
+		t.Fatalf("QuitApp failed: %v", err)
+	}
+
+	// Without a window manager the request goes straight to the client. With one,
+	// the window manager forwards it as the same client message.
+	deadline := time.After(2 * time.Second)
+	for {
+		events := make(chan xgb.Event, 1)
+		go func() {
+			event, _ := conn.WaitForEvent()
+			events <- event
+		}()
+		select {
+		case event := <-events:
+			message, ok := event.(xproto.ClientMessageEvent)
+			if ok && message.Window == win && xproto.Atom(message.Data.Data32[0]) == deleteAtom {
+				return
+			}
+		case <-deadline:
+			t.Fatal("no WM_DELETE_WINDOW message received")
+		}
+	}
+}
+
+func TestIntegrationLinux_QuitAppSignalsWithoutDeleteWindow(t *testing.T) {
+	if os.Getenv("DISPLAY") == "" {
+		t.Skip("DISPLAY is not set")
+	}
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("sleep is not installed")
+	}
+	conn, err := xgb.NewConn()
+	if err != nil {
+		t.Fatalf("connect to X: %v", err)
+	}
+	t.Cleanup(conn.Close)
+	screen := xproto.Setup(conn).DefaultScreen(conn)
+	wmCheck, err := xproto.InternAtom(conn, false, uint16(len("_NET_SUPPORTING_WM_CHECK")), "_NET_SUPPORTING_WM_CHECK").Reply()
+	if err != nil {
+		t.Fatalf("intern atom: %v", err)
+	}
+	if reply, err := xproto.GetProperty(conn, false, screen.Root, wmCheck.Atom, xproto.AtomWindow, 0, 1).Reply(); err == nil && reply.ValueLen > 0 {
+		t.Skip("a window manager would close the window itself")
+	}
+
+	// The process owns a window that does not support WM_DELETE_WINDOW.
+	binary := filepath.Join(t.TempDir(), "mcpnodelete")
+	copyExecutable(t, sleep, binary)
+	cmd := exec.Command(binary, "30")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("cannot run copied binary: %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(done)
+	}()
+	win, err := xproto.NewWindowId(conn)
+	if err != nil {
+		t.Fatalf("allocate window id: %v", err)
+	}
+	if err := xproto.CreateWindowChecked(conn, screen.RootDepth, win, screen.Root, 0, 0, 200, 100, 0,
+		xproto.WindowClassInputOutput, screen.RootVisual, 0, nil).Check(); err != nil {
+		t.Fatalf("create window: %v", err)
+	}
+	class := []byte("mcpnodelete\x00mcpnodelete\x00")
+	xproto.ChangeProperty(conn, xproto.PropModeReplace, win, xproto.AtomWmClass, xproto.AtomString, 8, uint32(len(class)), class)
+	if err := xproto.MapWindowChecked(conn, win).Check(); err != nil {
+		t.Fatalf("map window: %v", err)
+	}
+
+	started := time.Now()
+	if err := QuitApp(context.Background(), "mcpnodelete"); err != nil {
+		t.Fatalf("QuitApp failed: %v", err)
+	}
+	if elapsed := time.Since(started); elapsed >= quitGrace {
+		t.Fatalf("QuitApp took %v, want SIGTERM without waiting for the window to close", elapsed)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("process still running after quit_app")
+	}
+}
@@ -0,0 +1,17 @@
+package window
+
+import "fmt"
+
+// PermissionError indicates missing macOS permissions required by the tool.
+type PermissionError struct {
+	ToolName      string
+	Screen        bool
+	Accessibility bool
+}
+
+func (e *PermissionError) Error() string {
+	if e.ToolName == "" {
+		return "required macOS permissions are not granted"
+	}
+	return fmt.Sprintf("%s requires macOS permissions: screen recording=%t accessibility=%t. enable both in System Settings > Privacy & Security", e.ToolName, e.Screen, e.Accessibility)
+}
@@ -0,0 +1,38 @@
+//go:build darwin || linux
+
+package window
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// WaitForProcess waits for a process with the given name to appear.
+// timeoutMs is the maximum time to wait in milliseconds.
+// pollIntervalMs is how often to check in milliseconds.
+func WaitForProcess(ctx context.Context, processName string, timeoutMs, pollIntervalMs int) error {
+	if pollIntervalMs <= 0 {
+		pollIntervalMs = 100
+	}
+	if timeoutMs <= 0 {
+		timeoutMs = 5000
+	}
+
+	timeout := time.After(time.Duration(timeoutMs) * time.Millisecond)
+	ticker := time.NewTicker(time.Duration(pollIntervalMs) * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("wait for process: %w", ctx.Err())
+		case <-timeout:
+			return fmt.Errorf("timeout waiting for process %q", processName)
+		case <-ticker.C:
+			if isProcessRunning(ctx, processName) {
+				return nil
+			}
+		}
+	}
+}
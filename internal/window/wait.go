@@ -1,4 +1,4 @@
-//go:build darwin
+//go:build darwin || linux
 
 package window
 
@@ -8,8 +8,6 @@ import (
 	"image"
 	"image/draw"
 	"time"
-
-	"github.com/brainwhocodes/screenshot_mcp_server/internal/screenshot"
 )
 
 // WaitForPixel waits until the pixel at (x,y) matches the expected RGBA within tolerance.
@@ -152,29 +150,18 @@ func imageHash(img image.Image, region image.Rectangle) uint64 {
 	return hash
 }
 
+// captureWindowImageForWait captures a window into an RGBA image whose origin is (0, 0),
+// so pixel coordinates from tool arguments index it directly.
 func captureWindowImageForWait(ctx context.Context, windowID uint32) (image.Image, *ScreenshotMetadata, error) {
-	targetWindow, err := findWindowByID(ctx, windowID)
+	captured, metadata, err := captureWindowImageRaw(ctx, windowID)
 	if err != nil {
-		return nil, nil, fmt.Errorf("find window %d: %w", windowID, err)
+		return nil, nil, err
 	}
 
-	capturer := screenshot.NewCapturer()
-	fullImage, err := capturer.Capture(ctx)
-	if err != nil {
-		return nil, nil, fmt.Errorf("capture screen: %w", err)
+	if rgba, ok := captured.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba, metadata, nil
 	}
-
-	scale := getScaleForWindow(targetWindow.Bounds)
-	cropRect := cropRectForWindow(targetWindow.Bounds, fullImage.Bounds(), scale)
-	croppedImage := cropImage(fullImage, cropRect)
-	cropped := image.NewRGBA(croppedImage.Bounds())
-	draw.Draw(cropped, croppedImage.Bounds(), croppedImage, croppedImage.Bounds().Min, draw.Src)
-
-	return cropped, &ScreenshotMetadata{
-		WindowID:    windowID,
-		Bounds:      targetWindow.Bounds,
-		ImageWidth:  cropRect.Dx(),
-		ImageHeight: cropRect.Dy(),
-		Scale:       scale,
-	}, nil
+	normalized := image.NewRGBA(image.Rect(0, 0, metadata.ImageWidth, metadata.ImageHeight))
+	draw.Draw(normalized, normalized.Bounds(), captured, captured.Bounds().Min, draw.Src)
+	return normalized, metadata, nil
 }
@@ -1,7 +1,6 @@
 //go:build darwin
 // +build darwin
 
-// Package window provides macOS window discovery and input helpers used by MCP tools.
 package window
 
 /*
@@ -272,24 +271,29 @@ func SupportsWindowTools() bool {
 	return true
 }
 
-func postMouseMoveEvent(x, y float64) {
+func postMouseMoveEvent(_ context.Context, x, y float64) error {
 	C.post_mouse_move(C.double(x), C.double(y))
+	return nil
 }
 
-func postMouseDownEvent(x, y float64, button int) {
+func postMouseDownEvent(_ context.Context, x, y float64, button int) error {
 	C.post_mouse_down(C.double(x), C.double(y), C.int(button))
+	return nil
 }
 
-func postMouseUpEvent(x, y float64, button int) {
+func postMouseUpEvent(_ context.Context, x, y float64, button int) error {
 	C.post_mouse_up(C.double(x), C.double(y), C.int(button))
+	return nil
 }
 
-func postMouseClickEvent(x, y float64, button int, clicks int) {
+func postMouseClickEvent(_ context.Context, x, y float64, button int, clicks int) error {
 	C.post_mouse_click(C.double(x), C.double(y), C.int(button), C.int(clicks))
+	return nil
 }
 
-func postScrollEvent(x, y, deltaX, deltaY float64) {
+func postScrollEvent(_ context.Context, x, y, deltaX, deltaY float64) error {
 	C.post_scroll(C.double(x), C.double(y), C.double(deltaX), C.double(deltaY))
+	return nil
 }
 
 func scaleAtPoint(x, y float64) float64 {
@@ -458,56 +462,3 @@ func permissionState() (bool, bool) {
 func UnsupportedWindowToolsReason() string {
 	return ""
 }
-
-// Window represents a macOS window
-type Window struct {
-	WindowID   uint32 `json:"window_id"`
-	OwnerName  string `json:"owner_name"`
-	PID        int32  `json:"pid"`
-	Title      string `json:"title"`
-	Bounds     Bounds `json:"bounds"`
-	IsOnScreen bool   `json:"is_on_screen"`
-}
-
-// Bounds represents window bounds in screen coordinates (points)
-type Bounds struct {
-	X      float64 `json:"x"`
-	Y      float64 `json:"y"`
-	Width  float64 `json:"width"`
-	Height float64 `json:"height"`
-}
-
-// ScreenshotMetadata contains metadata about a window screenshot
-type ScreenshotMetadata struct {
-	WindowID    uint32  `json:"window_id"`
-	Bounds      Bounds  `json:"bounds"`
-	ImageWidth  int     `json:"image_width"`
-	ImageHeight int     `json:"image_height"`
-	Scale       float64 `json:"scale"`
-}
-
-// IsTiny returns true if window is smaller than 50x50
-func (w *Window) IsTiny() bool {
-	return w.Bounds.Width < 50 || w.Bounds.Height < 50
-}
-
-// IsSystemWindow returns true if this looks like a system overlay window
-func (w *Window) IsSystemWindow() bool {
-	// Filter out known system/window server windows
-	systemOwners := map[string]bool{
-		"Window Server":  true,
-		"SystemUIServer": true,
-		"Dock":           true,
-		"loginwindow":    true,
-		"coreauthd":      true,
-		"AppleSpell":     true,
-		"Finder":         false, // Keep Finder
-		"":               true,
-	}
-
-	if isSystem, exists := systemOwners[w.OwnerName]; exists {
-		return isSystem
-	}
-
-	return false
-}
@@ -0,0 +1,97 @@
+//go:build darwin || linux
+
+package window
+
+import (
+	"context"
+	"fmt"
+	"image"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/screenshot"
+)
+
+// WindowCapturer captures the pixels of a single window.
+type WindowCapturer interface {
+	CaptureWindow(ctx context.Context, target Window) (*WindowCapture, error)
+}
+
+// WindowCapture is a captured window image.
+type WindowCapture struct {
+	Image image.Image
+	// Scale is the number of image pixels per bounds unit.
+	Scale float64
+	// OcclusionFree reports that the pixels came from the window itself, so
+	// overlapping windows and off-screen areas do not affect the image.
+	OcclusionFree bool
+}
+
+// defaultWindowCapturer backs window screenshots and wait polling. Its frames
+// are shared for the context's screenshot.CacheMaxAge like full-screen captures.
+var defaultWindowCapturer WindowCapturer = cachedWindowCapturer{
+	capturer: NewWindowCapturer(),
+	frames:   screenshot.NewCache[*WindowCapture](),
+}
+
+// NewWindowCapturer returns the platform's direct window capturer, falling back
+// to cropping a full-screen capture when the direct path fails.
+func NewWindowCapturer() WindowCapturer {
+	return fallbackWindowCapturer{
+		primary:  nativeWindowCapturer{},
+		fallback: screenCropCapturer{screen: screenshot.NewCapturer()},
+	}
+}
+
+type fallbackWindowCapturer struct {
+	primary  WindowCapturer
+	fallback WindowCapturer
+}
+
+func (c fallbackWindowCapturer) CaptureWindow(ctx context.Context, target Window) (*WindowCapture, error) {
+	// A replay backend only provides whole screens, so crop those instead of
+	// reading the live window.
+	if !screenshot.SystemBackendActive() {
+		return c.fallback.CaptureWindow(ctx, target)
+	}
+	capture, err := c.primary.CaptureWindow(ctx, target)
+	if err == nil {
+		return capture, nil
+	}
+	if ctx.Err() != nil {
+		return nil, err
+	}
+	return c.fallback.CaptureWindow(ctx, target)
+}
+
+// cachedWindowCapturer shares recent captures of the same window. The key
+// includes the bounds so a moved or resized window is captured again.
+type cachedWindowCapturer struct {
+	capturer WindowCapturer
+	frames   *screenshot.Cache[*WindowCapture]
+}
+
+func (c cachedWindowCapturer) CaptureWindow(ctx context.Context, target Window) (*WindowCapture, error) {
+	key := fmt.Sprintf("%d@%v", target.WindowID, target.Bounds)
+	return c.frames.Get(ctx, key, func(ctx context.Context) (*WindowCapture, error) {
+		return c.capturer.CaptureWindow(ctx, target)
+	})
+}
+
+// screenCropCapturer captures every display and crops to the window bounds.
+// Overlapping windows show through, and off-screen parts are clipped.
+type screenCropCapturer struct {
+	screen screenshot.Capturer
+}
+
+func (c screenCropCapturer) CaptureWindow(ctx context.Context, target Window) (*WindowCapture, error) {
+	fullImg, err := c.screen.Capture(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("capture screen: %w", err)
+	}
+
+	scale := getScaleForWindow(target.Bounds)
+	cropRect := cropRectForWindow(target.Bounds, fullImg.Bounds(), scale)
+	return &WindowCapture{
+		Image: cropImage(fullImg, cropRect),
+		Scale: scale,
+	}, nil
+}
@@ -0,0 +1,77 @@
+//go:build darwin
+
+package window
+
+/*
+#cgo LDFLAGS: -framework CoreGraphics -framework CoreFoundation
+#include <CoreGraphics/CoreGraphics.h>
+#include <stdint.h>
+
+#pragma clang diagnostic push
+#pragma clang diagnostic ignored "-Wdeprecated-declarations"
+static CGImageRef create_window_image(uint32_t windowID) {
+    return CGWindowListCreateImage(
+        CGRectNull,
+        kCGWindowListOptionIncludingWindow,
+        (CGWindowID)windowID,
+        kCGWindowImageBoundsIgnoreFraming | kCGWindowImageBestResolution
+    );
+}
+#pragma clang diagnostic pop
+
+// Draws image into a caller-owned RGBA buffer of width*height*4 bytes.
+static int copy_image_rgba(CGImageRef image, void *buffer, size_t width, size_t height) {
+    CGColorSpaceRef colorSpace = CGColorSpaceCreateDeviceRGB();
+    if (colorSpace == NULL) return 0;
+    CGContextRef context = CGBitmapContextCreate(
+        buffer, width, height, 8, width * 4, colorSpace,
+        kCGImageAlphaPremultipliedLast | kCGBitmapByteOrder32Big
+    );
+    CGColorSpaceRelease(colorSpace);
+    if (context == NULL) return 0;
+    CGContextDrawImage(context, CGRectMake(0, 0, width, height), image);
+    CGContextRelease(context);
+    return 1;
+}
+*/
+import "C"
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"unsafe"
+)
+
+// nativeWindowCapturer captures a single window with CGWindowListCreateImage,
+// which renders the window's own backing store regardless of what covers it.
+type nativeWindowCapturer struct{}
+
+func (nativeWindowCapturer) CaptureWindow(ctx context.Context, target Window) (*WindowCapture, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("capture window: %w", err)
+	}
+
+	cgImage := C.create_window_image(C.uint32_t(target.WindowID))
+	if cgImage == 0 {
+		return nil, fmt.Errorf("no image returned by CGWindowListCreateImage for window %d", target.WindowID)
+	}
+	defer C.CGImageRelease(cgImage)
+
+	width := int(C.CGImageGetWidth(cgImage))
+	height := int(C.CGImageGetHeight(cgImage))
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("window %d image is empty", target.WindowID)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	if C.copy_image_rgba(cgImage, unsafe.Pointer(&img.Pix[0]), C.size_t(width), C.size_t(height)) == 0 {
+		return nil, fmt.Errorf("convert window %d image", target.WindowID)
+	}
+
+	scale := getScaleForWindow(target.Bounds)
+	if target.Bounds.Width > 0 {
+		scale = float64(width) / target.Bounds.Width
+	}
+	return &WindowCapture{Image: img, Scale: scale, OcclusionFree: true}, nil
+}
@@ -0,0 +1,177 @@
+//go:build linux
+
+package window
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"strconv"
+
+	"github.com/jezek/xgb/composite"
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/screenshot"
+)
+
+// nativeWindowCapturer reads window contents with GetImage. Under a compositing
+// manager it reads the window's offscreen pixmap via XComposite, which is free of
+// occlusion even for covered or partially off-screen windows.
+type nativeWindowCapturer struct{}
+
+func (nativeWindowCapturer) CaptureWindow(ctx context.Context, target Window) (*WindowCapture, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("capture window: %w", err)
+	}
+	session, err := openX11(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer session.close()
+	return session.captureWindow(xproto.Window(target.WindowID))
+}
+
+func (s *x11Session) captureWindow(win xproto.Window) (*WindowCapture, error) {
+	geometry, err := xproto.GetGeometry(s.conn, xproto.Drawable(win)).Reply()
+	if err != nil {
+		return nil, fmt.Errorf("get geometry of window %d: %w", win, err)
+	}
+
+	if s.compositorRunning() {
+		if capture, err := s.captureComposited(win, geometry.Width, geometry.Height); err == nil {
+			return capture, nil
+		}
+	}
+
+	// Without a compositor GetImage returns what is on screen inside the window,
+	// and fails when part of the window is off-screen.
+	img, err := s.getImage(xproto.Drawable(win), 0, 0, geometry.Width, geometry.Height)
+	if err != nil {
+		return nil, fmt.Errorf("read contents of window %d: %w", win, err)
+	}
+	obscured, err := s.isObscured(win)
+	if err != nil {
+		return nil, err
+	}
+	return &WindowCapture{Image: img, Scale: 1, OcclusionFree: !obscured}, nil
+}
+
+// compositorRunning reports whether a compositing manager owns _NET_WM_CM_Sn.
+func (s *x11Session) compositorRunning() bool {
+	atom, err := s.atom("_NET_WM_CM_S" + strconv.Itoa(s.conn.DefaultScreen))
+	if err != nil {
+		return false
+	}
+	owner, err := xproto.GetSelectionOwner(s.conn, atom).Reply()
+	return err == nil && owner.Owner != xproto.WindowNone
+}
+
+func (s *x11Session) captureComposited(win xproto.Window, width, height uint16) (*WindowCapture, error) {
+	if err := composite.Init(s.conn); err != nil {
+		return nil, fmt.Errorf("initialize Composite extension: %w", err)
+	}
+
+	// Only top-level windows (frames, under a reparenting window manager) are redirected.
+	top, err := s.topLevel(win)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := xproto.TranslateCoordinates(s.conn, win, top, 0, 0).Reply()
+	if err != nil {
+		return nil, fmt.Errorf("translate coordinates of window %d: %w", win, err)
+	}
+	topGeometry, err := xproto.GetGeometry(s.conn, xproto.Drawable(top)).Reply()
+	if err != nil {
+		return nil, fmt.Errorf("get geometry of window %d: %w", top, err)
+	}
+
+	pixmap, err := xproto.NewPixmapId(s.conn)
+	if err != nil {
+		return nil, fmt.Errorf("allocate pixmap id: %w", err)
+	}
+	if err := composite.NameWindowPixmapChecked(s.conn, top, pixmap).Check(); err != nil {
+		return nil, fmt.Errorf("name pixmap of window %d: %w", top, err)
+	}
+	defer xproto.FreePixmap(s.conn, pixmap)
+
+	// The pixmap includes the border; translated coordinates start inside it.
+	border := int16(topGeometry.BorderWidth)
+	img, err := s.getImage(xproto.Drawable(pixmap), offset.DstX+border, offset.DstY+border, width, height)
+	if err != nil {
+		return nil, fmt.Errorf("read pixmap of window %d: %w", top, err)
+	}
+	return &WindowCapture{Image: img, Scale: 1, OcclusionFree: true}, nil
+}
+
+// topLevel returns the ancestor of win that is a direct child of the root.
+func (s *x11Session) topLevel(win xproto.Window) (xproto.Window, error) {
+	for {
+		tree, err := xproto.QueryTree(s.conn, win).Reply()
+		if err != nil {
+			return 0, fmt.Errorf("query tree of window %d: %w", win, err)
+		}
+		if tree.Parent == s.root || tree.Parent == xproto.WindowNone {
+			return win, nil
+		}
+		win = tree.Parent
+	}
+}
+
+// isObscured reports whether any viewable window stacked above win's top-level overlaps it.
+func (s *x11Session) isObscured(win xproto.Window) (bool, error) {
+	top, err := s.topLevel(win)
+	if err != nil {
+		return false, err
+	}
+	target, err := s.rootRect(top)
+	if err != nil {
+		return false, err
+	}
+	tree, err := xproto.QueryTree(s.conn, s.root).Reply()
+	if err != nil {
+		return false, fmt.Errorf("query window tree: %w", err)
+	}
+
+	// Children are listed in stacking order, bottom first.
+	above := false
+	for _, child := range tree.Children {
+		if child == top {
+			above = true
+			continue
+		}
+		if !above {
+			continue
+		}
+		attrs, err := xproto.GetWindowAttributes(s.conn, child).Reply()
+		if err != nil || attrs.MapState != xproto.MapStateViewable || attrs.Class == xproto.WindowClassInputOnly {
+			continue
+		}
+		rect, err := s.rootRect(child)
+		if err != nil {
+			continue
+		}
+		if rect.Overlaps(target) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// rootRect returns the outer rectangle (including border) of a child of the root.
+func (s *x11Session) rootRect(win xproto.Window) (image.Rectangle, error) {
+	geometry, err := xproto.GetGeometry(s.conn, xproto.Drawable(win)).Reply()
+	if err != nil {
+		return image.Rectangle{}, fmt.Errorf("get geometry of window %d: %w", win, err)
+	}
+	border := 2 * int(geometry.BorderWidth)
+	return image.Rect(
+		int(geometry.X),
+		int(geometry.Y),
+		int(geometry.X)+int(geometry.Width)+border,
+		int(geometry.Y)+int(geometry.Height)+border,
+	), nil
+}
+
+func (s *x11Session) getImage(drawable xproto.Drawable, x, y int16, width, height uint16) (*image.RGBA, error) {
+	return screenshot.GetX11Image(s.conn, drawable, x, y, width, height)
+}
@@ -0,0 +1,122 @@
+//go:build darwin || linux
+
+package window
+
+import (
+	"context"
+	"errors"
+	"image"
+	"testing"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/screenshot"
+)
+
+type stubScreenCapturer struct {
+	img   image.Image
+	calls int
+}
+
+func (s *stubScreenCapturer) Capture(context.Context) (image.Image, error) {
+	s.calls++
+	return s.img, nil
+}
+
+type stubWindowCapturer struct {
+	capture *WindowCapture
+	err     error
+}
+
+func (s stubWindowCapturer) CaptureWindow(context.Context, Window) (*WindowCapture, error) {
+	return s.capture, s.err
+}
+
+func TestFallbackWindowCapturer_PrefersPrimary(t *testing.T) {
+	screen := &stubScreenCapturer{img: image.NewRGBA(image.Rect(0, 0, 100, 100))}
+	direct := &WindowCapture{Image: image.NewRGBA(image.Rect(0, 0, 10, 10)), Scale: 1, OcclusionFree: true}
+	capturer := fallbackWindowCapturer{
+		primary:  stubWindowCapturer{capture: direct},
+		fallback: screenCropCapturer{screen: screen},
+	}
+
+	got, err := capturer.CaptureWindow(context.Background(), Window{Bounds: Bounds{Width: 10, Height: 10}})
+	if err != nil {
+		t.Fatalf("CaptureWindow failed: %v", err)
+	}
+	if got != direct || screen.calls != 0 {
+		t.Fatalf("expected the direct capture without a screen capture, got %+v (screen calls %d)", got, screen.calls)
+	}
+}
+
+func TestFallbackWindowCapturer_CropsScreenOnFailure(t *testing.T) {
+	screen := &stubScreenCapturer{img: image.NewRGBA(image.Rect(0, 0, 400, 300))}
+	capturer := fallbackWindowCapturer{
+		primary:  stubWindowCapturer{err: errors.New("direct capture unavailable")},
+		fallback: screenCropCapturer{screen: screen},
+	}
+
+	target := Window{Bounds: Bounds{X: 10, Y: 20, Width: 50, Height: 40}}
+	got, err := capturer.CaptureWindow(context.Background(), target)
+	if err != nil {
+		t.Fatalf("CaptureWindow failed: %v", err)
+	}
+	if screen.calls != 1 {
+		t.Fatalf("expected one screen capture, got %d", screen.calls)
+	}
+	if got.OcclusionFree {
+		t.Fatal("screen crops must not be reported as occlusion-free")
+	}
+	want := cropRectForWindow(target.Bounds, screen.img.Bounds(), got.Scale)
+	if got.Image.Bounds() != want {
+		t.Fatalf("crop = %v, want %v", got.Image.Bounds(), want)
+	}
+}
+
+func TestFallbackWindowCapturer_StopsOnCancel(t *testing.T) {
+	screen := &stubScreenCapturer{img: image.NewRGBA(image.Rect(0, 0, 10, 10))}
+	capturer := fallbackWindowCapturer{
+		primary:  stubWindowCapturer{err: context.Canceled},
+		fallback: screenCropCapturer{screen: screen},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := capturer.CaptureWindow(ctx, Window{}); err == nil {
+		t.Fatal("expected an error for a canceled context")
+	}
+	if screen.calls != 0 {
+		t.Fatal("canceled captures must not fall back to the screen")
+	}
+}
+
+// replayBackend stands in for a screenshot.Replay.
+type replayBackend struct {
+	stubScreenCapturer
+}
+
+func (*replayBackend) Displays(context.Context) ([]screenshot.Display, error) {
+	return nil, nil
+}
+
+func (r *replayBackend) CaptureDisplay(ctx context.Context, _ int) (image.Image, error) {
+	return r.Capture(ctx)
+}
+
+func TestFallbackWindowCapturer_CropsReplayedFrames(t *testing.T) {
+	screenshot.SetBackend(&replayBackend{})
+	t.Cleanup(func() { screenshot.SetBackend(nil) })
+
+	screen := &stubScreenCapturer{img: image.NewRGBA(image.Rect(0, 0, 100, 100))}
+	direct := &WindowCapture{Image: image.NewRGBA(image.Rect(0, 0, 10, 10)), Scale: 1, OcclusionFree: true}
+	capturer := fallbackWindowCapturer{
+		primary:  stubWindowCapturer{capture: direct},
+		fallback: screenCropCapturer{screen: screen},
+	}
+
+	got, err := capturer.CaptureWindow(context.Background(), Window{Bounds: Bounds{Width: 10, Height: 10}})
+	if err != nil {
+		t.Fatalf("CaptureWindow failed: %v", err)
+	}
+	if got == direct || screen.calls != 1 {
+		t.Fatalf("expected a crop of the replayed screen, got %+v (screen calls %d)", got, screen.calls)
+	}
+}
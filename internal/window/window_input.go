@@ -1,5 +1,4 @@
-//go:build darwin
-// +build darwin
+//go:build darwin || linux
 
 package window
 
@@ -18,15 +17,12 @@ func MouseMove(ctx context.Context, windowID uint32, x, y float64) error {
 		return err
 	}
 
-	postMouseMoveEvent(xPt, yPt)
-	return nil
+	return postMouseMoveEvent(ctx, xPt, yPt)
 }
 
 // MouseDown sends a mouse down event at the specified coordinates.
 func MouseDown(ctx context.Context, windowID uint32, x, y float64, button string) error {
-	return postMouseButton(ctx, windowID, x, y, button, func(xPt, yPt float64, btn int) {
-		postMouseDownEvent(xPt, yPt, btn)
-	})
+	return postMouseButton(ctx, windowID, x, y, button, postMouseDownEvent)
 }
 
 // ClickAt performs a mouse click at screen coordinates.
@@ -41,15 +37,12 @@ func ClickAt(ctx context.Context, x, y float64, button string, clicks int, coord
 	}
 
 	btn := buttonToInt(button)
-	postMouseClickEvent(pointX, pointY, btn, clicks)
-	return nil
+	return postMouseClickEvent(ctx, pointX, pointY, btn, clicks)
 }
 
 // MouseUp sends a mouse up event at the specified coordinates.
 func MouseUp(ctx context.Context, windowID uint32, x, y float64, button string) error {
-	return postMouseButton(ctx, windowID, x, y, button, func(xPt, yPt float64, btn int) {
-		postMouseUpEvent(xPt, yPt, btn)
-	})
+	return postMouseButton(ctx, windowID, x, y, button, postMouseUpEvent)
 }
 
 // Drag performs a drag operation from one point to another.
@@ -59,10 +52,13 @@ func Drag(ctx context.Context, windowID uint32, fromX, fromY, toX, toY float64,
 		return err
 	}
 	btn := buttonToInt(button)
-	postMouseDownEvent(fromXPt, fromYPt, btn)
-	postMouseMoveEvent(toXPt, toYPt)
-	postMouseUpEvent(toXPt, toYPt, btn)
-	return nil
+	if err := postMouseDownEvent(ctx, fromXPt, fromYPt, btn); err != nil {
+		return err
+	}
+	if err := postMouseMoveEvent(ctx, toXPt, toYPt); err != nil {
+		return err
+	}
+	return postMouseUpEvent(ctx, toXPt, toYPt, btn)
 }
 
 // Scroll performs a scroll operation at the specified coordinates.
@@ -72,8 +68,7 @@ func Scroll(ctx context.Context, windowID uint32, x, y, deltaX, deltaY float64)
 	if err != nil {
 		return err
 	}
-	postScrollEvent(xPt, yPt, deltaX, deltaY)
-	return nil
+	return postScrollEvent(ctx, xPt, yPt, deltaX, deltaY)
 }
 
 func postMouseButton(
@@ -81,15 +76,14 @@ func postMouseButton(
 	windowID uint32,
 	x, y float64,
 	button string,
-	send func(float64, float64, int),
+	send func(context.Context, float64, float64, int) error,
 ) error {
 	_, _, xPt, yPt, err := mapWindowInputPoint(ctx, windowID, x, y)
 	if err != nil {
 		return err
 	}
 	btn := buttonToInt(button)
-	send(xPt, yPt, btn)
-	return nil
+	return send(ctx, xPt, yPt, btn)
 }
 
 func mapWindowInputPoint(ctx context.Context, windowID uint32, x, y float64) (*Window, *ScreenshotMetadata, float64, float64, error) {
@@ -173,19 +167,7 @@ func Click(ctx context.Context, windowID uint32, x, y float64, button string, cl
 	}
 
 	btn := buttonToInt(button)
-	postMouseClickEvent(xPt, yPt, btn, clicks)
-
-	return nil
-}
-
-func clampCoord(val, maxValue float64) float64 {
-	if val < 0 {
-		return 0
-	}
-	if val >= maxValue {
-		return maxValue - 1
-	}
-	return val
+	return postMouseClickEvent(ctx, xPt, yPt, btn, clicks)
 }
 
 func buttonToInt(button string) int {
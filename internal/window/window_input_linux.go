@@ -0,0 +1,163 @@
+//go:build linux
+
+package window
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/jezek/xgb/xproto"
+	"github.com/jezek/xgb/xtest"
+)
+
+// scrollPixelsPerStep converts pixel scroll deltas into X11 wheel button clicks.
+const scrollPixelsPerStep = 40.0
+
+// X11 core pointer buttons. Wheel motion is reported as presses of buttons 4-7.
+const (
+	x11ButtonLeft       = 1
+	x11ButtonMiddle     = 2
+	x11ButtonRight      = 3
+	x11ButtonWheelUp    = 4
+	x11ButtonWheelDown  = 5
+	x11ButtonWheelLeft  = 6
+	x11ButtonWheelRight = 7
+)
+
+// withXTest runs fn on a fresh X connection with the XTEST extension initialized.
+func withXTest(ctx context.Context, fn func(*x11Session) error) error {
+	session, err := openX11(ctx)
+	if err != nil {
+		return err
+	}
+	defer session.close()
+	if err := xtest.Init(session.conn); err != nil {
+		return fmt.Errorf("initialize XTEST extension: %w", err)
+	}
+	if err := fn(session); err != nil {
+		return err
+	}
+	// Round-trip so every fake event is processed before the connection closes.
+	if _, err := xproto.GetInputFocus(session.conn).Reply(); err != nil {
+		return fmt.Errorf("sync X connection: %w", err)
+	}
+	return nil
+}
+
+func (s *x11Session) fakePointerMove(x, y float64) error {
+	rootX, rootY := x11Coord(x), x11Coord(y)
+	if err := xtest.FakeInputChecked(s.conn, xproto.MotionNotify, 0, 0, s.root, rootX, rootY, 0).Check(); err != nil {
+		return fmt.Errorf("move pointer to (%d, %d): %w", rootX, rootY, err)
+	}
+	return nil
+}
+
+func (s *x11Session) fakeButton(button byte, press bool) error {
+	eventType := byte(xproto.ButtonRelease)
+	if press {
+		eventType = xproto.ButtonPress
+	}
+	if err := xtest.FakeInputChecked(s.conn, eventType, button, 0, s.root, 0, 0, 0).Check(); err != nil {
+		return fmt.Errorf("send button %d event: %w", button, err)
+	}
+	return nil
+}
+
+func (s *x11Session) fakeButtonClicks(button byte, clicks int) error {
+	for i := 0; i < clicks; i++ {
+		if err := s.fakeButton(button, true); err != nil {
+			return err
+		}
+		if err := s.fakeButton(button, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func postMouseMoveEvent(ctx context.Context, x, y float64) error {
+	return withXTest(ctx, func(s *x11Session) error {
+		return s.fakePointerMove(x, y)
+	})
+}
+
+func postMouseDownEvent(ctx context.Context, x, y float64, button int) error {
+	return withXTest(ctx, func(s *x11Session) error {
+		if err := s.fakePointerMove(x, y); err != nil {
+			return err
+		}
+		return s.fakeButton(x11Button(button), true)
+	})
+}
+
+func postMouseUpEvent(ctx context.Context, x, y float64, button int) error {
+	return withXTest(ctx, func(s *x11Session) error {
+		if err := s.fakePointerMove(x, y); err != nil {
+			return err
+		}
+		return s.fakeButton(x11Button(button), false)
+	})
+}
+
+func postMouseClickEvent(ctx context.Context, x, y float64, button int, clicks int) error {
+	return withXTest(ctx, func(s *x11Session) error {
+		if err := s.fakePointerMove(x, y); err != nil {
+			return err
+		}
+		return s.fakeButtonClicks(x11Button(button), clicks)
+	})
+}
+
+// postScrollEvent moves the pointer to (x, y) and clicks the wheel buttons.
+// Positive deltas scroll down/right; each scrollPixelsPerStep pixels is one click.
+func postScrollEvent(ctx context.Context, x, y, deltaX, deltaY float64) error {
+	return withXTest(ctx, func(s *x11Session) error {
+		if err := s.fakePointerMove(x, y); err != nil {
+			return err
+		}
+		if err := s.fakeButtonClicks(scrollButton(deltaY, x11ButtonWheelUp, x11ButtonWheelDown), scrollSteps(deltaY)); err != nil {
+			return err
+		}
+		return s.fakeButtonClicks(scrollButton(deltaX, x11ButtonWheelLeft, x11ButtonWheelRight), scrollSteps(deltaX))
+	})
+}
+
+// x11Button maps buttonToInt values (0 left, 1 right, 2 middle) to X11 buttons.
+func x11Button(button int) byte {
+	switch button {
+	case 1:
+		return x11ButtonRight
+	case 2:
+		return x11ButtonMiddle
+	default:
+		return x11ButtonLeft
+	}
+}
+
+func scrollButton(delta float64, negative, positive byte) byte {
+	if delta < 0 {
+		return negative
+	}
+	return positive
+}
+
+// scrollSteps returns the wheel clicks for a pixel delta; any non-zero delta scrolls at least once.
+func scrollSteps(delta float64) int {
+	steps := int(math.Round(math.Abs(delta) / scrollPixelsPerStep))
+	if steps == 0 && delta != 0 {
+		return 1
+	}
+	return steps
+}
+
+func x11Coord(value float64) int16 {
+	rounded := floatToIntBounded(math.Round(value))
+	if rounded > math.MaxInt16 {
+		return math.MaxInt16
+	}
+	if rounded < math.MinInt16 {
+		return math.MinInt16
+	}
+	return int16(rounded)
+}
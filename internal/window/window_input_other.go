@@ -0,0 +1,43 @@
+//go:build !darwin && !linux
+
+package window
+
+import (
+	"context"
+	"fmt"
+)
+
+// Click returns an unsupported error on platforms without an input backend.
+func Click(context.Context, uint32, float64, float64, string, int) error {
+	return fmt.Errorf("%w", unsupportedPlatformError("click"))
+}
+
+// ClickAt returns an unsupported error on platforms without an input backend.
+func ClickAt(context.Context, float64, float64, string, int, string) error {
+	return fmt.Errorf("%w", unsupportedPlatformError("click_at"))
+}
+
+// MouseMove returns an unsupported error on platforms without an input backend.
+func MouseMove(context.Context, uint32, float64, float64) error {
+	return fmt.Errorf("%w", unsupportedPlatformError("mouse_move"))
+}
+
+// MouseDown returns an unsupported error on platforms without an input backend.
+func MouseDown(context.Context, uint32, float64, float64, string) error {
+	return fmt.Errorf("%w", unsupportedPlatformError("mouse_down"))
+}
+
+// MouseUp returns an unsupported error on platforms without an input backend.
+func MouseUp(context.Context, uint32, float64, float64, string) error {
+	return fmt.Errorf("%w", unsupportedPlatformError("mouse_up"))
+}
+
+// Drag returns an unsupported error on platforms without an input backend.
+func Drag(context.Context, uint32, float64, float64, float64, float64, string) error {
+	return fmt.Errorf("%w", unsupportedPlatformError("drag"))
+}
+
+// Scroll returns an unsupported error on platforms without an input backend.
+func Scroll(context.Context, uint32, float64, float64, float64, float64) error {
+	return fmt.Errorf("%w", unsupportedPlatformError("scroll"))
+}
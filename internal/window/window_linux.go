@@ -0,0 +1,112 @@
+//go:build linux
+
+package window
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/virtualdisplay"
+)
+
+const missingDisplayMessage = "window automation on Linux requires an X11 display; set DISPLAY or call start_virtual_display"
+
+// SupportsWindowTools reports whether an X11 display is configured or Xvfb can start one.
+func SupportsWindowTools() bool {
+	return os.Getenv("DISPLAY") != "" || virtualdisplay.Available()
+}
+
+// UnsupportedWindowToolsReason returns the human-readable reason automation features are unavailable.
+func UnsupportedWindowToolsReason() string {
+	if SupportsWindowTools() {
+		return ""
+	}
+	return missingDisplayMessage
+}
+
+// ListWindows returns the visible application windows managed on the X display.
+func ListWindows(ctx context.Context) ([]Window, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("list windows: %w", err)
+	}
+	session, err := openX11(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer session.close()
+	return listWindows(session)
+}
+
+func listWindows(session *x11Session) ([]Window, error) {
+	ids, err := session.clientWindows()
+	if err != nil {
+		return nil, err
+	}
+
+	var windows []Window
+	for _, id := range ids {
+		if session.isShellWindow(id) {
+			continue
+		}
+		// Windows can disappear between listing and inspection; skip them.
+		win, err := session.describeWindow(id)
+		if err != nil {
+			continue
+		}
+		if !win.IsTiny() && !win.IsSystemWindow() && win.IsOnScreen {
+			windows = append(windows, *win)
+		}
+	}
+	return windows, nil
+}
+
+// FocusWindow activates and raises a window.
+func FocusWindow(ctx context.Context, windowID uint32) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("focus window: %w", err)
+	}
+	session, err := openX11(ctx)
+	if err != nil {
+		return err
+	}
+	defer session.close()
+
+	target, err := findListedWindow(session, windowID)
+	if err != nil {
+		return err
+	}
+	return session.activate(xproto.Window(target.WindowID))
+}
+
+func findWindowByID(ctx context.Context, windowID uint32) (*Window, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("find window: %w", err)
+	}
+	session, err := openX11(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer session.close()
+	return findListedWindow(session, windowID)
+}
+
+func findListedWindow(session *x11Session, windowID uint32) (*Window, error) {
+	windows, err := listWindows(session)
+	if err != nil {
+		return nil, fmt.Errorf("list windows: %w", err)
+	}
+	for i := range windows {
+		if windows[i].WindowID == windowID {
+			return &windows[i], nil
+		}
+	}
+	return nil, fmt.Errorf("window %d not found", windowID)
+}
+
+// scaleAtPoint is always 1 on X11: window geometry is reported in device pixels.
+func scaleAtPoint(_, _ float64) float64 {
+	return 1.0
+}
@@ -0,0 +1,89 @@
+//go:build !darwin && !linux
+
+package window
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/imgencode"
+)
+
+const unsupportedWindowToolsMessage = "window automation is only supported on macOS (darwin) and Linux (X11)"
+
+// SupportsWindowTools reports whether this platform supports window-level automation features.
+func SupportsWindowTools() bool {
+	return false
+}
+
+// UnsupportedWindowToolsReason returns the platform-specific reason window automation is unavailable.
+func UnsupportedWindowToolsReason() string {
+	return unsupportedWindowToolsMessage
+}
+
+// ListWindows returns an error on unsupported platforms.
+func ListWindows(context.Context) ([]Window, error) {
+	return nil, errors.New(unsupportedWindowToolsMessage)
+}
+
+// FocusWindow returns an error on unsupported platforms.
+func FocusWindow(ctx context.Context, _ uint32) error {
+	return fmt.Errorf("%w", unsupportedPlatformError("focus_window"))
+}
+
+// TakeWindowScreenshot returns an unsupported error on unsupported platforms.
+func TakeWindowScreenshot(context.Context, uint32, imgencode.Options) ([]byte, *ScreenshotMetadata, error) {
+	return nil, nil, errors.New(unsupportedWindowToolsMessage)
+}
+
+// TakeWindowScreenshotImage returns an unsupported error on unsupported platforms.
+func TakeWindowScreenshotImage(context.Context, uint32) (image.Image, *ScreenshotMetadata, error) {
+	return nil, nil, errors.New(unsupportedWindowToolsMessage)
+}
+
+// TakeWindowScreenshotPNG returns an unsupported error on unsupported platforms.
+func TakeWindowScreenshotPNG(context.Context, uint32) ([]byte, *ScreenshotMetadata, error) {
+	return nil, nil, errors.New(unsupportedWindowToolsMessage)
+}
+
+// TakeRegionScreenshot returns an unsupported error on unsupported platforms.
+func TakeRegionScreenshot(context.Context, float64, float64, float64, float64, string, imgencode.Options) ([]byte, *RegionMetadata, error) {
+	return nil, nil, errors.New(unsupportedWindowToolsMessage)
+}
+
+// TakeRegionScreenshotImage returns an unsupported error on unsupported platforms.
+func TakeRegionScreenshotImage(context.Context, float64, float64, float64, float64, string) (image.Image, *RegionMetadata, error) {
+	return nil, nil, errors.New(unsupportedWindowToolsMessage)
+}
+
+// TakeRegionScreenshotPNG returns an unsupported error on unsupported platforms.
+func TakeRegionScreenshotPNG(context.Context, float64, float64, float64, float64, string) ([]byte, *RegionMetadata, error) {
+	return nil, nil, errors.New(unsupportedWindowToolsMessage)
+}
+
+// DisplayScale returns 1: the screenshot backend reports pixels on these platforms.
+func DisplayScale(_, _ float64) float64 {
+	return 1.0
+}
+
+// CheckPermissions returns unsupported state on unsupported platforms.
+func CheckPermissions() (screenRecording bool, accessibility bool) {
+	return false, false
+}
+
+// EnsureAutomationPermissions returns a platform-specific error on unsupported platforms.
+func EnsureAutomationPermissions(_ context.Context, toolName string, _ Requirement) error {
+	return fmt.Errorf("%w", unsupportedPlatformError(toolName))
+}
+
+// WaitForPixel is unsupported on unsupported platforms.
+func WaitForPixel(context.Context, uint32, float64, float64, [4]uint8, int, int, int) error {
+	return fmt.Errorf("%w", unsupportedPlatformError("wait_for_pixel"))
+}
+
+// WaitForRegionStable is unsupported on unsupported platforms.
+func WaitForRegionStable(context.Context, uint32, float64, float64, float64, float64, int, int, int) error {
+	return fmt.Errorf("%w", unsupportedPlatformError("wait_for_region_stable"))
+}
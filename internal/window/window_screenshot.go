@@ -1,5 +1,4 @@
-//go:build darwin
-// +build darwin
+//go:build darwin || linux
 
 package window
 
@@ -7,14 +6,13 @@ import (
 	"context"
 	"fmt"
 	"image"
-	"math"
 
 	"github.com/brainwhocodes/screenshot_mcp_server/internal/imgencode"
 	"github.com/brainwhocodes/screenshot_mcp_server/internal/screenshot"
 )
 
-// TakeWindowScreenshot captures a window and returns JPEG bytes with metadata
-// Uses full screen capture and crop to window bounds
+// TakeWindowScreenshot captures a window and returns JPEG bytes with metadata.
+// The window is captured directly when the platform allows it; see WindowCapturer.
 func TakeWindowScreenshot(ctx context.Context, windowID uint32, opts imgencode.Options) ([]byte, *ScreenshotMetadata, error) {
 	encode := func(img image.Image) ([]byte, error) {
 		return imgencode.EncodeJPEG(img, opts)
@@ -47,37 +45,22 @@ func captureWindowImageRaw(ctx context.Context, windowID uint32) (image.Image, *
 		return nil, nil, err
 	}
 
-	capturer := screenshot.NewCapturer()
-	fullImg, err := capturer.Capture(ctx)
+	capture, err := defaultWindowCapturer.CaptureWindow(ctx, *targetWindow)
 	if err != nil {
-		return nil, nil, fmt.Errorf("capture screen: %w", err)
+		return nil, nil, fmt.Errorf("capture window %d: %w", windowID, err)
 	}
 
-	scale := getScaleForWindow(targetWindow.Bounds)
-	cropRect := cropRectForWindow(targetWindow.Bounds, fullImg.Bounds(), scale)
-	croppedImg := cropImage(fullImg, cropRect)
-
-	return croppedImg, &ScreenshotMetadata{
-		WindowID:    windowID,
-		Bounds:      targetWindow.Bounds,
-		ImageWidth:  cropRect.Dx(),
-		ImageHeight: cropRect.Dy(),
-		Scale:       scale,
+	imgBounds := capture.Image.Bounds()
+	return capture.Image, &ScreenshotMetadata{
+		WindowID:      windowID,
+		Bounds:        targetWindow.Bounds,
+		ImageWidth:    imgBounds.Dx(),
+		ImageHeight:   imgBounds.Dy(),
+		Scale:         capture.Scale,
+		OcclusionFree: capture.OcclusionFree,
 	}, nil
 }
 
-// RegionMetadata contains metadata about a region screenshot
-type RegionMetadata struct {
-	X           float64 `json:"x"`
-	Y           float64 `json:"y"`
-	Width       float64 `json:"width"`
-	Height      float64 `json:"height"`
-	ImageWidth  int     `json:"image_width"`
-	ImageHeight int     `json:"image_height"`
-	Scale       float64 `json:"scale"`
-	CoordSpace  string  `json:"coord_space"`
-}
-
 // TakeRegionScreenshot captures a region of the screen and returns JPEG bytes with metadata.
 // coordSpace can be "points" (screen coordinates) or "pixels" (image coordinates).
 // When coordSpace is "points", the region is specified in screen points (Quartz coordinates).
@@ -105,7 +88,24 @@ func TakeRegionScreenshotPNG(ctx context.Context, x, y, width, height float64, c
 	return captureRegionScreenshot(ctx, x, y, width, height, coordSpace, encode)
 }
 
+// TakeRegionScreenshotImage captures a region and returns the image with metadata.
+func TakeRegionScreenshotImage(ctx context.Context, x, y, width, height float64, coordSpace string) (image.Image, *RegionMetadata, error) {
+	return captureRegionImage(ctx, x, y, width, height, coordSpace)
+}
+
 func captureRegionScreenshot(ctx context.Context, x, y, width, height float64, coordSpace string, encode func(image.Image) ([]byte, error)) ([]byte, *RegionMetadata, error) {
+	croppedImg, metadata, err := captureRegionImage(ctx, x, y, width, height, coordSpace)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := encode(croppedImg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("encode screenshot: %w", err)
+	}
+	return data, metadata, nil
+}
+
+func captureRegionImage(ctx context.Context, x, y, width, height float64, coordSpace string) (image.Image, *RegionMetadata, error) {
 	fullImg, err := screenshot.NewCapturer().Capture(ctx)
 	if err != nil {
 		return nil, nil, fmt.Errorf("capture screen: %w", err)
@@ -117,11 +117,6 @@ func captureRegionScreenshot(ctx context.Context, x, y, width, height float64, c
 	cropRect := cropRectForRegion(fullImg.Bounds(), x, y, width, height, scale, coordSpace)
 	croppedImg := cropImage(fullImg, cropRect)
 
-	data, err := encode(croppedImg)
-	if err != nil {
-		return nil, nil, fmt.Errorf("encode screenshot: %w", err)
-	}
-
 	metadata := &RegionMetadata{
 		X:           float64(cropRect.Min.X) / scale,
 		Y:           float64(cropRect.Min.Y) / scale,
@@ -132,23 +127,7 @@ func captureRegionScreenshot(ctx context.Context, x, y, width, height float64, c
 		Scale:       scale,
 		CoordSpace:  coordSpace,
 	}
-	return data, metadata, nil
-}
-
-func cropRectForRegion(imgBounds image.Rectangle, x, y, width, height, scale float64, coordSpace string) image.Rectangle {
-	var x1f, y1f, x2f, y2f float64
-	if coordSpace == "pixels" {
-		x1f, y1f, x2f, y2f = x, y, x+width, y+height
-	} else {
-		x1f, y1f, x2f, y2f = x*scale, y*scale, (x+width)*scale, (y+height)*scale
-	}
-
-	return clampRect(image.Rect(
-		floatToIntBounded(x1f),
-		floatToIntBounded(y1f),
-		floatToIntBounded(x2f),
-		floatToIntBounded(y2f),
-	), imgBounds)
+	return croppedImg, metadata, nil
 }
 
 func getScaleForWindow(bounds Bounds) float64 {
@@ -157,49 +136,8 @@ func getScaleForWindow(bounds Bounds) float64 {
 	return scaleAtPoint(centerX, centerY)
 }
 
-func cropRectForWindow(bounds Bounds, imgBounds image.Rectangle, scale float64) image.Rectangle {
-	x1 := floatToIntBounded(bounds.X * scale)
-	y1 := floatToIntBounded(bounds.Y * scale)
-	x2 := floatToIntBounded((bounds.X + bounds.Width) * scale)
-	y2 := floatToIntBounded((bounds.Y + bounds.Height) * scale)
-	return clampRect(image.Rect(x1, y1, x2, y2), imgBounds)
-}
-
-func floatToIntBounded(value float64) int {
-	if math.IsNaN(value) || math.IsInf(value, 0) {
-		return 0
-	}
-
-	maxInt := float64(int(^uint(0) >> 1))
-	minInt := float64(-int(^uint(0)>>1) - 1)
-	if value > maxInt {
-		return int(^uint(0) >> 1)
-	}
-	if value < minInt {
-		return -int(^uint(0)>>1) - 1
-	}
-	return int(value)
-}
-
-func clampRect(rect, bounds image.Rectangle) image.Rectangle {
-	x1, y1, x2, y2 := rect.Min.X, rect.Min.Y, rect.Max.X, rect.Max.Y
-	if x1 < bounds.Min.X {
-		x1 = bounds.Min.X
-	}
-	if y1 < bounds.Min.Y {
-		y1 = bounds.Min.Y
-	}
-	if x2 > bounds.Max.X {
-		x2 = bounds.Max.X
-	}
-	if y2 > bounds.Max.Y {
-		y2 = bounds.Max.Y
-	}
-	return image.Rect(x1, y1, x2, y2)
-}
-
-func cropImage(img image.Image, rect image.Rectangle) image.Image {
-	return img.(interface {
-		SubImage(r image.Rectangle) image.Image
-	}).SubImage(rect)
+// DisplayScale returns the backing scale factor of the display containing the
+// screen point (x, y): 2 on Retina displays, 1 on X11.
+func DisplayScale(x, y float64) float64 {
+	return scaleAtPoint(x, y)
 }
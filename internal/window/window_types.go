@@ -0,0 +1,71 @@
+// Package window provides desktop window discovery, capture and input helpers used by MCP tools.
+// macOS uses CoreGraphics; Linux uses X11 with EWMH hints.
+package window
+
+// Window represents a top-level desktop window.
+type Window struct {
+	WindowID   uint32 `json:"window_id"`
+	OwnerName  string `json:"owner_name"`
+	PID        int32  `json:"pid"`
+	Title      string `json:"title"`
+	Bounds     Bounds `json:"bounds"`
+	IsOnScreen bool   `json:"is_on_screen"`
+}
+
+// Bounds represents window bounds in screen coordinates (points on macOS, pixels on X11).
+type Bounds struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// ScreenshotMetadata contains metadata about a window screenshot.
+type ScreenshotMetadata struct {
+	WindowID    uint32  `json:"window_id"`
+	Bounds      Bounds  `json:"bounds"`
+	ImageWidth  int     `json:"image_width"`
+	ImageHeight int     `json:"image_height"`
+	Scale       float64 `json:"scale"`
+	// OcclusionFree is true when the image was read from the window itself
+	// rather than cropped from the screen, so overlapping windows are not visible.
+	OcclusionFree bool `json:"occlusion_free"`
+}
+
+// RegionMetadata contains metadata about a region screenshot.
+type RegionMetadata struct {
+	X           float64 `json:"x"`
+	Y           float64 `json:"y"`
+	Width       float64 `json:"width"`
+	Height      float64 `json:"height"`
+	ImageWidth  int     `json:"image_width"`
+	ImageHeight int     `json:"image_height"`
+	Scale       float64 `json:"scale"`
+	CoordSpace  string  `json:"coord_space"`
+}
+
+// IsTiny returns true if window is smaller than 50x50.
+func (w *Window) IsTiny() bool {
+	return w.Bounds.Width < 50 || w.Bounds.Height < 50
+}
+
+// IsSystemWindow returns true if this looks like a system overlay window.
+func (w *Window) IsSystemWindow() bool {
+	// Filter out known system/window server windows
+	systemOwners := map[string]bool{
+		"Window Server":  true,
+		"SystemUIServer": true,
+		"Dock":           true,
+		"loginwindow":    true,
+		"coreauthd":      true,
+		"AppleSpell":     true,
+		"Finder":         false, // Keep Finder
+		"":               true,
+	}
+
+	if isSystem, exists := systemOwners[w.OwnerName]; exists {
+		return isSystem
+	}
+
+	return false
+}
@@ -0,0 +1,236 @@
+//go:build linux
+
+package window
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/brainwhocodes/screenshot_mcp_server/internal/virtualdisplay"
+)
+
+// x11Session is one X connection plus the atoms interned on it.
+type x11Session struct {
+	conn  *xgb.Conn
+	root  xproto.Window
+	atoms map[string]xproto.Atom
+}
+
+// openX11 connects to the display ctx targets: a session's virtual display or DISPLAY.
+func openX11(ctx context.Context) (*x11Session, error) {
+	display := virtualdisplay.XDisplay(ctx)
+	if display == "" {
+		return nil, errors.New(missingDisplayMessage)
+	}
+	conn, err := xgb.NewConnDisplay(display)
+	if err != nil {
+		return nil, fmt.Errorf("connect to X display %q: %w", display, err)
+	}
+	return &x11Session{
+		conn:  conn,
+		root:  xproto.Setup(conn).DefaultScreen(conn).Root,
+		atoms: make(map[string]xproto.Atom),
+	}, nil
+}
+
+func (s *x11Session) close() {
+	s.conn.Close()
+}
+
+func (s *x11Session) atom(name string) (xproto.Atom, error) {
+	if atom, ok := s.atoms[name]; ok {
+		return atom, nil
+	}
+	reply, err := xproto.InternAtom(s.conn, false, uint16(len(name)), name).Reply()
+	if err != nil {
+		return 0, fmt.Errorf("intern atom %s: %w", name, err)
+	}
+	s.atoms[name] = reply.Atom
+	return reply.Atom, nil
+}
+
+// property returns the raw value of a window property, or nil when it is not set.
+func (s *x11Session) property(win xproto.Window, name string) (*xproto.GetPropertyReply, error) {
+	atom, err := s.atom(name)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := xproto.GetProperty(s.conn, false, win, atom, xproto.GetPropertyTypeAny, 0, 1<<16).Reply()
+	if err != nil {
+		return nil, fmt.Errorf("get property %s of window %d: %w", name, win, err)
+	}
+	if reply.Format == 0 || reply.ValueLen == 0 {
+		return nil, nil
+	}
+	return reply, nil
+}
+
+func (s *x11Session) stringProperty(win xproto.Window, name string) string {
+	reply, err := s.property(win, name)
+	if err != nil || reply == nil || reply.Format != 8 {
+		return ""
+	}
+	return string(reply.Value[:reply.ValueLen])
+}
+
+func (s *x11Session) uint32Property(win xproto.Window, name string) []uint32 {
+	reply, err := s.property(win, name)
+	if err != nil || reply == nil || reply.Format != 32 {
+		return nil
+	}
+	values := make([]uint32, reply.ValueLen)
+	for i := range values {
+		values[i] = xgb.Get32(reply.Value[i*4:])
+	}
+	return values
+}
+
+// supports reports whether the window manager advertises an EWMH hint in _NET_SUPPORTED.
+func (s *x11Session) supports(name string) bool {
+	atom, err := s.atom(name)
+	if err != nil {
+		return false
+	}
+	for _, supported := range s.uint32Property(s.root, "_NET_SUPPORTED") {
+		if xproto.Atom(supported) == atom {
+			return true
+		}
+	}
+	return false
+}
+
+// clientWindows returns the managed top-level windows. Without an EWMH window
+// manager (a bare Xvfb, for example) it falls back to mapped children of the root.
+func (s *x11Session) clientWindows() ([]xproto.Window, error) {
+	if ids := s.uint32Property(s.root, "_NET_CLIENT_LIST"); len(ids) > 0 {
+		windows := make([]xproto.Window, len(ids))
+		for i, id := range ids {
+			windows[i] = xproto.Window(id)
+		}
+		return windows, nil
+	}
+
+	tree, err := xproto.QueryTree(s.conn, s.root).Reply()
+	if err != nil {
+		return nil, fmt.Errorf("query window tree: %w", err)
+	}
+	var windows []xproto.Window
+	for _, child := range tree.Children {
+		attrs, err := xproto.GetWindowAttributes(s.conn, child).Reply()
+		if err != nil || attrs.OverrideRedirect || attrs.MapState != xproto.MapStateViewable {
+			continue
+		}
+		windows = append(windows, child)
+	}
+	return windows, nil
+}
+
+// isShellWindow reports docks, panels and desktop backgrounds, which are not application windows.
+func (s *x11Session) isShellWindow(win xproto.Window) bool {
+	for _, typeName := range []string{"_NET_WM_WINDOW_TYPE_DOCK", "_NET_WM_WINDOW_TYPE_DESKTOP"} {
+		typeAtom, err := s.atom(typeName)
+		if err != nil {
+			continue
+		}
+		for _, windowType := range s.uint32Property(win, "_NET_WM_WINDOW_TYPE") {
+			if xproto.Atom(windowType) == typeAtom {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *x11Session) describeWindow(win xproto.Window) (*Window, error) {
+	geometry, err := xproto.GetGeometry(s.conn, xproto.Drawable(win)).Reply()
+	if err != nil {
+		return nil, fmt.Errorf("get geometry of window %d: %w", win, err)
+	}
+	origin, err := xproto.TranslateCoordinates(s.conn, win, s.root, 0, 0).Reply()
+	if err != nil {
+		return nil, fmt.Errorf("translate coordinates of window %d: %w", win, err)
+	}
+	attrs, err := xproto.GetWindowAttributes(s.conn, win).Reply()
+	if err != nil {
+		return nil, fmt.Errorf("get attributes of window %d: %w", win, err)
+	}
+
+	result := &Window{
+		WindowID: uint32(win),
+		Title:    s.stringProperty(win, "_NET_WM_NAME"),
+		Bounds: Bounds{
+			X:      float64(origin.DstX),
+			Y:      float64(origin.DstY),
+			Width:  float64(geometry.Width),
+			Height: float64(geometry.Height),
+		},
+		IsOnScreen: attrs.MapState == xproto.MapStateViewable,
+	}
+	if result.Title == "" {
+		result.Title = s.stringProperty(win, "WM_NAME")
+	}
+	if pid := s.uint32Property(win, "_NET_WM_PID"); len(pid) > 0 {
+		result.PID = int32(pid[0])
+	}
+	result.OwnerName = wmClassName(s.stringProperty(win, "WM_CLASS"))
+	if result.OwnerName == "" && result.PID > 0 {
+		result.OwnerName = processName(result.PID)
+	}
+	return result, nil
+}
+
+// activate asks the window manager to focus and raise win, or does it directly
+// when no EWMH window manager is running.
+func (s *x11Session) activate(win xproto.Window) error {
+	if s.supports("_NET_ACTIVE_WINDOW") {
+		activeAtom, err := s.atom("_NET_ACTIVE_WINDOW")
+		if err != nil {
+			return err
+		}
+		// Source indication 2 marks the request as coming from a pager, which
+		// window managers honor without focus-stealing prevention.
+		event := xproto.ClientMessageEvent{
+			Format: 32,
+			Window: win,
+			Type:   activeAtom,
+			Data:   xproto.ClientMessageDataUnionData32New([]uint32{2, xproto.TimeCurrentTime, 0, 0, 0}),
+		}
+		mask := uint32(xproto.EventMaskSubstructureRedirect | xproto.EventMaskSubstructureNotify)
+		if err := xproto.SendEventChecked(s.conn, false, s.root, mask, string(event.Bytes())).Check(); err != nil {
+			return fmt.Errorf("request activation of window %d: %w", win, err)
+		}
+		return nil
+	}
+
+	if err := xproto.ConfigureWindowChecked(s.conn, win, xproto.ConfigWindowStackMode, []uint32{xproto.StackModeAbove}).Check(); err != nil {
+		return fmt.Errorf("raise window %d: %w", win, err)
+	}
+	if err := xproto.SetInputFocusChecked(s.conn, xproto.InputFocusParent, win, xproto.TimeCurrentTime).Check(); err != nil {
+		return fmt.Errorf("set input focus to window %d: %w", win, err)
+	}
+	return nil
+}
+
+// wmClassName returns the class part of WM_CLASS ("instance\x00Class\x00").
+func wmClassName(wmClass string) string {
+	parts := strings.Split(strings.TrimRight(wmClass, "\x00"), "\x00")
+	return parts[len(parts)-1]
+}
+
+func processName(pid int32) string {
+	// Accepted G304 suppression: the path is built from a numeric PID under /proc.
+	// #nosec G304
+	comm, err := os.ReadFile("/proc/" + strconv.Itoa(int(pid)) + "/comm")
+	if err != nil {
+		return ""
+	}
+	return string(bytes.TrimSpace(comm))
+}
@@ -0,0 +1,54 @@
+package window
+
+import "testing"
+
+func TestWMClassName(t *testing.T) {
+	tests := []struct {
+		wmClass string
+		want    string
+	}{
+		{"xterm\x00XTerm\x00", "XTerm"},
+		{"navigator\x00firefox", "firefox"},
+		{"single", "single"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := wmClassName(tt.wmClass); got != tt.want {
+			t.Errorf("wmClassName(%q) = %q, want %q", tt.wmClass, got, tt.want)
+		}
+	}
+}
+
+func TestScrollSteps(t *testing.T) {
+	tests := []struct {
+		delta float64
+		want  int
+	}{
+		{0, 0},
+		{5, 1},
+		{-5, 1},
+		{40, 1},
+		{100, 3},
+		{-120, 3},
+	}
+	for _, tt := range tests {
+		if got := scrollSteps(tt.delta); got != tt.want {
+			t.Errorf("scrollSteps(%v) = %d, want %d", tt.delta, got, tt.want)
+		}
+	}
+	if scrollButton(-1, x11ButtonWheelUp, x11ButtonWheelDown) != x11ButtonWheelUp ||
+		scrollButton(1, x11ButtonWheelUp, x11ButtonWheelDown) != x11ButtonWheelDown {
+		t.Fatal("scrollButton should map negative deltas to up/left and positive to down/right")
+	}
+}
+
+func TestX11ButtonAndCoord(t *testing.T) {
+	if x11Button(buttonToInt("left")) != x11ButtonLeft ||
+		x11Button(buttonToInt("right")) != x11ButtonRight ||
+		x11Button(buttonToInt("middle")) != x11ButtonMiddle {
+		t.Fatal("unexpected button mapping")
+	}
+	if x11Coord(10.6) != 11 || x11Coord(1e9) != 32767 || x11Coord(-1e9) != -32768 {
+		t.Fatal("unexpected coordinate conversion")
+	}
+}